@@ -432,7 +432,7 @@ type SignInResponseDataSession struct {
 	Server           string
 	FilesServerUrl   string `json:"filesServerUrl"`
 	Token            string
-	MasterKey        string
+	MasterKey        crypto.SecretKey
 	// ImporterItemsKeys is the key used to encrypt exported items and set during import only
 	KeyParams         KeyParams `json:"keyParams"`
 	AccessToken       string    `json:"access_token"`
@@ -499,6 +499,8 @@ type SignInInput struct {
 	Password   string
 	APIServer  string
 	Debug      bool
+	// Crypto derives the account keys, defaulting to the Provider registered for the account's protocol version
+	Crypto crypto.Provider
 }
 
 type SignInOutput struct {
@@ -590,15 +592,28 @@ func SignIn(input SignInInput) (output SignInOutput, err error) {
 	genEncPasswordInput.PasswordNonce = getAuthParamsOutput.PasswordNonce
 	genEncPasswordInput.Debug = input.Debug
 
-	var _, sp string
+	var mk, sp crypto.SecretKey
 
-	var mk string
+	p := input.Crypto
+	if p == nil {
+		p = crypto.DefaultProvider
 
-	mk, sp, err = crypto.GenerateMasterKeyAndServerPassword004(genEncPasswordInput)
+		if getAuthParamsOutput.Version != "" {
+			p, err = crypto.GetProvider(getAuthParamsOutput.Version)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	mk, sp, err = p.DeriveKeys(genEncPasswordInput)
 	if err != nil {
 		return
 	}
 
+	// the server password is only needed for the token request
+	defer sp.Destroy()
+
 	// request token
 	var tokenResp signInResponse
 
@@ -606,7 +621,7 @@ func SignIn(input SignInInput) (output SignInOutput, err error) {
 	tokenResp, requestTokenFailure, err = requestToken(signInInput{
 		client:       input.HTTPClient,
 		email:        input.Email,
-		encPassword:  sp,
+		encPassword:  sp.Hex(),
 		tokenName:    input.TokenName,
 		tokenValue:   input.TokenVal,
 		signInURL:    input.APIServer + common.SignInPath,
@@ -856,15 +871,22 @@ func (input RegisterInput) Register() (token string, err error) {
 		input.APIServer = common.APIServer
 	}
 
-	var pwNonce, serverPassword string
-	_, pwNonce, _, serverPassword, err = generateInitialKeysAndAuthParamsForUser(input.Email, input.Password)
+	var pwNonce string
+
+	var masterKey, serverPassword crypto.SecretKey
+
+	_, pwNonce, masterKey, serverPassword, err = generateInitialKeysAndAuthParamsForUser(input.Email, input.Password)
 	if err != nil {
 		return "", err
 	}
 
+	// the keys are only needed to register
+	masterKey.Destroy()
+	defer serverPassword.Destroy()
+
 	var req *retryablehttp.Request
 
-	reqBody := fmt.Sprintf(`{"email":"%s","identifier":"%s","password":"%s","pw_nonce":"%s","version":"%s","origination":"registration","created":"1608473387799","api":"%s"}`, input.Email, input.Email, serverPassword, pwNonce, common.DefaultSNVersion, common.APIVersion)
+	reqBody := fmt.Sprintf(`{"email":"%s","identifier":"%s","password":"%s","pw_nonce":"%s","version":"%s","origination":"registration","created":"1608473387799","api":"%s"}`, input.Email, input.Email, serverPassword.Hex(), pwNonce, common.DefaultSNVersion, common.APIVersion)
 
 	reqBodyBytes := []byte(reqBody)
 
@@ -972,7 +994,7 @@ func GenerateAuthData(ct, uuid string, kp KeyParams) string {
 	return string(b)
 }
 
-func generateInitialKeysAndAuthParamsForUser(email, password string) (pw, pwNonce, masterKey, serverPassword string, err error) {
+func generateInitialKeysAndAuthParamsForUser(email, password string) (pw, pwNonce string, masterKey, serverPassword crypto.SecretKey, err error) {
 	var genInput crypto.GenerateEncryptedPasswordInput
 	genInput.UserPassword = password
 	// genInput.Version = defaultSNVersion
@@ -982,7 +1004,7 @@ func generateInitialKeysAndAuthParamsForUser(email, password string) (pw, pwNonc
 	// generate salt seed (password nonce) using crypto-secure random seed
 	seed, err := generateCryptoSeed()
 	if err != nil {
-		return "", "", "", "", fmt.Errorf("failed to generate password nonce seed: %w", err)
+		return "", "", nil, nil, fmt.Errorf("failed to generate password nonce seed: %w", err)
 	}
 	rnd := rand.New(rand.NewSource(seed))
 
@@ -996,7 +1018,7 @@ func generateInitialKeysAndAuthParamsForUser(email, password string) (pw, pwNonc
 	genInput.PasswordNonce = string(b)
 	pwNonce = string(b)[:32]
 	// pw, _, _, err = generateEncryptedPasswordAndKeys(genInput)
-	masterKey, serverPassword, err = crypto.GenerateMasterKeyAndServerPassword004(crypto.GenerateEncryptedPasswordInput{
+	masterKey, serverPassword, err = crypto.DeriveKeys004(crypto.GenerateEncryptedPasswordInput{
 		UserPassword:  password,
 		Identifier:    email,
 		PasswordNonce: pwNonce,
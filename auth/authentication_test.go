@@ -87,7 +87,7 @@ func TestMain(m *testing.M) {
 			Server:            "",
 			FilesServerUrl:    "",
 			Token:             "",
-			MasterKey:         "",
+			MasterKey:         nil,
 			KeyParams:         out.KeyParams,
 			AccessToken:       "",
 			RefreshToken:      "",
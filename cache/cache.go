@@ -14,7 +14,9 @@ import (
 	"time"
 
 	"github.com/asdine/storm/v3"
+	sq "github.com/asdine/storm/v3/q"
 	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/crypto"
 	"github.com/jonhadfield/gosn-v2/items"
 	log "github.com/jonhadfield/gosn-v2/log"
 	"github.com/jonhadfield/gosn-v2/session"
@@ -40,6 +42,7 @@ const (
 	SyncErrorItemsKey
 	SyncErrorConflict
 	SyncErrorNetwork
+	SyncErrorReadOnly
 )
 
 // SyncError provides detailed error information
@@ -55,6 +58,10 @@ func (e *SyncError) Error() string {
 	return e.Message
 }
 
+func (e *SyncError) Unwrap() error {
+	return e.Original
+}
+
 // RateLimitBackoff tracks exponential backoff for rate limiting
 type RateLimitBackoff struct {
 	attempts    int64
@@ -95,10 +102,17 @@ type SyncToken struct {
 type SyncInput struct {
 	*Session
 	Close bool
+	// SearchIndex, if set, is updated along with its persisted entries with the notes changed by the sync.
+	// It should be loaded with LoadSearchIndex before the first sync.
+	SearchIndex *items.SearchIndex
+	// History limits the previous versions of items kept in the cache, with DefaultHistoryRetention used for any unset.
+	History HistoryRetention
 }
 
 type SyncOutput struct {
 	DB *storm.DB
+	// Quarantined lists items that could not be processed and were neither stored nor pushed
+	Quarantined items.Quarantine
 }
 
 type Items []Item
@@ -169,15 +183,31 @@ func (s *Session) gosn() *session.Session {
 		AccessExpiration:  s.AccessExpiration,
 		RefreshExpiration: s.RefreshExpiration,
 		ReadOnlyAccess:    s.ReadOnlyAccess,
+		ReadOnly:          s.ReadOnly,
 		PasswordNonce:     s.PasswordNonce,
 		Schemas:           s.Schemas,
+		Crypto:            s.Crypto,
 	}
 
 	return &gs
 }
 
 func (pi Items) ToItems(s *Session) (items.Items, error) {
+	its, _, err := pi.toItems(s, false)
+
+	return its, err
+}
+
+// ToItemsLenient converts the cache items to gosn items in the same way as ToItems, but rather than failing
+// when an item cannot be decrypted or parsed, it is left encrypted in the cache and reported in the Quarantine.
+// Items quarantined due to a missing items key can be retried with RetryQuarantined once the key is available.
+func (pi Items) ToItemsLenient(s *Session) (items.Items, items.Quarantine, error) {
+	return pi.toItems(s, true)
+}
+
+func (pi Items) toItems(s *Session, lenient bool) (items.Items, items.Quarantine, error) {
 	var its items.Items
+	var quarantined items.Quarantine
 	var err error
 	//log.DebugPrint(s.Debug, fmt.Sprintf("ToItems | Converting %d cache items to gosn items", len(pi)), common.MaxDebugChars)
 
@@ -225,22 +255,68 @@ func (pi Items) ToItems(s *Session) (items.Items, error) {
 
 	if eItems != nil {
 		if len(s.Session.ItemsKeys) == 0 {
-			panic("trying to convert cache items to items with no items keys")
+			return items.Items{}, nil, fmt.Errorf("ToItems | %w", items.ErrNoItemsKeys)
 		}
 
-		if s.Session.DefaultItemsKey.ItemsKey == "" {
-			panic("trying to convert cache items to items with no default items key")
+		if len(s.Session.DefaultItemsKey.ItemsKey) == 0 {
+			return items.Items{}, nil, fmt.Errorf("ToItems | %w: no default items key", items.ErrNoItemsKeys)
+		}
+
+		if lenient {
+			its, quarantined = eItems.DecryptAndParseLenient(s.Session)
+
+			return its, quarantined, nil
+		}
+
+		// skip corrupt items so the remainder can still be returned
+		eItems, quarantined = eItems.Quarantine()
+		for _, q := range quarantined {
+			log.DebugPrint(s.Debug, fmt.Sprintf("ToItems | skipping quarantined item: %s", q.Err), common.MaxDebugChars)
 		}
 
 		its, err = eItems.DecryptAndParse(s.Session)
 		if err != nil {
-			return items.Items{}, err
+			return items.Items{}, nil, err
 		}
 	}
 
 	// log.DebugPrint(s.Debug, fmt.Sprintf("ToItems took: %s", time.Since(start).String()), 50)
 
-	return its, nil
+	return its, quarantined, nil
+}
+
+// RetryQuarantined attempts to decrypt and parse quarantined items that were encrypted with an items key
+// that has since become available, e.g. following a sync. It returns the recovered items along with
+// those that remain quarantined.
+func RetryQuarantined(db *storm.DB, s *Session, q items.Quarantine) (recovered items.Items, remaining items.Quarantine, err error) {
+	retryable := q.Retryable(s.Session.ItemsKeys)
+	if len(retryable) == 0 {
+		return nil, q, nil
+	}
+
+	retry := make(map[string]bool, len(retryable))
+	for _, uuid := range retryable.UUIDs() {
+		retry[uuid] = true
+	}
+
+	for _, qi := range q {
+		if !retry[qi.UUID] {
+			remaining = append(remaining, qi)
+		}
+	}
+
+	var cItems Items
+
+	if err = db.Select(sq.In("UUID", retryable.UUIDs())).Find(&cItems); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		return nil, q, fmt.Errorf("RetryQuarantined | %w", err)
+	}
+
+	recovered, stillQuarantined, err := cItems.ToItemsLenient(s)
+	if err != nil {
+		return nil, q, fmt.Errorf("RetryQuarantined | %w", err)
+	}
+
+	return recovered, append(remaining, stillQuarantined...), nil
 }
 
 // func (s *Session) Export(path string) error {
@@ -308,8 +384,10 @@ func (s *Session) RemoveDB() {
 // 	return err
 // }
 
-func ToCacheItems(items items.EncryptedItems, clean bool) (pitems Items) {
-	for _, i := range items {
+// ToCacheItems converts encrypted items to cache items. Items that cannot be decrypted as they are
+// missing an ItemsKeyID are not converted and are returned in the Quarantine instead.
+func ToCacheItems(eItems items.EncryptedItems, clean bool) (pitems Items, q items.Quarantine) {
+	for _, i := range eItems {
 		var cItem Item
 		cItem.UUID = i.UUID
 		cItem.Content = i.Content
@@ -326,7 +404,9 @@ func ToCacheItems(items items.EncryptedItems, clean bool) (pitems Items) {
 		iik := ""
 
 		if !i.Deleted && i.ItemsKeyID == "" && !(i.ContentType == common.SNItemTypeItemsKey || strings.HasPrefix(i.ContentType, "SF")) {
-			panic(fmt.Sprintf("we've received %s %s from SN without ItemsKeyID", i.ContentType, i.UUID))
+			q.Add(i, fmt.Errorf("%w: %s %s is missing items key id", crypto.ErrMalformedPayload, i.ContentType, i.UUID))
+
+			continue
 		}
 
 		if i.ItemsKeyID != "" {
@@ -346,7 +426,7 @@ func ToCacheItems(items items.EncryptedItems, clean bool) (pitems Items) {
 		pitems = append(pitems, cItem)
 	}
 
-	return pitems
+	return pitems, q
 }
 
 // SaveNotes encrypts, converts to cache items, and then persists to db.
@@ -356,9 +436,7 @@ func SaveNotes(s *Session, db *storm.DB, notes items.Notes, close bool) error {
 		return err
 	}
 
-	cItems := ToCacheItems(eItems, false)
-
-	return SaveCacheItems(db, cItems, close)
+	return SaveEncryptedItems(db, eItems, close)
 }
 
 // SaveTags encrypts, converts to cache items, and then persists to db.
@@ -368,14 +446,16 @@ func SaveTags(db *storm.DB, s *Session, tags items.Tags, close bool) error {
 		return err
 	}
 
-	cItems := ToCacheItems(eItems, false)
-
-	return SaveCacheItems(db, cItems, close)
+	return SaveEncryptedItems(db, eItems, close)
 }
 
-// SaveEncryptedItems converts to cache items and persists to db.
-func SaveEncryptedItems(db *storm.DB, items items.EncryptedItems, close bool) error {
-	cItems := ToCacheItems(items, false)
+// SaveEncryptedItems converts to cache items and persists to db. An error is returned, and nothing
+// is saved, if any of the items would be quarantined by ToCacheItems.
+func SaveEncryptedItems(db *storm.DB, eItems items.EncryptedItems, close bool) error {
+	cItems, q := ToCacheItems(eItems, false)
+	if len(q) > 0 {
+		return fmt.Errorf("SaveEncryptedItems | %w", q[0].Err)
+	}
 
 	return SaveCacheItems(db, cItems, close)
 }
@@ -401,9 +481,7 @@ func SaveItems(s *Session, db *storm.DB, its items.Items, close bool) error {
 		eItems = append(eItems, eItem)
 	}
 
-	cItems := ToCacheItems(eItems, false)
-
-	return SaveCacheItems(db, cItems, close)
+	return SaveEncryptedItems(db, eItems, close)
 }
 
 // SaveCacheItems saves Cache Items to the provided database.
@@ -435,7 +513,10 @@ func SaveCacheItems(db *storm.DB, items Items, close bool) error {
 		sl := items[i:j]
 
 		for v := range sl {
-			err = tx.Save(&sl[v])
+			if err = saveHistory(tx, sl[v].UUID, &sl[v]); err == nil {
+				err = tx.Save(&sl[v])
+			}
+
 			if err != nil {
 				if rErr := tx.Rollback(); rErr != nil {
 					return fmt.Errorf("saveCacheItems | save error: %s | rollback error: %w",
@@ -494,7 +575,10 @@ func DeleteCacheItems(db *storm.DB, items Items, close bool) error {
 		sl := items[i:j]
 
 		for v := range sl {
-			err = tx.DeleteStruct(&sl[v])
+			if err = saveHistory(tx, sl[v].UUID, nil); err == nil {
+				err = tx.DeleteStruct(&sl[v])
+			}
+
 			if err != nil {
 				if strings.Contains(err.Error(), "not found") {
 					continue
@@ -713,6 +797,26 @@ func classifySyncError(err error) *SyncError {
 		return nil
 	}
 
+	// Read-only sessions cannot write so retrying will not help
+	if errors.Is(err, session.ErrReadOnlySession) {
+		return &SyncError{
+			Type:      SyncErrorReadOnly,
+			Original:  err,
+			Message:   fmt.Sprintf("Session is read-only - dirty items retained in cache: %v", err),
+			Retryable: false,
+		}
+	}
+
+	// Locked sessions have no keys until unlocked by the user
+	if errors.Is(err, session.ErrSessionLocked) {
+		return &SyncError{
+			Type:      SyncErrorAuthentication,
+			Original:  err,
+			Message:   "Session is locked - unlock with the account password before syncing",
+			Retryable: false,
+		}
+	}
+
 	errMsg := err.Error()
 	errLower := strings.ToLower(errMsg)
 
@@ -803,7 +907,7 @@ func validateSessionItemsKey(s *Session) error {
 		return errors.New("session.Session is nil")
 	}
 	// Check if DefaultItemsKey is zero value (empty struct)
-	if s.DefaultItemsKey.ItemsKey == "" {
+	if len(s.DefaultItemsKey.ItemsKey) == 0 {
 		return &SyncError{
 			Type:      SyncErrorItemsKey,
 			Original:  errors.New("no default ItemsKey available"),
@@ -811,7 +915,7 @@ func validateSessionItemsKey(s *Session) error {
 			Retryable: false,
 		}
 	}
-	if s.DefaultItemsKey.ItemsKey == "" {
+	if len(s.DefaultItemsKey.ItemsKey) == 0 {
 		return &SyncError{
 			Type:      SyncErrorItemsKey,
 			Original:  errors.New("empty default ItemsKey"),
@@ -1025,6 +1129,10 @@ func Sync(si SyncInput) (so SyncOutput, err error) {
 		}
 	}
 
+	if si.Session.Session.IsLocked() {
+		return so, session.ErrSessionLocked
+	}
+
 	// Prevent rapid consecutive syncs
 	enforceMinimumSyncDelay()
 
@@ -1169,7 +1277,7 @@ func Sync(si SyncInput) (so SyncOutput, err error) {
 	}
 
 	// if session doesn't contain items keys then remove sync token so we bring all items in
-	if si.Session.DefaultItemsKey.ItemsKey == "" {
+	if len(si.Session.DefaultItemsKey.ItemsKey) == 0 {
 		fmt.Printf("Sync | no default items key in session so resetting sync token\n")
 		log.DebugPrint(si.Session.Debug, "Sync | no default items key in session so resetting sync token", common.MaxDebugChars)
 		syncToken = ""
@@ -1189,7 +1297,14 @@ func Sync(si SyncInput) (so SyncOutput, err error) {
 				continue // Skip this item entirely
 			}
 			if d.Content == "" {
-				panic("dirty items key is empty")
+				log.DebugPrint(si.Session.Debug, fmt.Sprintf("Sync | WARNING: Quarantining empty SN|ItemsKey %s from cache", d.UUID), common.MaxDebugChars)
+				so.Quarantined = append(so.Quarantined, items.QuarantinedItem{
+					UUID:        d.UUID,
+					ContentType: d.ContentType,
+					Err:         fmt.Errorf("%w: dirty items key %s is empty", crypto.ErrMalformedPayload, d.UUID),
+				})
+
+				continue // Skip this item entirely
 			}
 			// Skip any attempt to modify existing ItemsKeys
 			if d.UUID != "" && d.UpdatedAt != "" {
@@ -1221,6 +1336,24 @@ func Sync(si SyncInput) (so SyncOutput, err error) {
 		// fmt.Printf("dirty item: %+v\n", dirtyItemsToPush)
 	}
 
+	// a read-only session cannot push changes, so hold dirty items in the cache and only retrieve
+	var readOnlyErr error
+
+	heldItems := make(map[string]struct{})
+
+	if err = si.Session.CheckWritable(len(dirtyItemsToPush)); err != nil {
+		log.DebugPrint(si.Session.Debug, fmt.Sprintf("Sync | %s", err), common.MaxDebugChars)
+
+		readOnlyErr = err
+		err = nil
+
+		for _, d := range dirtyItemsToPush {
+			heldItems[d.UUID] = struct{}{}
+		}
+
+		dirtyItemsToPush = dirtyItemsToPush[:0]
+	}
+
 	// TODO: add all the items keys in the session to SN (dupes will be handled)?
 
 	// Optimization: Skip API call if no changes and recent sync token exists
@@ -1233,7 +1366,7 @@ func Sync(si SyncInput) (so SyncOutput, err error) {
 					fmt.Sprintf("Sync | Skipping API call - no changes and recent sync (age: %v)", tokenAge),
 					common.MaxDebugChars)
 				so.DB = db
-				return so, nil
+				return so, readOnlyErr
 			}
 		}
 	}
@@ -1259,7 +1392,9 @@ func Sync(si SyncInput) (so SyncOutput, err error) {
 	}
 
 	if !gSI.Session.Valid() {
-		panic("invalid Session")
+		so.DB = db
+
+		return so, errors.New("Sync | invalid Session")
 	}
 
 	var gSO items.SyncOutput
@@ -1309,7 +1444,23 @@ func Sync(si SyncInput) (so SyncOutput, err error) {
 	log.DebugPrint(si.Session.Debug, fmt.Sprintf("Sync | initial sync retrieved sync token %s from SN", gSO.SyncToken), common.MaxDebugChars)
 
 	if len(gSO.Conflicts) > 0 {
-		panic("conflicts should have been resolved by gosn sync")
+		so.DB = db
+
+		return so, fmt.Errorf("Sync | %d conflicts were not resolved by gosn sync", len(gSO.Conflicts))
+	}
+
+	// corrupt items returned by the server are excluded from gSO.Items so are not stored
+	so.Quarantined = append(so.Quarantined, gSO.Quarantined...)
+
+	// items rejected by the server due to read-only access remain dirty in the cache
+	if len(gSO.ReadOnly) > 0 {
+		for _, ro := range gSO.ReadOnly {
+			heldItems[ro.UnsavedItem.UUID] = struct{}{}
+		}
+
+		readOnlyErr = &session.ReadOnlyError{Items: len(gSO.ReadOnly)}
+
+		log.DebugPrint(si.Session.Debug, fmt.Sprintf("Sync | %s", readOnlyErr), common.MaxDebugChars)
 	}
 
 	// check items are valid
@@ -1415,10 +1566,18 @@ func Sync(si SyncInput) (so SyncOutput, err error) {
 	}
 
 	// unset dirty flag and date on anything that has now been synced back to SN
-	if len(dirty) > 0 {
-		log.DebugPrint(si.Debug, fmt.Sprintf("Sync | removing dirty flag on %d db items now synced back to SN", len(dirty)), common.MaxDebugChars)
+	var synced Items
 
-		if err = CleanCacheItems(db, dirty, false); err != nil {
+	for _, d := range dirty {
+		if _, held := heldItems[d.UUID]; !held {
+			synced = append(synced, d)
+		}
+	}
+
+	if len(synced) > 0 {
+		log.DebugPrint(si.Debug, fmt.Sprintf("Sync | removing dirty flag on %d db items now synced back to SN", len(synced)), common.MaxDebugChars)
+
+		if err = CleanCacheItems(db, synced, false); err != nil {
 			return
 		}
 	}
@@ -1448,6 +1607,13 @@ func Sync(si SyncInput) (so SyncOutput, err error) {
 			continue // Skip deletion of UserPreferences
 		}
 
+		// don't replace local changes that could not be pushed
+		if _, held := heldItems[i.UUID]; held {
+			log.DebugPrint(si.Debug, fmt.Sprintf("Sync | retaining dirty %s %s in db in place of SN version", i.ContentType, i.UUID), common.MaxDebugChars)
+
+			continue
+		}
+
 		// if the item has been deleted in SN, then delete from db
 		if i.Deleted {
 			log.DebugPrint(si.Debug, fmt.Sprintf("Sync | adding uuid for deletion %s %s and skipping addition to db", i.ContentType, i.UUID), common.MaxDebugChars)
@@ -1485,6 +1651,41 @@ func Sync(si SyncInput) (so SyncOutput, err error) {
 		return
 	}
 
+	if si.SearchIndex != nil {
+		var changed []string
+
+		for _, changes := range []Items{savedItems, itemsToDeleteFromDB, newItems, itemsToDelete} {
+			for _, x := range changes {
+				if x.ContentType == common.SNItemTypeNote {
+					changed = append(changed, x.UUID)
+				}
+			}
+		}
+
+		log.DebugPrint(si.Debug, fmt.Sprintf("Sync | updating search index with %d changed notes", len(changed)), common.MaxDebugChars)
+
+		if err = UpdateSearchIndex(db, si.Session, si.SearchIndex, changed...); err != nil {
+			err = fmt.Errorf("Sync | updating search index: %w", err)
+
+			return
+		}
+	}
+
+	// only the items changed by this sync can have new versions in their history
+	var changedUUIDs []string
+
+	for _, changes := range []Items{savedItems, itemsToDeleteFromDB, newItems, itemsToDelete} {
+		for _, x := range changes {
+			changedUUIDs = append(changedUUIDs, x.UUID)
+		}
+	}
+
+	if err = pruneSyncedHistory(db, si.History, changedUUIDs, time.Now()); err != nil {
+		err = fmt.Errorf("Sync | %w", err)
+
+		return
+	}
+
 	log.DebugPrint(si.Debug, "Sync | retrieving all items from db in preparation for decryption", common.MaxDebugChars)
 
 	err = db.All(&all)
@@ -1519,6 +1720,10 @@ func Sync(si SyncInput) (so SyncOutput, err error) {
 
 	so.DB = db
 
+	if err == nil {
+		err = readOnlyErr
+	}
+
 	return
 }
 
@@ -1662,8 +1867,15 @@ func GenCacheDBPath(session Session, dir, appName string) (string, error) {
 		return "", fmt.Errorf("failed to make cache directory: %s", dir)
 	}
 
+	// the digest is of the hex encoded master key so that existing cache paths are unchanged
+	mk := session.MasterKey.AppendHex(nil)
+	defer clear(mk)
+
 	h := sha256.New()
-	h.Write([]byte(session.MasterKey[:2] + session.MasterKey[len(session.MasterKey)-2:] + session.MasterKey + appName))
+	h.Write(mk[:2])
+	h.Write(mk[len(mk)-2:])
+	h.Write(mk)
+	h.Write([]byte(appName))
 	hexedDigest := hex.EncodeToString(h.Sum(nil))[:8]
 
 	return filepath.Join(dir, appName+"-"+hexedDigest+".db"), nil
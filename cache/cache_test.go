@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"github.com/asdine/storm/v3"
 	"github.com/jonhadfield/gosn-v2/auth"
 	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/crypto"
 	"github.com/jonhadfield/gosn-v2/items"
 	"github.com/jonhadfield/gosn-v2/session"
 	"github.com/stretchr/testify/require"
@@ -67,7 +69,7 @@ func testSetup() {
 		panic(err)
 	}
 
-	if testSession.Session.DefaultItemsKey.ItemsKey == "" {
+	if len(testSession.Session.DefaultItemsKey.ItemsKey) == 0 {
 		// Don't panic - allow tests to run with warnings
 		// Tests that require ItemsKey will skip or handle gracefully
 	}
@@ -80,6 +82,26 @@ func TestMain(m *testing.M) {
 
 // Create 200 notes in and sync to SN
 // Bring them into Cache and check all exist.
+func TestGenCacheDBPathUnchangedBySecretKey(t *testing.T) {
+	mk, err := crypto.NewSecretKey("5319f9c148ee3dbe78fc149e8643775242d7e83216060ee5e228ab2ec3d88a76")
+	require.NoError(t, err)
+
+	s := Session{Session: &session.Session{
+		MasterKey:         mk,
+		AccessToken:       "access",
+		RefreshToken:      "refresh",
+		AccessExpiration:  1,
+		RefreshExpiration: 1,
+	}}
+
+	dir := t.TempDir()
+
+	// the path must match that generated when the master key was held as a hex string
+	path, err := GenCacheDBPath(s, dir, common.LibName)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "gosn-v2-bbf15dd4.db"), path)
+}
+
 func TestSync20Notes(t *testing.T) {
 	if os.Getenv(common.EnvSkipSessionTests) != "" {
 		t.Skip("skipping session test")
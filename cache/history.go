@@ -0,0 +1,266 @@
+package cache
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/asdine/storm/v3"
+)
+
+const (
+	historyBucket = "history"
+	// historyPrunedBucket holds when the history of every item was last pruned
+	historyPrunedBucket = "historyPruned"
+	historyPrunedKey    = "last"
+	// historyPruneInterval is the most time between pruning the history of every item during Sync
+	historyPruneInterval = 24 * time.Hour
+)
+
+// ErrHistoryNotFound is returned when the cache has no previous version of an item with the timestamp requested.
+var ErrHistoryNotFound = errors.New("item version not found in history")
+
+// DefaultHistoryRetention is used for any limits of a HistoryRetention that are not set.
+var DefaultHistoryRetention = HistoryRetention{MaxVersions: 20, MaxAge: 90 * 24 * time.Hour}
+
+// HistoryRetention limits the previous versions of items kept in the cache.
+type HistoryRetention struct {
+	// MaxVersions is the number of previous versions kept of each item. A negative number keeps none.
+	MaxVersions int
+	// MaxAge is how long a version is kept after being replaced. A negative duration keeps versions regardless of age.
+	MaxAge time.Duration
+}
+
+func (r HistoryRetention) withDefaults() HistoryRetention {
+	if r.MaxVersions == 0 {
+		r.MaxVersions = DefaultHistoryRetention.MaxVersions
+	}
+
+	if r.MaxAge == 0 {
+		r.MaxAge = DefaultHistoryRetention.MaxAge
+	}
+
+	return r
+}
+
+// HistoryEntry is a previous version of an item, kept in the cache after it was replaced or deleted.
+// Only versions that were synced are kept, so local changes that were overwritten before syncing are not.
+type HistoryEntry struct {
+	// ID is made of the item's UUID and the timestamp of the version.
+	ID                 string `storm:"id,unique"`
+	UUID               string `storm:"index"`
+	UpdatedAtTimestamp int64
+	// ReplacedAt is when the version was replaced or deleted.
+	ReplacedAt time.Time
+	// Item is the version as it was stored, with its content still encrypted.
+	Item Item
+}
+
+func historyID(uuid string, updatedAtTimestamp int64) string {
+	return fmt.Sprintf("%s/%020d", uuid, updatedAtTimestamp)
+}
+
+// saveHistory adds the stored version of an item to its history, if it was synced and is being changed
+// by next, or deleted if next is nil.
+func saveHistory(tx storm.Node, uuid string, next *Item) error {
+	var prev Item
+
+	if err := tx.One("UUID", uuid, &prev); err != nil {
+		if errors.Is(err, storm.ErrNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	if prev.Dirty || prev.Deleted || prev.Content == "" {
+		return nil
+	}
+
+	if next != nil && next.Content == prev.Content && next.Deleted == prev.Deleted {
+		return nil
+	}
+
+	e := HistoryEntry{
+		ID:                 historyID(prev.UUID, prev.UpdatedAtTimestamp),
+		UUID:               prev.UUID,
+		UpdatedAtTimestamp: prev.UpdatedAtTimestamp,
+		ReplacedAt:         time.Now().UTC(),
+		Item:               prev,
+	}
+
+	return tx.From(historyBucket).Save(&e)
+}
+
+// History returns the previous versions of an item kept in the cache, newest first.
+func History(db *storm.DB, uuid string) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+
+	if err := db.From(historyBucket).Find("UUID", uuid, &entries); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		return nil, fmt.Errorf("History | %w", err)
+	}
+
+	slices.SortFunc(entries, func(a, b HistoryEntry) int {
+		return cmp.Compare(b.UpdatedAtTimestamp, a.UpdatedAtTimestamp)
+	})
+
+	return entries, nil
+}
+
+// Restore replaces an item in the cache with a previous version from its history, marked as dirty so that it
+// is pushed on the next Sync. The version keeps the current item's timestamps so that it is not treated as a
+// conflict, but an item that has since been deleted may be saved by the server as a copy.
+func Restore(db *storm.DB, uuid string, updatedAtTimestamp int64) error {
+	var e HistoryEntry
+
+	if err := db.From(historyBucket).One("ID", historyID(uuid, updatedAtTimestamp), &e); err != nil {
+		if errors.Is(err, storm.ErrNotFound) {
+			return fmt.Errorf("Restore | %w: %s %d", ErrHistoryNotFound, uuid, updatedAtTimestamp)
+		}
+
+		return fmt.Errorf("Restore | %w", err)
+	}
+
+	restored := e.Item
+
+	var current Item
+
+	switch err := db.One("UUID", uuid, &current); {
+	case err == nil:
+		restored.UpdatedAt = current.UpdatedAt
+		restored.UpdatedAtTimestamp = current.UpdatedAtTimestamp
+	case !errors.Is(err, storm.ErrNotFound):
+		return fmt.Errorf("Restore | %w", err)
+	}
+
+	restored.Deleted = false
+	restored.Dirty = true
+	restored.DirtiedDate = time.Now()
+
+	if err := SaveCacheItems(db, Items{restored}, false); err != nil {
+		return fmt.Errorf("Restore | %w", err)
+	}
+
+	return nil
+}
+
+// PruneHistory removes the versions of items that exceed the retention limits. If UUIDs are given, only the
+// history of those items is loaded and pruned, otherwise the history of every item is. Sync prunes the
+// history of the items it changes, and of every item once a day so that the versions of items that no
+// longer change still expire.
+func PruneHistory(db *storm.DB, r HistoryRetention, uuids ...string) error {
+	r = r.withDefaults()
+
+	entries, err := historyEntries(db, uuids)
+	if err != nil {
+		return fmt.Errorf("PruneHistory | %w", err)
+	}
+
+	// newest first for each item
+	slices.SortFunc(entries, func(a, b HistoryEntry) int {
+		return cmp.Or(cmp.Compare(a.UUID, b.UUID), cmp.Compare(b.UpdatedAtTimestamp, a.UpdatedAtTimestamp))
+	})
+
+	now := time.Now()
+
+	var expired []HistoryEntry
+
+	kept := 0
+
+	for x, e := range entries {
+		if x == 0 || entries[x-1].UUID != e.UUID {
+			kept = 0
+		}
+
+		if kept >= max(r.MaxVersions, 0) || r.MaxAge > 0 && now.Sub(e.ReplacedAt) > r.MaxAge {
+			expired = append(expired, e)
+
+			continue
+		}
+
+		kept++
+	}
+
+	if len(expired) == 0 {
+		return nil
+	}
+
+	tx, err := db.From(historyBucket).Begin(true)
+	if err != nil {
+		return fmt.Errorf("PruneHistory | %w", err)
+	}
+
+	for x := range expired {
+		if err = tx.DeleteStruct(&expired[x]); err != nil {
+			_ = tx.Rollback()
+
+			return fmt.Errorf("PruneHistory | %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("PruneHistory | %w", err)
+	}
+
+	return nil
+}
+
+// pruneSyncedHistory prunes the history of the items changed by a sync. The history of every item is
+// pruned once per historyPruneInterval, or half of MaxAge if shorter, so that the versions of items that no
+// longer change still expire.
+func pruneSyncedHistory(db *storm.DB, r HistoryRetention, uuids []string, now time.Time) error {
+	r = r.withDefaults()
+
+	if r.MaxAge > 0 {
+		var last time.Time
+
+		if err := db.Get(historyPrunedBucket, historyPrunedKey, &last); err != nil && !errors.Is(err, storm.ErrNotFound) {
+			return fmt.Errorf("pruneSyncedHistory | %w", err)
+		}
+
+		if now.Sub(last) >= min(historyPruneInterval, r.MaxAge/2) {
+			if err := PruneHistory(db, r); err != nil {
+				return err
+			}
+
+			if err := db.Set(historyPrunedBucket, historyPrunedKey, now); err != nil {
+				return fmt.Errorf("pruneSyncedHistory | %w", err)
+			}
+
+			return nil
+		}
+	}
+
+	if len(uuids) == 0 {
+		return nil
+	}
+
+	return PruneHistory(db, r, uuids...)
+}
+
+// historyEntries returns the history of the items with the UUIDs given, or of every item if none are.
+func historyEntries(db *storm.DB, uuids []string) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+
+	if len(uuids) == 0 {
+		if err := db.From(historyBucket).All(&entries); err != nil && !errors.Is(err, storm.ErrNotFound) {
+			return nil, err
+		}
+
+		return entries, nil
+	}
+
+	for _, uuid := range slices.Compact(slices.Sorted(slices.Values(uuids))) {
+		var found []HistoryEntry
+
+		if err := db.From(historyBucket).Find("UUID", uuid, &found); err != nil && !errors.Is(err, storm.ErrNotFound) {
+			return nil, err
+		}
+
+		entries = append(entries, found...)
+	}
+
+	return entries, nil
+}
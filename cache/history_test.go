@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/asdine/storm/v3"
+)
+
+func historyTestItem(content string, ts int64, dirty bool) Item {
+	return Item{
+		UUID:               "n1",
+		Content:            content,
+		ContentType:        "Note",
+		UpdatedAtTimestamp: ts,
+		Dirty:              dirty,
+	}
+}
+
+// TestHistory tests that synced versions of items are kept when they are replaced or deleted,
+// and can be restored
+func TestHistory(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	save := func(i Item) {
+		t.Helper()
+
+		if err = SaveCacheItems(db, Items{i}, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	save(historyTestItem("v1", 1, false))
+
+	// saving the same content again is not a new version
+	save(historyTestItem("v1", 1, false))
+
+	// a local change replaces v1, and is then replaced by another local change before being synced
+	save(historyTestItem("v2", 1, true))
+	save(historyTestItem("v3", 1, true))
+
+	// the server returns the synced change, and then a change from elsewhere
+	save(historyTestItem("v3", 2, false))
+	save(historyTestItem("v4", 3, false))
+
+	entries, err := History(db, "n1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 || entries[0].Item.Content != "v3" || entries[1].Item.Content != "v1" {
+		t.Fatalf("Expected v3 and v1 in history, got: %+v", entries)
+	}
+
+	if err = Restore(db, "n1", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var restored Item
+	if err = db.One("UUID", "n1", &restored); err != nil {
+		t.Fatal(err)
+	}
+
+	// the current timestamp is kept so the restored version is not a conflict
+	if restored.Content != "v1" || !restored.Dirty || restored.UpdatedAtTimestamp != 3 {
+		t.Errorf("Expected v1 to be restored, got: %+v", restored)
+	}
+
+	// the version replaced by the restore is kept, so the restore can be undone
+	if entries, _ = History(db, "n1"); len(entries) != 3 || entries[0].Item.Content != "v4" {
+		t.Errorf("Expected v4 in history, got: %+v", entries)
+	}
+
+	if err = Restore(db, "n1", 42); !errors.Is(err, ErrHistoryNotFound) {
+		t.Errorf("Expected ErrHistoryNotFound, got: %v", err)
+	}
+
+	// deleted items are kept
+	save(historyTestItem("v5", 4, false))
+
+	if err = DeleteCacheItems(db, Items{{UUID: "n1", Deleted: true}}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if entries, _ = History(db, "n1"); len(entries) != 4 || entries[0].Item.Content != "v5" {
+		t.Errorf("Expected v5 in history, got: %+v", entries)
+	}
+}
+
+func TestPruneHistory(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	now := time.Now().UTC()
+
+	for _, e := range []HistoryEntry{
+		{UUID: "a", UpdatedAtTimestamp: 1, ReplacedAt: now},
+		{UUID: "a", UpdatedAtTimestamp: 2, ReplacedAt: now},
+		{UUID: "a", UpdatedAtTimestamp: 3, ReplacedAt: now},
+		{UUID: "b", UpdatedAtTimestamp: 1, ReplacedAt: now.Add(-48 * time.Hour)},
+		{UUID: "b", UpdatedAtTimestamp: 2, ReplacedAt: now},
+	} {
+		e.ID = historyID(e.UUID, e.UpdatedAtTimestamp)
+		if err = db.From(historyBucket).Save(&e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	remainingIDs := func() []string {
+		var remaining []HistoryEntry
+		if err = db.From(historyBucket).All(&remaining); err != nil {
+			t.Fatal(err)
+		}
+
+		var ids []string
+		for _, e := range remaining {
+			ids = append(ids, e.ID)
+		}
+
+		return ids
+	}
+
+	// only the history of the items given is pruned
+	if err = PruneHistory(db, HistoryRetention{MaxVersions: 2, MaxAge: 24 * time.Hour}, "a", "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := remainingIDs(); len(ids) != 4 || ids[0] != historyID("a", 2) || ids[2] != historyID("b", 1) {
+		t.Errorf("Expected only the oldest version of a to be pruned, got: %v", ids)
+	}
+
+	if err = PruneHistory(db, HistoryRetention{MaxVersions: 2, MaxAge: 24 * time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := remainingIDs(); len(ids) != 3 || ids[0] != historyID("a", 2) || ids[1] != historyID("a", 3) || ids[2] != historyID("b", 2) {
+		t.Errorf("Expected the oldest and expired versions to be pruned, got: %v", ids)
+	}
+
+	// a negative limit keeps none
+	if err = PruneHistory(db, HistoryRetention{MaxVersions: -1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if entries, _ := History(db, "a"); len(entries) != 0 {
+		t.Errorf("Expected no history, got: %+v", entries)
+	}
+}
+
+func TestPruneSyncedHistory(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	now := time.Now().UTC()
+	r := HistoryRetention{MaxVersions: 5, MaxAge: 24 * time.Hour}
+
+	save := func(uuid string, updatedAtTimestamp int64, replacedAt time.Time) {
+		e := HistoryEntry{ID: historyID(uuid, updatedAtTimestamp), UUID: uuid, UpdatedAtTimestamp: updatedAtTimestamp, ReplacedAt: replacedAt}
+		if err = db.From(historyBucket).Save(&e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// "untouched" is not changed by any sync, but its old version still expires
+	save("changed", 1, now)
+	save("untouched", 1, now.Add(-48*time.Hour))
+
+	if err = pruneSyncedHistory(db, r, []string{"changed"}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if entries, _ := History(db, "untouched"); len(entries) != 0 {
+		t.Errorf("Expected the expired version of an untouched item to be pruned, got: %+v", entries)
+	}
+
+	// every item is only pruned once per interval
+	save("untouched", 2, now.Add(-48*time.Hour))
+
+	if err = pruneSyncedHistory(db, r, nil, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if entries, _ := History(db, "untouched"); len(entries) != 1 {
+		t.Errorf("Expected the history of untouched items to be kept until the next interval, got: %+v", entries)
+	}
+
+	if err = pruneSyncedHistory(db, r, nil, now.Add(historyPruneInterval)); err != nil {
+		t.Fatal(err)
+	}
+
+	if entries, _ := History(db, "untouched"); len(entries) != 0 {
+		t.Errorf("Expected the expired version of an untouched item to be pruned, got: %+v", entries)
+	}
+
+	if entries, _ := History(db, "changed"); len(entries) != 1 {
+		t.Errorf("Expected the recent version to be kept, got: %+v", entries)
+	}
+}
@@ -0,0 +1,578 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/items"
+	"github.com/jonhadfield/gosn-v2/log"
+)
+
+const (
+	// ConflictFileExtension is the extension of the files holding local changes that conflicted with
+	// changes made in the account. They are ignored when mirroring, so can be merged and removed by hand.
+	ConflictFileExtension = ".conflict" + items.MarkdownFileExtension
+	mirrorBucket          = "mirror"
+)
+
+// MirrorFile records the file a note was mirrored to, so that changes to either can be detected.
+type MirrorFile struct {
+	UUID string `storm:"id,unique"`
+	Path string
+	// Hash is the SHA-256 of the file's content when last mirrored.
+	Hash string
+}
+
+type MirrorInput struct {
+	*Session
+	// Dir is the directory of Markdown files to keep in sync with the account's notes.
+	Dir string
+	// SearchIndex, if set, is updated by the syncs made while mirroring.
+	SearchIndex *items.SearchIndex
+}
+
+type MirrorOutput struct {
+	// Written lists the files written with changes from the account.
+	Written []string
+	// Imported lists the files whose changes were saved to the cache and pushed.
+	Imported []string
+	// Removed lists the files removed as their notes were moved, deleted or trashed.
+	Removed []string
+	// Trashed lists the UUIDs of the notes moved to the trash as their files were deleted.
+	Trashed []string
+	// Conflicts lists the conflict files written where a file and its note had both changed.
+	Conflicts []string
+	// Quarantined lists items that could not be processed by the syncs.
+	Quarantined items.Quarantine
+}
+
+// Mirror keeps a directory of Markdown files, as written by items.ExportMarkdown, in sync with the
+// account's notes. The cache is synced, changes to the files since the last mirror are saved to the
+// cache as dirty items, changes to the notes are written to the files, and the dirty items are pushed.
+//
+// New files create notes, renaming a file changes the note's title and deleting a file moves the note
+// to the trash. Files are kept in the folder of their first tag, so notes are retagged by editing
+// their front matter. Where a file and its note have both changed, the account's version is written
+// and the local version is kept alongside in a file with the ConflictFileExtension.
+// The files mirrored are tracked in the cache, so mirroring can be stopped and restarted at any time.
+func Mirror(mi MirrorInput) (mo MirrorOutput, err error) {
+	so, err := Sync(SyncInput{Session: mi.Session, SearchIndex: mi.SearchIndex})
+	mo.Quarantined = so.Quarantined
+
+	if err != nil {
+		if so.DB != nil {
+			_ = so.DB.Close()
+		}
+
+		return mo, fmt.Errorf("Mirror | %w", err)
+	}
+
+	dirty, err := mirrorDir(so.DB, mi.Session, mi.Dir, &mo)
+
+	if cErr := so.DB.Close(); cErr != nil && err == nil {
+		err = cErr
+	}
+
+	if err != nil || !dirty {
+		return mo, err
+	}
+
+	log.DebugPrint(mi.Debug, "Mirror | pushing changes from files", common.MaxDebugChars)
+
+	so, err = Sync(SyncInput{Session: mi.Session, SearchIndex: mi.SearchIndex, Close: true})
+	mo.Quarantined = append(mo.Quarantined, so.Quarantined...)
+
+	if err != nil {
+		return mo, fmt.Errorf("Mirror | %w", err)
+	}
+
+	return mo, nil
+}
+
+// RunMirror calls Mirror every interval until the context is cancelled, passing the result of each to fn.
+// As Sync only calls the server every common.MinSyncInterval unless there are changes to push,
+// changes made elsewhere may take that long to be written to the files.
+func RunMirror(ctx context.Context, mi MirrorInput, interval time.Duration, fn func(MirrorOutput, error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		mo, err := Mirror(mi)
+		if fn != nil {
+			fn(mo, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func mirrorHash(f items.MarkdownFile) (string, error) {
+	b, err := f.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// mirrorDir reconciles the directory with the notes in the cache, returning true if changes
+// from the files were saved to the cache as dirty items.
+func mirrorDir(db *storm.DB, s *Session, dir string, mo *MirrorOutput) (dirty bool, err error) {
+	if dir, err = filepath.Abs(dir); err != nil {
+		return false, fmt.Errorf("mirrorDir | %w", err)
+	}
+
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return false, fmt.Errorf("mirrorDir | %w", err)
+	}
+
+	node := db.From(mirrorBucket, dir)
+
+	var mfs []MirrorFile
+	if err = node.All(&mfs); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		return false, fmt.Errorf("mirrorDir | %w", err)
+	}
+
+	state := make(map[string]MirrorFile, len(mfs))
+	for _, mf := range mfs {
+		state[mf.UUID] = mf
+	}
+
+	var cItems Items
+	if err = db.All(&cItems); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		return false, fmt.Errorf("mirrorDir | %w", err)
+	}
+
+	all, quarantined, err := cItems.ToItemsLenient(s)
+	if err != nil {
+		return false, fmt.Errorf("mirrorDir | %w", err)
+	}
+
+	mo.Quarantined = append(mo.Quarantined, quarantined...)
+
+	rendered, err := mirrorFiles(all)
+	if err != nil {
+		return false, err
+	}
+
+	local, err := readMirrorDir(dir, state)
+	if err != nil {
+		return false, err
+	}
+
+	// find the files changed since the last mirror, and the notes whose files were deleted
+	var toImport []items.MarkdownFile
+
+	toTrash := make(map[string]bool)
+
+	for _, f := range local {
+		u := f.FrontMatter.UUID
+		mf, mirrored := state[u]
+		r, ok := rendered[u]
+
+		if !ok && !mirrored {
+			toImport = append(toImport, f)
+
+			continue
+		}
+
+		fHash, err := mirrorHash(f)
+		if err != nil {
+			return false, fmt.Errorf("mirrorDir | %w", err)
+		}
+
+		renamed := mirrored && path.Base(f.Path) != path.Base(mf.Path)
+		localChanged := !mirrored || fHash != mf.Hash || renamed
+		remoteChanged := !ok || !mirrored || r.hash != mf.Hash
+
+		switch {
+		case !localChanged:
+		case !remoteChanged:
+			if renamed {
+				f.FrontMatter.Title = strings.TrimSuffix(path.Base(f.Path), path.Ext(f.Path))
+			}
+
+			toImport = append(toImport, f)
+		case ok && !mirrored && fHash == r.hash:
+			// the file was already up to date
+		default:
+			conflict, err := writeMirrorConflict(dir, f)
+			if err != nil {
+				return false, err
+			}
+
+			log.DebugPrint(s.Debug, fmt.Sprintf("mirrorDir | %s and note %s both changed, so keeping local copy in %s", f.Path, u, conflict), common.MaxDebugChars)
+
+			mo.Conflicts = append(mo.Conflicts, conflict)
+		}
+	}
+
+	for u, mf := range state {
+		if r, ok := rendered[u]; ok && !hasMirrorFile(local, u) && r.hash == mf.Hash {
+			toTrash[u] = true
+		}
+	}
+
+	changed, err := items.ImportMarkdownFiles(toImport, all)
+	if err != nil {
+		return false, fmt.Errorf("mirrorDir | %w", err)
+	}
+
+	var changedNotes items.Notes
+
+	var changedTags items.Tags
+
+	for _, i := range changed {
+		switch x := i.(type) {
+		case *items.Note:
+			// a file restored for a trashed note restores the note
+			if x.Content.GetTrashed() {
+				x.Content.SetTrashed(false)
+			}
+
+			changedNotes = append(changedNotes, *x)
+		case *items.Tag:
+			changedTags = append(changedTags, *x)
+		}
+	}
+
+	for _, n := range all.Notes() {
+		if toTrash[n.UUID] {
+			n.Content.SetTrashed(true)
+			n.Content.SetUpdateTime(time.Now().UTC())
+			changedNotes = append(changedNotes, n)
+			mo.Trashed = append(mo.Trashed, n.UUID)
+		}
+	}
+
+	for _, f := range toImport {
+		mo.Imported = append(mo.Imported, f.Path)
+	}
+
+	// the files are written before the cache is updated, so that a mirror that is interrupted
+	// imports the files again rather than creating duplicate notes for files without a UUID
+	desired, err := mirrorFiles(mergeMirrorItems(all, changed, changedNotes))
+	if err != nil {
+		return false, err
+	}
+
+	if err = writeMirrorFiles(dir, local, desired, mo); err != nil {
+		return false, err
+	}
+
+	if len(changedNotes) > 0 {
+		if err = SaveNotes(s, db, changedNotes, false); err != nil {
+			return false, fmt.Errorf("mirrorDir | %w", err)
+		}
+	}
+
+	if len(changedTags) > 0 {
+		if err = SaveTags(db, s, changedTags, false); err != nil {
+			return false, fmt.Errorf("mirrorDir | %w", err)
+		}
+	}
+
+	if err = saveMirrorState(node, state, desired); err != nil {
+		return false, err
+	}
+
+	return len(changedNotes) > 0 || len(changedTags) > 0, nil
+}
+
+type mirrorFile struct {
+	items.MarkdownFile
+	hash string
+}
+
+// mirrorFiles returns the files for the items' notes by UUID.
+func mirrorFiles(its items.Items) (map[string]mirrorFile, error) {
+	files, err := its.MarkdownFiles()
+	if err != nil {
+		return nil, fmt.Errorf("mirrorFiles | %w", err)
+	}
+
+	m := make(map[string]mirrorFile, len(files))
+
+	for _, f := range files {
+		h, err := mirrorHash(f)
+		if err != nil {
+			return nil, fmt.Errorf("mirrorFiles | %w", err)
+		}
+
+		m[f.FrontMatter.UUID] = mirrorFile{MarkdownFile: f, hash: h}
+	}
+
+	return m, nil
+}
+
+// readMirrorDir returns the Markdown files in dir, other than conflict files. Where the same UUID is
+// found in more than one file, e.g. as a file was copied, the file that was mirrored keeps the UUID
+// and the others become new notes. Files without a UUID at the path of a mirrored note whose file is
+// missing are given its UUID, in case a previous mirror was interrupted.
+func readMirrorDir(dir string, state map[string]MirrorFile) ([]items.MarkdownFile, error) {
+	files, err := items.ReadMarkdownDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("readMirrorDir | %w", err)
+	}
+
+	var local []items.MarkdownFile
+
+	owner := make(map[string]int)
+
+	for _, f := range files {
+		if strings.HasSuffix(strings.ToLower(f.Path), ConflictFileExtension) {
+			continue
+		}
+
+		u := f.FrontMatter.UUID
+		if u == "" {
+			local = append(local, f)
+
+			continue
+		}
+
+		x, seen := owner[u]
+
+		switch {
+		case !seen:
+			owner[u] = len(local)
+		case state[u].Path == f.Path:
+			local[x].FrontMatter.UUID = ""
+			owner[u] = len(local)
+		default:
+			f.FrontMatter.UUID = ""
+		}
+
+		local = append(local, f)
+	}
+
+	for x, f := range local {
+		if f.FrontMatter.UUID != "" {
+			continue
+		}
+
+		for u, mf := range state {
+			if _, found := owner[u]; !found && mf.Path == f.Path {
+				local[x].FrontMatter.UUID = u
+				owner[u] = x
+
+				break
+			}
+		}
+
+		// new notes are given a UUID up front, so their files can be rewritten with it
+		if local[x].FrontMatter.UUID == "" {
+			local[x].FrontMatter.UUID = items.GenUUID()
+		}
+	}
+
+	return local, nil
+}
+
+func hasMirrorFile(files []items.MarkdownFile, uuid string) bool {
+	for _, f := range files {
+		if f.FrontMatter.UUID == uuid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeMirrorItems returns the items with the changed items in place of the originals.
+func mergeMirrorItems(all, changed items.Items, notes items.Notes) items.Items {
+	replaced := make(map[string]items.Item)
+
+	for _, i := range changed {
+		replaced[i.GetUUID()] = i
+	}
+
+	for x := range notes {
+		replaced[notes[x].UUID] = &notes[x]
+	}
+
+	merged := make(items.Items, 0, len(all)+len(replaced))
+
+	for _, i := range all {
+		if r, ok := replaced[i.GetUUID()]; ok {
+			merged = append(merged, r)
+			delete(replaced, i.GetUUID())
+
+			continue
+		}
+
+		merged = append(merged, i)
+	}
+
+	for _, i := range changed {
+		if r, ok := replaced[i.GetUUID()]; ok {
+			merged = append(merged, r)
+		}
+	}
+
+	return merged
+}
+
+// writeMirrorConflict writes the local version of a file alongside it with the ConflictFileExtension.
+func writeMirrorConflict(dir string, f items.MarkdownFile) (string, error) {
+	b, err := f.Marshal()
+	if err != nil {
+		return "", fmt.Errorf("writeMirrorConflict | %w", err)
+	}
+
+	base := strings.TrimSuffix(f.Path, path.Ext(f.Path))
+
+	for x := 1; ; x++ {
+		conflict := base + ConflictFileExtension
+		if x > 1 {
+			conflict = fmt.Sprintf("%s (%d)%s", base, x, ConflictFileExtension)
+		}
+
+		file, err := os.OpenFile(filepath.Join(dir, filepath.FromSlash(conflict)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("writeMirrorConflict | %w", err)
+		}
+
+		_, err = file.Write(b)
+		if cErr := file.Close(); err == nil {
+			err = cErr
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("writeMirrorConflict | %w", err)
+		}
+
+		return conflict, nil
+	}
+}
+
+// writeMirrorFiles removes the files that no longer belong to a note at their path, then writes
+// each note's file where it differs from what is there. Local changes have been imported or kept
+// in a conflict file by this point, so nothing is lost.
+func writeMirrorFiles(dir string, local []items.MarkdownFile, desired map[string]mirrorFile, mo *MirrorOutput) error {
+	wanted := make(map[string]string, len(desired))
+	for _, d := range desired {
+		wanted[d.Path] = d.hash
+	}
+
+	existing := make(map[string]string, len(local))
+
+	for _, f := range local {
+		if _, ok := wanted[f.Path]; !ok {
+			p := filepath.Join(dir, filepath.FromSlash(f.Path))
+			if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("writeMirrorFiles | %w", err)
+			}
+
+			removeEmptyMirrorDirs(dir, filepath.Dir(p))
+
+			mo.Removed = append(mo.Removed, f.Path)
+
+			continue
+		}
+
+		h, err := mirrorHash(f)
+		if err != nil {
+			return fmt.Errorf("writeMirrorFiles | %w", err)
+		}
+
+		existing[f.Path] = h
+	}
+
+	for _, d := range desired {
+		if h, ok := existing[d.Path]; ok && h == d.hash {
+			continue
+		}
+
+		b, err := d.Marshal()
+		if err != nil {
+			return fmt.Errorf("writeMirrorFiles | %w", err)
+		}
+
+		p := filepath.Join(dir, filepath.FromSlash(d.Path))
+
+		if err = os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+			return fmt.Errorf("writeMirrorFiles | %w", err)
+		}
+
+		if err = os.WriteFile(p, b, 0o600); err != nil {
+			return fmt.Errorf("writeMirrorFiles | %w", err)
+		}
+
+		mo.Written = append(mo.Written, d.Path)
+	}
+
+	return nil
+}
+
+// removeEmptyMirrorDirs removes p, and then its parents, until one is not empty or dir is reached.
+func removeEmptyMirrorDirs(dir, p string) {
+	for p != dir && strings.HasPrefix(p, dir) {
+		if os.Remove(p) != nil {
+			return
+		}
+
+		p = filepath.Dir(p)
+	}
+}
+
+// saveMirrorState replaces the record of the files mirrored.
+func saveMirrorState(node storm.Node, state map[string]MirrorFile, desired map[string]mirrorFile) error {
+	tx, err := node.Begin(true)
+	if err != nil {
+		return fmt.Errorf("saveMirrorState | %w", err)
+	}
+
+	for u, mf := range state {
+		if _, ok := desired[u]; !ok {
+			if err = tx.DeleteStruct(&mf); err != nil {
+				return rollbackMirrorState(tx, err)
+			}
+		}
+	}
+
+	for u, d := range desired {
+		mf := MirrorFile{UUID: u, Path: d.Path, Hash: d.hash}
+		if state[u] == mf {
+			continue
+		}
+
+		if err = tx.Save(&mf); err != nil {
+			return rollbackMirrorState(tx, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("saveMirrorState | %w", err)
+	}
+
+	return nil
+}
+
+func rollbackMirrorState(tx storm.Node, err error) error {
+	if rErr := tx.Rollback(); rErr != nil {
+		return fmt.Errorf("saveMirrorState | %w | rollback error: %w", err, rErr)
+	}
+
+	return fmt.Errorf("saveMirrorState | %w", err)
+}
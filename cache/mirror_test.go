@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/asdine/storm/v3"
+	"github.com/jonhadfield/gosn-v2/crypto/vectors"
+	"github.com/jonhadfield/gosn-v2/items"
+	"github.com/jonhadfield/gosn-v2/session"
+)
+
+func mirrorTestNotes(t *testing.T, db *storm.DB, s *Session) map[string]items.Note {
+	t.Helper()
+
+	var cItems Items
+	if err := db.All(&cItems); err != nil {
+		t.Fatal(err)
+	}
+
+	its, err := cItems.ToItems(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notes := make(map[string]items.Note)
+	for _, n := range its.Notes() {
+		notes[n.Content.Title] = n
+	}
+
+	return notes
+}
+
+func mirrorTestRun(t *testing.T, db *storm.DB, s *Session, dir string) MirrorOutput {
+	t.Helper()
+
+	var mo MirrorOutput
+
+	if _, err := mirrorDir(db, s, dir, &mo); err != nil {
+		t.Fatal(err)
+	}
+
+	slices.Sort(mo.Written)
+	slices.Sort(mo.Imported)
+	slices.Sort(mo.Removed)
+
+	return mo
+}
+
+func mirrorTestWrite(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mirrorTestRead(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(b)
+}
+
+// TestMirrorDir tests that changes to mirrored files are saved to the cache, and changes to
+// the notes in the cache are written to the files
+func TestMirrorDir(t *testing.T) {
+	ik := session.SessionItemsKey{UUID: "ik1", ItemsKey: mustSecretKey(vectors.Items004[1].Key)}
+	s := &Session{Session: &session.Session{
+		ItemsKeys:       []session.SessionItemsKey{ik},
+		DefaultItemsKey: ik,
+	}}
+
+	db, err := storm.Open(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	dir := t.TempDir()
+
+	plan, _ := items.NewNote("Plan", "ship it", nil)
+	shopping, _ := items.NewNote("Shopping", "milk", nil)
+	ideas, _ := items.NewNote("Ideas", "", nil)
+	work, _ := items.NewTag("work", items.ItemReferences{{UUID: plan.UUID, ContentType: "Note"}})
+
+	if err = SaveNotes(s, db, items.Notes{plan, shopping, ideas}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = SaveTags(db, s, items.Tags{work}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	mo := mirrorTestRun(t, db, s, dir)
+	if want := []string{"Ideas.md", "Shopping.md", "work/Plan.md"}; !slices.Equal(want, mo.Written) {
+		t.Fatalf("Expected %v written, got: %+v", want, mo)
+	}
+
+	// nothing has changed
+	if mo = mirrorTestRun(t, db, s, dir); mo.Written != nil || mo.Imported != nil || mo.Removed != nil {
+		t.Fatalf("Expected no changes, got: %+v", mo)
+	}
+
+	// edit, add, rename and delete files
+	planFile := strings.Replace(mirrorTestRead(t, dir, "work/Plan.md"), "ship it", "ship it today", 1)
+	mirrorTestWrite(t, dir, "work/Plan.md", planFile)
+	mirrorTestWrite(t, dir, "New idea.md", "an idea\n")
+
+	if err = os.Rename(filepath.Join(dir, "Ideas.md"), filepath.Join(dir, "Brainstorm.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.Remove(filepath.Join(dir, "Shopping.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	mo = mirrorTestRun(t, db, s, dir)
+	if want := []string{"Brainstorm.md", "New idea.md", "work/Plan.md"}; !slices.Equal(want, mo.Imported) {
+		t.Errorf("Expected %v imported, got: %+v", want, mo)
+	}
+
+	if len(mo.Trashed) != 1 || mo.Trashed[0] != shopping.UUID {
+		t.Errorf("Expected %s to be trashed, got: %+v", shopping.UUID, mo)
+	}
+
+	notes := mirrorTestNotes(t, db, s)
+
+	if notes["Plan"].Content.Text != "ship it today" {
+		t.Errorf("Expected edited note, got: %+v", notes["Plan"])
+	}
+
+	var planItem Item
+	if err = db.One("UUID", plan.UUID, &planItem); err != nil || !planItem.Dirty {
+		t.Errorf("Expected edited note to be dirty, got: %+v %v", planItem, err)
+	}
+
+	if n, ok := notes["Brainstorm"]; !ok || n.UUID != ideas.UUID {
+		t.Errorf("Expected renamed note, got: %+v", notes)
+	}
+
+	if trashed := notes["Shopping"]; !trashed.Content.GetTrashed() {
+		t.Error("Expected deleted file's note to be trashed")
+	}
+
+	idea, ok := notes["New idea"]
+	if !ok || idea.Content.Text != "an idea" {
+		t.Fatalf("Expected new note, got: %+v", notes)
+	}
+
+	// the new file is given the note's UUID, so it is not imported again
+	if !strings.Contains(mirrorTestRead(t, dir, "New idea.md"), "uuid: "+idea.UUID+"\n") {
+		t.Errorf("Expected new file to be given UUID %s", idea.UUID)
+	}
+
+	if mo = mirrorTestRun(t, db, s, dir); mo.Written != nil || mo.Imported != nil || mo.Removed != nil || mo.Trashed != nil {
+		t.Fatalf("Expected no changes after import, got: %+v", mo)
+	}
+
+	if n := len(mirrorTestNotes(t, db, s)); n != 4 {
+		t.Errorf("Expected 4 notes, got: %d", n)
+	}
+
+	// change notes in the account, as a sync would, with one file also changed locally
+	plan = notes["Plan"]
+	plan.Content.Text = "ship it tomorrow"
+	idea.Content.Title = "Old idea"
+
+	if err = SaveNotes(s, db, items.Notes{plan, idea}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	mirrorTestWrite(t, dir, "work/Plan.md", strings.Replace(mirrorTestRead(t, dir, "work/Plan.md"), "ship it today", "ship it now", 1))
+
+	mo = mirrorTestRun(t, db, s, dir)
+	if want := []string{"Old idea.md", "work/Plan.md"}; !slices.Equal(want, mo.Written) {
+		t.Errorf("Expected %v written, got: %+v", want, mo)
+	}
+
+	if want := []string{"New idea.md"}; !slices.Equal(want, mo.Removed) {
+		t.Errorf("Expected %v removed, got: %+v", want, mo)
+	}
+
+	if want := []string{"work/Plan" + ConflictFileExtension}; !slices.Equal(want, mo.Conflicts) {
+		t.Errorf("Expected %v conflicts, got: %+v", want, mo)
+	}
+
+	if !strings.HasSuffix(mirrorTestRead(t, dir, "work/Plan.md"), "\n\nship it tomorrow\n") {
+		t.Error("Expected the account's version to be written")
+	}
+
+	if !strings.HasSuffix(mirrorTestRead(t, dir, "work/Plan"+ConflictFileExtension), "\n\nship it now\n") {
+		t.Error("Expected the local version to be kept in the conflict file")
+	}
+
+	// conflict files are ignored
+	if mo = mirrorTestRun(t, db, s, dir); mo.Written != nil || mo.Imported != nil || mo.Conflicts != nil {
+		t.Fatalf("Expected no changes after conflict, got: %+v", mo)
+	}
+
+	// notes deleted from the account have their files removed
+	plan.Deleted = true
+	if err = SaveNotes(s, db, items.Notes{plan}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if mo = mirrorTestRun(t, db, s, dir); !slices.Equal([]string{"work/Plan.md"}, mo.Removed) {
+		t.Errorf("Expected removed file, got: %+v", mo)
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, "work", "Plan.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected file to be removed, got: %v", err)
+	}
+}
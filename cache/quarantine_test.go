@@ -0,0 +1,183 @@
+package cache
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/asdine/storm/v3"
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/crypto"
+	"github.com/jonhadfield/gosn-v2/crypto/vectors"
+	"github.com/jonhadfield/gosn-v2/items"
+	"github.com/jonhadfield/gosn-v2/session"
+)
+
+// TestImportNilSession tests that importing a nil session returns an error rather than panicking
+func TestImportNilSession(t *testing.T) {
+	s, err := ImportSession(nil, "")
+	if err == nil {
+		t.Fatal("Expected error importing nil session")
+	}
+
+	if s != nil {
+		t.Errorf("Expected nil session, got: %+v", s)
+	}
+}
+
+// TestToItemsWithoutItemsKeys tests that converting cache items without items keys returns ErrNoItemsKeys
+func TestToItemsWithoutItemsKeys(t *testing.T) {
+	s := &Session{Session: &session.Session{}}
+	cItems := Items{{
+		UUID:        "a",
+		ContentType: common.SNItemTypeNote,
+		ItemsKeyID:  "b",
+		Content:     "004:abc:def:ghi",
+		EncItemKey:  "004:abc:def:ghi",
+	}}
+
+	if _, err := cItems.ToItems(s); !errors.Is(err, items.ErrNoItemsKeys) {
+		t.Errorf("Expected ErrNoItemsKeys, got: %v", err)
+	}
+
+	s.ItemsKeys = []session.SessionItemsKey{{UUID: "b", ItemsKey: crypto.SecretKey("c")}}
+
+	if _, err := cItems.ToItems(s); !errors.Is(err, items.ErrNoItemsKeys) {
+		t.Errorf("Expected ErrNoItemsKeys without default items key, got: %v", err)
+	}
+
+	// nothing to decrypt so no keys required
+	its, err := Items{}.ToItems(s)
+	if err != nil || len(its) != 0 {
+		t.Errorf("Expected no items and no error, got: %v %v", its, err)
+	}
+}
+
+// TestToItemsSkipsQuarantinedItems tests that a corrupt cached item does not prevent conversion of the others
+func TestToItemsSkipsQuarantinedItems(t *testing.T) {
+	s := &Session{Session: &session.Session{
+		ItemsKeys:       []session.SessionItemsKey{{UUID: "b", ItemsKey: crypto.SecretKey("c")}},
+		DefaultItemsKey: session.SessionItemsKey{UUID: "b", ItemsKey: crypto.SecretKey("c")},
+	}}
+	cItems := Items{{
+		UUID:        "a",
+		ContentType: common.SNItemTypeNote,
+		ItemsKeyID:  "b",
+		Content:     "corrupt",
+		EncItemKey:  "corrupt",
+	}}
+
+	its, err := cItems.ToItems(s)
+	if err != nil {
+		t.Fatalf("Expected corrupt item to be skipped, got: %v", err)
+	}
+
+	if len(its) != 0 {
+		t.Errorf("Expected no items, got: %d", len(its))
+	}
+}
+
+// TestToCacheItemsQuarantinesItemsWithoutItemsKeyID tests that items which cannot be decrypted are
+// reported rather than stored
+func TestToCacheItemsQuarantinesItemsWithoutItemsKeyID(t *testing.T) {
+	eItems := items.EncryptedItems{
+		{UUID: "a", ContentType: common.SNItemTypeNote, Content: "004:a:b:c"},
+		{UUID: "b", ContentType: common.SNItemTypeNote, ItemsKeyID: "c", Content: "004:a:b:c"},
+	}
+
+	cItems, q := ToCacheItems(eItems, true)
+
+	if len(cItems) != 1 || cItems[0].UUID != "b" {
+		t.Errorf("Expected only item with ItemsKeyID to be converted, got: %+v", cItems)
+	}
+
+	if len(q) != 1 || q[0].UUID != "a" || q[0].Reason != items.QuarantineMalformedPayload {
+		t.Errorf("Expected item without ItemsKeyID to be quarantined, got: %+v", q)
+	}
+
+	db, err := storm.Open(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err = SaveEncryptedItems(db, eItems, false); !errors.Is(err, crypto.ErrMalformedPayload) {
+		t.Errorf("Expected ErrMalformedPayload saving item without ItemsKeyID, got: %v", err)
+	}
+}
+
+// TestToItemsLenientRetry tests that items encrypted with an unknown items key are reported, left in
+// the cache, and recovered once the items key becomes available
+func TestToItemsLenientRetry(t *testing.T) {
+	ik := session.SessionItemsKey{UUID: "ik1", ItemsKey: mustSecretKey(vectors.Items004[1].Key)}
+	laterIK := session.SessionItemsKey{UUID: "ik2", ItemsKey: mustSecretKey(vectors.Items004[0].Key)}
+	s := &Session{Session: &session.Session{
+		ItemsKeys:       []session.SessionItemsKey{ik},
+		DefaultItemsKey: ik,
+	}}
+
+	note, err := items.NewNote("available", "text", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	laterNote, err := items.NewNote("later", "text", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ei, err := items.EncryptItem(&note, ik, s.Session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	laterEI, err := items.EncryptItem(&laterNote, laterIK, s.Session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := storm.Open(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cItems, _ := ToCacheItems(items.EncryptedItems{ei, laterEI}, false)
+	if err = SaveCacheItems(db, cItems, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = cItems.ToItems(s); err == nil {
+		t.Fatal("Expected error converting item with missing items key")
+	}
+
+	its, q, err := cItems.ToItemsLenient(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(its) != 1 || its[0].GetUUID() != note.UUID {
+		t.Errorf("Expected only %s to be converted, got: %+v", note.UUID, its)
+	}
+
+	if len(q) != 1 || q[0].UUID != laterNote.UUID || q[0].ItemsKeyID != laterIK.UUID || q[0].Reason != items.QuarantineMissingItemsKey {
+		t.Fatalf("Expected %s to be quarantined with missing items key, got: %+v", laterNote.UUID, q)
+	}
+
+	// nothing to retry until the items key is available
+	recovered, remaining, err := RetryQuarantined(db, s, q)
+	if err != nil || len(recovered) != 0 || len(remaining) != 1 {
+		t.Errorf("Expected nothing to be recovered, got: %+v %+v %v", recovered, remaining, err)
+	}
+
+	s.ItemsKeys = append(s.ItemsKeys, laterIK)
+
+	recovered, remaining, err = RetryQuarantined(db, s, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recovered) != 1 || recovered[0].GetUUID() != laterNote.UUID || len(remaining) != 0 {
+		t.Errorf("Expected %s to be recovered, got: %+v %+v", laterNote.UUID, recovered, remaining)
+	}
+}
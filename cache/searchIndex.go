@@ -0,0 +1,283 @@
+package cache
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/asdine/storm/v3"
+	sq "github.com/asdine/storm/v3/q"
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/crypto"
+	"github.com/jonhadfield/gosn-v2/items"
+	log "github.com/jonhadfield/gosn-v2/log"
+)
+
+// SearchIndexEntry is a note's items.SearchDocument, encrypted with an items key, persisted
+// alongside the cache Items so that the search index can be loaded without decrypting every note.
+type SearchIndexEntry struct {
+	UUID               string `storm:"id,unique"`
+	Content            string
+	ItemsKeyID         string
+	UpdatedAtTimestamp int64
+}
+
+// searchIndexAuthData binds the encrypted content to the entry's UUID.
+func searchIndexAuthData(uuid string) string {
+	return base64.StdEncoding.EncodeToString([]byte("search-index:" + uuid))
+}
+
+func encryptSearchDocument(s *Session, doc items.SearchDocument) (SearchIndexEntry, error) {
+	ik := s.DefaultItemsKey
+	if len(ik.ItemsKey) == 0 {
+		return SearchIndexEntry{}, fmt.Errorf("%w: no default items key", items.ErrNoItemsKeys)
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return SearchIndexEntry{}, err
+	}
+
+	p := s.CryptoProvider()
+
+	nonce, err := p.GenerateNonce()
+	if err != nil {
+		return SearchIndexEntry{}, err
+	}
+
+	authData := searchIndexAuthData(doc.UUID)
+
+	cipherText, err := p.Encrypt(b, ik.ItemsKey, nonce, authData)
+	if err != nil {
+		return SearchIndexEntry{}, err
+	}
+
+	return SearchIndexEntry{
+		UUID:               doc.UUID,
+		Content:            fmt.Sprintf("%s:%s:%s:%s", p.Version(), nonce, cipherText, authData),
+		ItemsKeyID:         ik.UUID,
+		UpdatedAtTimestamp: doc.UpdatedAtTimestamp,
+	}, nil
+}
+
+func decryptSearchIndexEntry(s *Session, e SearchIndexEntry) (doc items.SearchDocument, err error) {
+	version, nonce, cipherText, authData, err := crypto.SplitContent(e.Content)
+	if err != nil {
+		return doc, err
+	}
+
+	if authData != searchIndexAuthData(e.UUID) {
+		return doc, fmt.Errorf("%w: search index entry %s has unexpected authenticated data", crypto.ErrAuthenticationFailed, e.UUID)
+	}
+
+	var key crypto.SecretKey
+
+	for _, ik := range s.ItemsKeys {
+		if ik.UUID == e.ItemsKeyID {
+			key = ik.ItemsKey
+
+			break
+		}
+	}
+
+	if len(key) == 0 {
+		return doc, fmt.Errorf("%w: %s", items.ErrMissingItemsKey, e.ItemsKeyID)
+	}
+
+	p, err := s.CryptoProviderFor(version)
+	if err != nil {
+		return doc, err
+	}
+
+	b, err := p.Decrypt(cipherText, key, nonce, authData)
+	if err != nil {
+		return doc, err
+	}
+
+	if err = json.Unmarshal(b, &doc); err != nil {
+		return doc, err
+	}
+
+	if doc.UUID != e.UUID {
+		return doc, fmt.Errorf("search index entry %s contains document %s", e.UUID, doc.UUID)
+	}
+
+	return doc, nil
+}
+
+// LoadSearchIndex loads the full-text search index persisted in the cache. Notes added, changed
+// or removed since the index was persisted, or whose entries cannot be decrypted, are re-indexed
+// from the cache, so the first call for a cache builds and persists the whole index.
+func LoadSearchIndex(db *storm.DB, s *Session) (*items.SearchIndex, error) {
+	if db == nil {
+		return nil, errors.New("db not passed to LoadSearchIndex")
+	}
+
+	var notes Items
+
+	if err := db.Find("ContentType", common.SNItemTypeNote, &notes); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		return nil, fmt.Errorf("LoadSearchIndex | %w", err)
+	}
+
+	var entries []SearchIndexEntry
+
+	if err := db.All(&entries); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		return nil, fmt.Errorf("LoadSearchIndex | %w", err)
+	}
+
+	cached := make(map[string]Item, len(notes))
+	for _, n := range notes {
+		if !n.Deleted {
+			cached[n.UUID] = n
+		}
+	}
+
+	idx := items.NewSearchIndex()
+
+	var docs []items.SearchDocument
+
+	var stale []string
+
+	for _, e := range entries {
+		n, ok := cached[e.UUID]
+		delete(cached, e.UUID)
+
+		if !ok || n.UpdatedAtTimestamp != e.UpdatedAtTimestamp {
+			stale = append(stale, e.UUID)
+
+			continue
+		}
+
+		doc, err := decryptSearchIndexEntry(s, e)
+		if err != nil {
+			log.DebugPrint(s.Debug, fmt.Sprintf("LoadSearchIndex | re-indexing %s: %s", e.UUID, err), common.MaxDebugChars)
+
+			stale = append(stale, e.UUID)
+
+			continue
+		}
+
+		docs = append(docs, doc)
+	}
+
+	idx.AddDocuments(docs...)
+
+	// notes without a current entry
+	for uuid := range cached {
+		stale = append(stale, uuid)
+	}
+
+	log.DebugPrint(s.Debug, fmt.Sprintf("LoadSearchIndex | loaded %d notes and re-indexing %d", len(docs), len(stale)), common.MaxDebugChars)
+
+	if err := UpdateSearchIndex(db, s, idx, stale...); err != nil {
+		return nil, fmt.Errorf("LoadSearchIndex | %w", err)
+	}
+
+	return idx, nil
+}
+
+// UpdateSearchIndex re-indexes the notes with the specified UUIDs from the cache, updating both
+// the index and its persisted entries. Notes no longer in the cache, deleted, or that cannot be
+// decrypted are removed. UUIDs of items other than notes are ignored.
+func UpdateSearchIndex(db *storm.DB, s *Session, idx *items.SearchIndex, uuids ...string) error {
+	if len(uuids) == 0 {
+		return nil
+	}
+
+	if db == nil {
+		return errors.New("db not passed to UpdateSearchIndex")
+	}
+
+	var cItems Items
+
+	if err := db.Select(sq.In("UUID", uuids)).Find(&cItems); err != nil && !errors.Is(err, storm.ErrNotFound) {
+		return fmt.Errorf("UpdateSearchIndex | %w", err)
+	}
+
+	var notes Items
+
+	for _, ci := range cItems {
+		if ci.ContentType == common.SNItemTypeNote && !ci.Deleted {
+			notes = append(notes, ci)
+		}
+	}
+
+	its, q, err := notes.ToItemsLenient(s)
+	if err != nil {
+		return fmt.Errorf("UpdateSearchIndex | %w", err)
+	}
+
+	for _, qi := range q {
+		log.DebugPrint(s.Debug, fmt.Sprintf("UpdateSearchIndex | not indexing quarantined note: %s", qi.Err), common.MaxDebugChars)
+	}
+
+	indexed := make(map[string]bool, len(its))
+
+	var entries []SearchIndexEntry
+
+	for _, it := range its {
+		n, ok := it.(*items.Note)
+		if !ok {
+			continue
+		}
+
+		doc := items.NewSearchDocument(*n)
+
+		e, err := encryptSearchDocument(s, doc)
+		if err != nil {
+			return fmt.Errorf("UpdateSearchIndex | %w", err)
+		}
+
+		idx.AddDocuments(doc)
+		entries = append(entries, e)
+		indexed[n.UUID] = true
+	}
+
+	var removed []string
+
+	for _, uuid := range uuids {
+		if !indexed[uuid] {
+			removed = append(removed, uuid)
+		}
+	}
+
+	idx.Remove(removed...)
+
+	tx, err := db.Begin(true)
+	if err != nil {
+		return fmt.Errorf("UpdateSearchIndex | %w", err)
+	}
+
+	for x := range entries {
+		if err = tx.Save(&entries[x]); err != nil {
+			return rollbackSearchIndex(tx, fmt.Errorf("UpdateSearchIndex | save error: %w", err))
+		}
+	}
+
+	for _, uuid := range removed {
+		if err = tx.DeleteStruct(&SearchIndexEntry{UUID: uuid}); err != nil && !errors.Is(err, storm.ErrNotFound) {
+			return rollbackSearchIndex(tx, fmt.Errorf("UpdateSearchIndex | delete error: %w", err))
+		}
+	}
+
+	return tx.Commit()
+}
+
+func rollbackSearchIndex(tx storm.Node, err error) error {
+	if rErr := tx.Rollback(); rErr != nil {
+		return fmt.Errorf("%w | rollback error: %w", err, rErr)
+	}
+
+	return err
+}
+
+// DeleteSearchIndex removes the persisted search index from the cache.
+func DeleteSearchIndex(db *storm.DB) error {
+	if err := db.Drop(&SearchIndexEntry{}); err != nil && !strings.Contains(err.Error(), "not found") {
+		return fmt.Errorf("DeleteSearchIndex | %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/asdine/storm/v3"
+	"github.com/jonhadfield/gosn-v2/crypto"
+	"github.com/jonhadfield/gosn-v2/crypto/vectors"
+	"github.com/jonhadfield/gosn-v2/items"
+	"github.com/jonhadfield/gosn-v2/session"
+)
+
+// mustSecretKey decodes a hex encoded test key.
+func mustSecretKey(hexKey string) crypto.SecretKey {
+	k, err := crypto.NewSecretKey(hexKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return k
+}
+
+func searchIndexTestNote(t *testing.T, s *Session, title, text string) (items.Note, Item) {
+	t.Helper()
+
+	note, err := items.NewNote(title, text, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ei, err := items.EncryptItem(&note, s.DefaultItemsKey, s.Session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cItems, _ := ToCacheItems(items.EncryptedItems{ei}, false)
+
+	return note, cItems[0]
+}
+
+func searchIndexUUIDs(t *testing.T, idx *items.SearchIndex, query string) []string {
+	t.Helper()
+
+	results, err := idx.Search(query, items.SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var uuids []string
+	for _, r := range results {
+		uuids = append(uuids, r.UUID)
+	}
+
+	return uuids
+}
+
+// TestSearchIndexPersistence tests that the search index is built from, persisted encrypted in,
+// and incrementally updated from the cache
+func TestSearchIndexPersistence(t *testing.T) {
+	ik := session.SessionItemsKey{UUID: "ik1", ItemsKey: mustSecretKey(vectors.Items004[1].Key)}
+	s := &Session{Session: &session.Session{
+		ItemsKeys:       []session.SessionItemsKey{ik},
+		DefaultItemsKey: ik,
+	}}
+
+	db, err := storm.Open(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	k8s, k8sItem := searchIndexTestNote(t, s, "Kubernetes", "operators and clusters")
+	shopping, shoppingItem := searchIndexTestNote(t, s, "Shopping", "milk")
+
+	if err = SaveCacheItems(db, Items{k8sItem, shoppingItem}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := LoadSearchIndex(db, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := searchIndexUUIDs(t, idx, "oper*"); len(got) != 1 || got[0] != k8s.UUID {
+		t.Errorf("Expected %s, got: %v", k8s.UUID, got)
+	}
+
+	var entries []SearchIndexEntry
+	if err = db.All(&entries); err != nil || len(entries) != 2 {
+		t.Fatalf("Expected 2 persisted entries, got: %d %v", len(entries), err)
+	}
+
+	for _, e := range entries {
+		doc, dErr := decryptSearchIndexEntry(s, e)
+		if dErr != nil || doc.UUID != e.UUID || doc.Title == "" {
+			t.Errorf("Expected entry %s to decrypt, got: %+v %v", e.UUID, doc, dErr)
+		}
+	}
+
+	// entries cannot be swapped between notes
+	swapped := entries[0]
+	swapped.UUID = entries[1].UUID
+
+	if _, err = decryptSearchIndexEntry(s, swapped); err == nil {
+		t.Error("Expected error decrypting entry with another note's UUID")
+	}
+
+	// change one note and delete the other, as a sync would
+	shopping.Content.Text = "eggs"
+	shopping.UpdatedAtTimestamp++
+
+	ei, err := items.EncryptItem(&shopping, ik, s.Session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = SaveEncryptedItems(db, items.EncryptedItems{ei}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = DeleteCacheItems(db, Items{k8sItem}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = UpdateSearchIndex(db, s, idx, shopping.UUID, k8s.UUID); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := searchIndexUUIDs(t, idx, "eggs"); len(got) != 1 || got[0] != shopping.UUID {
+		t.Errorf("Expected %s, got: %v", shopping.UUID, got)
+	}
+
+	for _, query := range []string{"milk", "kubernetes"} {
+		if got := searchIndexUUIDs(t, idx, query); len(got) != 0 {
+			t.Errorf("Expected no results for %s, got: %v", query, got)
+		}
+	}
+
+	// reloading uses the persisted entry
+	reloaded, err := LoadSearchIndex(db, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded.Len() != 1 {
+		t.Errorf("Expected 1 note in reloaded index, got: %d", reloaded.Len())
+	}
+
+	if got := searchIndexUUIDs(t, reloaded, "eggs"); len(got) != 1 || got[0] != shopping.UUID {
+		t.Errorf("Expected %s, got: %v", shopping.UUID, got)
+	}
+
+	// notes changed without updating the index are re-indexed on load
+	shopping.Content.Text = "bread"
+	shopping.UpdatedAtTimestamp++
+
+	if ei, err = items.EncryptItem(&shopping, ik, s.Session); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = SaveEncryptedItems(db, items.EncryptedItems{ei}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded, err = LoadSearchIndex(db, s); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := searchIndexUUIDs(t, reloaded, "bread"); len(got) != 1 {
+		t.Errorf("Expected stale note to be re-indexed, got: %v", got)
+	}
+
+	if err = DeleteSearchIndex(db); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = DeleteSearchIndex(db); err != nil {
+		t.Errorf("Expected deleting a missing index to succeed, got: %v", err)
+	}
+}
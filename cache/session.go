@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"errors"
+
 	"github.com/asdine/storm/v3"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/jonhadfield/gosn-v2/auth"
@@ -20,7 +22,7 @@ func ImportSession(gs *auth.SignInResponseDataSession, path string) (*Session, e
 	var err error
 	var s *Session
 	if gs == nil {
-		panic("gs is nil")
+		return nil, errors.New("ImportSession | session is nil")
 	}
 
 	s = &Session{}
@@ -48,6 +50,7 @@ func ImportSession(gs *auth.SignInResponseDataSession, path string) (*Session, e
 	s.Session.RefreshToken = gs.RefreshToken
 	s.Session.AccessExpiration = gs.AccessExpiration
 	s.Session.RefreshExpiration = gs.RefreshExpiration
+	s.Session.ReadOnlyAccess = gs.ReadOnlyAccess
 	s.Session.SchemaValidation = gs.SchemaValidation
 	s.Session.PasswordNonce = gs.PasswordNonce
 
@@ -103,6 +106,8 @@ func GetSession(httpClient *retryablehttp.Client, loadSession bool, sessionKey,
 			RefreshToken:       gs.RefreshToken,
 			AccessExpiration:   gs.AccessExpiration,
 			RefreshExpiration:  gs.RefreshExpiration,
+			ReadOnlyAccess:     gs.ReadOnlyAccess,
+			ReadOnly:           gs.ReadOnly,
 			PasswordNonce:      gs.PasswordNonce,
 			AccessTokenCookie:  gs.AccessTokenCookie,
 			RefreshTokenCookie: gs.RefreshTokenCookie,
@@ -131,9 +136,11 @@ func (s Session) Gosn() session.Session {
 		AccessExpiration:   s.AccessExpiration,
 		RefreshExpiration:  s.RefreshExpiration,
 		ReadOnlyAccess:     s.ReadOnlyAccess,
+		ReadOnly:           s.ReadOnly,
 		PasswordNonce:      s.PasswordNonce,
 		Schemas:            s.Schemas,
 		AccessTokenCookie:  s.AccessTokenCookie,
 		RefreshTokenCookie: s.RefreshTokenCookie,
+		Crypto:             s.Crypto,
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jonhadfield/gosn-v2/crypto"
 	"github.com/jonhadfield/gosn-v2/session"
 )
 
@@ -59,6 +60,18 @@ func TestSyncErrorClassification(t *testing.T) {
 			expectedType:      SyncErrorConflict,
 			expectedRetryable: true,
 		},
+		{
+			name:              "Read-only session error",
+			inputError:        &session.ReadOnlyError{Items: 2},
+			expectedType:      SyncErrorReadOnly,
+			expectedRetryable: false,
+		},
+		{
+			name:              "Locked session error",
+			inputError:        session.ErrSessionLocked,
+			expectedType:      SyncErrorAuthentication,
+			expectedRetryable: false,
+		},
 		{
 			name:              "Unknown error",
 			inputError:        errors.New("some unknown error occurred"),
@@ -137,7 +150,7 @@ func TestSessionItemsKeyValidation(t *testing.T) {
 		session := &Session{
 			Session: &session.Session{
 				DefaultItemsKey: session.SessionItemsKey{
-					ItemsKey: "valid-items-key-12345",
+					ItemsKey: crypto.SecretKey("valid-items-key-12345"),
 					UUID:     "test-uuid",
 				},
 			},
@@ -181,7 +194,7 @@ func TestSessionItemsKeyValidation(t *testing.T) {
 		session := &Session{
 			Session: &session.Session{
 				DefaultItemsKey: session.SessionItemsKey{
-					ItemsKey: "", // Empty key
+					ItemsKey: nil, // Empty key
 					UUID:     "test-uuid",
 				},
 			},
@@ -224,6 +237,20 @@ func TestSyncErrorInterface(t *testing.T) {
 	t.Log("✅ SyncError correctly implements error interface")
 }
 
+// TestSyncErrorUnwrapsReadOnly tests that a classified read-only error can still be matched
+func TestSyncErrorUnwrapsReadOnly(t *testing.T) {
+	syncErr := classifySyncError(&session.ReadOnlyError{Requested: true, Items: 1})
+
+	if !errors.Is(syncErr, session.ErrReadOnlySession) {
+		t.Errorf("Expected SyncError to match ErrReadOnlySession, got: %v", syncErr)
+	}
+
+	var roErr *session.ReadOnlyError
+	if !errors.As(syncErr, &roErr) || roErr.Items != 1 {
+		t.Errorf("Expected SyncError to unwrap to ReadOnlyError, got: %v", syncErr)
+	}
+}
+
 // TestSyncWithMissingItemsKey tests that Sync continues with ItemsKey warnings
 func TestSyncWithMissingItemsKey(t *testing.T) {
 	// Create a session without ItemsKey (like test accounts)
@@ -18,6 +18,8 @@ const (
 	APIServer  = "https://api.standardnotes.com"
 	APIVersion = "20240226"  // API version used in requests (latest version with cookie support)
 	SyncPath   = "/v1/items" // remote path for making sync calls
+	// RevisionsPath is the remote path for an item's revisions, formatted with the item's UUID.
+	RevisionsPath = "/v1/items/%s/revisions"
 
 	// Type names.
 	SNItemTypeNote                 = "Note"
@@ -46,6 +48,7 @@ const (
 	AuthParamsPath    = "/v2/login-params" // remote path for getting auth parameters
 	AuthRegisterPath  = "/v1/users"        // remote path for registering user
 	AuthRefreshPath   = "/v1/sessions/refresh"
+	SessionsPath      = "/v1/sessions" // remote path for listing active sessions
 	SignInPath        = "/v2/login" // remote path for authenticating
 	MinPasswordLength = 8           // minimum password length when registering
 	// PageSize is the maximum number of items to return with each call.
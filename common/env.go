@@ -10,6 +10,7 @@ const (
 	EnvPostSyncRequestDelay = "SN_POST_SYNC_REQUEST_DELAY"
 	EnvPostSignInDelay      = "SN_POST_SIGN_IN_DELAY"
 	EnvSchemaValidation     = "SN_SCHEMA_VALIDATION"
+	EnvReadOnly             = "SN_READ_ONLY" // Open sessions in read-only mode so no items are written
 	EnvServer               = "SN_SERVER"
 	EnvEmail                = "SN_EMAIL"
 	EnvPassword             = "SN_PASSWORD"
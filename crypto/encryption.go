@@ -13,7 +13,6 @@ import (
 	"io"
 	"strings"
 
-	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/chacha20poly1305"
 )
 
@@ -46,10 +45,15 @@ const (
 	MaxPlaintextSize = 10000000
 )
 
-func SplitContent(in string) (version, nonce, cipherText, authenticatedData string) {
+// ErrMalformedPayload is matched by errors returned when an encrypted protocol string cannot be split into its components.
+var ErrMalformedPayload = errors.New("malformed encrypted payload")
+
+// SplitContent splits an encrypted protocol string into its components. Any components after
+// the authenticated data, such as the additional data added by newer clients, are ignored.
+func SplitContent(in string) (version, nonce, cipherText, authenticatedData string, err error) {
 	components := strings.Split(in, ":")
-	if len(components) < 3 {
-		panic(components)
+	if len(components) < 4 {
+		return "", "", "", "", fmt.Errorf("%w: expected at least 4 components but found %d", ErrMalformedPayload, len(components))
 	}
 
 	version = components[0]           // protocol version
@@ -75,36 +79,15 @@ func hexDecodeBytes(in []byte, noBytes int) (dn []byte, err error) {
 }
 
 func DecryptCipherText(cipherText, rawKey, nonce, rawAuthenticatedData string) (result []byte, err error) {
-	dct, err := base64.StdEncoding.DecodeString(cipherText)
-	if err != nil {
-		return nil, err
-	}
-
-	masterKeyBytes := []byte(rawKey)
-
-	dst1, err := hexDecodeBytes(masterKeyBytes, KeySize)
+	key, err := hexDecodeStrings(rawKey, KeySize)
 	if err != nil {
 		return
 	}
 
-	aead, err := chacha20poly1305.NewX(dst1)
-	if err != nil {
-		return nil, err
-	}
-
-	var dst []byte
+	sk := SecretKey(key)
+	defer sk.Destroy()
 
-	hexDecodedNonce, err := hexDecodeStrings(nonce, NonceSizeX)
-	if err != nil {
-		return nil, err
-	}
-
-	plaintext, err := aead.Open(dst, hexDecodedNonce, dct, []byte(rawAuthenticatedData))
-	if err != nil {
-		err = fmt.Errorf("decryptString: %w", err)
-	}
-
-	return plaintext, err
+	return DecryptWithKey(cipherText, sk, nonce, rawAuthenticatedData)
 }
 
 func GenerateItemKey(returnBytes int) string {
@@ -137,31 +120,15 @@ func EncryptString(plainText, key, nonce, authenticatedData string, noBytes int)
 		panic("empty nonce")
 	}
 
-	itemKey := make([]byte, noBytes)
+	itemKey := make(SecretKey, noBytes)
+	defer itemKey.Destroy()
 
 	_, err = hex.Decode(itemKey, []byte(key))
 	if err != nil {
 		return
 	}
 
-	aead, err := chacha20poly1305.NewX(itemKey)
-	if err != nil {
-		panic(err)
-	}
-
-	var encryptedMsg []byte
-
-	msg := []byte(plainText)
-
-	hexDecodedNonce, err := hexDecodeStrings(nonce, NonceSizeX)
-	if err != nil {
-		return
-	}
-
-	// Encrypt the message and append the ciphertext to the nonce.
-	encryptedMsg = aead.Seal(nil, hexDecodedNonce, msg, []byte(authenticatedData))
-
-	return base64.StdEncoding.EncodeToString(encryptedMsg), err
+	return EncryptWithKey([]byte(plainText), itemKey, nonce, authenticatedData)
 }
 
 func generateSalt(identifier, nonce string) []byte {
@@ -197,19 +164,18 @@ type GenerateEncryptedPasswordInput struct {
 	Debug         bool
 }
 
+// GenerateMasterKeyAndServerPassword004 returns the hex encoded master key and server password.
+// The strings returned cannot be wiped, so DeriveKeys004 should be used where possible.
 func GenerateMasterKeyAndServerPassword004(input GenerateEncryptedPasswordInput) (masterKey, serverPassword string, err error) {
-	keyLength := uint32(64)
-	iterations := uint32(5)
-	memory := uint32(64 * 1024)
-	parallel := uint8(1)
-	salt := generateSalt(input.Identifier, input.PasswordNonce)
-	derivedKey := argon2.IDKey([]byte(input.UserPassword), salt, iterations, memory, parallel, keyLength)
-	derivedKeyHex := make([]byte, hex.EncodedLen(len(derivedKey)))
-	hex.Encode(derivedKeyHex, derivedKey)
-	masterKey = string(derivedKeyHex[:64])
-	serverPassword = string(derivedKeyHex[64:])
+	mk, sp, err := DeriveKeys004(input)
+	if err != nil {
+		return
+	}
 
-	return
+	defer mk.Destroy()
+	defer sp.Destroy()
+
+	return mk.Hex(), sp.Hex(), nil
 }
 
 func padToAESBlockSize(b []byte) []byte {
@@ -3,6 +3,7 @@ package crypto
 import (
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -86,3 +87,30 @@ func TestEncryptPlaintextTooLong(t *testing.T) {
 	Encrypt(key, string(long))
 	t.Fatalf("expected panic for long plaintext")
 }
+
+func TestSplitContent(t *testing.T) {
+	version, nonce, cipherText, authData, err := SplitContent("004:nonce:cipher:auth")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if version != "004" || nonce != "nonce" || cipherText != "cipher" || authData != "auth" {
+		t.Fatalf("unexpected components: %s %s %s %s", version, nonce, cipherText, authData)
+	}
+
+	// the additional data component added by newer clients is ignored
+	_, _, cipherText, authData, err = SplitContent("004:nonce:cipher:auth:e30=")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cipherText != "cipher" || authData != "auth" {
+		t.Fatalf("unexpected components: %s %s", cipherText, authData)
+	}
+
+	for _, in := range []string{"", "004", "004:nonce:cipher"} {
+		if _, _, _, _, err = SplitContent(in); !errors.Is(err, ErrMalformedPayload) {
+			t.Fatalf("expected ErrMalformedPayload for %q got %v", in, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package crypto
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Provider implements the cryptographic operations for a single protocol version.
+// Keys are held as SecretKeys so that they can be wiped once finished with. Nonces are
+// hex encoded and cipher texts are base64 encoded so that they can be used directly
+// in encrypted protocol strings.
+type Provider interface {
+	// Version returns the protocol version implemented, e.g. "004".
+	Version() string
+	// DeriveKeys derives the master key and server password from the user's credentials.
+	DeriveKeys(input GenerateEncryptedPasswordInput) (masterKey, serverPassword SecretKey, err error)
+	// GenerateItemKey returns a new random key for encrypting a single item.
+	GenerateItemKey() (SecretKey, error)
+	// GenerateNonce returns a new random nonce for a single encryption.
+	GenerateNonce() (string, error)
+	// Encrypt seals the plain text and returns the cipher text.
+	Encrypt(plainText []byte, key SecretKey, nonce, authenticatedData string) (string, error)
+	// Decrypt opens the cipher text and returns the plain text.
+	Decrypt(cipherText string, key SecretKey, nonce, authenticatedData string) ([]byte, error)
+}
+
+// XChaCha20Provider is the protocol 004 Provider using argon2id key derivation
+// and XChaCha20-Poly1305 encryption.
+type XChaCha20Provider struct {
+	// Rand is the source of randomness for keys and nonces. crypto/rand is used if nil.
+	Rand io.Reader
+}
+
+func (p XChaCha20Provider) random(n int) ([]byte, error) {
+	r := p.Rand
+	if r == nil {
+		r = crand.Reader
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, fmt.Errorf("XChaCha20Provider | failed to read random bytes: %w", err)
+	}
+
+	return b, nil
+}
+
+func (p XChaCha20Provider) Version() string {
+	return ProtocolVersion004
+}
+
+func (p XChaCha20Provider) DeriveKeys(input GenerateEncryptedPasswordInput) (masterKey, serverPassword SecretKey, err error) {
+	return DeriveKeys004(input)
+}
+
+func (p XChaCha20Provider) GenerateItemKey() (SecretKey, error) {
+	return p.random(KeySize)
+}
+
+func (p XChaCha20Provider) GenerateNonce() (string, error) {
+	b, err := p.random(chacha20poly1305.NonceSizeX)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func (p XChaCha20Provider) Encrypt(plainText []byte, key SecretKey, nonce, authenticatedData string) (string, error) {
+	if len(nonce) == 0 {
+		return "", fmt.Errorf("XChaCha20Provider | empty nonce")
+	}
+
+	return EncryptWithKey(plainText, key, nonce, authenticatedData)
+}
+
+func (p XChaCha20Provider) Decrypt(cipherText string, key SecretKey, nonce, authenticatedData string) ([]byte, error) {
+	return DecryptWithKey(cipherText, key, nonce, authenticatedData)
+}
+
+// DefaultProvider is used where no Provider has been specified.
+var DefaultProvider Provider = XChaCha20Provider{}
+
+var (
+	providersMutex sync.RWMutex
+	providers      = map[string]Provider{
+		ProtocolVersion004: DefaultProvider,
+	}
+)
+
+// RegisterProvider makes a Provider available for decrypting payloads of its protocol version,
+// replacing any Provider previously registered for that version.
+func RegisterProvider(p Provider) {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+
+	providers[p.Version()] = p
+}
+
+// GetProvider returns the Provider registered for a protocol version.
+func GetProvider(version string) (Provider, error) {
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
+
+	p, ok := providers[version]
+	if !ok {
+		return nil, fmt.Errorf("no crypto provider registered for protocol version %q", version)
+	}
+
+	return p, nil
+}
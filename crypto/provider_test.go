@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/jonhadfield/gosn-v2/crypto/vectors"
+	"github.com/stretchr/testify/require"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("no entropy")
+}
+
+func TestXChaCha20ProviderDeterministicRand(t *testing.T) {
+	p := XChaCha20Provider{Rand: bytes.NewReader(bytes.Repeat([]byte{0xab}, KeySize+NonceSizeX))}
+
+	key, err := p.GenerateItemKey()
+	require.NoError(t, err)
+	require.Len(t, key, KeySize)
+	require.Equal(t, "abab", key.Hex()[:4])
+
+	nonce, err := p.GenerateNonce()
+	require.NoError(t, err)
+	require.Len(t, nonce, NonceSizeX*2)
+
+	// source exhausted
+	_, err = p.GenerateNonce()
+	require.Error(t, err)
+
+	_, err = XChaCha20Provider{Rand: failingReader{}}.GenerateItemKey()
+	require.Error(t, err)
+}
+
+func TestXChaCha20ProviderVectors(t *testing.T) {
+	p := XChaCha20Provider{}
+	require.Equal(t, ProtocolVersion004, p.Version())
+
+	kd := vectors.KeyDerivation004[0]
+	mk, sp, err := p.DeriveKeys(GenerateEncryptedPasswordInput{
+		UserPassword:  kd.Password,
+		Identifier:    kd.Identifier,
+		PasswordNonce: kd.PasswordNonce,
+	})
+	require.NoError(t, err)
+	require.Equal(t, kd.MasterKey, mk.Hex())
+	require.Equal(t, kd.ServerPassword, sp.Hex())
+
+	for _, v := range vectors.Encryption004 {
+		key, err := NewSecretKey(v.Key)
+		require.NoError(t, err, v.Description)
+
+		ct, err := p.Encrypt([]byte(v.PlainText), key, v.Nonce, v.AuthenticatedData)
+		require.NoError(t, err, v.Description)
+		require.Equal(t, v.CipherText, ct, v.Description)
+
+		pt, err := p.Decrypt(ct, key, v.Nonce, v.AuthenticatedData)
+		require.NoError(t, err, v.Description)
+		require.Equal(t, v.PlainText, string(pt), v.Description)
+	}
+
+	key, err := NewSecretKey(vectors.Encryption004[0].Key)
+	require.NoError(t, err)
+
+	_, err = p.Encrypt([]byte("text"), key, "", "")
+	require.Error(t, err)
+}
+
+type versionedProvider struct {
+	XChaCha20Provider
+	version string
+}
+
+func (p versionedProvider) Version() string {
+	return p.version
+}
+
+func TestProviderRegistry(t *testing.T) {
+	p, err := GetProvider(ProtocolVersion004)
+	require.NoError(t, err)
+	require.Equal(t, DefaultProvider, p)
+
+	_, err = GetProvider("test-unregistered")
+	require.Error(t, err)
+
+	RegisterProvider(versionedProvider{version: "test-registered"})
+
+	p, err = GetProvider("test-registered")
+	require.NoError(t, err)
+	require.Equal(t, "test-registered", p.Version())
+}
@@ -0,0 +1,171 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// ErrAuthenticationFailed is matched by errors returned when cipher text cannot be decrypted,
+// either because the wrong key was used or the cipher text or authenticated data has been altered.
+var ErrAuthenticationFailed = errors.New("message authentication failed")
+
+// SecretKey holds raw key material in a mutable buffer so that it can be wiped
+// with Destroy once no longer required. Unlike a string, the contents are never
+// copied by the runtime, so a destroyed key leaves no readable copies behind.
+type SecretKey []byte
+
+// NewSecretKey decodes a hex encoded key into a SecretKey.
+func NewSecretKey(hexKey string) (SecretKey, error) {
+	return DecodeSecretKey([]byte(hexKey))
+}
+
+// DecodeSecretKey decodes a hex encoded key held in a byte slice into a SecretKey,
+// so that decrypted keys never need to be converted to a string. The caller remains
+// responsible for wiping hexKey.
+func DecodeSecretKey(hexKey []byte) (SecretKey, error) {
+	k := make(SecretKey, hex.DecodedLen(len(hexKey)))
+	if _, err := hex.Decode(k, hexKey); err != nil {
+		k.Destroy()
+
+		return nil, fmt.Errorf("DecodeSecretKey | %w", err)
+	}
+
+	return k, nil
+}
+
+// Destroy overwrites the key material with zeros.
+func (k SecretKey) Destroy() {
+	clear(k)
+}
+
+// Destroyed returns true if the key is empty or has been wiped.
+func (k SecretKey) Destroyed() bool {
+	for _, b := range k {
+		if b != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Hex returns the hex encoding of the key for use with APIs that require a string.
+// The string returned cannot be wiped, so should only be used where unavoidable.
+func (k SecretKey) Hex() string {
+	return hex.EncodeToString(k)
+}
+
+// AppendHex appends the hex encoding of the key to dst. Unlike Hex, the result can be wiped.
+func (k SecretKey) AppendHex(dst []byte) []byte {
+	return hex.AppendEncode(dst, k)
+}
+
+// MarshalJSON encodes the key as a hex string, matching the format used before keys were held as SecretKeys.
+func (k SecretKey) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0, hex.EncodedLen(len(k))+2)
+	b = append(b, '"')
+	b = k.AppendHex(b)
+
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON decodes a hex string into the key without passing through a string.
+func (k *SecretKey) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("SecretKey | expected a hex encoded string")
+	}
+
+	if len(data) == 2 {
+		*k = nil
+
+		return nil
+	}
+
+	d, err := DecodeSecretKey(data[1 : len(data)-1])
+	if err != nil {
+		return err
+	}
+
+	*k = d
+
+	return nil
+}
+
+// String prevents key material from being written to logs.
+func (k SecretKey) String() string {
+	return "[REDACTED]"
+}
+
+// GoString prevents key material from being written to logs with %#v.
+func (k SecretKey) GoString() string {
+	return k.String()
+}
+
+// DeriveKeys004 derives the master key and server password from the user's credentials,
+// returning both as SecretKeys that the caller must Destroy once finished with.
+func DeriveKeys004(input GenerateEncryptedPasswordInput) (masterKey, serverPassword SecretKey, err error) {
+	keyLength := uint32(64)
+	iterations := uint32(5)
+	memory := uint32(64 * 1024)
+	parallel := uint8(1)
+	salt := generateSalt(input.Identifier, input.PasswordNonce)
+	derivedKey := argon2.IDKey([]byte(input.UserPassword), salt, iterations, memory, parallel, keyLength)
+
+	masterKey = make(SecretKey, KeySize)
+	serverPassword = make(SecretKey, KeySize)
+	copy(masterKey, derivedKey[:KeySize])
+	copy(serverPassword, derivedKey[KeySize:])
+	clear(derivedKey)
+
+	return masterKey, serverPassword, nil
+}
+
+// EncryptWithKey seals the plain text with XChaCha20-Poly1305 and returns the base64 encoded cipher text.
+func EncryptWithKey(plainText []byte, key SecretKey, nonce, authenticatedData string) (string, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return "", err
+	}
+
+	hexDecodedNonce, err := hexDecodeStrings(nonce, NonceSizeX)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(aead.Seal(nil, hexDecodedNonce, plainText, []byte(authenticatedData))), nil
+}
+
+// DecryptWithKey opens base64 encoded cipher text sealed with XChaCha20-Poly1305.
+func DecryptWithKey(cipherText string, key SecretKey, nonce, authenticatedData string) ([]byte, error) {
+	dct, err := base64.StdEncoding.DecodeString(cipherText)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	hexDecodedNonce, err := hexDecodeStrings(nonce, NonceSizeX)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, hexDecodedNonce, dct, []byte(authenticatedData))
+	if err != nil {
+		return nil, fmt.Errorf("decryptString: %w: %w", ErrAuthenticationFailed, err)
+	}
+
+	return plaintext, nil
+}
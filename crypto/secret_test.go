@@ -0,0 +1,104 @@
+package crypto
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jonhadfield/gosn-v2/crypto/vectors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretKey(t *testing.T) {
+	t.Parallel()
+
+	hexKey := vectors.Encryption004[0].Key
+
+	k, err := NewSecretKey(hexKey)
+	require.NoError(t, err)
+	require.Len(t, k, KeySize)
+	require.Equal(t, hexKey, k.Hex())
+	require.False(t, k.Destroyed())
+
+	// key material must not be printed
+	require.Equal(t, "[REDACTED]", k.String())
+	require.NotContains(t, fmt.Sprintf("%s %v %#v", k, k, k), hexKey[:8])
+
+	k.Destroy()
+	require.True(t, k.Destroyed())
+	require.Equal(t, make([]byte, KeySize), []byte(k))
+
+	_, err = NewSecretKey("not hex")
+	require.Error(t, err)
+}
+
+func TestSecretKeyJSON(t *testing.T) {
+	t.Parallel()
+
+	hexKey := vectors.Encryption004[0].Key
+
+	k, err := NewSecretKey(hexKey)
+	require.NoError(t, err)
+
+	// keys are persisted in the same hex format used when they were held as strings
+	b, err := json.Marshal(struct {
+		Key   SecretKey `json:"key"`
+		Empty SecretKey `json:"empty"`
+	}{Key: k})
+	require.NoError(t, err)
+	require.JSONEq(t, fmt.Sprintf(`{"key":%q,"empty":""}`, hexKey), string(b))
+
+	var out struct {
+		Key   SecretKey `json:"key"`
+		Empty SecretKey `json:"empty"`
+	}
+
+	require.NoError(t, json.Unmarshal(b, &out))
+	require.Equal(t, k, out.Key)
+	require.Empty(t, out.Empty)
+
+	require.Error(t, json.Unmarshal([]byte(`{"key":"not hex"}`), &out))
+	require.Error(t, json.Unmarshal([]byte(`{"key":1}`), &out))
+}
+
+func TestDeriveKeys004(t *testing.T) {
+	t.Parallel()
+
+	v := vectors.KeyDerivation004[0]
+
+	mk, sp, err := DeriveKeys004(GenerateEncryptedPasswordInput{
+		UserPassword:  v.Password,
+		Identifier:    v.Identifier,
+		PasswordNonce: v.PasswordNonce,
+	})
+	require.NoError(t, err)
+	require.Equal(t, v.MasterKey, mk.Hex())
+	require.Equal(t, v.ServerPassword, sp.Hex())
+
+	mk.Destroy()
+	sp.Destroy()
+	require.True(t, mk.Destroyed())
+	require.True(t, sp.Destroyed())
+}
+
+func TestEncryptDecryptWithKey(t *testing.T) {
+	t.Parallel()
+
+	v := vectors.Encryption004[1]
+
+	k, err := NewSecretKey(v.Key)
+	require.NoError(t, err)
+
+	defer k.Destroy()
+
+	ct, err := EncryptWithKey([]byte(v.PlainText), k, v.Nonce, v.AuthenticatedData)
+	require.NoError(t, err)
+	require.Equal(t, v.CipherText, ct)
+
+	pt, err := DecryptWithKey(ct, k, v.Nonce, v.AuthenticatedData)
+	require.NoError(t, err)
+	require.Equal(t, v.PlainText, string(pt))
+
+	_, err = DecryptWithKey(ct, k, v.Nonce, "other")
+	require.Error(t, err)
+}
@@ -0,0 +1,166 @@
+// Package vectors provides known-answer test vectors for Standard Notes protocol 004.
+//
+// Vectors described as written by the Standard Notes web app come from an encrypted backup
+// the app exported for the account "testuser", with the password "testuser", so they check
+// that gosn derives the same keys and decrypts the same payloads as the official apps.
+// The others pin the output of gosn for fixed inputs, so that any change in behaviour is caught.
+package vectors
+
+// KeyDerivation is the result of deriving the root key from an account's credentials.
+// Salt is the hex encoded argon2 salt generated from the identifier and nonce.
+type KeyDerivation struct {
+	Description    string
+	Password       string
+	Identifier     string
+	PasswordNonce  string
+	Salt           string
+	MasterKey      string
+	ServerPassword string
+}
+
+// Encryption is the result of encrypting a plain text with XChaCha20-Poly1305.
+// AuthenticatedData is passed to the cipher as is, so is the base64 encoded string
+// where the plain text is an item's content or key.
+type Encryption struct {
+	Description       string
+	Key               string
+	Nonce             string
+	AuthenticatedData string
+	PlainText         string
+	CipherText        string
+}
+
+// Item is an encrypted item and the keys needed to decrypt it.
+// Key is the master key for items keys and the items key for all other items.
+// ItemKey is the plain text of EncItemKey and PlainText is the plain text of Content.
+type Item struct {
+	Description string
+	UUID        string
+	ContentType string
+	ItemsKeyID  string
+	Key         string
+	ItemKey     string
+	EncItemKey  string
+	Content     string
+	PlainText   string
+}
+
+const (
+	identifier    = "vectors@example.com"
+	passwordNonce = "4f3b0b6f0e0d6a8c1b3e5a7c9d2f4e6a8b0c2d4e6f8a0b2c4d6e8f0a2b4c6d8e"
+	masterKey     = "5a6cc239026d528a10bb539a1fcdfa4b568913c76d60ed8379f8fd047ea60b7a"
+	itemsKeyUUID  = "0d1f8c5a-6b1e-4c52-9a3f-3f7c2b8e9a10"
+	itemsKey      = "8d5e7b2a4c6f9e1d3b5a7c9e0f2d4b6a8c0e2f4d6b8a0c2e4f6d8b0a2c4e6f8a"
+
+	officialMasterKey    = "aa33e44e77c0dc6c0771ba0b0ce6660e9f463968c54fcd024ea66541ce2b245d"
+	officialItemsKeyUUID = "17680236-e597-44eb-95c2-581377b7692a"
+	officialItemsKey     = "298ce8bc0662b98a4cfb7c392d97727440913997addcc6eab42c3dae747590c2"
+)
+
+// KeyDerivation004 are vectors for deriving the master key and server password.
+var KeyDerivation004 = []KeyDerivation{
+	{
+		Description:    "account with an email identifier",
+		Password:       "debugtest",
+		Identifier:     "sn004@lessknown.co.uk",
+		PasswordNonce:  "2c409996650e46c748856fbd6aa549f89f35be055a8f9bfacdf0c4b29b2152e9",
+		Salt:           "7129955dbbbfb376fdcac49890ef17bc",
+		MasterKey:      "2396d6ac0bc70fe45db1d2bcf3daa522603e9c6fcc88dc933ce1a3a31bbc08ed",
+		ServerPassword: "a5eb9fbc767eafd6e54fd9d3646b19520e038ba2ccc9cceddf2340b37b788b47",
+	},
+	{
+		Description:    "passphrase containing spaces",
+		Password:       "correct horse battery staple",
+		Identifier:     identifier,
+		PasswordNonce:  passwordNonce,
+		MasterKey:      masterKey,
+		ServerPassword: "f8edf37c3e3dd0eab1fe6c07ba9383107d804545c0006e167a8fff895c7eb1ea",
+	},
+	{
+		Description:    "account registered with the Standard Notes web app",
+		Password:       "testuser",
+		Identifier:     "testuser",
+		PasswordNonce:  "iS6qXMblCCiIoW5TndjYAALO3kZ68wnz",
+		MasterKey:      officialMasterKey,
+		ServerPassword: "84eabc59e9f7b91c84d20454e3401673bb356d7cc9e938b4b36a6e937aa784c8",
+	},
+}
+
+// Encryption004 are vectors for encrypting and decrypting a single payload component.
+var Encryption004 = []Encryption{
+	{
+		Description:       "item key with key params in its authenticated data",
+		Key:               "e73faf921cc265b7a001451d8760a6a6e2270d0dbf1668f9971fd75c8018ffd4",
+		Nonce:             "d211fc5dee400fe54ca04ac43ecac512c9d0dabb6c4ee0f3",
+		AuthenticatedData: "eyJrcCI6eyJpZGVudGlmaWVyIjoiZ29zbi12MkBsZXNza25vd24uY28udWsiLCJwd19ub25jZSI6ImIzYjc3Yzc5YzlmZWE5ODY3MWU2NmFmNDczMzZhODhlNWE1MTUyMjI4YjEwMTQ2NDEwM2M1MjJiMWUzYWU0ZGEiLCJ2ZXJzaW9uIjoiMDA0Iiwib3JpZ2luYXRpb24iOiJyZWdpc3RyYXRpb24iLCJjcmVhdGVkIjoiMTYwODEzNDk0NjY5MiJ9LCJ1IjoiNjI3YTg4YTAtY2NkNi00YTY4LWFjZWUtYjM0ODQ5NDZmMjY1IiwidiI6IjAwNCJ9",
+		PlainText:         "9381f4ac4371cd9e31c3389442897d5c7de3da3d787927709ab601e28767d18a",
+		CipherText:        "kRd2w+7FQBIXaNGze7G28GOIUSngrqtx/t5Jus76z3z+eM18GkJT7Lc/ZpqJiH9I6fdksNdo6uvfip8TCIT458XxcrqIP24Bxk9xaz2Q9IQ=",
+	},
+	{
+		Description:       "note content with unencoded authenticated data",
+		Key:               "b396412f690bfb40801c764af7975bc019f3de79b1ed24385e98787aff81c003",
+		Nonce:             "6045eaf9774a877203b68bb12159f9c5c0c3d19df4949e40",
+		AuthenticatedData: `{"u":"7eacf350-f4ce-44dd-8525-2457b19047dd","v":"004"}`,
+		PlainText:         `{"text":"Note Text","title":"Note Title","references":[],"appData":{"org.standardnotes.sn":{"client_updated_at":"2021-03-20T12:59:46.734Z"}},"preview_plain":"Note Text"}`,
+		CipherText:        "B+8vUwmSTGZCba6mU2gMSMl55fpt38Wv/yWxAF4pEveX0sjqSYgjT5PA8/yy7LKotF+kjmuiHNvYtH7hB7BaqJrG8Q4G5Sj15tIu8PtlWECJWHnPxHkeiJW1MiS1ypR0t3y+Uc7cRpGPwnQIqJDr/Yl1vp2tZXlaSy0zYtGYlw5GwUnLxXtQBQC1Ml3rzZDpaIT9zIr9Qluv7Q7JXOJ7rAbj95MtsV2CJD4RwjhhJ11fpI3N8+uXqp4=",
+	},
+}
+
+// Items004 are vectors for complete encrypted items. The first items key is encrypted with the
+// master key of the second key derivation vector and the first note with that items key. The
+// items written by the Standard Notes web app are encrypted with the keys of the last vector.
+var Items004 = []Item{
+	{
+		Description: "items key with key params in its authenticated data",
+		UUID:        itemsKeyUUID,
+		ContentType: "SN|ItemsKey",
+		Key:         masterKey,
+		ItemKey:     "5b8e1f4a7d0c3b6e9f2a5d8c1b4e7a0d3c6f9b2e5a8d1c4f7b0e3a6d9c2f5b8e",
+		EncItemKey:  "004:8192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8:D4kwxg/xn6K+2PvVLV9H9liP9cgSod8n/qlk6a8jXhyUZiewknBAMmEmlNBW+0plwG6o1oU4AfRGfmaJfbfSkmbekGGvysKsbDvou0Bqch4=:eyJrcCI6eyJjcmVhdGVkIjoiMTcwMDAwMDAwMDAwMCIsImlkZW50aWZpZXIiOiJ2ZWN0b3JzQGV4YW1wbGUuY29tIiwib3JpZ2luYXRpb24iOiJyZWdpc3RyYXRpb24iLCJwd19ub25jZSI6IjRmM2IwYjZmMGUwZDZhOGMxYjNlNWE3YzlkMmY0ZTZhOGIwYzJkNGU2ZjhhMGIyYzRkNmU4ZjBhMmI0YzZkOGUiLCJ2ZXJzaW9uIjoiMDA0In0sInUiOiIwZDFmOGM1YS02YjFlLTRjNTItOWEzZi0zZjdjMmI4ZTlhMTAiLCJ2IjoiMDA0In0=",
+		Content:     "004:1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f7081:KVhHIJa/2V3uV9qBF8pN/wYXbohE/Fvui+itlHEj4bq5h2KncqeqfHZhNBxJWE5qmR46acrvzUsI2ONhkUp2/XsCHLXHjNCxStb5u+geqkupIB2ypKk82bNADyeOiXUWaat4c9ar1VJHVEaLPirnDKVG1CFlOjkZ3xAxTD9ZJIKJqjvu+cdpOSRPZ4J9lxBlAuTH9v2Dn30K7Tv2rg==:eyJrcCI6eyJjcmVhdGVkIjoiMTcwMDAwMDAwMDAwMCIsImlkZW50aWZpZXIiOiJ2ZWN0b3JzQGV4YW1wbGUuY29tIiwib3JpZ2luYXRpb24iOiJyZWdpc3RyYXRpb24iLCJwd19ub25jZSI6IjRmM2IwYjZmMGUwZDZhOGMxYjNlNWE3YzlkMmY0ZTZhOGIwYzJkNGU2ZjhhMGIyYzRkNmU4ZjBhMmI0YzZkOGUiLCJ2ZXJzaW9uIjoiMDA0In0sInUiOiIwZDFmOGM1YS02YjFlLTRjNTItOWEzZi0zZjdjMmI4ZTlhMTAiLCJ2IjoiMDA0In0=",
+		PlainText:   `{"itemsKey":"` + itemsKey + `","version":"004","isDefault":true,"references":[],"appData":{}}`,
+	},
+	{
+		Description: "note encrypted with the items key",
+		UUID:        "7a4e2c1b-9d3f-4e8a-b6c5-1f0e9d8c7b6a",
+		ContentType: "Note",
+		ItemsKeyID:  itemsKeyUUID,
+		Key:         itemsKey,
+		ItemKey:     "c3f6a9d2e5b8c1f4a7d0e3b6c9f2a5d8e1b4c7f0a3d6e9b2c5f8a1d4e7b0c3f6",
+		EncItemKey:  "004:f00e1d2c3b4a59687786a5b4c3d2e1f0f00e1d2c3b4a5968:X76SddJidiplvZvGC4iNdSUQM+jR/fjsgzsuu1FaTlfYwMruOlU84UIu8izRBmz74HQIZcxzqhE96ouOqHiILrM5UZFH8dAB2ttLQm/9fJw=:eyJ1IjoiN2E0ZTJjMWItOWQzZi00ZThhLWI2YzUtMWYwZTlkOGM3YjZhIiwidiI6IjAwNCJ9",
+		Content:     "004:0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a6978:YsWiJVn2392S8xZf7qG+3lFoNwbdpx4TWya7GcAyHcJRreCzEG1JBps//7QPKOHYjlp7fyLUlA4FpnnbW/w25rJKT14rXF5jkdM3Q34m9nZ5uu7OJ1hsxluJiYi4N3Pu6aswjUjP:eyJ1IjoiN2E0ZTJjMWItOWQzZi00ZThhLWI2YzUtMWYwZTlkOGM3YjZhIiwidiI6IjAwNCJ9",
+		PlainText:   `{"title":"Vector Note","text":"protocol 004 test vector","references":[],"appData":{}}`,
+	},
+	{
+		Description: "items key written by the Standard Notes web app",
+		UUID:        officialItemsKeyUUID,
+		ContentType: "SN|ItemsKey",
+		Key:         officialMasterKey,
+		ItemKey:     "67a628ce8a3a073ead586dcbfb5f1c22d5ba28783ed7480d444c7ed099b5999d",
+		EncItemKey:  "004:f3e3724d88a3a80c8000aec05a0f242fbd00a9bd4fb4c609:u5brFIcfXPYoPIJouHdwhIUU0QGtVVv6O1r0DIs3DeuIIGWHjv9pN85frWBqv1ADrHb1XfFUzNCx9MH9fmpS/0SAEFALLoxFqgmA0IQJtTY=:eyJrcCI6eyJjcmVhdGVkIjoiMTYwODQ3MzM4Nzc5OSIsImlkZW50aWZpZXIiOiJ0ZXN0dXNlciIsIm9yaWdpbmF0aW9uIjoicmVnaXN0cmF0aW9uIiwicHdfbm9uY2UiOiJpUzZxWE1ibENDaUlvVzVUbmRqWUFBTE8za1o2OHdueiIsInZlcnNpb24iOiIwMDQifSwidSI6IjE3NjgwMjM2LWU1OTctNDRlYi05NWMyLTU4MTM3N2I3NjkyYSIsInYiOiIwMDQifQ==",
+		Content:     "004:d0693e54b30a229d10b2d39bde12d52c3b289a0dd3689187:sk5PI3rXDXF3Uux7DiEHkl4R1JetcltVXPACnhumrRepSzmLdAme6qSHnRQdearOoZquysnKcP3Qx82ZDy29BfrUohOP2TbgddgaNsX5e3Gpq8Y5QKabnVHQcqcblEXW0sZ5eCLRRKn4KNkjy+AbMrY6Tdw4M/hFAJQTIB0D1lqIbZAZ92BbFTM1eIHBg+6IYC1SNCpiVGxnm1Vc/ymN6opgQwFxD/BsBcnnNrmLQgwiKE/W1NtblTZy2e92IXFJIKGlzebZYd/j5ekR1oA/BrC3a+JPkuLUKt3VCUGaGYd5AF7mVGdYzwfHGTzDPXzgvyrMQgs646ZwhdRQnWvRPJbQLHg8R0Nh5Lp8BcXTzMqdy5EBgWv7Af7mdBq936r1Uj+YKSTSQ9NcM4dRZw==:eyJrcCI6eyJjcmVhdGVkIjoiMTYwODQ3MzM4Nzc5OSIsImlkZW50aWZpZXIiOiJ0ZXN0dXNlciIsIm9yaWdpbmF0aW9uIjoicmVnaXN0cmF0aW9uIiwicHdfbm9uY2UiOiJpUzZxWE1ibENDaUlvVzVUbmRqWUFBTE8za1o2OHdueiIsInZlcnNpb24iOiIwMDQifSwidSI6IjE3NjgwMjM2LWU1OTctNDRlYi05NWMyLTU4MTM3N2I3NjkyYSIsInYiOiIwMDQifQ==",
+		PlainText:   `{"itemsKey":"` + officialItemsKey + `","version":"004","references":[],"appData":{"org.standardnotes.sn":{"client_updated_at":"Sat Jan 29 2022 16:23:35 GMT+0000 (Greenwich Mean Time)","prefersPlainEditor":false,"pinned":false}},"isDefault":true}`,
+	},
+	{
+		Description: "note written by the Standard Notes web app",
+		UUID:        "e04385a9-8f20-4b04-8769-16c18bbee7e9",
+		ContentType: "Note",
+		ItemsKeyID:  officialItemsKeyUUID,
+		Key:         officialItemsKey,
+		ItemKey:     "a7171263c69634723a7b8be09ddc9df29ffee38604806e0198fa1023ce5a213a",
+		EncItemKey:  "004:86650685188678ac238eccc05fdf93087b7a6f9f62c3175c:cvgoXsmYgfTc7lOu79wbafc6T/JrpXbg6VFGsf5dRad4Y1loHv54yKJZ6hZiOqfpej+mjpbmLx/Cr1VzGfntjVyJhqBQkLwk6Wv4WiJJDZo=:eyJ1IjoiZTA0Mzg1YTktOGYyMC00YjA0LTg3NjktMTZjMThiYmVlN2U5IiwidiI6IjAwNCJ9",
+		Content:     "004:26003a2f8d064b2b362e322941120a0319dfa1654c1dc822:asYNistYNzV4JghP/rs0/vW2Q9yaO4VzJb+lJUfnJZRUTdBdrKkIbSFWaRE7aZgmx4cJ9dlFQ5rcClEF5kRQKWvYdiew/+axCwqP7oMgjuRLl7TPAZzRpoQ+8c5j8ua2tFeLIEcEo0EYU1GiPEfUQWdB6gHJHqYRbaCxssdxE99yXI1h6kVXBoJm8NO1Wn0AuHT+Kn+R1FgwiySMgRe3qc1BFWUndcGTiST/YEK1/GQ=:eyJ1IjoiZTA0Mzg1YTktOGYyMC00YjA0LTg3NjktMTZjMThiYmVlN2U5IiwidiI6IjAwNCJ9",
+		PlainText:   `{"text":"dog text","title":"dog","references":[],"appData":{"org.standardnotes.sn":{"client_updated_at":"2022-01-29T16:25:27.654Z"}},"preview_plain":"dog text"}`,
+	},
+	{
+		Description: "tag written by the Standard Notes web app",
+		UUID:        "82b0c00f-f821-495b-981f-bf5f55577ec1",
+		ContentType: "Tag",
+		ItemsKeyID:  officialItemsKeyUUID,
+		Key:         officialItemsKey,
+		ItemKey:     "4ce67a2d7aa325f7d80a8f0c61326b99d11ff14b75b4c7749fc713edb5b0b5f7",
+		EncItemKey:  "004:927cc630086aa8336d88fd5ca9291d7a3e27bcbc14bb6fa5:j+cuRZilX9b/4vormuHMO0co+G6azXazBRBh4yGdueHkImmsVLfw8PMqr4A2YeozkwmIPO9/rZNaNPPZUAKA/NWu83srqaDefi+0zHUInCc=:eyJ1IjoiODJiMGMwMGYtZjgyMS00OTViLTk4MWYtYmY1ZjU1NTc3ZWMxIiwidiI6IjAwNCJ9",
+		Content:     "004:2f200fb871787719753a2e7ca9b763fdb576af843743f12f:rMhdHGlDw7hhVmhzoGE1tSEtGwrWMvEEePxD9lRuKToD1YQ89y+56URzePb+uKyhVLT8o8UmOVkxxeH7NyIHN3+je6OyXG8iI2/XHFHjf3FY7qT0utSG07tt7iPNOdV0+YplL+SSZ+52zNQco80jv916eW5ZvCCBlu0MinRXvu+g/i8iGOTjL2dRwdoMOVv8Rw7JvFhdIzAvJnr3AJYgr5px10bze/Yv7M052byhpeIE7Cd3W90CgVDXWMvtvcXLe/vqfSxZq8phBNmwfBPi8PmX3nz118Rhmy3d4LMj2mmWL9HoogaVNcxwS9przwGbkzaH6Kf9z7mhfIN1Iv1yk2rwOewxHncO1JmYgIeMJYLaFxR94RERSt5ah03s8kur2skfuk+8F2dfNZnZUNWzfWPMAMS/NiofIyvlsUazlM4Lzxwx7gXvYJ1u2r2XKIbgTs6ARo778TU=:eyJ1IjoiODJiMGMwMGYtZjgyMS00OTViLTk4MWYtYmY1ZjU1NTc3ZWMxIiwidiI6IjAwNCJ9",
+		PlainText:   `{"references":[{"uuid":"a86c6ee1-dcc2-44e9-9928-f48ea4e6088b","content_type":"Note"},{"uuid":"99450c45-aaca-4948-9bc3-ff43ace7a606","content_type":"Note"},{"uuid":"62ec65ca-e737-4dd5-b376-39b8fa9299d6","content_type":"Note"}],"appData":{"org.standardnotes.sn":{"client_updated_at":"2022-01-29T16:25:45.913Z"}},"title":"planets"}`,
+	},
+}
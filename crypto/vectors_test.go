@@ -0,0 +1,107 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jonhadfield/gosn-v2/crypto/vectors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyDerivationVectors004(t *testing.T) {
+	t.Parallel()
+
+	for _, v := range vectors.KeyDerivation004 {
+		if v.Salt != "" {
+			require.Equal(t, v.Salt, hex.EncodeToString(generateSalt(v.Identifier, v.PasswordNonce)), v.Description)
+		}
+
+		masterKey, serverPassword, err := GenerateMasterKeyAndServerPassword004(GenerateEncryptedPasswordInput{
+			UserPassword:  v.Password,
+			Identifier:    v.Identifier,
+			PasswordNonce: v.PasswordNonce,
+		})
+		require.NoError(t, err, v.Description)
+		require.Equal(t, v.MasterKey, masterKey, v.Description)
+		require.Equal(t, v.ServerPassword, serverPassword, v.Description)
+	}
+}
+
+func TestEncryptionVectors004(t *testing.T) {
+	t.Parallel()
+
+	for _, v := range vectors.Encryption004 {
+		cipherText, err := EncryptString(v.PlainText, v.Key, v.Nonce, v.AuthenticatedData, KeySize)
+		require.NoError(t, err, v.Description)
+		require.Equal(t, v.CipherText, cipherText, v.Description)
+
+		plainText, err := DecryptCipherText(v.CipherText, v.Key, v.Nonce, v.AuthenticatedData)
+		require.NoError(t, err, v.Description)
+		require.Equal(t, v.PlainText, string(plainText), v.Description)
+	}
+}
+
+func TestItemVectors004(t *testing.T) {
+	t.Parallel()
+
+	for _, v := range vectors.Items004 {
+		_, err := VerifyPayload(v.EncItemKey, v.UUID)
+		require.NoError(t, err, v.Description)
+
+		keyPayload, err := ParsePayload(v.EncItemKey)
+		require.NoError(t, err, v.Description)
+
+		itemKey, err := DecryptCipherText(keyPayload.CipherText, v.Key, keyPayload.Nonce, keyPayload.AuthenticatedData)
+		require.NoError(t, err, v.Description)
+		require.Equal(t, v.ItemKey, string(itemKey), v.Description)
+
+		_, err = VerifyPayload(v.Content, v.UUID)
+		require.NoError(t, err, v.Description)
+
+		contentPayload, err := ParsePayload(v.Content)
+		require.NoError(t, err, v.Description)
+
+		content, err := DecryptCipherText(contentPayload.CipherText, string(itemKey), contentPayload.Nonce, contentPayload.AuthenticatedData)
+		require.NoError(t, err, v.Description)
+		require.Equal(t, v.PlainText, string(content), v.Description)
+
+		// the payload must not decrypt once bound to a different item
+		otherAD := base64.StdEncoding.EncodeToString([]byte(`{"u":"00000000-0000-0000-0000-000000000000","v":"004"}`))
+		_, err = DecryptCipherText(contentPayload.CipherText, string(itemKey), contentPayload.Nonce, otherAD)
+		require.Error(t, err, v.Description)
+	}
+}
+
+func TestVerifyPayload(t *testing.T) {
+	t.Parallel()
+
+	v := vectors.Items004[1]
+
+	ad, err := VerifyPayload(v.Content, v.UUID)
+	require.NoError(t, err)
+	require.Equal(t, v.UUID, ad.UUID)
+	require.Equal(t, ProtocolVersion004, ad.Version)
+	require.Nil(t, ad.KeyParams)
+
+	ad, err = VerifyPayload(vectors.Items004[0].Content, vectors.Items004[0].UUID)
+	require.NoError(t, err)
+	require.NotNil(t, ad.KeyParams)
+	require.Equal(t, "vectors@example.com", ad.KeyParams.Identifier)
+
+	p, err := ParsePayload(v.Content)
+	require.NoError(t, err)
+
+	for name, in := range map[string]string{
+		"wrong uuid":          vectors.Items004[0].Content,
+		"too few components":  "004:" + p.Nonce + ":" + p.CipherText,
+		"unsupported version": "003:" + p.Nonce + ":" + p.CipherText + ":" + p.AuthenticatedData,
+		"short nonce":         "004:" + p.Nonce[2:] + ":" + p.CipherText + ":" + p.AuthenticatedData,
+		"invalid cipher text": "004:" + p.Nonce + ":not base64!:" + p.AuthenticatedData,
+		"short cipher text":   "004:" + p.Nonce + ":AAAA:" + p.AuthenticatedData,
+		"invalid auth data":   "004:" + p.Nonce + ":" + p.CipherText + ":e30",
+	} {
+		_, err = VerifyPayload(in, v.UUID)
+		require.ErrorIs(t, err, ErrInvalidPayload, name)
+	}
+}
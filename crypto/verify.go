@@ -0,0 +1,120 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// ProtocolVersion004 is the only protocol version this package can encrypt and verify.
+const ProtocolVersion004 = "004"
+
+// ErrInvalidPayload is matched by errors returned when an encrypted payload fails verification.
+var ErrInvalidPayload = errors.New("invalid encrypted payload")
+
+// Payload is an encrypted protocol string split into its components.
+type Payload struct {
+	Version           string
+	Nonce             string
+	CipherText        string
+	AuthenticatedData string
+}
+
+// KeyParams are the key parameters embedded in the authenticated data of items keys.
+type KeyParams struct {
+	Created     string `json:"created"`
+	Identifier  string `json:"identifier"`
+	Origination string `json:"origination"`
+	PwNonce     string `json:"pw_nonce"`
+	Version     string `json:"version"`
+}
+
+// AuthenticatedData is the additional data bound to an encrypted payload.
+// KeyParams are only present for payloads encrypted with the master key.
+type AuthenticatedData struct {
+	KeyParams *KeyParams `json:"kp,omitempty"`
+	UUID      string     `json:"u"`
+	Version   string     `json:"v"`
+}
+
+func invalidPayload(format string, a ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidPayload, fmt.Sprintf(format, a...))
+}
+
+// ParsePayload splits an encrypted protocol string and checks each component is well-formed.
+// The cipher text is not decrypted.
+func ParsePayload(in string) (Payload, error) {
+	version, nonce, cipherText, authenticatedData, err := SplitContent(in)
+	if err != nil {
+		return Payload{}, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+
+	p := Payload{
+		Version:           version,
+		Nonce:             nonce,
+		CipherText:        cipherText,
+		AuthenticatedData: authenticatedData,
+	}
+
+	if p.Version != ProtocolVersion004 {
+		return Payload{}, invalidPayload("unsupported protocol version %q", p.Version)
+	}
+
+	if nonce, err := hex.DecodeString(p.Nonce); err != nil || len(nonce) != NonceSizeX {
+		return Payload{}, invalidPayload("nonce must be %d hex encoded bytes", NonceSizeX)
+	}
+
+	ct, err := base64.StdEncoding.DecodeString(p.CipherText)
+	if err != nil {
+		return Payload{}, invalidPayload("cipher text is not base64 encoded")
+	}
+
+	if len(ct) < chacha20poly1305.Overhead {
+		return Payload{}, invalidPayload("cipher text is shorter than the authentication tag")
+	}
+
+	return p, nil
+}
+
+// DecodeAuthenticatedData returns the authenticated data bound to the payload.
+func (p Payload) DecodeAuthenticatedData() (ad AuthenticatedData, err error) {
+	b, err := base64.StdEncoding.DecodeString(p.AuthenticatedData)
+	if err != nil {
+		return ad, invalidPayload("authenticated data is not base64 encoded")
+	}
+
+	if err = json.Unmarshal(b, &ad); err != nil {
+		return ad, invalidPayload("authenticated data is not valid json: %s", err)
+	}
+
+	return ad, nil
+}
+
+// VerifyPayload checks an encrypted protocol string is well-formed and that its
+// authenticated data binds it to the specified item UUID and protocol version.
+func VerifyPayload(in, uuid string) (AuthenticatedData, error) {
+	p, err := ParsePayload(in)
+	if err != nil {
+		return AuthenticatedData{}, err
+	}
+
+	ad, err := p.DecodeAuthenticatedData()
+	if err != nil {
+		return ad, err
+	}
+
+	switch {
+	case ad.UUID != uuid:
+		return ad, invalidPayload("authenticated data uuid %q does not match item uuid %q", ad.UUID, uuid)
+	case ad.Version != p.Version:
+		return ad, invalidPayload("authenticated data version %q does not match payload version %q", ad.Version, p.Version)
+	case ad.KeyParams != nil && ad.KeyParams.Version != p.Version:
+		return ad, invalidPayload("key params version %q does not match payload version %q", ad.KeyParams.Version, p.Version)
+	}
+
+	return ad, nil
+}
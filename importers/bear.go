@@ -0,0 +1,266 @@
+package importers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jonhadfield/gosn-v2/items"
+)
+
+const bearNoteExtension = ".bearnote"
+
+// Bear imports a Bear backup (.bear2bk), or a directory of .bearnote bundles or Markdown files.
+// The first heading of a note is its title, and the #tags in its text, including nested tags
+// such as #work/projects, become tags.
+type Bear struct{}
+
+type bearInfo struct {
+	Bear struct {
+		CreationDate     time.Time `json:"creationDate"`
+		ModificationDate time.Time `json:"modificationDate"`
+		Pinned           bearFlag  `json:"pinned"`
+		Archived         bearFlag  `json:"archived"`
+		Trashed          bearFlag  `json:"trashed"`
+	} `json:"net.shinyfrog.bear"`
+}
+
+// bearFlag is a boolean that Bear writes as either 0 and 1, or false and true.
+type bearFlag bool
+
+func (f *bearFlag) UnmarshalJSON(b []byte) error {
+	switch string(b) {
+	case "1", "true":
+		*f = true
+	case "0", "false", "null":
+		*f = false
+	default:
+		return fmt.Errorf("invalid flag %s", b)
+	}
+
+	return nil
+}
+
+// Import implements Importer.
+func (Bear) Import(p string, existing items.Items) (Result, error) {
+	fsys, file, closer, err := openExport(p)
+	if err != nil {
+		return Result{}, fmt.Errorf("Bear.Import | %w", err)
+	}
+
+	defer closer()
+
+	b := newBatch(existing)
+
+	// a single bundle
+	if strings.HasSuffix(filepath.Clean(p), bearNoteExtension) {
+		if err = b.addBearNote(os.DirFS(filepath.Dir(p)), filepath.Base(p)); err != nil {
+			return Result{}, fmt.Errorf("Bear.Import | %w", err)
+		}
+
+		return b.finish()
+	}
+
+	// a bundle's text is imported along with its info, and the rest of its files are attachments
+	bundles := make(map[string]bool)
+
+	match := func(name string) bool {
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			if strings.HasSuffix(dir, bearNoteExtension) {
+				if !bundles[dir] {
+					bundles[dir] = true
+
+					return true
+				}
+
+				return false
+			}
+		}
+
+		switch strings.ToLower(path.Ext(name)) {
+		case ".md", ".markdown", ".txt":
+			return true
+		}
+
+		return false
+	}
+
+	err = walkExport(fsys, file, match, func(name string) error {
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			if strings.HasSuffix(dir, bearNoteExtension) {
+				return b.addBearNote(fsys, dir)
+			}
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		fi, err := fs.Stat(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		var info bearInfo
+		info.Bear.ModificationDate = fi.ModTime()
+
+		return b.addBear(strings.TrimSuffix(path.Base(name), path.Ext(name)), string(data), info)
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("Bear.Import | %w", err)
+	}
+
+	return b.finish()
+}
+
+func (b *batch) addBearNote(fsys fs.FS, dir string) error {
+	var data []byte
+
+	var err error
+
+	for _, name := range []string{"text.markdown", "text.md", "text.txt"} {
+		if data, err = fs.ReadFile(fsys, path.Join(dir, name)); !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var info bearInfo
+
+	if infoData, err := fs.ReadFile(fsys, path.Join(dir, "info.json")); err == nil {
+		if err = json.Unmarshal(infoData, &info); err != nil {
+			b.warn(dir, "invalid info.json: %s", err)
+		}
+	}
+
+	if assets, err := fs.ReadDir(fsys, path.Join(dir, "assets")); err == nil && len(assets) > 0 {
+		b.warn(dir, "%d attachments not imported", len(assets))
+	}
+
+	return b.addBear(strings.TrimSuffix(path.Base(dir), bearNoteExtension), string(data), info)
+}
+
+func (b *batch) addBear(name, text string, info bearInfo) error {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	title := name
+
+	// Bear uses the first line as the title, written as a heading
+	if first, rest := splitTitle(text); strings.HasPrefix(first, "# ") {
+		title, text = strings.TrimSpace(strings.TrimPrefix(first, "# ")), rest
+	}
+
+	note, err := newNote(title, strings.Trim(text, "\n"), info.Bear.CreationDate, info.Bear.ModificationDate)
+	if err != nil {
+		return err
+	}
+
+	note.SetPinned(bool(info.Bear.Pinned))
+	note.SetArchived(bool(info.Bear.Archived))
+
+	if info.Bear.Trashed {
+		note.Content.SetTrashed(true)
+	}
+
+	return b.add(note, bearTags(text))
+}
+
+// bearTags returns the tags in Markdown, written as #tag, #parent/child or #multiple words#.
+// Tags in code, headings and links are ignored.
+func bearTags(text string) []string {
+	var tags []string
+
+	seen := make(map[string]bool)
+
+	fenced := false
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+
+			continue
+		}
+
+		if fenced {
+			continue
+		}
+
+		for _, tag := range bearLineTags(line) {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+func bearLineTags(line string) []string {
+	var tags []string
+
+	r := []rune(line)
+
+	inCode := false
+
+	for x := 0; x < len(r); x++ {
+		if r[x] == '`' {
+			inCode = !inCode
+
+			continue
+		}
+
+		if inCode || r[x] != '#' || x > 0 && !unicode.IsSpace(r[x-1]) || x+1 == len(r) || unicode.IsSpace(r[x+1]) || r[x+1] == '#' {
+			continue
+		}
+
+		rest := r[x+1:]
+
+		// a multi-word tag ends with a # that does not follow a space
+		if end := slices.Index(rest, '#'); end > 0 {
+			if candidate := rest[:end]; isBearMultiWordTag(candidate) {
+				tags = append(tags, string(candidate))
+				x += end + 1
+
+				continue
+			}
+		}
+
+		end := x + 1
+		for end < len(r) && !unicode.IsSpace(r[end]) && r[end] != '#' {
+			end++
+		}
+
+		tag := strings.TrimRight(string(r[x+1:end]), ".,;:!?)]}\"'/")
+		if tag != "" && strings.Trim(tag, "0123456789") != "" {
+			tags = append(tags, tag)
+		}
+
+		x = end
+	}
+
+	return tags
+}
+
+// isBearMultiWordTag returns whether the text between a # and the next # is a tag of several words.
+func isBearMultiWordTag(r []rune) bool {
+	if unicode.IsSpace(r[len(r)-1]) || !slices.ContainsFunc(r, unicode.IsSpace) {
+		return false
+	}
+
+	return !slices.ContainsFunc(r, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c) && !unicode.IsSpace(c) && !strings.ContainsRune("/-_", c)
+	})
+}
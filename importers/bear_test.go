@@ -0,0 +1,78 @@
+package importers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/items"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBearTags(t *testing.T) {
+	text := "# Title\n" +
+		"#work/projects and #home, also #multi word tag# and #work/projects again\n" +
+		"not a tag: issue #42, a#b, [link](http://example.com/#anchor), `#code`\n" +
+		"## Heading\n" +
+		"```\n#fenced\n```\n" +
+		"#last"
+
+	require.Equal(t, []string{"work/projects", "home", "multi word tag", "last"}, bearTags(text))
+}
+
+func TestBearImport(t *testing.T) {
+	dir := t.TempDir()
+
+	bundle := filepath.Join(dir, "Plan.bearnote")
+	require.NoError(t, os.MkdirAll(filepath.Join(bundle, "assets"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(bundle, "text.markdown"), []byte("# Plan\n\nship it #work/projects\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(bundle, "assets", "diagram.png"), []byte("png"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(bundle, "info.json"), []byte(`{
+		"net.shinyfrog.bear": {
+			"creationDate": "2023-01-02T03:04:05Z",
+			"modificationDate": "2023-02-03T04:05:06Z",
+			"pinned": 1,
+			"archived": 0,
+			"trashed": 0
+		}
+	}`), 0o600))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "loose.md"), []byte("no heading #work"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "image.png"), []byte("png"), 0o600))
+
+	work, err := items.NewTag("work", nil)
+	require.NoError(t, err)
+
+	res, err := Bear{}.Import(dir, items.Items{&work})
+	require.NoError(t, err)
+	require.Equal(t, 2, res.Report.Notes)
+	require.Equal(t, 1, res.Report.TagsCreated)
+	require.Len(t, res.Report.Warnings, 1)
+
+	plan, loose := res.Notes[0], res.Notes[1]
+
+	require.Equal(t, "Plan", plan.Content.Title)
+	require.Equal(t, "ship it #work/projects", plan.Content.Text)
+	require.True(t, plan.IsPinned())
+	require.Equal(t, time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC).UnixMicro(), plan.CreatedAtTimestamp)
+
+	require.Equal(t, "loose", loose.Content.Title)
+	require.Equal(t, "no heading #work", loose.Content.Text)
+
+	// projects is created within the existing work tag
+	require.Len(t, res.Tags, 2)
+	require.Equal(t, work.UUID, res.Tags[0].UUID)
+	require.Equal(t, loose.UUID, res.Tags[0].Content.ItemReferences[0].UUID)
+
+	projects := res.Tags[1]
+	require.Equal(t, "projects", projects.Content.Title)
+	require.Equal(t, work.UUID, items.TagParentUUID(projects))
+	require.Equal(t, plan.UUID, projects.Content.ItemReferences[1].UUID)
+
+	// a single bundle
+	res, err = Bear{}.Import(bundle, nil)
+	require.NoError(t, err)
+	require.Len(t, res.Notes, 1)
+	require.Equal(t, "Plan", res.Notes[0].Content.Title)
+}
@@ -0,0 +1,280 @@
+package importers
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jonhadfield/gosn-v2/items"
+)
+
+// enexTimeLayout is the layout of the timestamps in an Evernote export.
+const enexTimeLayout = "20060102T150405Z"
+
+// ErrInvalidENEX is returned when an Evernote export cannot be parsed.
+var ErrInvalidENEX = errors.New("invalid evernote export")
+
+// ENEX imports an Evernote export (.enex), or a directory or zip archive of them. Evernote tags become
+// tags, and the content of each note is converted from ENML to the format chosen, keeping its created
+// and updated timestamps. Attachments are listed in the report and, if a directory is provided, saved
+// to it and linked from their notes.
+type ENEX struct {
+	// Format is the format notes are converted to, items.ENEXMarkdown by default.
+	Format items.ENEXTextFormat
+	// AttachmentsDir, if set, is the directory attachments are saved to, in a folder per note named
+	// after a hash of the note, so that a note imported again links to the same files and is skipped.
+	// Notes link to the saved attachments, so a relative path results in relative links.
+	AttachmentsDir string
+}
+
+type enexNote struct {
+	Title     string         `xml:"title"`
+	Content   string         `xml:"content"`
+	Created   string         `xml:"created"`
+	Updated   string         `xml:"updated"`
+	Tags      []string       `xml:"tag"`
+	Resources []enexResource `xml:"resource"`
+}
+
+type enexResource struct {
+	Data struct {
+		Encoding string `xml:"encoding,attr"`
+		Value    string `xml:",chardata"`
+	} `xml:"data"`
+	MIME     string `xml:"mime"`
+	FileName string `xml:"resource-attributes>file-name"`
+}
+
+// Import implements Importer.
+func (e ENEX) Import(p string, existing items.Items) (Result, error) {
+	fsys, file, closer, err := openExport(p)
+	if err != nil {
+		return Result{}, fmt.Errorf("ENEX.Import | %w", err)
+	}
+
+	defer closer()
+
+	b := newBatch(existing)
+
+	match := func(name string) bool { return strings.EqualFold(path.Ext(name), ".enex") }
+
+	err = walkExport(fsys, file, match, func(name string) error {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		return b.addENEX(name, f, e)
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("ENEX.Import | %w", err)
+	}
+
+	return b.finish()
+}
+
+// addENEX adds the notes of an export, streaming them so that large exports are not read into memory.
+func (b *batch) addENEX(name string, r io.Reader, e ENEX) error {
+	dec := xml.NewDecoder(r)
+
+	root := false
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidENEX, name, err)
+		}
+
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case !root && se.Name.Local != "en-export":
+			return fmt.Errorf("%w: %s: unexpected root element %s", ErrInvalidENEX, name, se.Name.Local)
+		case !root:
+			root = true
+		case se.Name.Local == "note":
+			var en enexNote
+			if err = dec.DecodeElement(&en, &se); err != nil {
+				return fmt.Errorf("%w: %s: %w", ErrInvalidENEX, name, err)
+			}
+
+			if err = b.addENEXNote(en, e); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !root {
+		return fmt.Errorf("%w: %s: no en-export element found", ErrInvalidENEX, name)
+	}
+
+	return nil
+}
+
+func (b *batch) addENEXNote(en enexNote, e ENEX) error {
+	title := strings.TrimSpace(en.Title)
+	if title == "" {
+		title = untitled
+	}
+
+	note, err := newNote(title, "", b.enexTime(title, en.Created), b.enexTime(title, en.Updated))
+	if err != nil {
+		return err
+	}
+
+	sum := md5.Sum([]byte(title + "\n" + en.Content))
+	dir := ""
+
+	if e.AttachmentsDir != "" {
+		dir = filepath.Join(e.AttachmentsDir, hex.EncodeToString(sum[:]))
+	}
+
+	attachments := make([]Attachment, 0, len(en.Resources))
+	data := make([][]byte, 0, len(en.Resources))
+	media := make([]items.ENMLMedia, 0, len(en.Resources))
+	used := make(map[string]bool)
+
+	for _, res := range en.Resources {
+		a, d := b.enexAttachment(note, res)
+
+		m := items.ENMLMedia{Hash: a.Hash, Name: a.FileName}
+
+		if dir != "" && d != nil {
+			a.Path = filepath.Join(dir, attachmentFileName(a.FileName, used))
+			m.URL = filepath.ToSlash(a.Path)
+		}
+
+		attachments = append(attachments, a)
+		data = append(data, d)
+		media = append(media, m)
+	}
+
+	warnings, err := note.Content.SetENML(en.Content, e.Format, media)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidENEX, title, err)
+	}
+
+	for _, w := range warnings {
+		b.warn(title, "%s", w)
+	}
+
+	// attachments are only saved for the notes added
+	if !b.duplicate(note) {
+		for x, a := range attachments {
+			if a.Path == "" {
+				continue
+			}
+
+			if err = os.MkdirAll(dir, 0o700); err != nil {
+				return err
+			}
+
+			if err = os.WriteFile(a.Path, data[x], 0o600); err != nil {
+				return err
+			}
+		}
+
+		b.result.Report.Attachments = append(b.result.Report.Attachments, attachments...)
+	}
+
+	return b.add(note, en.Tags)
+}
+
+func (b *batch) enexTime(title, s string) time.Time {
+	if s = strings.TrimSpace(s); s == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(enexTimeLayout, s)
+	if err != nil {
+		b.warn(title, "invalid timestamp %q", s)
+
+		return time.Time{}
+	}
+
+	return t
+}
+
+// enexAttachment returns the attachment of a resource and its data, or nil data if it could not be decoded.
+func (b *batch) enexAttachment(note items.Note, res enexResource) (Attachment, []byte) {
+	a := Attachment{
+		NoteUUID:  note.UUID,
+		NoteTitle: note.Content.Title,
+		FileName:  strings.TrimSpace(res.FileName),
+		MIMEType:  strings.TrimSpace(res.MIME),
+	}
+
+	if a.FileName == "" {
+		a.FileName = "attachment"
+		if exts, _ := mime.ExtensionsByType(a.MIMEType); len(exts) > 0 {
+			a.FileName += exts[0]
+		}
+	}
+
+	if enc := strings.TrimSpace(res.Data.Encoding); enc != "" && enc != "base64" {
+		b.warn(note.Content.Title, "attachment %s has unsupported encoding %s", a.FileName, enc)
+
+		return a, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(res.Data.Value), ""))
+	if err != nil {
+		// the data decoded before the error is incomplete
+		b.warn(note.Content.Title, "attachment %s could not be decoded: %s", a.FileName, err)
+
+		return a, nil
+	}
+
+	sum := md5.Sum(data)
+	a.Hash = hex.EncodeToString(sum[:])
+	a.Size = int64(len(data))
+
+	return a, data
+}
+
+// attachmentFileName returns the name with characters that are not valid in file names replaced, and
+// numbered if it has already been used.
+func attachmentFileName(name string, used map[string]bool) string {
+	name = strings.Trim(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+
+		return r
+	}, name), ". ")
+
+	if name == "" {
+		name = "attachment"
+	}
+
+	ext := filepath.Ext(name)
+	unique := name
+
+	for x := 2; used[strings.ToLower(unique)]; x++ {
+		unique = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), x, ext)
+	}
+
+	used[strings.ToLower(unique)] = true
+
+	return unique
+}
@@ -0,0 +1,177 @@
+package importers
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/items"
+	"github.com/stretchr/testify/require"
+)
+
+func enexTestExport(t *testing.T, notes string) string {
+	t.Helper()
+
+	p := filepath.Join(t.TempDir(), "export.enex")
+	require.NoError(t, os.WriteFile(p, []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE en-export SYSTEM "http://xml.evernote.com/pub/evernote-export4.dtd">
+<en-export export-date="20240301T100000Z" application="Evernote" version="10.0">
+`+notes+`
+</en-export>`), 0o600))
+
+	return p
+}
+
+func TestENEXImport(t *testing.T) {
+	image := []byte("not really a png")
+	sum := md5.Sum(image)
+	hash := hex.EncodeToString(sum[:])
+
+	export := enexTestExport(t, `<note>
+<title>Trip</title>
+<created>20240102T030405Z</created>
+<updated>20240203T040506Z</updated>
+<tag>travel</tag>
+<tag>work</tag>
+<tag>work/2024</tag>
+<content><![CDATA[<en-note><div>Book <b>flights</b></div><div><en-media type="image/png" hash="`+hash+`"/></div><en-crypt>secret</en-crypt></en-note>]]></content>
+<resource>
+<data encoding="base64">
+`+base64.StdEncoding.EncodeToString(image)+`
+</data>
+<mime>image/png</mime>
+<resource-attributes><file-name>map: rome.png</file-name></resource-attributes>
+</resource>
+</note>
+<note>
+<title>Empty</title>
+<tag>travel</tag>
+<content><![CDATA[<en-note></en-note>]]></content>
+</note>`)
+
+	dir := t.TempDir()
+
+	work, err := items.NewTag("work", nil)
+	require.NoError(t, err)
+
+	res, err := ENEX{AttachmentsDir: dir}.Import(export, items.Items{&work})
+	require.NoError(t, err)
+	require.Len(t, res.Notes, 2)
+	require.Equal(t, 2, res.Report.Notes)
+	require.Equal(t, 2, res.Report.TagsCreated)
+	require.Len(t, res.Report.Warnings, 1)
+	require.Contains(t, res.Report.Warnings[0], "encrypted")
+
+	trip, empty := res.Notes[0], res.Notes[1]
+	require.Equal(t, "Trip", trip.Content.Title)
+	require.Empty(t, empty.Content.Text)
+
+	require.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).UnixMicro(), trip.CreatedAtTimestamp)
+	updated, err := trip.Content.GetUpdateTime()
+	require.NoError(t, err)
+	require.True(t, updated.Equal(time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)))
+
+	// tags are matched by path, including nested tags
+	tags := make(items.Items, 0, len(res.Tags))
+	for x := range res.Tags {
+		tags = append(tags, &res.Tags[x])
+	}
+
+	tt := tags.TagTree()
+
+	for _, path := range []string{"work", "work/2024", "travel"} {
+		n, err := tt.Find(path)
+		require.NoError(t, err, path)
+		require.Contains(t, n.Tag.Content.ItemReferences, items.ItemReference{UUID: trip.UUID, ContentType: common.SNItemTypeNote}, path)
+	}
+
+	w, err := tt.Find("work")
+	require.NoError(t, err)
+	require.Equal(t, work.UUID, w.Tag.UUID)
+	require.Empty(t, work.Content.ItemReferences)
+
+	require.Len(t, res.Report.Attachments, 1)
+
+	a := res.Report.Attachments[0]
+	require.Equal(t, trip.UUID, a.NoteUUID)
+	require.Equal(t, "map: rome.png", a.FileName)
+	require.Equal(t, "image/png", a.MIMEType)
+	require.Equal(t, int64(len(image)), a.Size)
+	require.Equal(t, hash, a.Hash)
+	require.Equal(t, dir, filepath.Dir(filepath.Dir(a.Path)))
+	require.Equal(t, "map- rome.png", filepath.Base(a.Path))
+
+	b, err := os.ReadFile(a.Path)
+	require.NoError(t, err)
+	require.Equal(t, image, b)
+
+	require.Equal(t, "Book **flights**\n\n"+
+		"[map: rome.png]("+strings.ReplaceAll(filepath.ToSlash(a.Path), " ", "%20")+")\n\n"+
+		"\\[encrypted content\\]", trip.Content.Text)
+
+	// importing again links to the same attachments, so finds the notes are already present
+	existing := items.Items{&work}
+	for x := range res.Notes {
+		existing = append(existing, &res.Notes[x])
+	}
+
+	res, err = ENEX{AttachmentsDir: dir}.Import(export, existing)
+	require.NoError(t, err)
+	require.Empty(t, res.Notes)
+	require.Equal(t, []string{"Trip", "Empty"}, res.Report.Duplicates)
+	require.Empty(t, res.Report.Attachments)
+}
+
+func TestENEXImportCorruptAttachment(t *testing.T) {
+	export := enexTestExport(t, `<note><title>Corrupt</title>
+<content><![CDATA[<en-note><div>see <en-media type="text/plain" hash="0123"/></div></en-note>]]></content>
+<resource><data encoding="base64">aGVsbG8gd29y!GQ=</data><mime>text/plain</mime>
+<resource-attributes><file-name>x.txt</file-name></resource-attributes></resource>
+</note>`)
+
+	dir := t.TempDir()
+
+	res, err := ENEX{AttachmentsDir: dir}.Import(export, nil)
+	require.NoError(t, err)
+
+	// the data decoded before the error is not saved or reported
+	require.Equal(t, []Attachment{{
+		NoteUUID:  res.Notes[0].UUID,
+		NoteTitle: "Corrupt",
+		FileName:  "x.txt",
+		MIMEType:  "text/plain",
+	}}, res.Report.Attachments)
+	require.Len(t, res.Report.Warnings, 1)
+	require.Contains(t, res.Report.Warnings[0], "could not be decoded")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	require.Equal(t, `see \[attachment: x.txt\]`, res.Notes[0].Content.Text)
+}
+
+func TestENEXImportErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, content := range map[string]string{
+		"root.enex":      "<notes></notes>",
+		"empty.enex":     "",
+		"truncated.enex": "<en-export><note><title>a</title>",
+	} {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+
+		_, err := ENEX{}.Import(p, nil)
+		require.ErrorIs(t, err, ErrInvalidENEX, name)
+	}
+
+	_, err := ENEX{}.Import(enexTestExport(t, ""), nil)
+	require.ErrorIs(t, err, ErrNoNotesFound)
+}
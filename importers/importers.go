@@ -0,0 +1,231 @@
+// Package importers converts the exports of other note taking apps to notes and tags.
+package importers
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/items"
+)
+
+const untitled = "Untitled"
+
+// ErrNoNotesFound is returned when an export does not contain any notes the importer recognises.
+var ErrNoNotesFound = errors.New("no notes found in export")
+
+// Importer converts an export to notes, and the tags that need to be created or updated to reference them.
+type Importer interface {
+	// Import reads the export at path, which may be a directory, a zip archive or a single file.
+	// Notes with the same content as an existing note, or another imported note, are skipped.
+	Import(path string, existing items.Items) (Result, error)
+}
+
+// Result is the outcome of an import.
+type Result struct {
+	Notes items.Notes
+	// Tags are the tags created, and the existing tags updated to reference the notes.
+	Tags   items.Tags
+	Report Report
+}
+
+// Report describes the outcome of an import.
+type Report struct {
+	Notes       int `json:"notes"`
+	TagsCreated int `json:"tags_created"`
+	// Duplicates are the titles of notes that were skipped as their content was already present.
+	Duplicates []string `json:"duplicates"`
+	// Warnings describe content that could not be fully imported.
+	Warnings []string `json:"warnings"`
+	// Attachments are the attachments of the notes imported, by importers that read them.
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is an attachment of a note found in an export.
+type Attachment struct {
+	NoteUUID  string `json:"note_uuid"`
+	NoteTitle string `json:"note_title"`
+	FileName  string `json:"file_name"`
+	MIMEType  string `json:"mime_type"`
+	// Size and Hash are not set if the attachment could not be decoded.
+	Size int64 `json:"size,omitempty"`
+	// Hash is the MD5 hash Evernote uses to reference the attachment from the note's content.
+	Hash string `json:"hash,omitempty"`
+	// Path is where the attachment was saved, or empty if it was not.
+	Path string `json:"path,omitempty"`
+}
+
+// openExport returns the files of the export at path. If path is a single file, the file system is
+// its directory and the file's name is returned so that only it is imported.
+func openExport(path string) (fsys fs.FS, file string, closer func() error, err error) {
+	closer = func() error { return nil }
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, "", closer, err
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); {
+	case fi.IsDir():
+		return os.DirFS(path), "", closer, nil
+	case ext == ".zip" || ext == ".bear2bk":
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, "", closer, err
+		}
+
+		return zr, "", zr.Close, nil
+	default:
+		return os.DirFS(filepath.Dir(path)), filepath.Base(path), closer, nil
+	}
+}
+
+// walkExport calls fn for each file in the export with a matching name, or only the file provided.
+// Hidden files and directories are skipped.
+func walkExport(fsys fs.FS, file string, match func(name string) bool, fn func(name string) error) error {
+	if file != "" {
+		return fn(file)
+	}
+
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if name != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.IsDir() || !match(name) {
+			return nil
+		}
+
+		return fn(name)
+	})
+}
+
+// batch accumulates the notes and tags of an import, skipping duplicate notes.
+type batch struct {
+	tags     *items.TagPaths
+	existing map[string]bool
+	hashes   map[string]bool
+	result   Result
+}
+
+func newBatch(existing items.Items) *batch {
+	b := &batch{
+		tags:     items.NewTagPaths(existing),
+		existing: make(map[string]bool),
+		hashes:   make(map[string]bool),
+	}
+
+	for _, t := range existing.Tags() {
+		b.existing[t.UUID] = true
+	}
+
+	for _, n := range existing.Notes() {
+		if !n.Deleted {
+			b.hashes[n.ContentHash()] = true
+		}
+	}
+
+	return b
+}
+
+func (b *batch) warn(name, format string, a ...any) {
+	b.result.Report.Warnings = append(b.result.Report.Warnings, fmt.Sprintf("%s: %s", name, fmt.Sprintf(format, a...)))
+}
+
+// duplicate returns true if the content of the note is already present.
+func (b *batch) duplicate(note items.Note) bool {
+	return b.hashes[note.ContentHash()]
+}
+
+// add adds the note, referenced by the tags at the paths provided, unless its content is already present.
+func (b *batch) add(note items.Note, tagPaths []string) error {
+	if b.duplicate(note) {
+		b.result.Report.Duplicates = append(b.result.Report.Duplicates, note.Content.Title)
+
+		return nil
+	}
+
+	b.hashes[note.ContentHash()] = true
+
+	for _, path := range tagPaths {
+		if path = strings.Trim(strings.TrimSpace(path), items.TagPathSeparator); path == "" {
+			continue
+		}
+
+		t, err := b.tags.Tag(path)
+		if err != nil {
+			return err
+		}
+
+		b.tags.Reference(t, &note)
+	}
+
+	b.result.Notes = append(b.result.Notes, note)
+
+	return nil
+}
+
+func (b *batch) finish() (Result, error) {
+	b.result.Tags = b.tags.Changed()
+	b.result.Report.Notes = len(b.result.Notes)
+
+	for _, t := range b.result.Tags {
+		if !b.existing[t.UUID] {
+			b.result.Report.TagsCreated++
+		}
+	}
+
+	if len(b.result.Notes) == 0 && len(b.result.Report.Duplicates) == 0 {
+		return b.result, ErrNoNotesFound
+	}
+
+	return b.result, nil
+}
+
+// newNote returns a note with the timestamps provided, if they are set.
+func newNote(title, text string, created, updated time.Time) (items.Note, error) {
+	if title = strings.TrimSpace(title); title == "" {
+		title = untitled
+	}
+
+	note, err := items.NewNote(title, text, nil)
+	if err != nil {
+		return note, err
+	}
+
+	if !created.IsZero() {
+		note.CreatedAt = created.UTC().Format(common.TimeLayout)
+		note.CreatedAtTimestamp = created.UnixMicro()
+	}
+
+	if !updated.IsZero() {
+		note.UpdatedAt = updated.UTC().Format(common.TimeLayout)
+		note.UpdatedAtTimestamp = updated.UnixMicro()
+		note.Content.SetUpdateTime(updated.UTC())
+	}
+
+	return note, nil
+}
+
+// splitTitle returns the first non-empty line of text as the title, and the text that follows it.
+func splitTitle(text string) (title, body string) {
+	text = strings.TrimLeft(strings.ReplaceAll(text, "\r\n", "\n"), " \t\n")
+
+	title, body, _ = strings.Cut(text, "\n")
+
+	return strings.TrimSpace(title), strings.Trim(body, "\n")
+}
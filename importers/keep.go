@@ -0,0 +1,152 @@
+package importers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/items"
+)
+
+// Keep imports the notes of a Google Takeout export of Keep, either the Keep folder or the Takeout zip
+// archive. Labels become tags, and checklists become notes for the checklist editor chosen.
+type Keep struct {
+	// ChecklistEditor is the editor checklists are imported for, either items.SimpleTaskEditorNoteType,
+	// the default, or items.AdvancedChecklistNoteType.
+	ChecklistEditor string
+}
+
+type keepNote struct {
+	Title       string `json:"title"`
+	TextContent string `json:"textContent"`
+	ListContent []struct {
+		Text      string `json:"text"`
+		IsChecked bool   `json:"isChecked"`
+	} `json:"listContent"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Attachments []struct {
+		FilePath string `json:"filePath"`
+	} `json:"attachments"`
+	IsPinned                bool  `json:"isPinned"`
+	IsArchived              bool  `json:"isArchived"`
+	IsTrashed               bool  `json:"isTrashed"`
+	CreatedTimestampUsec    int64 `json:"createdTimestampUsec"`
+	UserEditedTimestampUsec int64 `json:"userEditedTimestampUsec"`
+}
+
+// Import implements Importer.
+func (k Keep) Import(p string, existing items.Items) (Result, error) {
+	fsys, file, closer, err := openExport(p)
+	if err != nil {
+		return Result{}, fmt.Errorf("Keep.Import | %w", err)
+	}
+
+	defer closer()
+
+	b := newBatch(existing)
+
+	match := func(name string) bool { return strings.EqualFold(path.Ext(name), ".json") }
+
+	err = walkExport(fsys, file, match, func(name string) error {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		var kn keepNote
+		if err = json.Unmarshal(data, &kn); err != nil || kn.CreatedTimestampUsec == 0 && kn.UserEditedTimestampUsec == 0 {
+			// Takeout contains other JSON files, such as the labels and the archive's browser
+			b.warn(name, "not a Keep note")
+
+			return nil
+		}
+
+		return b.addKeep(name, kn, k.ChecklistEditor)
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("Keep.Import | %w", err)
+	}
+
+	return b.finish()
+}
+
+func (b *batch) addKeep(name string, kn keepNote, editor string) error {
+	title := kn.Title
+	if strings.TrimSpace(title) == "" {
+		title, _ = splitTitle(kn.TextContent)
+	}
+
+	note, err := newNote(title, kn.TextContent, usecTime(kn.CreatedTimestampUsec), usecTime(kn.UserEditedTimestampUsec))
+	if err != nil {
+		return err
+	}
+
+	if kn.ListContent != nil {
+		switch editor {
+		case items.AdvancedChecklistNoteType:
+			cl := items.AdvancedChecklist{
+				SchemaVersion: "1.0.0",
+				DefaultSections: []items.DefaultSection{
+					{Id: "open-tasks", Name: "Open"},
+					{Id: "completed-tasks", Name: "Completed"},
+				},
+			}
+
+			// tasks are added to the top of the list
+			for _, task := range slices.Backward(kn.ListContent) {
+				if err = cl.AddTask(note.Content.Title, task.Text); err != nil {
+					return err
+				}
+			}
+
+			for x, task := range kn.ListContent {
+				cl.Groups[0].Tasks[x].Completed = task.IsChecked
+			}
+
+			note.Content.Text = items.AdvancedCheckListToNoteText(cl)
+		default:
+			editor = items.SimpleTaskEditorNoteType
+
+			var tasks items.Tasks
+			for _, task := range kn.ListContent {
+				tasks = append(tasks, items.Task{Title: task.Text, Completed: task.IsChecked})
+			}
+
+			note.Content.Text = items.TasksToNoteText(tasks)
+		}
+
+		note.Content.EditorIdentifier = editor
+	}
+
+	note.SetPinned(kn.IsPinned)
+	note.SetArchived(kn.IsArchived)
+
+	if kn.IsTrashed {
+		note.Content.SetTrashed(true)
+	}
+
+	if len(kn.Attachments) > 0 {
+		b.warn(name, "%d attachments not imported", len(kn.Attachments))
+	}
+
+	var labels []string
+	for _, l := range kn.Labels {
+		labels = append(labels, l.Name)
+	}
+
+	return b.add(note, labels)
+}
+
+func usecTime(usec int64) time.Time {
+	if usec == 0 {
+		return time.Time{}
+	}
+
+	return time.UnixMicro(usec).UTC()
+}
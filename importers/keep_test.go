@@ -0,0 +1,111 @@
+package importers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/items"
+	"github.com/stretchr/testify/require"
+)
+
+func keepTestExport(t *testing.T) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "Takeout", "Keep")
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+
+	files := map[string]string{
+		"Ideas.json": `{"color":"DEFAULT","isTrashed":false,"isPinned":true,"isArchived":true,
+			"textContent":"write more tests","title":"Ideas",
+			"userEditedTimestampUsec":1675397106000000,"createdTimestampUsec":1672628645000000,
+			"labels":[{"name":"Work"},{"name":"Work/Q1"}],
+			"attachments":[{"filePath":"photo.jpg","mimetype":"image/jpeg"}]}`,
+		"Groceries.json": `{"isTrashed":false,"isPinned":false,"isArchived":false,"textContent":"","title":"Groceries",
+			"userEditedTimestampUsec":1675397106000000,"createdTimestampUsec":1672628645000000,
+			"listContent":[{"text":"milk","isChecked":true},{"text":"eggs","isChecked":false}]}`,
+		"Untitled.json": `{"textContent":"first line\nsecond line","title":"","createdTimestampUsec":1672628645000000}`,
+		"Labels.json":   `{"labels":[]}`,
+	}
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	return filepath.Dir(dir)
+}
+
+func keepTestNotes(res Result) map[string]items.Note {
+	notes := make(map[string]items.Note)
+	for _, n := range res.Notes {
+		notes[n.Content.Title] = n
+	}
+
+	return notes
+}
+
+func TestKeepImport(t *testing.T) {
+	export := keepTestExport(t)
+
+	res, err := Keep{}.Import(export, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, res.Report.Notes)
+	require.Equal(t, 2, res.Report.TagsCreated)
+	require.Len(t, res.Report.Warnings, 2)
+
+	notes := keepTestNotes(res)
+
+	ideas := notes["Ideas"]
+	require.Equal(t, "write more tests", ideas.Content.Text)
+	require.True(t, ideas.IsPinned())
+	require.True(t, ideas.IsArchived())
+	require.Equal(t, time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC).UnixMicro(), ideas.CreatedAtTimestamp)
+
+	// labels with slashes are nested tags
+	tags := items.Items{&res.Tags[0], &res.Tags[1]}
+	tt := tags.TagTree()
+	q1, err := tt.Find("Work/Q1")
+	require.NoError(t, err)
+	require.Equal(t, ideas.UUID, q1.Tag.Content.ItemReferences[1].UUID)
+
+	groceries := notes["Groceries"]
+	require.Equal(t, items.SimpleTaskEditorNoteType, groceries.Content.EditorIdentifier)
+
+	tl, err := groceries.Content.ToTaskList()
+	require.NoError(t, err)
+	require.Equal(t, items.Tasks{{Title: "eggs"}, {Title: "milk", Completed: true}}, items.Tasks(tl.Tasks))
+
+	require.Equal(t, "first line\nsecond line", notes["first line"].Content.Text)
+
+	// importing again finds the notes are already present
+	var existing items.Items
+	for _, n := range res.Notes {
+		existing = append(existing, &n)
+	}
+
+	res, err = Keep{}.Import(export, existing)
+	require.NoError(t, err)
+	require.Empty(t, res.Notes)
+	require.Len(t, res.Report.Duplicates, 3)
+}
+
+func TestKeepImportAdvancedChecklist(t *testing.T) {
+	res, err := Keep{ChecklistEditor: items.AdvancedChecklistNoteType}.Import(keepTestExport(t), nil)
+	require.NoError(t, err)
+
+	groceries := keepTestNotes(res)["Groceries"]
+
+	cl, err := groceries.Content.ToAdvancedCheckList()
+	require.NoError(t, err)
+	require.Equal(t, "1.0.0", cl.SchemaVersion)
+	require.Len(t, cl.Groups, 1)
+	require.Equal(t, "Groceries", cl.Groups[0].Name)
+
+	tasks := cl.Groups[0].Tasks
+	require.Len(t, tasks, 2)
+	require.Equal(t, "milk", tasks[0].Description)
+	require.True(t, tasks[0].Completed)
+	require.Equal(t, "eggs", tasks[1].Description)
+	require.False(t, tasks[1].Completed)
+}
@@ -0,0 +1,94 @@
+package importers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/items"
+)
+
+// simplenoteExportFile is the file in a Simplenote export that contains the notes.
+const simplenoteExportFile = "notes.json"
+
+// Simplenote imports the notes.json file of a Simplenote export, or the export's zip archive.
+// The first line of each note is its title. Tags, pinned notes and trashed notes are kept.
+type Simplenote struct{}
+
+type simplenoteExport struct {
+	ActiveNotes  []simplenoteNote `json:"activeNotes"`
+	TrashedNotes []simplenoteNote `json:"trashedNotes"`
+}
+
+type simplenoteNote struct {
+	ID           string    `json:"id"`
+	Content      string    `json:"content"`
+	CreationDate time.Time `json:"creationDate"`
+	LastModified time.Time `json:"lastModified"`
+	Pinned       bool      `json:"pinned"`
+	Tags         []string  `json:"tags"`
+}
+
+// Import implements Importer.
+func (Simplenote) Import(p string, existing items.Items) (Result, error) {
+	fsys, file, closer, err := openExport(p)
+	if err != nil {
+		return Result{}, fmt.Errorf("Simplenote.Import | %w", err)
+	}
+
+	defer closer()
+
+	b := newBatch(existing)
+
+	match := func(name string) bool { return path.Base(name) == simplenoteExportFile }
+
+	err = walkExport(fsys, file, match, func(name string) error {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		var export simplenoteExport
+		if err = json.Unmarshal(data, &export); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		for _, sn := range export.ActiveNotes {
+			if err = b.addSimplenote(sn, false); err != nil {
+				return err
+			}
+		}
+
+		for _, sn := range export.TrashedNotes {
+			if err = b.addSimplenote(sn, true); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("Simplenote.Import | %w", err)
+	}
+
+	return b.finish()
+}
+
+func (b *batch) addSimplenote(sn simplenoteNote, trashed bool) error {
+	title, text := splitTitle(sn.Content)
+
+	note, err := newNote(title, text, sn.CreationDate, sn.LastModified)
+	if err != nil {
+		return err
+	}
+
+	note.SetPinned(sn.Pinned)
+
+	if trashed {
+		note.Content.SetTrashed(true)
+	}
+
+	return b.add(note, sn.Tags)
+}
@@ -0,0 +1,103 @@
+package importers
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/items"
+	"github.com/stretchr/testify/require"
+)
+
+const simplenoteTestExport = `{
+  "activeNotes": [
+    {
+      "id": "a1",
+      "content": "Shopping\r\n\r\nmilk\r\neggs",
+      "creationDate": "2023-01-02T03:04:05.000Z",
+      "lastModified": "2023-02-03T04:05:06.000Z",
+      "pinned": true,
+      "markdown": false,
+      "tags": ["home", "errands"]
+    },
+    {
+      "id": "a2",
+      "content": "Existing\nalready here",
+      "creationDate": "2023-01-02T03:04:05.000Z",
+      "lastModified": "2023-01-02T03:04:05.000Z",
+      "tags": ["home"]
+    }
+  ],
+  "trashedNotes": [
+    {
+      "id": "t1",
+      "content": "Old list\nbread",
+      "creationDate": "2022-01-02T03:04:05.000Z",
+      "lastModified": "2022-01-02T03:04:05.000Z"
+    }
+  ]
+}`
+
+func TestSimplenoteImport(t *testing.T) {
+	dir := t.TempDir()
+
+	// the notes are in the source folder of the export's zip archive
+	archive := filepath.Join(dir, "notes.zip")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("source/notes.json")
+	require.NoError(t, err)
+	_, err = w.Write([]byte(simplenoteTestExport))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	existingNote, err := items.NewNote("Existing", "already here\n", nil)
+	require.NoError(t, err)
+
+	home, err := items.NewTag("home", nil)
+	require.NoError(t, err)
+
+	res, err := Simplenote{}.Import(archive, items.Items{&existingNote, &home})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"Existing"}, res.Report.Duplicates)
+	require.Equal(t, 2, res.Report.Notes)
+	require.Equal(t, 1, res.Report.TagsCreated)
+	require.Len(t, res.Notes, 2)
+
+	shopping := res.Notes[0]
+	require.Equal(t, "Shopping", shopping.Content.Title)
+	require.Equal(t, "milk\neggs", shopping.Content.Text)
+	require.True(t, shopping.IsPinned())
+	require.Equal(t, time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC).UnixMicro(), shopping.CreatedAtTimestamp)
+
+	updated, err := shopping.Content.GetUpdateTime()
+	require.NoError(t, err)
+	require.True(t, updated.Equal(time.Date(2023, 2, 3, 4, 5, 6, 0, time.UTC)))
+
+	old := res.Notes[1]
+	require.Equal(t, "Old list", old.Content.Title)
+	require.True(t, old.Content.GetTrashed())
+
+	// the existing tag is updated and errands is created
+	require.Len(t, res.Tags, 2)
+	require.Equal(t, home.UUID, res.Tags[0].UUID)
+	require.Equal(t, shopping.UUID, res.Tags[0].Content.ItemReferences[0].UUID)
+	require.Empty(t, home.Content.ItemReferences)
+	require.Equal(t, "errands", res.Tags[1].Content.Title)
+
+	// the notes file can be imported directly
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.json"), []byte(simplenoteTestExport), 0o600))
+
+	res, err = Simplenote{}.Import(filepath.Join(dir, "notes.json"), nil)
+	require.NoError(t, err)
+	require.Len(t, res.Notes, 3)
+
+	_, err = Simplenote{}.Import(t.TempDir(), nil)
+	require.ErrorIs(t, err, ErrNoNotesFound)
+}
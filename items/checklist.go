@@ -2,8 +2,8 @@ package items
 
 import (
 	"errors"
-	"fmt"
-	"sort"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 )
@@ -13,12 +13,22 @@ const (
 	taskAlreadyCompleted     = "task already complete"
 	taskNotOpen              = "task not open"
 	taskAlreadyOpen          = "task already open"
+	openTaskPrefix           = "- [ ] "
+	completedTaskPrefix      = "- [x] "
+	// completedTaskUpperPrefix is also read as a completed task
+	completedTaskUpperPrefix = "- [X] "
 )
 
 var (
 	errMissingContent = errors.New("missing content")
 	errGroupNotFound  = errors.New("group not found")
 	errTaskNotFound   = errors.New("task not found")
+	errDuplicateTask  = errors.New("more than one task has the title, use its index instead")
+	errTaskIndex      = errors.New("task index out of range")
+	errTaskOrder      = errors.New("task order must include each task once")
+	errTaskTitle      = errors.New("task title cannot contain a new line")
+
+	markdownTaskRegex = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+\[([ xX])\]\s+(.*?)\s*$`)
 )
 
 type Tasks []Task
@@ -41,9 +51,16 @@ func splitTaskText(text string) []string {
 	return parts
 }
 
+// Task is a task of a note written by the Checklist editor. The editor has no due dates or notes for
+// tasks, so neither is kept.
 type Task struct {
 	Title     string `json:"title"`
 	Completed bool   `json:"completed"`
+
+	// blankBefore holds the blank lines written before the task, and upper whether it was completed
+	// with "[X]", so that the text it was parsed from is written back unchanged
+	blankBefore []string
+	upper       bool
 }
 
 type Tasklist struct {
@@ -53,48 +70,148 @@ type Tasklist struct {
 	Tasks      []Task
 	UpdatedAt  time.Time `json:"updatedAt"`
 	Trashed    bool      `json:"trashed"`
+
+	// sep separates the lines of the text the tasks were parsed from, which is an escaped "\n" in
+	// older notes, and blankAfter holds the blank lines after the last task, including a trailing newline
+	sep        string
+	blankAfter []string
 }
 
-func NoteTextToTasks(text string) (tasks Tasks, err error) {
+// NoteTextToTasks returns the tasks of the note text, parsed as ParseTasklistText does, with those
+// completed at the bottom.
+func NoteTextToTasks(text string) (Tasks, error) {
 	if len(text) == 0 {
 		return Tasks{}, errMissingContent
 	}
 
-	var matches []string
+	tasks := Tasks(ParseTasklistText(text).Tasks)
+
+	// put completed at bottom
+	tasks.Sort()
+
+	return tasks, nil
+}
+
+func (ts *Tasks) Sort() {
+	slices.SortStableFunc(*ts, func(a, b Task) int {
+		switch {
+		case !a.Completed && b.Completed:
+			return -1
+		case a.Completed && !b.Completed:
+			return 1
+		}
+
+		return 0
+	})
+}
+
+// ParseTasklistText returns the task list of note text written by the Checklist editor, one task per line,
+// in the order they are written. Older notes with the lines separated by an escaped "\n" are also read.
+// Blank lines are skipped as they are by the editor, and other lines without a checkbox are open tasks.
+func ParseTasklistText(text string) Tasklist {
+	tl := Tasklist{Tasks: Tasks{}, sep: "\n"}
+
+	if text == "" {
+		return tl
+	}
+
+	lines := strings.Split(text, "\n")
 
-	// support both formats
-	if strings.Contains(text, "\n") {
-		matches = strings.Split(text, "\n")
-	} else {
-		matches = splitTaskText(text)
+	// support the escaped format
+	if !strings.Contains(text, "\n") && strings.Contains(text, `\n`) {
+		tl.sep = `\n`
+		lines = splitTaskText(text)
 	}
 
-	for _, match := range matches {
-		i := strings.Index(match, "]")
-		task := Task{
-			Title: match[i+2:],
+	var blank []string
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			blank = append(blank, line)
+
+			continue
 		}
 
-		if match[i-1:i] == "x" {
-			task.Completed = true
+		t := Task{Title: line, blankBefore: blank}
+		blank = nil
+
+		switch {
+		case strings.HasPrefix(line, openTaskPrefix):
+			t.Title = line[len(openTaskPrefix):]
+		case strings.HasPrefix(line, completedTaskPrefix):
+			t.Title, t.Completed = line[len(completedTaskPrefix):], true
+		case strings.HasPrefix(line, completedTaskUpperPrefix):
+			t.Title, t.Completed, t.upper = line[len(completedTaskUpperPrefix):], true, true
 		}
 
-		tasks = append(tasks, task)
+		tl.Tasks = append(tl.Tasks, t)
 	}
 
-	// put completed at bottom
-	tasks.Sort()
+	tl.blankAfter = blank
 
-	return tasks, nil
+	return tl
 }
 
-func (ts *Tasks) Sort() {
-	dt := *ts
-	sort.Slice(dt, func(i, j int) bool {
-		return !dt[i].Completed && dt[j].Completed
-	})
+// Text returns the note text of the tasks as written by the Checklist editor, keeping their order, so
+// that text parsed with ParseTasklistText is returned unchanged, including its blank lines.
+func (c Tasklist) Text() string {
+	lines := make([]string, 0, len(c.Tasks)+len(c.blankAfter))
+
+	for _, t := range c.Tasks {
+		prefix := openTaskPrefix
+
+		switch {
+		case t.Completed && t.upper:
+			prefix = completedTaskUpperPrefix
+		case t.Completed:
+			prefix = completedTaskPrefix
+		}
 
-	*ts = dt
+		lines = append(lines, t.blankBefore...)
+		lines = append(lines, prefix+t.Title)
+	}
+
+	lines = append(lines, c.blankAfter...)
+
+	sep := c.sep
+	if sep == "" {
+		sep = "\n"
+	}
+
+	return strings.Join(lines, sep)
+}
+
+// MarkdownToTasks returns the checklist items of Markdown text, such as "- [ ] task" and "* [x] task",
+// in the order they appear. Nested items are included and other lines are ignored.
+func MarkdownToTasks(markdown string) Tasks {
+	tasks := Tasks{}
+
+	for _, line := range strings.Split(markdown, "\n") {
+		m := markdownTaskRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		tasks = append(tasks, Task{Title: m[2], Completed: m[1] != " "})
+	}
+
+	return tasks
+}
+
+// TasksToMarkdown returns the tasks as a Markdown checklist, keeping their order.
+func TasksToMarkdown(tasks Tasks) string {
+	text := strings.Builder{}
+
+	for _, t := range tasks {
+		completed := " "
+		if t.Completed {
+			completed = "x"
+		}
+
+		text.WriteString("- [" + completed + "] " + t.Title + "\n")
+	}
+
+	return text.String()
 }
 
 func TasksToNoteText(tasks Tasks) string {
@@ -133,99 +250,214 @@ func (c *Tasklist) AddTask(taskTitle string) error {
 	return nil
 }
 
-func (c *Tasklist) CompleteTask(taskTitle string) error {
-	// check group exists
-	var taskFound bool
-
-	var updatedTasks []Task
+// TaskIndexes returns the indexes of the tasks with the title.
+func (c *Tasklist) TaskIndexes(taskTitle string) []int {
+	var indexes []int
 
-	for _, t := range c.Tasks {
+	for x, t := range c.Tasks {
 		if t.Title == taskTitle {
-			if t.Completed {
-				return fmt.Errorf(taskAlreadyCompleted)
-			}
+			indexes = append(indexes, x)
+		}
+	}
 
-			taskFound = true
-			t.Completed = true
-			updatedTasks = append(updatedTasks, t)
+	return indexes
+}
 
-			continue
-		}
+// taskIndex returns the index of the only task with the title. Tasks sharing their title with
+// another must be changed by index.
+func (c *Tasklist) taskIndex(taskTitle string) (int, error) {
+	indexes := c.TaskIndexes(taskTitle)
+
+	switch len(indexes) {
+	case 0:
+		return 0, errTaskNotFound
+	case 1:
+		return indexes[0], nil
+	default:
+		return 0, errDuplicateTask
+	}
+}
+
+func (c *Tasklist) checkIndex(x int) error {
+	if x < 0 || x >= len(c.Tasks) {
+		return errTaskIndex
+	}
+
+	return nil
+}
 
-		updatedTasks = append(updatedTasks, t)
+func (c *Tasklist) CompleteTask(taskTitle string) error {
+	x, err := c.taskIndex(taskTitle)
+	if err != nil {
+		return err
+	}
+
+	return c.CompleteTaskAt(x)
+}
+
+// CompleteTaskAt completes the task at the index.
+func (c *Tasklist) CompleteTaskAt(x int) error {
+	if err := c.checkIndex(x); err != nil {
+		return err
 	}
 
-	if !taskFound {
-		return errTaskNotFound
+	if c.Tasks[x].Completed {
+		return errors.New(taskAlreadyCompleted)
 	}
 
-	c.Tasks = updatedTasks
+	c.Tasks[x].Completed = true
 
 	return nil
 }
 
 func (c *Tasklist) ReopenTask(taskTitle string) error {
-	// check group exists
-	var taskFound bool
+	x, err := c.taskIndex(taskTitle)
+	if err != nil {
+		return err
+	}
 
-	var updatedTasks []Task
+	return c.ReopenTaskAt(x)
+}
 
-	for _, t := range c.Tasks {
-		if t.Title == taskTitle {
-			if !t.Completed {
-				return fmt.Errorf(taskAlreadyOpen)
-			}
+// ReopenTaskAt reopens the completed task at the index.
+func (c *Tasklist) ReopenTaskAt(x int) error {
+	if err := c.checkIndex(x); err != nil {
+		return err
+	}
 
-			taskFound = true
-			t.Completed = false
-			updatedTasks = append(updatedTasks, t)
+	if !c.Tasks[x].Completed {
+		return errors.New(taskAlreadyOpen)
+	}
 
-			continue
-		}
+	c.Tasks[x].Completed = false
+
+	return nil
+}
 
-		updatedTasks = append(updatedTasks, t)
+func (c *Tasklist) DeleteTask(taskTitle string) error {
+	x, err := c.taskIndex(taskTitle)
+	if err != nil {
+		return err
 	}
 
-	if !taskFound {
-		return errTaskNotFound
+	return c.DeleteTaskAt(x)
+}
+
+// DeleteTaskAt removes the task at the index.
+func (c *Tasklist) DeleteTaskAt(x int) error {
+	if err := c.checkIndex(x); err != nil {
+		return err
 	}
 
-	c.Tasks = updatedTasks
+	c.Tasks = slices.Delete(c.Tasks, x, x+1)
 
 	return nil
 }
 
-func (c *Tasklist) removeTask(taskTitle string) ([]Task, bool) {
-	var taskFound bool
+// RenameTask changes the title of the task.
+func (c *Tasklist) RenameTask(taskTitle, newTitle string) error {
+	x, err := c.taskIndex(taskTitle)
+	if err != nil {
+		return err
+	}
 
-	var updatedTasks []Task
+	return c.RenameTaskAt(x, newTitle)
+}
 
-	for _, task := range c.Tasks {
-		if task.Title == taskTitle {
-			taskFound = true
+// RenameTaskAt changes the title of the task at the index.
+func (c *Tasklist) RenameTaskAt(x int, newTitle string) error {
+	if err := c.checkIndex(x); err != nil {
+		return err
+	}
 
-			continue
-		}
+	if strings.Contains(newTitle, "\n") {
+		return errTaskTitle
+	}
+
+	c.Tasks[x].Title = newTitle
+
+	return nil
+}
+
+// MoveTask moves the task at index from to index to, shifting the tasks between them.
+func (c *Tasklist) MoveTask(from, to int) error {
+	if err := c.checkIndex(from); err != nil {
+		return err
+	}
 
-		updatedTasks = append(updatedTasks, task)
+	if err := c.checkIndex(to); err != nil {
+		return err
 	}
 
-	return updatedTasks, taskFound
+	t := c.Tasks[from]
+	c.Tasks = slices.Insert(slices.Delete(c.Tasks, from, from+1), to, t)
+
+	return nil
 }
 
-func (c *Tasklist) DeleteTask(taskTitle string) error {
-	// check group exists
-	updatedTasks, taskFound := c.removeTask(taskTitle)
+// ReorderTasks puts the tasks in a new order, given as the current index of each task in turn.
+func (c *Tasklist) ReorderTasks(order []int) error {
+	if len(order) != len(c.Tasks) {
+		return errTaskOrder
+	}
+
+	seen := make([]bool, len(c.Tasks))
+	tasks := make([]Task, 0, len(c.Tasks))
 
-	if !taskFound {
-		return errTaskNotFound
+	for _, x := range order {
+		if x < 0 || x >= len(c.Tasks) || seen[x] {
+			return errTaskOrder
+		}
+
+		seen[x] = true
+
+		tasks = append(tasks, c.Tasks[x])
 	}
 
-	c.Tasks = updatedTasks
+	c.Tasks = tasks
 
 	return nil
 }
 
+// CompleteAllTasks completes the open tasks and returns how many there were.
+func (c *Tasklist) CompleteAllTasks() int {
+	var n int
+
+	for x := range c.Tasks {
+		if !c.Tasks[x].Completed {
+			c.Tasks[x].Completed = true
+			n++
+		}
+	}
+
+	return n
+}
+
+// ReopenAllTasks reopens the completed tasks and returns how many there were.
+func (c *Tasklist) ReopenAllTasks() int {
+	var n int
+
+	for x := range c.Tasks {
+		if c.Tasks[x].Completed {
+			c.Tasks[x].Completed = false
+			n++
+		}
+	}
+
+	return n
+}
+
+// ClearCompletedTasks removes the completed tasks and returns how many there were.
+func (c *Tasklist) ClearCompletedTasks() int {
+	n := len(c.Tasks)
+
+	c.Tasks = slices.DeleteFunc(c.Tasks, func(t Task) bool {
+		return t.Completed
+	})
+
+	return n - len(c.Tasks)
+}
+
 //
 // func (c *Tasklist) Sort() {
 // 	// sort groups
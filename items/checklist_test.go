@@ -1,6 +1,7 @@
 package items
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -100,6 +101,31 @@ func TestNoteTextToChecklist2(t *testing.T) {
 	require.Empty(t, tasks)
 }
 
+func TestNoteTextToTasksBlankLines(t *testing.T) {
+	t.Parallel()
+
+	tasks, err := NoteTextToTasks("- [ ] a\n- [x] b\n\n- [X] c")
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+	require.Equal(t, "a", tasks[0].Title)
+	require.False(t, tasks[0].Completed)
+	require.Equal(t, "b", tasks[1].Title)
+	require.True(t, tasks[1].Completed)
+	require.Equal(t, "c", tasks[2].Title)
+	require.True(t, tasks[2].Completed)
+
+	// lines without a checkbox are kept as open tasks
+	tasks, err = NoteTextToTasks("]\nno checkbox\n- [x] done\n")
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+	require.Equal(t, "]", tasks[0].Title)
+	require.False(t, tasks[0].Completed)
+	require.Equal(t, "no checkbox", tasks[1].Title)
+	require.False(t, tasks[1].Completed)
+	require.Equal(t, "done", tasks[2].Title)
+	require.True(t, tasks[2].Completed)
+}
+
 func TestAddChecklistTask(t *testing.T) {
 	t.Parallel()
 
@@ -222,3 +248,161 @@ func TestDeleteTask(t *testing.T) {
 	require.Equal(t, "Task Two", cl.Tasks[0].Title)
 	require.False(t, cl.Tasks[0].Completed)
 }
+
+func TestTasklistNoteRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// content as written by the Checklist editor, with completed tasks above open ones
+	content := `{"title":"Stand-up","text":"- [x] Deploy\n- [ ] Review PR\n- [ ] Review PR\n- [ ] Fix \\n escaping","references":[],` +
+		`"appData":{"org.standardnotes.sn":{"client_updated_at":"2024-01-01T00:00:00.000Z","prefersPlainEditor":false,"pinned":false}},` +
+		`"noteType":"task","editorIdentifier":"org.standardnotes.simple-task-editor","preview_plain":"","preview_html":"","spellcheck":false}`
+
+	var nc NoteContent
+	require.NoError(t, json.Unmarshal([]byte(content), &nc))
+
+	tl, err := nc.ToTaskList()
+	require.NoError(t, err)
+	require.Len(t, tl.Tasks, 4)
+	require.True(t, tl.Tasks[0].Completed)
+	require.Equal(t, `Fix \n escaping`, tl.Tasks[3].Title)
+
+	nc.SetTaskList(tl)
+
+	b, err := json.Marshal(nc)
+	require.NoError(t, err)
+	require.JSONEq(t, content, string(b))
+
+	// an empty list has no tasks
+	nc.Text = ""
+	tl, err = nc.ToTaskList()
+	require.NoError(t, err)
+	require.Empty(t, tl.Tasks)
+}
+
+func TestTasklistDuplicateTitles(t *testing.T) {
+	t.Parallel()
+
+	tl := ParseTasklistText("- [ ] Review PR\n- [ ] Deploy\n- [ ] Review PR")
+
+	require.Equal(t, []int{0, 2}, tl.TaskIndexes("Review PR"))
+	require.ErrorIs(t, tl.CompleteTask("Review PR"), errDuplicateTask)
+	require.ErrorIs(t, tl.DeleteTask("Review PR"), errDuplicateTask)
+	require.ErrorIs(t, tl.CompleteTask("Missing"), errTaskNotFound)
+
+	require.NoError(t, tl.CompleteTaskAt(2))
+	require.Equal(t, "- [ ] Review PR\n- [ ] Deploy\n- [x] Review PR", tl.Text())
+	require.ErrorContains(t, tl.CompleteTaskAt(2), taskAlreadyCompleted)
+	require.ErrorIs(t, tl.CompleteTaskAt(3), errTaskIndex)
+
+	require.NoError(t, tl.ReopenTaskAt(2))
+	require.ErrorContains(t, tl.ReopenTaskAt(2), taskAlreadyOpen)
+
+	require.NoError(t, tl.RenameTaskAt(2, "Review PR 2"))
+	require.ErrorIs(t, tl.RenameTaskAt(0, "a\nb"), errTaskTitle)
+	require.NoError(t, tl.RenameTask("Review PR", "Review PR 1"))
+	require.NoError(t, tl.DeleteTask("Deploy"))
+	require.Equal(t, "- [ ] Review PR 1\n- [ ] Review PR 2", tl.Text())
+}
+
+func TestTasklistReorder(t *testing.T) {
+	t.Parallel()
+
+	tl := ParseTasklistText("- [ ] A\n- [ ] B\n- [ ] C\n- [ ] D")
+
+	require.NoError(t, tl.MoveTask(0, 2))
+	require.Equal(t, "- [ ] B\n- [ ] C\n- [ ] A\n- [ ] D", tl.Text())
+
+	require.NoError(t, tl.MoveTask(3, 0))
+	require.Equal(t, "- [ ] D\n- [ ] B\n- [ ] C\n- [ ] A", tl.Text())
+	require.ErrorIs(t, tl.MoveTask(0, 4), errTaskIndex)
+
+	require.NoError(t, tl.ReorderTasks([]int{3, 1, 2, 0}))
+	require.Equal(t, "- [ ] A\n- [ ] B\n- [ ] C\n- [ ] D", tl.Text())
+	require.ErrorIs(t, tl.ReorderTasks([]int{0, 1, 2}), errTaskOrder)
+	require.ErrorIs(t, tl.ReorderTasks([]int{0, 1, 1, 2}), errTaskOrder)
+}
+
+func TestTasklistBulk(t *testing.T) {
+	t.Parallel()
+
+	tl := ParseTasklistText("- [ ] A\n- [x] B\n- [ ] C")
+
+	require.Equal(t, 2, tl.CompleteAllTasks())
+	require.Equal(t, 0, tl.CompleteAllTasks())
+	require.Equal(t, 3, tl.ReopenAllTasks())
+
+	require.NoError(t, tl.CompleteTask("B"))
+	require.Equal(t, 1, tl.ClearCompletedTasks())
+	require.Equal(t, "- [ ] A\n- [ ] C", tl.Text())
+}
+
+func TestTasklistMarkdown(t *testing.T) {
+	t.Parallel()
+
+	md := "# Stand-up\n\nYesterday:\n\n- [x] Deploy\n* [ ] Review PR  \n  - [X] Nested\n1. [ ] Numbered\n- not a task\n"
+
+	tasks := MarkdownToTasks(md)
+	require.Equal(t, Tasks{
+		{Title: "Deploy", Completed: true},
+		{Title: "Review PR"},
+		{Title: "Nested", Completed: true},
+		{Title: "Numbered"},
+	}, tasks)
+
+	md = TasksToMarkdown(tasks)
+	require.Equal(t, "- [x] Deploy\n- [ ] Review PR\n- [x] Nested\n- [ ] Numbered\n", md)
+	require.Equal(t, tasks, MarkdownToTasks(md))
+
+	tl := Tasklist{Tasks: tasks}
+	require.Equal(t, tasks, Tasks(ParseTasklistText(tl.Text()).Tasks))
+}
+
+func TestTasklistTextRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{
+		"",
+		"- [ ] A",
+		"- [ ] A\n",
+		"- [ ] A\n\n- [x] B\n\n",
+		"\n- [ ] A\n  \n\n- [X] B\n- [x] C",
+		`- [ ] A\n- [x] B\n- [X] C`,
+		`- [ ] A\n\n- [ ] B \\n escaped\n`,
+	} {
+		require.Equal(t, text, ParseTasklistText(text).Text(), text)
+	}
+
+	// blank lines are kept with the task after them, and tasks completed with "[X]" keep it
+	tl := ParseTasklistText("- [ ] A\n\n- [X] B\n")
+	require.Equal(t, []Task{{Title: "A"}, {Title: "B", Completed: true, blankBefore: []string{""}, upper: true}}, tl.Tasks)
+
+	require.NoError(t, tl.MoveTask(1, 0))
+	require.NoError(t, tl.CompleteTaskAt(1))
+	require.Equal(t, "\n- [X] B\n- [x] A\n", tl.Text())
+
+	require.NoError(t, tl.ReopenTaskAt(0))
+	require.Equal(t, "\n- [ ] B\n- [x] A\n", tl.Text())
+}
+
+func TestTasklistEscapedFormat(t *testing.T) {
+	t.Parallel()
+
+	// older notes separate the tasks with an escaped new line
+	nc := NoteContent{EditorIdentifier: SimpleTaskEditorNoteType, Text: `- [ ] A\n- [x] B\\n\n- [ ] C`}
+
+	tl, err := nc.ToTaskList()
+	require.NoError(t, err)
+	require.Equal(t, []Task{{Title: "A"}, {Title: `B\\n`, Completed: true}, {Title: "C"}}, tl.Tasks)
+
+	// the format is kept when the tasks are changed
+	require.NoError(t, tl.CompleteTask("A"))
+	require.NoError(t, tl.AddTask("D"))
+
+	nc.SetTaskList(tl)
+	require.Equal(t, `- [ ] D\n- [x] A\n- [x] B\\n\n- [ ] C`, nc.Text)
+
+	// new lists and those with a single task use new lines
+	tl = ParseTasklistText("- [ ] A")
+	require.NoError(t, tl.AddTask("B"))
+	require.Equal(t, "- [ ] B\n- [ ] A", tl.Text())
+}
@@ -3,7 +3,6 @@ package items
 import (
 	"encoding/json"
 	"fmt"
-	"slices"
 	"strconv"
 	"time"
 
@@ -46,29 +45,6 @@ func (fb FlexibleBool) Bool() bool {
 	return bool(fb)
 }
 
-func parseComponent(i DecryptedItem) Item {
-	c := Component{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		c.Content = *content.(*ComponentContent)
-	}
-
-	return &c
-}
-
 type ComponentContent struct {
 	Identifier         string         `json:"identifier"`
 	LegacyURL          string         `json:"legacy_url,omitempty"`
@@ -90,56 +66,32 @@ type ComponentContent struct {
 	AppData            AppDataContent `json:"appData"`
 	// Missing attributes from official Standard Notes
 	IsDeprecated       bool           `json:"isDeprecated,omitempty"` // Component deprecation flag
+	Unknown            UnknownFields  `json:"-"`
 }
 
-type Component struct {
-	ItemCommon
-	Content ComponentContent
-}
+func (cc *ComponentContent) UnmarshalJSON(data []byte) (err error) {
+	type alias ComponentContent
 
-func (c Component) IsDefault() bool {
-	return false
-}
-
-func (i Items) Components() (c Components) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeComponent {
-			component := x.(*Component)
-			c = append(c, *component)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *Components) DeDupe() {
-	var encountered []string
-
-	var deDuped Components
+func (cc ComponentContent) MarshalJSON() ([]byte, error) {
+	type alias ComponentContent
 
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-		encountered = append(encountered, i.UUID)
-	}
+type Component = TypedItem[ComponentContent, *ComponentContent]
 
-	*c = deDuped
+func (i Items) Components() (c Components) {
+	return ItemsOfType[ComponentContent](i)
 }
 
 // NewComponent returns an Item of type Component without content.
 func NewComponent() Component {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c Component
-
-	c.ContentType = common.SNItemTypeComponent
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.CreatedAt = now
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[ComponentContent](common.SNItemTypeComponent)
 }
 
 // NewComponentContent returns an empty Tag content instance.
@@ -150,120 +102,14 @@ func NewComponentContent() *ComponentContent {
 	return c
 }
 
-type Components []Component
-
-func (c Components) Validate() error {
-	var updatedTime time.Time
-
-	var err error
+type Components = TypedItems[ComponentContent, *ComponentContent]
 
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing title: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
+func (cc *ComponentContent) MissingField() string {
+	if cc.Name == "" {
+		return "title"
 	}
 
-	return err
-}
-
-func (c Component) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *Component) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c Component) GetContent() Content {
-	return &c.Content
-}
-
-func (c *Component) SetContent(cc Content) {
-	c.Content = *cc.(*ComponentContent)
-}
-
-func (c Component) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c Component) GetUUID() string {
-	return c.UUID
-}
-
-func (c Component) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *Component) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c Component) GetContentType() string {
-	return c.ContentType
-}
-
-func (c Component) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *Component) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c Component) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *Component) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c Component) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *Component) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c Component) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *Component) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *Component) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c Component) GetContentSize() int {
-	return c.ContentSize
-}
-
-func (c *Component) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *ComponentContent) AssociateItems(newItems []string) {
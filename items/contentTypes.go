@@ -0,0 +1,121 @@
+package items
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jonhadfield/gosn-v2/common"
+)
+
+// ErrContentTypeRegistered is returned when registering a content type that is already parsed by gosn.
+var ErrContentTypeRegistered = errors.New("content type already registered")
+
+// contentModel describes how the items of a content type are decoded.
+type contentModel struct {
+	// name identifies the content type in errors
+	name       string
+	newContent func() Content
+	parse      func(DecryptedItem) (Item, error)
+	// allowEmpty returns empty content, rather than an error, if the item has none
+	allowEmpty bool
+}
+
+// contentPointer is satisfied by a pointer to C that implements Content.
+type contentPointer[C any] interface {
+	*C
+	Content
+}
+
+func typedContentModel[C any, P contentPointer[C]](name string, allowEmpty bool) contentModel {
+	return contentModel{
+		name:       name,
+		newContent: func() Content { return P(new(C)) },
+		parse:      parseTypedItem[C, P],
+		allowEmpty: allowEmpty,
+	}
+}
+
+var (
+	contentModelsMutex sync.RWMutex
+	contentModels      map[string]contentModel
+)
+
+func init() {
+	contentModels = map[string]contentModel{
+		common.SNItemTypeNote: {
+			name:       "note",
+			newContent: func() Content { return &NoteContent{} },
+			parse:      parseNote,
+		},
+		common.SNItemTypeTag: {
+			name:       "tag",
+			newContent: func() Content { return &TagContent{} },
+			parse:      parseTag,
+		},
+		common.SNItemTypeComponent:            typedContentModel[ComponentContent]("component", false),
+		common.SNItemTypeTheme:                typedContentModel[ThemeContent]("theme", false),
+		common.SNItemTypePrivileges:           typedContentModel[PrivilegesContent]("privileges", false),
+		common.SNItemTypeExtension:            typedContentModel[ExtensionContent]("extension", false),
+		common.SNItemTypeSFExtension:          typedContentModel[SFExtensionContent]("sf extension", true),
+		common.SNItemTypeSFMFA:                typedContentModel[SFMFAContent]("sf mfa", true),
+		common.SNItemTypeSmartTag:             typedContentModel[SmartViewContent]("smart view", true),
+		common.SNItemTypeFileSafeFileMetaData: typedContentModel[FileSafeFileMetaDataContent]("sf metadata", true),
+		common.SNItemTypeFileSafeIntegration:  typedContentModel[FileSafeIntegrationContent]("fs integration", true),
+		common.SNItemTypeUserPreferences:      typedContentModel[UserPreferencesContent]("user preferences", true),
+		common.SNItemTypeExtensionRepo:        typedContentModel[ExtensionRepoContent]("extension repo", true),
+		common.SNItemTypeFileSafeCredentials:  typedContentModel[FileSafeCredentialsContent]("fs credentials", true),
+		common.SNItemTypeFile:                 typedContentModel[FileContent]("file", true),
+		common.SNItemTypeTrustedContact:       typedContentModel[TrustedContactContent]("trusted contact", true),
+		common.SNItemTypeVaultListing:         typedContentModel[VaultListingContent]("vault listing", true),
+		common.SNItemTypeKeySystemRootKey:     typedContentModel[KeySystemRootKeyContent]("key system root key", true),
+		common.SNItemTypeKeySystemItemsKey:    typedContentModel[KeySystemItemsKeyContent]("key system items key", true),
+	}
+}
+
+// RegisterContentType adds a content type, such as "Acme|Snippet", whose items are then parsed as
+// TypedItem[C, *C] instead of being passed through as an UnknownItem. Content types that are already
+// registered, including those built in, cannot be replaced.
+func RegisterContentType[C any, P contentPointer[C]](contentType string) error {
+	contentModelsMutex.Lock()
+	defer contentModelsMutex.Unlock()
+
+	if _, ok := contentModels[contentType]; ok || contentType == common.SNItemTypeItemsKey {
+		return fmt.Errorf("RegisterContentType | %s: %w", contentType, ErrContentTypeRegistered)
+	}
+
+	contentModels[contentType] = typedContentModel[C, P](contentType, true)
+
+	return nil
+}
+
+// getContentModel returns the model of the content type, with content types not known to gosn
+// passed through as UnknownItem.
+func getContentModel(contentType string) contentModel {
+	contentModelsMutex.RLock()
+	m, ok := contentModels[contentType]
+	contentModelsMutex.RUnlock()
+
+	if !ok {
+		m = typedContentModel[UnknownContent](contentType, true)
+	}
+
+	return m
+}
+
+func processContentModel(contentType, input string) (output Content, err error) {
+	m := getContentModel(contentType)
+
+	output = m.newContent()
+
+	if len(input) == 0 && m.allowEmpty {
+		return output, nil
+	}
+
+	if err = json.Unmarshal([]byte(input), output); err != nil {
+		return nil, fmt.Errorf("processContentModel %s | %w", m.name, err)
+	}
+
+	return output, nil
+}
@@ -0,0 +1,455 @@
+package items
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/session"
+)
+
+// DuplicateReason is why notes were found to be duplicates of each other.
+type DuplicateReason string
+
+const (
+	// DuplicateConflict is for notes linked by duplicate_of, i.e. copies made by the server of conflicting changes.
+	DuplicateConflict DuplicateReason = "duplicate_of"
+	// DuplicateContent is for notes with the same title and text.
+	DuplicateContent DuplicateReason = "content"
+	// DuplicateSimilar is for notes with near-identical titles and text.
+	DuplicateSimilar DuplicateReason = "similar"
+)
+
+// DefaultDuplicateSimilarity is the similarity used when FindDuplicatesOptions does not set one.
+const DefaultDuplicateSimilarity = 0.9
+
+// duplicateSeparator separates the texts of notes merged with MergeConcatenate.
+const duplicateSeparator = "\n\n---\n\n"
+
+// FindDuplicatesOptions configures how near-identical notes are found.
+type FindDuplicatesOptions struct {
+	// Similarity is the minimum similarity, from 0 to 1, of near-identical notes, measured as the share of
+	// pairs of consecutive words the notes have in common. Zero uses DefaultDuplicateSimilarity and a negative
+	// value only finds notes linked by duplicate_of or with the same content.
+	Similarity float64
+}
+
+// DuplicateGroup is a set of notes found to be duplicates of each other.
+type DuplicateGroup struct {
+	// Notes are the duplicates, most recently updated first.
+	Notes Notes
+	// Reasons are the reasons notes in the group were found to be duplicates.
+	Reasons []DuplicateReason
+}
+
+// Original returns the note the others are copies of, which is the note not marked as a duplicate
+// of another in the group, or the oldest if there is more than one.
+func (g DuplicateGroup) Original() Note {
+	uuids := make(map[string]bool, len(g.Notes))
+	for _, n := range g.Notes {
+		uuids[n.UUID] = true
+	}
+
+	var original *Note
+
+	for x := range g.Notes {
+		n := &g.Notes[x]
+
+		switch {
+		case original == nil:
+			original = n
+		case uuids[n.DuplicateOf] != uuids[original.DuplicateOf]:
+			if !uuids[n.DuplicateOf] {
+				original = n
+			}
+		case n.CreatedAtTimestamp < original.CreatedAtTimestamp:
+			original = n
+		}
+	}
+
+	return *original
+}
+
+// FindDuplicates groups the notes that are duplicates of each other, either because they are linked by
+// duplicate_of, have the same content, or are near-identical. Deleted and trashed notes are ignored.
+// Groups are returned in the order of their first note provided.
+func FindDuplicates(notes Notes, opts FindDuplicatesOptions) []DuplicateGroup {
+	var ns Notes
+
+	for _, n := range notes {
+		if !n.Deleted && !n.Content.GetTrashed() {
+			ns = append(ns, n)
+		}
+	}
+
+	ds := newDisjointSet(len(ns))
+
+	byUUID := make(map[string]int, len(ns))
+	byHash := make(map[string]int, len(ns))
+
+	for x, n := range ns {
+		byUUID[n.UUID] = x
+	}
+
+	for x, n := range ns {
+		if y, ok := byUUID[n.DuplicateOf]; ok && n.DuplicateOf != "" {
+			ds.union(x, y, DuplicateConflict)
+		}
+
+		h := n.ContentHash()
+		if y, ok := byHash[h]; ok {
+			ds.union(x, y, DuplicateContent)
+		} else {
+			byHash[h] = x
+		}
+	}
+
+	if opts.Similarity >= 0 {
+		similarity := cmp.Or(opts.Similarity, DefaultDuplicateSimilarity)
+		findSimilarNotes(ns, similarity, ds)
+	}
+
+	var groups []DuplicateGroup
+
+	index := make(map[int]int)
+
+	for x, n := range ns {
+		root := ds.find(x)
+		if len(ds.members[root]) < 2 {
+			continue
+		}
+
+		g, ok := index[root]
+		if !ok {
+			g = len(groups)
+			index[root] = g
+
+			reasons := make([]DuplicateReason, 0, len(ds.reasons[root]))
+			for r := range ds.reasons[root] {
+				reasons = append(reasons, r)
+			}
+
+			slices.Sort(reasons)
+
+			groups = append(groups, DuplicateGroup{Reasons: reasons})
+		}
+
+		groups[g].Notes = append(groups[g].Notes, n)
+	}
+
+	for _, g := range groups {
+		slices.SortStableFunc(g.Notes, func(a, b Note) int {
+			return noteUpdated(b).Compare(noteUpdated(a))
+		})
+	}
+
+	return groups
+}
+
+// findSimilarNotes joins the notes whose similarity is at least the threshold. Only notes with a similar
+// number of word pairs can be near-identical, so notes are compared in order of size.
+func findSimilarNotes(ns Notes, threshold float64, ds *disjointSet) {
+	shingles := make([]map[string]bool, len(ns))
+	order := make([]int, 0, len(ns))
+
+	for x, n := range ns {
+		shingles[x] = noteShingles(n)
+		if len(shingles[x]) > 0 {
+			order = append(order, x)
+		}
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(len(shingles[a]), len(shingles[b]))
+	})
+
+	for i, x := range order {
+		for _, y := range order[i+1:] {
+			if float64(len(shingles[x])) < threshold*float64(len(shingles[y])) {
+				break
+			}
+
+			if ds.find(x) != ds.find(y) && jaccard(shingles[x], shingles[y]) >= threshold {
+				ds.union(x, y, DuplicateSimilar)
+			}
+		}
+	}
+}
+
+// noteShingles returns the pairs of consecutive words in the note's title and text, or its words
+// if there is only one. Super notes are compared by their Markdown.
+func noteShingles(n Note) map[string]bool {
+	text, err := n.Content.GetMarkdown()
+	if err != nil {
+		text = n.Content.Text
+	}
+
+	words := strings.Fields(strings.ToLower(n.Content.Title + "\n" + text))
+	res := make(map[string]bool, len(words))
+
+	if len(words) == 1 {
+		res[words[0]] = true
+	}
+
+	for x := 1; x < len(words); x++ {
+		res[words[x-1]+" "+words[x]] = true
+	}
+
+	return res
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	shared := 0
+
+	for s := range a {
+		if b[s] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// disjointSet tracks which notes are in the same group, and why.
+type disjointSet struct {
+	parent  []int
+	members map[int][]int
+	reasons map[int]map[DuplicateReason]bool
+}
+
+func newDisjointSet(n int) *disjointSet {
+	ds := &disjointSet{
+		parent:  make([]int, n),
+		members: make(map[int][]int, n),
+		reasons: make(map[int]map[DuplicateReason]bool),
+	}
+
+	for x := range ds.parent {
+		ds.parent[x] = x
+		ds.members[x] = []int{x}
+	}
+
+	return ds
+}
+
+func (ds *disjointSet) find(x int) int {
+	for ds.parent[x] != x {
+		ds.parent[x] = ds.parent[ds.parent[x]]
+		x = ds.parent[x]
+	}
+
+	return x
+}
+
+func (ds *disjointSet) union(x, y int, reason DuplicateReason) {
+	rx, ry := ds.find(x), ds.find(y)
+
+	if rx != ry {
+		if len(ds.members[rx]) < len(ds.members[ry]) {
+			rx, ry = ry, rx
+		}
+
+		ds.parent[ry] = rx
+		ds.members[rx] = append(ds.members[rx], ds.members[ry]...)
+		delete(ds.members, ry)
+
+		if ds.reasons[rx] == nil {
+			ds.reasons[rx] = make(map[DuplicateReason]bool)
+		}
+
+		for r := range ds.reasons[ry] {
+			ds.reasons[rx][r] = true
+		}
+
+		delete(ds.reasons, ry)
+	}
+
+	if ds.reasons[rx] == nil {
+		ds.reasons[rx] = make(map[DuplicateReason]bool)
+	}
+
+	ds.reasons[rx][reason] = true
+}
+
+// MergeStrategy is how the texts of duplicate notes are merged.
+type MergeStrategy int
+
+const (
+	// MergeKeepNewest keeps the content of the most recently updated note.
+	MergeKeepNewest MergeStrategy = iota
+	// MergeConcatenate keeps the newest title and joins the distinct texts of the notes, newest first.
+	MergeConcatenate
+)
+
+type MergeDuplicatesInput struct {
+	Session *session.Session
+	Groups  []DuplicateGroup
+	// Tags are the tags that may reference the notes, so they can be updated to reference the notes kept.
+	Tags     Tags
+	Strategy MergeStrategy
+}
+
+type MergeDuplicatesOutput struct {
+	// Notes are the notes kept, one for each group, with the merged content and references.
+	Notes Notes
+	// Trashed are the other notes of each group, which are moved to the trash.
+	Trashed Notes
+	// Tags are the tags updated to reference the notes kept.
+	Tags Tags
+}
+
+// MergeDuplicates merges each group of duplicates into its original note, so links to it are kept, and moves
+// the other notes to the trash. The note kept has the content chosen by the strategy, the references of all
+// of the notes, and is added to the tags of the others. The changes are made with a single Sync.
+func MergeDuplicates(input MergeDuplicatesInput) (MergeDuplicatesOutput, error) {
+	output, err := mergeDuplicates(input.Groups, input.Tags, input.Strategy, time.Now().UTC())
+	if err != nil {
+		return MergeDuplicatesOutput{}, fmt.Errorf("MergeDuplicates | %w", err)
+	}
+
+	var toSync Items
+
+	for x := range output.Notes {
+		toSync = append(toSync, &output.Notes[x])
+	}
+
+	for x := range output.Trashed {
+		toSync = append(toSync, &output.Trashed[x])
+	}
+
+	for x := range output.Tags {
+		toSync = append(toSync, &output.Tags[x])
+	}
+
+	if len(toSync) == 0 {
+		return output, nil
+	}
+
+	e, err := encryptItems(input.Session, &toSync, input.Session.DefaultItemsKey)
+	if err != nil {
+		return MergeDuplicatesOutput{}, fmt.Errorf("MergeDuplicates | %w", err)
+	}
+
+	so, err := Sync(SyncInput{Session: input.Session, Items: e})
+	if err != nil {
+		return MergeDuplicatesOutput{}, fmt.Errorf("MergeDuplicates | %w", err)
+	}
+
+	if err = so.checkSaved(toSync.UUIDs()...); err != nil {
+		return MergeDuplicatesOutput{}, fmt.Errorf("MergeDuplicates | %w", err)
+	}
+
+	return output, nil
+}
+
+func mergeDuplicates(groups []DuplicateGroup, tags Tags, strategy MergeStrategy, now time.Time) (MergeDuplicatesOutput, error) {
+	var output MergeDuplicatesOutput
+
+	// the note kept for each of the notes trashed
+	keptFor := make(map[string]Note)
+
+	for _, g := range groups {
+		if len(g.Notes) < 2 {
+			continue
+		}
+
+		kept, err := mergeDuplicateGroup(g, strategy, now)
+		if err != nil {
+			return MergeDuplicatesOutput{}, err
+		}
+
+		output.Notes = append(output.Notes, kept)
+
+		for _, n := range g.Notes {
+			if n.UUID == kept.UUID {
+				continue
+			}
+
+			keptFor[n.UUID] = kept
+
+			n.Content.ItemReferences = slices.Clone(n.Content.ItemReferences)
+			n.Content.SetTrashed(true)
+			n.Content.SetUpdateTime(now)
+			output.Trashed = append(output.Trashed, n)
+		}
+	}
+
+	for _, t := range tags {
+		var toRef Items
+
+		for _, ref := range t.Content.ItemReferences {
+			kept, ok := keptFor[ref.UUID]
+			if ok && !slices.ContainsFunc(t.Content.ItemReferences, func(r ItemReference) bool { return r.UUID == kept.UUID }) &&
+				!slices.ContainsFunc(toRef, func(i Item) bool { return i.GetUUID() == kept.UUID }) {
+				toRef = append(toRef, &kept)
+			}
+		}
+
+		if len(toRef) == 0 {
+			continue
+		}
+
+		t.Content.ItemReferences = slices.Clone(t.Content.ItemReferences)
+		UpdateItemRefs(UpdateItemRefsInput{Items: Items{&t}, ToRef: toRef})
+		t.Content.SetUpdateTime(now)
+		output.Tags = append(output.Tags, t)
+	}
+
+	return output, nil
+}
+
+// mergeDuplicateGroup returns the group's original note with the merged content and references of the group.
+func mergeDuplicateGroup(g DuplicateGroup, strategy MergeStrategy, now time.Time) (Note, error) {
+	kept := g.Original()
+	newest := g.Notes[0]
+
+	kept.Content = newest.Content
+	kept.Content.ItemReferences = nil
+
+	if strategy == MergeConcatenate {
+		var texts []string
+
+		seen := make(map[string]bool)
+
+		for _, n := range g.Notes {
+			md, err := n.Content.GetMarkdown()
+			if err != nil {
+				return Note{}, fmt.Errorf("note %s: %w", n.UUID, err)
+			}
+
+			key := strings.TrimSpace(strings.ReplaceAll(md, "\r\n", "\n"))
+			if key == "" || seen[key] {
+				continue
+			}
+
+			seen[key] = true
+
+			texts = append(texts, key)
+		}
+
+		if newest.Content.IsSuper() {
+			kept.Content.SetSuperMarkdown(strings.Join(texts, duplicateSeparator))
+		} else {
+			kept.Content.Text = strings.Join(texts, duplicateSeparator)
+		}
+	}
+
+	members := make(map[string]bool, len(g.Notes))
+	for _, n := range g.Notes {
+		members[n.UUID] = true
+	}
+
+	for _, n := range g.Notes {
+		for _, ref := range n.Content.ItemReferences {
+			if !members[ref.UUID] {
+				kept.Content.UpsertReferences(ItemReferences{ref})
+			}
+		}
+	}
+
+	kept.Content.SetUpdateTime(now)
+
+	return kept, nil
+}
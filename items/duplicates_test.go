@@ -0,0 +1,137 @@
+package items
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func duplicateTestNote(title, text, uuid string, updated time.Time) *Note {
+	n := createNote(title, text, uuid)
+	n.CreatedAtTimestamp = updated.UnixMicro()
+	n.Content.SetUpdateTime(updated)
+
+	return n
+}
+
+func TestFindDuplicates(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	original := duplicateTestNote("Plan", "ship it", "n1", day)
+	conflict := duplicateTestNote("Plan", "ship it today", "n2", day.Add(2*time.Hour))
+	conflict.DuplicateOf = "n1"
+	copied := duplicateTestNote("Plan", "ship it", "n3", day.Add(time.Hour))
+
+	long := "the quick brown fox jumps over the lazy dog while the cat sleeps in the warm afternoon sun"
+	similar1 := duplicateTestNote("Fox", long, "s1", day)
+	similar2 := duplicateTestNote("Fox", long+" again", "s2", day.Add(time.Hour))
+
+	unrelated := duplicateTestNote("Other", "nothing in common", "u1", day)
+
+	trashed := duplicateTestNote("Plan", "ship it", "t1", day)
+	trashed.Content.SetTrashed(true)
+
+	notes := Notes{*original, *similar1, *conflict, *unrelated, *copied, *similar2, *trashed}
+
+	groups := FindDuplicates(notes, FindDuplicatesOptions{})
+	require.Len(t, groups, 2)
+
+	require.Equal(t, []DuplicateReason{DuplicateContent, DuplicateConflict}, groups[0].Reasons)
+	require.Equal(t, []string{"n2", "n3", "n1"}, noteUUIDs(groups[0].Notes))
+	require.Equal(t, "n1", groups[0].Original().UUID)
+
+	require.Equal(t, []DuplicateReason{DuplicateSimilar}, groups[1].Reasons)
+	require.Equal(t, []string{"s2", "s1"}, noteUUIDs(groups[1].Notes))
+	require.Equal(t, "s1", groups[1].Original().UUID)
+
+	// near-identical notes are not found with a higher threshold, or without comparing similarity
+	require.Len(t, FindDuplicates(notes, FindDuplicatesOptions{Similarity: 0.99}), 1)
+	require.Len(t, FindDuplicates(notes, FindDuplicatesOptions{Similarity: -1}), 1)
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := day.Add(24 * time.Hour)
+
+	original := duplicateTestNote("Plan", "ship it", "n1", day)
+	original.Content.ItemReferences = ItemReferences{{UUID: "linked", ContentType: "Note"}}
+	conflict := duplicateTestNote("Plan v2", "ship it today", "n2", day.Add(2*time.Hour))
+	conflict.DuplicateOf = "n1"
+	conflict.Content.ItemReferences = ItemReferences{{UUID: "n1", ContentType: "Note"}, {UUID: "other", ContentType: "Note"}}
+	copied := duplicateTestNote("Plan", "ship it", "n3", day.Add(time.Hour))
+
+	groups := FindDuplicates(Notes{*original, *conflict, *copied}, FindDuplicatesOptions{})
+	require.Len(t, groups, 1)
+
+	work, err := createTag("work", "tag1", ItemReferences{{UUID: "n2", ContentType: "Note"}})
+	require.NoError(t, err)
+
+	home, err := createTag("home", "tag2", ItemReferences{{UUID: "n1", ContentType: "Note"}})
+	require.NoError(t, err)
+
+	out, err := mergeDuplicates(groups, Tags{*work, *home}, MergeKeepNewest, now)
+	require.NoError(t, err)
+
+	// the original is kept with the newest content and the references of all of the notes
+	require.Len(t, out.Notes, 1)
+	kept := out.Notes[0]
+	require.Equal(t, "n1", kept.UUID)
+	require.Equal(t, "Plan v2", kept.Content.Title)
+	require.Equal(t, "ship it today", kept.Content.Text)
+	require.Equal(t, []string{"other", "linked"}, referenceUUIDs(kept.Content.ItemReferences))
+
+	updated, err := kept.Content.GetUpdateTime()
+	require.NoError(t, err)
+	require.Equal(t, now, updated)
+
+	require.Equal(t, []string{"n2", "n3"}, noteUUIDs(out.Trashed))
+
+	for _, n := range out.Trashed {
+		require.True(t, n.Content.GetTrashed())
+	}
+
+	// the copies are left untouched
+	require.False(t, conflict.Content.GetTrashed())
+
+	// only the tag of a trashed note is changed
+	require.Len(t, out.Tags, 1)
+	require.Equal(t, []string{"n2", "n1"}, referenceUUIDs(out.Tags[0].Content.ItemReferences))
+	require.Len(t, work.Content.ItemReferences, 1)
+
+	out, err = mergeDuplicates(groups, nil, MergeConcatenate, now)
+	require.NoError(t, err)
+	require.Equal(t, "Plan v2", out.Notes[0].Content.Title)
+	require.Equal(t, "ship it today\n\n---\n\nship it", out.Notes[0].Content.Text)
+
+	// Super notes are concatenated as Markdown
+	superNew, err := NewSuperNote("Plan", "- [x] ship it", nil)
+	require.NoError(t, err)
+	superNew.Content.SetUpdateTime(day.Add(time.Hour))
+
+	out, err = mergeDuplicates([]DuplicateGroup{{Notes: Notes{superNew, *original}}}, nil, MergeConcatenate, now)
+	require.NoError(t, err)
+	require.True(t, out.Notes[0].Content.IsSuper())
+
+	md, err := out.Notes[0].Content.GetMarkdown()
+	require.NoError(t, err)
+	require.Equal(t, "- [x] ship it\n\n---\n\nship it", md)
+}
+
+func noteUUIDs(notes Notes) []string {
+	uuids := make([]string, 0, len(notes))
+	for _, n := range notes {
+		uuids = append(uuids, n.UUID)
+	}
+
+	return uuids
+}
+
+func referenceUUIDs(refs ItemReferences) []string {
+	uuids := make([]string, 0, len(refs))
+	for _, r := range refs {
+		uuids = append(uuids, r.UUID)
+	}
+
+	return uuids
+}
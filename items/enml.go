@@ -0,0 +1,655 @@
+package items
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ENEXTextFormat is the format that the content of imported Evernote notes is converted to.
+type ENEXTextFormat string
+
+const (
+	// ENEXMarkdown converts notes to Markdown in plain text notes.
+	ENEXMarkdown ENEXTextFormat = "markdown"
+	// ENEXHTML converts notes to HTML in rich text notes.
+	ENEXHTML ENEXTextFormat = "html"
+	// ENEXSuper converts notes to Super notes.
+	ENEXSuper ENEXTextFormat = "super"
+)
+
+// richTextNoteType is the note type of notes whose text is HTML.
+const richTextNoteType = "rich-text"
+
+// ENMLMedia is an attachment of an Evernote note, referenced from the note's content by its MD5 hash.
+type ENMLMedia struct {
+	// Hash is empty if the attachment could not be decoded, in which case it is used in turn for the
+	// references to attachments that are not found by hash.
+	Hash string
+	// Name is the text of the link to the attachment and URL where it links to. Attachments without
+	// a URL are written as "[attachment: name]".
+	Name string
+	URL  string
+}
+
+// SetENML sets the text of the note to the ENML content of an Evernote note, converted to the format
+// provided, with the media it references linked to. It returns warnings about content that could not be
+// converted, such as encrypted text.
+func (noteContent *NoteContent) SetENML(enml string, format ENEXTextFormat, media []ENMLMedia) ([]string, error) {
+	conv := &enmlConverter{media: make(map[string]lexicalNode)}
+
+	for _, m := range media {
+		label := lexicalNode{Type: "text", Text: m.Name, Format: float64(0)}
+
+		link := lexicalNode{Type: "link", URL: m.URL, Children: []lexicalNode{label}}
+		if m.URL == "" {
+			label.Text = "[attachment: " + m.Name + "]"
+			link = label
+		}
+
+		if m.Hash == "" {
+			conv.undecoded = append(conv.undecoded, link)
+
+			continue
+		}
+
+		conv.media[strings.ToLower(m.Hash)] = link
+	}
+
+	blocks, err := conv.convert(enml)
+	if err != nil {
+		return nil, fmt.Errorf("SetENML | %w", err)
+	}
+
+	switch format {
+	case ENEXHTML:
+		var sb strings.Builder
+		for _, b := range blocks {
+			sb.WriteString(htmlBlock(b))
+		}
+
+		noteContent.Text = sb.String()
+		noteContent.NoteType = richTextNoteType
+	case ENEXSuper:
+		noteContent.SetSuperMarkdown(markdownBlocks(blocks))
+	default:
+		noteContent.Text = markdownBlocks(blocks)
+	}
+
+	return conv.warnings, nil
+}
+
+// ENML
+
+// enmlNode is an element or, if name is empty, text of ENML, the XHTML subset Evernote notes are written in.
+type enmlNode struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	children []*enmlNode
+}
+
+func parseENML(content string) (*enmlNode, error) {
+	dec := xml.NewDecoder(strings.NewReader(content))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+
+	root := &enmlNode{name: "#document"}
+	stack := []*enmlNode{root}
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		top := stack[len(stack)-1]
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &enmlNode{name: strings.ToLower(t.Name.Local), attrs: make(map[string]string, len(t.Attr))}
+			for _, a := range t.Attr {
+				n.attrs[strings.ToLower(a.Name.Local)] = a.Value
+			}
+
+			top.children = append(top.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+
+			for x := len(stack) - 1; x > 0; x-- {
+				if stack[x].name == name {
+					stack = stack[:x]
+
+					break
+				}
+			}
+		case xml.CharData:
+			top.children = append(top.children, &enmlNode{text: string(t)})
+		}
+	}
+
+	if note := root.find("en-note"); note != nil {
+		return note, nil
+	}
+
+	return root, nil
+}
+
+func (n *enmlNode) find(name string) *enmlNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+
+		if f := c.find(name); f != nil {
+			return f
+		}
+	}
+
+	return nil
+}
+
+func (n *enmlNode) style() string {
+	return strings.ToLower(strings.ReplaceAll(n.attrs["style"], " ", ""))
+}
+
+// textContent returns the text of the node, with a line for each block and line break.
+func (n *enmlNode) textContent() string {
+	if n.name == "" {
+		return n.text
+	}
+
+	if n.name == "br" {
+		return "\n"
+	}
+
+	var sb strings.Builder
+
+	for _, c := range n.children {
+		t := c.textContent()
+		sb.WriteString(t)
+
+		if isENMLBlock(c.name) && !strings.HasSuffix(t, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func isENMLBlock(name string) bool {
+	switch name {
+	case "div", "p", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "li", "blockquote", "pre", "hr",
+		"table", "tr", "en-note", "section", "article", "header", "footer", "center", "dl", "dt", "dd", "address":
+		return true
+	}
+
+	return false
+}
+
+var (
+	enmlSpaceRE      = regexp.MustCompile(`[ \t\r\n\f]+`)
+	enmlBoldWeightRE = regexp.MustCompile(`font-weight:[6-9]00`)
+)
+
+// enmlConverter converts ENML to Lexical nodes, so that it can be written as Markdown, HTML or a Super note.
+type enmlConverter struct {
+	// media are the nodes that link to attachments, by hash
+	media map[string]lexicalNode
+	// undecoded are the nodes of attachments that could not be decoded, so have no hash, in the order
+	// they were found, which are used in turn for media not found by hash
+	undecoded []lexicalNode
+	warnings  []string
+}
+
+func (c *enmlConverter) convert(content string) ([]lexicalNode, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, nil
+	}
+
+	root, err := parseENML(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.blocks(root.children), nil
+}
+
+func (c *enmlConverter) blocks(nodes []*enmlNode) []lexicalNode {
+	var out []lexicalNode
+
+	var inline []*enmlNode
+
+	var checks *lexicalNode
+
+	flushChecks := func() {
+		if checks != nil {
+			out = append(out, *checks)
+			checks = nil
+		}
+	}
+
+	addParagraph := func(nodes []*enmlNode) {
+		children, todo, checked := c.paragraph(nodes)
+
+		switch {
+		case todo:
+			if checks == nil {
+				checks = &lexicalNode{Type: "list", ListType: "check", Start: 1}
+			}
+
+			checks.Children = append(checks.Children, lexicalNode{Type: "listitem", Checked: checked, Children: children})
+		case len(children) > 0:
+			flushChecks()
+			out = append(out, lexicalNode{Type: "paragraph", Children: children})
+		}
+	}
+
+	flushInline := func() {
+		if len(inline) > 0 {
+			addParagraph(inline)
+			inline = nil
+		}
+	}
+
+	for _, n := range nodes {
+		if !isENMLBlock(n.name) {
+			inline = append(inline, n)
+
+			continue
+		}
+
+		flushInline()
+
+		switch n.name {
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			flushChecks()
+			out = append(out, lexicalNode{Type: "heading", Tag: n.name, Children: tidyInline(c.inline(n.children, 0))})
+		case "ul", "ol":
+			flushChecks()
+			out = append(out, c.list(n))
+		case "blockquote":
+			flushChecks()
+
+			var children []lexicalNode
+
+			for x, b := range c.blocks(n.children) {
+				if x > 0 {
+					children = append(children, lexicalNode{Type: "linebreak"})
+				}
+
+				children = append(children, blockInline(b)...)
+			}
+
+			out = append(out, lexicalNode{Type: "quote", Children: children})
+		case "pre":
+			flushChecks()
+			out = append(out, codeBlock(n.textContent()))
+		case "hr":
+			flushChecks()
+			out = append(out, lexicalNode{Type: "horizontalrule"})
+		case "table":
+			flushChecks()
+			out = append(out, c.table(n))
+		default:
+			switch {
+			case strings.Contains(n.style(), "-en-codeblock:true"):
+				flushChecks()
+				out = append(out, codeBlock(n.textContent()))
+			case hasENMLBlock(n):
+				flushChecks()
+				out = append(out, c.blocks(n.children)...)
+			default:
+				addParagraph(n.children)
+			}
+		}
+	}
+
+	flushInline()
+	flushChecks()
+
+	return out
+}
+
+func hasENMLBlock(n *enmlNode) bool {
+	for _, c := range n.children {
+		if isENMLBlock(c.name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// blockInline returns the inline content of a block, with a line for each nested block.
+func blockInline(n lexicalNode) []lexicalNode {
+	if allInline(n.Children) {
+		return n.Children
+	}
+
+	var out []lexicalNode
+
+	for _, c := range n.Children {
+		if len(out) > 0 {
+			out = append(out, lexicalNode{Type: "linebreak"})
+		}
+
+		if c.isInline() {
+			out = append(out, c)
+		} else {
+			out = append(out, blockInline(c)...)
+		}
+	}
+
+	return out
+}
+
+func codeBlock(text string) lexicalNode {
+	code := lexicalNode{Type: "code"}
+
+	for x, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if x > 0 {
+			code.Children = append(code.Children, lexicalNode{Type: "linebreak"})
+		}
+
+		if line != "" {
+			code.Children = append(code.Children, lexicalNode{Type: "code-highlight", Text: line, Format: float64(0)})
+		}
+	}
+
+	return code
+}
+
+// paragraph converts inline ENML, returning whether it began with a to-do checkbox.
+func (c *enmlConverter) paragraph(nodes []*enmlNode) (children []lexicalNode, todo, checked bool) {
+	for x, n := range nodes {
+		if n.name == "" && strings.TrimSpace(n.text) == "" {
+			continue
+		}
+
+		if n.name == "en-todo" {
+			todo, checked = true, strings.EqualFold(n.attrs["checked"], "true")
+			nodes = nodes[x+1:]
+		}
+
+		break
+	}
+
+	return tidyInline(c.inline(nodes, 0)), todo, checked
+}
+
+func (c *enmlConverter) list(n *enmlNode) lexicalNode {
+	l := lexicalNode{Type: "list", ListType: "bullet", Start: 1}
+
+	if n.name == "ol" {
+		l.ListType = "number"
+		if start, err := strconv.Atoi(n.attrs["start"]); err == nil && start > 0 {
+			l.Start = start
+		}
+	}
+
+	if strings.Contains(n.style(), "--en-todo:true") {
+		l.ListType = "check"
+	}
+
+	for _, child := range n.children {
+		switch {
+		case child.name == "" && strings.TrimSpace(child.text) == "":
+		case child.name == "ul" || child.name == "ol":
+			// lists nested directly within a list belong to the previous item
+			if len(l.Children) == 0 {
+				l.Children = append(l.Children, lexicalNode{Type: "listitem"})
+			}
+
+			last := &l.Children[len(l.Children)-1]
+			last.Children = append(last.Children, c.list(child))
+		default:
+			item := lexicalNode{Type: "listitem", Checked: strings.Contains(child.style(), "--en-checked:true")}
+
+			contents := []*enmlNode{child}
+			if child.name == "li" {
+				contents = child.children
+			}
+
+			var inline []*enmlNode
+
+			flush := func() {
+				if len(inline) > 0 {
+					item.Children = append(item.Children, tidyInline(c.inline(inline, 0))...)
+					inline = nil
+				}
+			}
+
+			for _, ch := range contents {
+				if ch.name == "ul" || ch.name == "ol" {
+					flush()
+					item.Children = append(item.Children, c.list(ch))
+				} else {
+					inline = append(inline, ch)
+				}
+			}
+
+			flush()
+
+			l.Children = append(l.Children, item)
+		}
+	}
+
+	return l
+}
+
+func (c *enmlConverter) table(n *enmlNode) lexicalNode {
+	t := lexicalNode{Type: "table"}
+
+	var rows func(n *enmlNode)
+
+	rows = func(n *enmlNode) {
+		for _, child := range n.children {
+			switch child.name {
+			case "tr":
+				row := lexicalNode{Type: "tablerow"}
+
+				for _, cell := range child.children {
+					if cell.name != "td" && cell.name != "th" {
+						continue
+					}
+
+					tc := lexicalNode{Type: "tablecell", Children: []lexicalNode{{Type: "paragraph", Children: tidyInline(c.inline(cell.children, 0))}}}
+					if cell.name == "th" {
+						tc.HeaderState = 1
+					}
+
+					row.Children = append(row.Children, tc)
+				}
+
+				t.Children = append(t.Children, row)
+			case "thead", "tbody", "tfoot":
+				rows(child)
+			}
+		}
+	}
+
+	rows(n)
+
+	return t
+}
+
+// inline converts ENML to inline Lexical nodes with the text format provided.
+func (c *enmlConverter) inline(nodes []*enmlNode, format int) []lexicalNode {
+	var out []lexicalNode
+
+	for _, n := range nodes {
+		f := format
+
+		switch n.name {
+		case "":
+			out = append(out, lexicalNode{Type: "text", Text: enmlSpaceRE.ReplaceAllString(n.text, " "), Format: float64(format)})
+
+			continue
+		case "br":
+			out = append(out, lexicalNode{Type: "linebreak"})
+
+			continue
+		case "a":
+			children := c.inline(n.children, format)
+			if href := strings.TrimSpace(n.attrs["href"]); href != "" {
+				out = append(out, lexicalNode{Type: "link", URL: href, Children: tidyInline(children)})
+			} else {
+				out = append(out, children...)
+			}
+
+			continue
+		case "en-media":
+			if link, ok := c.media[strings.ToLower(n.attrs["hash"])]; ok {
+				out = append(out, link)
+			} else if len(c.undecoded) > 0 {
+				out = append(out, c.undecoded[0])
+				c.undecoded = c.undecoded[1:]
+			} else {
+				c.warnings = append(c.warnings, fmt.Sprintf("attachment %s not found", n.attrs["hash"]))
+				out = append(out, lexicalNode{Type: "text", Text: "[missing attachment]", Format: float64(format)})
+			}
+
+			continue
+		case "en-todo":
+			box := "[ ] "
+			if strings.EqualFold(n.attrs["checked"], "true") {
+				box = "[x] "
+			}
+
+			out = append(out, lexicalNode{Type: "text", Text: box, Format: float64(format)})
+
+			continue
+		case "en-crypt":
+			c.warnings = append(c.warnings, "encrypted content cannot be imported")
+			out = append(out, lexicalNode{Type: "text", Text: "[encrypted content]", Format: float64(format)})
+
+			continue
+		case "img":
+			if src := strings.TrimSpace(n.attrs["src"]); src != "" {
+				alt := n.attrs["alt"]
+				if alt == "" {
+					alt = "image"
+				}
+
+				out = append(out, lexicalNode{Type: "link", URL: src, Children: []lexicalNode{{Type: "text", Text: alt, Format: float64(format)}}})
+			}
+
+			continue
+		case "b", "strong":
+			f |= lexicalBold
+		case "i", "em", "cite":
+			f |= lexicalItalic
+		case "u", "ins":
+			f |= lexicalUnderline
+		case "s", "strike", "del":
+			f |= lexicalStrikethrough
+		case "code", "tt", "kbd", "samp":
+			f |= lexicalCode
+		case "sub":
+			f |= lexicalSubscript
+		case "sup":
+			f |= lexicalSuperscript
+		case "mark":
+			f |= lexicalHighlight
+		}
+
+		style := n.style()
+
+		switch {
+		case strings.Contains(style, "font-weight:bold"), enmlBoldWeightRE.MatchString(style):
+			f |= lexicalBold
+		}
+
+		if strings.Contains(style, "font-style:italic") {
+			f |= lexicalItalic
+		}
+
+		if strings.Contains(style, "underline") {
+			f |= lexicalUnderline
+		}
+
+		if strings.Contains(style, "line-through") {
+			f |= lexicalStrikethrough
+		}
+
+		if strings.Contains(style, "--en-highlight") || strings.Contains(style, "background-color") {
+			f |= lexicalHighlight
+		}
+
+		out = append(out, c.inline(n.children, f)...)
+
+		// blocks within inline content, e.g. paragraphs within a list item, start new lines
+		if isENMLBlock(n.name) {
+			out = append(out, lexicalNode{Type: "linebreak"})
+		}
+	}
+
+	return out
+}
+
+// tidyInline merges adjacent text with the same format, and removes the whitespace HTML would collapse,
+// along with leading and trailing line breaks.
+func tidyInline(nodes []lexicalNode) []lexicalNode {
+	var merged []lexicalNode
+
+	for _, n := range nodes {
+		if n.Type == "text" && len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			if last.Type == "text" && last.textFormat() == n.textFormat() {
+				last.Text += n.Text
+
+				continue
+			}
+		}
+
+		merged = append(merged, n)
+	}
+
+	var out []lexicalNode
+
+	for x, n := range merged {
+		if n.Type == "text" {
+			atStart := len(out) == 0 || out[len(out)-1].Type == "linebreak" || endsWithSpace(out[len(out)-1])
+			atEnd := x == len(merged)-1 || merged[x+1].Type == "linebreak"
+
+			if atStart {
+				n.Text = strings.TrimLeft(n.Text, " ")
+			}
+
+			if atEnd {
+				n.Text = strings.TrimRight(n.Text, " ")
+			}
+
+			if n.Text == "" {
+				continue
+			}
+		}
+
+		if n.Type == "linebreak" && len(out) == 0 {
+			continue
+		}
+
+		out = append(out, n)
+	}
+
+	for len(out) > 0 && out[len(out)-1].Type == "linebreak" {
+		out = out[:len(out)-1]
+	}
+
+	return out
+}
+
+func endsWithSpace(n lexicalNode) bool {
+	return n.Type == "text" && strings.HasSuffix(n.Text, " ")
+}
@@ -0,0 +1,86 @@
+package items
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const enmlTestContent = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE en-note SYSTEM "http://xml.evernote.com/pub/enml2.dtd">
+<en-note>
+<h1>Trip</h1>
+<div>Book <b>flights</b> and <span style="font-style: italic;">hotel</span>&nbsp;today</div>
+<div><br/></div>
+<ul><li>passport</li><li>tickets<ul><li>return</li></ul></li></ul>
+<div><en-todo checked="true"/>pack</div>
+<div><en-todo/>leave</div>
+<ul style="--en-todo:true;"><li style="--en-checked:true;">booked</li><li>paid</li></ul>
+<div style="-en-codeblock:true;"><div>a := 1</div><div>b := 2</div></div>
+<table><tr><th>Day</th><th>Place</th></tr><tr><td>1</td><td><a href="https://example.com">Rome</a></td></tr></table>
+<hr/>
+<div><en-media type="image/png" hash="0CC175B9C0F1B6A831C399E269772661"/></div>
+<en-crypt>secret</en-crypt>
+</en-note>`
+
+func TestSetENML(t *testing.T) {
+	var nc NoteContent
+
+	warnings, err := nc.SetENML(enmlTestContent, ENEXMarkdown, []ENMLMedia{
+		{Hash: "0cc175b9c0f1b6a831c399e269772661", Name: "map: rome.png", URL: "attachments/map- rome.png"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"encrypted content cannot be imported"}, warnings)
+
+	require.Equal(t, "# Trip\n\n"+
+		"Book **flights** and *hotel*\u00a0today\n\n"+
+		"- passport\n- tickets\n  - return\n\n"+
+		"- [x] pack\n- [ ] leave\n\n"+
+		"- [x] booked\n- [ ] paid\n\n"+
+		"```\na := 1\nb := 2\n```\n\n"+
+		"| Day | Place |\n| --- | --- |\n| 1 | [Rome](https://example.com) |\n\n"+
+		"---\n\n"+
+		"[map: rome.png](attachments/map-%20rome.png)\n\n"+
+		"\\[encrypted content\\]", nc.Text)
+
+	_, err = nc.SetENML("", ENEXMarkdown, nil)
+	require.NoError(t, err)
+	require.Empty(t, nc.Text)
+}
+
+func TestSetENMLFormats(t *testing.T) {
+	media := []ENMLMedia{{Hash: "0cc175b9c0f1b6a831c399e269772661", Name: "map: rome.png"}}
+
+	var nc NoteContent
+
+	_, err := nc.SetENML(enmlTestContent, ENEXHTML, media)
+	require.NoError(t, err)
+
+	// media without a URL are not linked
+	require.Equal(t, richTextNoteType, nc.NoteType)
+	require.Contains(t, nc.Text, "<h1>Trip</h1>")
+	require.Contains(t, nc.Text, "<strong>flights</strong>")
+	require.Contains(t, nc.Text, "[attachment: map: rome.png]")
+
+	nc = NoteContent{}
+
+	_, err = nc.SetENML(enmlTestContent, ENEXSuper, media)
+	require.NoError(t, err)
+	require.True(t, nc.IsSuper())
+
+	md, err := nc.GetMarkdown()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(md, "# Trip\n\nBook **flights**"), md)
+}
+
+func TestSetENMLMedia(t *testing.T) {
+	var nc NoteContent
+
+	// media that could not be decoded have no hash, so are used in turn for those not found
+	warnings, err := nc.SetENML(`<en-note><div><en-media hash="a"/> <en-media hash="b"/> <en-media hash="c"/></div></en-note>`,
+		ENEXMarkdown, []ENMLMedia{{Name: "x.txt"}, {Hash: "B", Name: "b.txt", URL: "b.txt"}})
+	require.NoError(t, err)
+	require.Equal(t, `\[attachment: x.txt\] [b.txt](b.txt) \[missing attachment\]`, nc.Text)
+	require.Equal(t, []string{"attachment c not found"}, warnings)
+}
@@ -2,35 +2,11 @@ package items
 
 import (
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parseExtension(i DecryptedItem) Item {
-	c := Extension{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		c.Content = *content.(*ExtensionContent)
-	}
-
-	return &c
-}
-
 type ExtensionContent struct {
 	ItemReferences     ItemReferences `json:"references"`
 	AppData            AppDataContent `json:"appData"`
@@ -38,56 +14,32 @@ type ExtensionContent struct {
 	DissociatedItemIds []string       `json:"disassociatedItemIds"`
 	AssociatedItemIds  []string       `json:"associatedItemIds"`
 	Active             interface{}    `json:"active"`
+	Unknown            UnknownFields  `json:"-"`
 }
 
-type Extension struct {
-	ItemCommon
-	Content ExtensionContent
-}
+func (cc *ExtensionContent) UnmarshalJSON(data []byte) (err error) {
+	type alias ExtensionContent
 
-func (c Extension) IsDefault() bool {
-	return false
-}
-
-func (i Items) Extension() (c Extensions) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeExtension {
-			component := x.(*Extension)
-			c = append(c, *component)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *Extensions) DeDupe() {
-	var encountered []string
-
-	var deDuped Extensions
+func (cc ExtensionContent) MarshalJSON() ([]byte, error) {
+	type alias ExtensionContent
 
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-		encountered = append(encountered, i.UUID)
-	}
+type Extension = TypedItem[ExtensionContent, *ExtensionContent]
 
-	*c = deDuped
+func (i Items) Extension() (c Extensions) {
+	return ItemsOfType[ExtensionContent](i)
 }
 
 // NewExtension returns an Item of type Extension without content.
 func NewExtension() Extension {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c Extension
-
-	c.ContentType = common.SNItemTypeExtension
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[ExtensionContent](common.SNItemTypeExtension)
 }
 
 // NewExtensionContent returns an empty Tag content instance.
@@ -98,120 +50,14 @@ func NewExtensionContent() *ExtensionContent {
 	return c
 }
 
-type Extensions []Extension
-
-func (c Extensions) Validate() error {
-	var updatedTime time.Time
-
-	var err error
+type Extensions = TypedItems[ExtensionContent, *ExtensionContent]
 
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing title: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
+func (cc *ExtensionContent) MissingField() string {
+	if cc.Name == "" {
+		return "title"
 	}
 
-	return err
-}
-
-func (c Extension) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *Extension) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c Extension) GetContent() Content {
-	return &c.Content
-}
-
-func (c *Extension) SetContent(cc Content) {
-	c.Content = *cc.(*ExtensionContent)
-}
-
-func (c Extension) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c Extension) GetUUID() string {
-	return c.UUID
-}
-
-func (c Extension) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *Extension) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c Extension) GetContentType() string {
-	return c.ContentType
-}
-
-func (c Extension) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *Extension) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c Extension) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *Extension) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c Extension) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *Extension) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c Extension) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *Extension) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *Extension) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c Extension) GetContentSize() int {
-	return c.ContentSize
-}
-
-func (c *Extension) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *ExtensionContent) AssociateItems(newItems []string) {
@@ -2,35 +2,11 @@ package items
 
 import (
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parseExtensionRepo(i DecryptedItem) Item {
-	c := ExtensionRepo{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		c.Content = *content.(*ExtensionRepoContent)
-	}
-
-	return &c
-}
-
 type ExtensionRepoContent struct {
 	ItemReferences     ItemReferences `json:"references"`
 	AppData            AppDataContent `json:"appData"`
@@ -38,56 +14,32 @@ type ExtensionRepoContent struct {
 	DissociatedItemIds []string       `json:"disassociatedItemIds"`
 	AssociatedItemIds  []string       `json:"associatedItemIds"`
 	Active             interface{}    `json:"active"`
+	Unknown            UnknownFields  `json:"-"`
 }
 
-type ExtensionRepo struct {
-	ItemCommon
-	Content ExtensionRepoContent
-}
+func (cc *ExtensionRepoContent) UnmarshalJSON(data []byte) (err error) {
+	type alias ExtensionRepoContent
 
-func (c ExtensionRepo) IsDefault() bool {
-	return false
-}
-
-func (i Items) ExtensionRepo() (c ExtensionRepos) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeExtensionRepo {
-			component := x.(*ExtensionRepo)
-			c = append(c, *component)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *ExtensionRepos) DeDupe() {
-	var encountered []string
-
-	var deDuped ExtensionRepos
+func (cc ExtensionRepoContent) MarshalJSON() ([]byte, error) {
+	type alias ExtensionRepoContent
 
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-		encountered = append(encountered, i.UUID)
-	}
+type ExtensionRepo = TypedItem[ExtensionRepoContent, *ExtensionRepoContent]
 
-	*c = deDuped
+func (i Items) ExtensionRepo() (c ExtensionRepos) {
+	return ItemsOfType[ExtensionRepoContent](i)
 }
 
 // NewExtensionRepo returns an Item of type ExtensionRepo without content.
 func NewExtensionRepo() ExtensionRepo {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c ExtensionRepo
-
-	c.ContentType = common.SNItemTypeExtensionRepo
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[ExtensionRepoContent](common.SNItemTypeExtensionRepo)
 }
 
 // NewExtensionRepoContent returns an empty Tag content instance.
@@ -98,120 +50,14 @@ func NewExtensionRepoContent() *ExtensionRepoContent {
 	return c
 }
 
-type ExtensionRepos []ExtensionRepo
-
-func (c ExtensionRepos) Validate() error {
-	var updatedTime time.Time
-
-	var err error
+type ExtensionRepos = TypedItems[ExtensionRepoContent, *ExtensionRepoContent]
 
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing title: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
+func (cc *ExtensionRepoContent) MissingField() string {
+	if cc.Name == "" {
+		return "title"
 	}
 
-	return err
-}
-
-func (c ExtensionRepo) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *ExtensionRepo) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c ExtensionRepo) GetContent() Content {
-	return &c.Content
-}
-
-func (c *ExtensionRepo) SetContent(cc Content) {
-	c.Content = *cc.(*ExtensionRepoContent)
-}
-
-func (c ExtensionRepo) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c ExtensionRepo) GetUUID() string {
-	return c.UUID
-}
-
-func (c ExtensionRepo) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *ExtensionRepo) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c ExtensionRepo) GetContentType() string {
-	return c.ContentType
-}
-
-func (c ExtensionRepo) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *ExtensionRepo) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c ExtensionRepo) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *ExtensionRepo) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c ExtensionRepo) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *ExtensionRepo) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c ExtensionRepo) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *ExtensionRepo) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *ExtensionRepo) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c ExtensionRepo) GetContentSize() int {
-	return c.ContentSize
-}
-
-func (c *ExtensionRepo) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *ExtensionRepoContent) AssociateItems(newItems []string) {
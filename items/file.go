@@ -2,35 +2,11 @@ package items
 
 import (
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parseFile(i DecryptedItem) Item {
-	c := File{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		c.Content = *content.(*FileContent)
-	}
-
-	return &c
-}
-
 type FileContent struct {
 	// Core file attributes from official Standard Notes
 	RemoteIdentifier     string         `json:"remoteIdentifier"`
@@ -50,56 +26,32 @@ type FileContent struct {
 	DissociatedItemIds   []string       `json:"disassociatedItemIds,omitempty"`
 	AssociatedItemIds    []string       `json:"associatedItemIds,omitempty"`
 	Active               interface{}    `json:"active,omitempty"`
+	Unknown              UnknownFields  `json:"-"`
 }
 
-type File struct {
-	ItemCommon
-	Content FileContent
-}
+func (cc *FileContent) UnmarshalJSON(data []byte) (err error) {
+	type alias FileContent
 
-func (c File) IsDefault() bool {
-	return false
-}
-
-func (i Items) File() (c Files) {
-	for _, x := range i {
-		if slices.Contains([]string{"File", common.SNItemTypeFile}, x.GetContentType()) {
-			component := x.(*File)
-			c = append(c, *component)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *Files) DeDupe() {
-	var encountered []string
-
-	var deDuped Files
+func (cc FileContent) MarshalJSON() ([]byte, error) {
+	type alias FileContent
 
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-		encountered = append(encountered, i.UUID)
-	}
+type File = TypedItem[FileContent, *FileContent]
 
-	*c = deDuped
+func (i Items) File() (c Files) {
+	return ItemsOfType[FileContent](i)
 }
 
 // NewFile returns an Item of type File without content.
 func NewFile() File {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c File
-
-	c.ContentType = common.SNItemTypeFile
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[FileContent](common.SNItemTypeFile)
 }
 
 // NewTagContent returns an empty Tag content instance.
@@ -110,120 +62,14 @@ func NewFileContent() *FileContent {
 	return c
 }
 
-type Files []File
-
-func (c Files) Validate() error {
-	var updatedTime time.Time
-
-	var err error
+type Files = TypedItems[FileContent, *FileContent]
 
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing title: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
+func (cc *FileContent) MissingField() string {
+	if cc.Name == "" {
+		return "title"
 	}
 
-	return err
-}
-
-func (c File) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *File) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c File) GetContent() Content {
-	return &c.Content
-}
-
-func (c *File) SetContent(cc Content) {
-	c.Content = *cc.(*FileContent)
-}
-
-func (c File) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c File) GetUUID() string {
-	return c.UUID
-}
-
-func (c File) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *File) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c File) GetContentType() string {
-	return c.ContentType
-}
-
-func (c File) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *File) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c File) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *File) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c File) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *File) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c File) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *File) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *File) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c File) GetContentSize() int {
-	return c.ContentSize
-}
-
-func (c *File) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *FileContent) AssociateItems(newItems []string) {
@@ -3,7 +3,6 @@ package items
 import (
 	"encoding/json"
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
@@ -19,79 +18,32 @@ type FileSafeCredentialsContent struct {
 	DissociatedItemIds []string        `json:"disassociatedItemIds"`
 	AssociatedItemIds  []string        `json:"associatedItemIds"`
 	Active             interface{}     `json:"active"`
+	Unknown            UnknownFields   `json:"-"`
 }
 
-func parseFileSafeCredentials(i DecryptedItem) Item {
-	c := FileSafeCredentials{}
+func (cc *FileSafeCredentialsContent) UnmarshalJSON(data []byte) (err error) {
+	type alias FileSafeCredentialsContent
 
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-		c.Content = *content.(*FileSafeCredentialsContent)
-	}
-
-	return &c
-}
-
-type FileSafeCredentials struct {
-	ItemCommon
-	Content FileSafeCredentialsContent
-}
-
-func (c FileSafeCredentials) IsDefault() bool {
-	return false
+	return err
 }
 
-func (i Items) FileSafeCredentials() (c FileSafeCredentialsList) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeFileSafeCredentials {
-			component := x.(*FileSafeCredentials)
-			c = append(c, *component)
-		}
-	}
+func (cc FileSafeCredentialsContent) MarshalJSON() ([]byte, error) {
+	type alias FileSafeCredentialsContent
 
-	return c
+	return encodeWithUnknown(alias(cc), cc.Unknown)
 }
 
-func (c *FileSafeCredentialsList) DeDupe() {
-	var encountered []string
-
-	var deDuped FileSafeCredentialsList
-
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
-
-		encountered = append(encountered, i.UUID)
-	}
+type FileSafeCredentials = TypedItem[FileSafeCredentialsContent, *FileSafeCredentialsContent]
 
-	*c = deDuped
+func (i Items) FileSafeCredentials() (c FileSafeCredentialsList) {
+	return ItemsOfType[FileSafeCredentialsContent](i)
 }
 
 // NewFileSafeCredentials returns an Item of type FileSafeCredentials without content.
 func NewFileSafeCredentials() FileSafeCredentials {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c FileSafeCredentials
-
-	c.ContentType = common.SNItemTypeFileSafeCredentials
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[FileSafeCredentialsContent](common.SNItemTypeFileSafeCredentials)
 }
 
 // NewTagContent returns an empty Tag content instance.
@@ -102,120 +54,14 @@ func NewFileSafeCredentialsContent() *FileSafeCredentialsContent {
 	return c
 }
 
-type FileSafeCredentialsList []FileSafeCredentials
-
-func (c FileSafeCredentialsList) Validate() error {
-	var updatedTime time.Time
+type FileSafeCredentialsList = TypedItems[FileSafeCredentialsContent, *FileSafeCredentialsContent]
 
-	var err error
-
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing title: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
+func (cc *FileSafeCredentialsContent) MissingField() string {
+	if cc.Name == "" {
+		return "title"
 	}
 
-	return err
-}
-
-func (c FileSafeCredentials) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *FileSafeCredentials) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c FileSafeCredentials) GetContent() Content {
-	return &c.Content
-}
-
-func (c *FileSafeCredentials) SetContent(cc Content) {
-	c.Content = *cc.(*FileSafeCredentialsContent)
-}
-
-func (c FileSafeCredentials) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c FileSafeCredentials) GetUUID() string {
-	return c.UUID
-}
-
-func (c FileSafeCredentials) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *FileSafeCredentials) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c FileSafeCredentials) GetContentType() string {
-	return c.ContentType
-}
-
-func (c FileSafeCredentials) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *FileSafeCredentials) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c FileSafeCredentials) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *FileSafeCredentials) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c FileSafeCredentials) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *FileSafeCredentials) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c FileSafeCredentials) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *FileSafeCredentials) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *FileSafeCredentials) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c FileSafeCredentials) GetContentSize() int {
-	return c.ContentSize
-}
-
-func (c *FileSafeCredentials) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *FileSafeCredentialsContent) AssociateItems(newItems []string) {
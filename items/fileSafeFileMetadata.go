@@ -3,7 +3,6 @@ package items
 import (
 	"encoding/json"
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
@@ -17,79 +16,32 @@ type FileSafeFileMetaDataContent struct {
 	DissociatedItemIds []string        `json:"disassociatedItemIds"`
 	AssociatedItemIds  []string        `json:"associatedItemIds"`
 	Active             interface{}     `json:"active"`
+	Unknown            UnknownFields   `json:"-"`
 }
 
-func parseFileSafeFileMetadata(i DecryptedItem) Item {
-	c := FileSafeFileMetaData{}
+func (cc *FileSafeFileMetaDataContent) UnmarshalJSON(data []byte) (err error) {
+	type alias FileSafeFileMetaDataContent
 
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-		c.Content = *content.(*FileSafeFileMetaDataContent)
-	}
-
-	return &c
-}
-
-type FileSafeFileMetaData struct {
-	ItemCommon
-	Content FileSafeFileMetaDataContent
-}
-
-func (c FileSafeFileMetaData) IsDefault() bool {
-	return false
+	return err
 }
 
-func (i Items) FileSafeFileMetaData() (c FileSafeFileMetaDataList) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeFileSafeFileMetaData {
-			component := x.(*FileSafeFileMetaData)
-			c = append(c, *component)
-		}
-	}
+func (cc FileSafeFileMetaDataContent) MarshalJSON() ([]byte, error) {
+	type alias FileSafeFileMetaDataContent
 
-	return c
+	return encodeWithUnknown(alias(cc), cc.Unknown)
 }
 
-func (c *FileSafeFileMetaDataList) DeDupe() {
-	var encountered []string
-
-	var deDuped FileSafeFileMetaDataList
-
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
-
-		encountered = append(encountered, i.UUID)
-	}
+type FileSafeFileMetaData = TypedItem[FileSafeFileMetaDataContent, *FileSafeFileMetaDataContent]
 
-	*c = deDuped
+func (i Items) FileSafeFileMetaData() (c FileSafeFileMetaDataList) {
+	return ItemsOfType[FileSafeFileMetaDataContent](i)
 }
 
 // NewFileSafeFileMetaData returns an Item of type FileSafeFileMetaData without content.
 func NewFileSafeFileMetaData() FileSafeFileMetaData {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c FileSafeFileMetaData
-
-	c.ContentType = common.SNItemTypeFileSafeFileMetaData
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[FileSafeFileMetaDataContent](common.SNItemTypeFileSafeFileMetaData)
 }
 
 // NewTagContent returns an empty Tag content instance.
@@ -100,120 +52,14 @@ func NewFileSafeFileMetaDataContent() *FileSafeFileMetaDataContent {
 	return c
 }
 
-type FileSafeFileMetaDataList []FileSafeFileMetaData
-
-func (c FileSafeFileMetaDataList) Validate() error {
-	var updatedTime time.Time
+type FileSafeFileMetaDataList = TypedItems[FileSafeFileMetaDataContent, *FileSafeFileMetaDataContent]
 
-	var err error
-
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing title: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
+func (cc *FileSafeFileMetaDataContent) MissingField() string {
+	if cc.Name == "" {
+		return "title"
 	}
 
-	return err
-}
-
-func (c FileSafeFileMetaData) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *FileSafeFileMetaData) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c FileSafeFileMetaData) GetContent() Content {
-	return &c.Content
-}
-
-func (c *FileSafeFileMetaData) SetContent(cc Content) {
-	c.Content = *cc.(*FileSafeFileMetaDataContent)
-}
-
-func (c FileSafeFileMetaData) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c FileSafeFileMetaData) GetUUID() string {
-	return c.UUID
-}
-
-func (c FileSafeFileMetaData) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *FileSafeFileMetaData) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c FileSafeFileMetaData) GetContentType() string {
-	return c.ContentType
-}
-
-func (c FileSafeFileMetaData) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *FileSafeFileMetaData) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c FileSafeFileMetaData) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *FileSafeFileMetaData) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c FileSafeFileMetaData) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *FileSafeFileMetaData) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c FileSafeFileMetaData) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *FileSafeFileMetaData) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *FileSafeFileMetaData) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c FileSafeFileMetaData) GetContentSize() int {
-	return c.ContentSize
-}
-
-func (c *FileSafeFileMetaData) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *FileSafeFileMetaDataContent) AssociateItems(newItems []string) {
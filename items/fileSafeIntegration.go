@@ -2,35 +2,11 @@ package items
 
 import (
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parseFileSafeIntegration(i DecryptedItem) Item {
-	c := FileSafeIntegration{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		c.Content = *content.(*FileSafeIntegrationContent)
-	}
-
-	return &c
-}
-
 type FileSafeIntegrationContent struct {
 	Source                string         `json:"source"`
 	Authorization         string         `json:"authorization"`
@@ -43,56 +19,32 @@ type FileSafeIntegrationContent struct {
 	DissociatedItemIds    []string       `json:"disassociatedItemIds"`
 	AssociatedItemIds     []string       `json:"associatedItemIds"`
 	Active                interface{}    `json:"active"`
+	Unknown               UnknownFields  `json:"-"`
 }
 
-type FileSafeIntegration struct {
-	ItemCommon
-	Content FileSafeIntegrationContent
-}
+func (cc *FileSafeIntegrationContent) UnmarshalJSON(data []byte) (err error) {
+	type alias FileSafeIntegrationContent
 
-func (c FileSafeIntegration) IsDefault() bool {
-	return false
-}
-
-func (i Items) FileSafeIntegration() (c FileSafeIntegrationList) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeFileSafeIntegration {
-			component := x.(*FileSafeIntegration)
-			c = append(c, *component)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *FileSafeIntegrationList) DeDupe() {
-	var encountered []string
-
-	var deDuped FileSafeIntegrationList
+func (cc FileSafeIntegrationContent) MarshalJSON() ([]byte, error) {
+	type alias FileSafeIntegrationContent
 
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-		encountered = append(encountered, i.UUID)
-	}
+type FileSafeIntegration = TypedItem[FileSafeIntegrationContent, *FileSafeIntegrationContent]
 
-	*c = deDuped
+func (i Items) FileSafeIntegration() (c FileSafeIntegrationList) {
+	return ItemsOfType[FileSafeIntegrationContent](i)
 }
 
 // NewFileSafeIntegration returns an Item of type FileSafeIntegration without content.
 func NewFileSafeIntegration() FileSafeIntegration {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c FileSafeIntegration
-
-	c.ContentType = common.SNItemTypeFileSafeIntegration
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[FileSafeIntegrationContent](common.SNItemTypeFileSafeIntegration)
 }
 
 // NewTagContent returns an empty Tag content instance.
@@ -103,120 +55,14 @@ func NewFileSafeIntegrationContent() *FileSafeIntegrationContent {
 	return c
 }
 
-type FileSafeIntegrationList []FileSafeIntegration
-
-func (c FileSafeIntegrationList) Validate() error {
-	var updatedTime time.Time
-
-	var err error
+type FileSafeIntegrationList = TypedItems[FileSafeIntegrationContent, *FileSafeIntegrationContent]
 
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing title: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
+func (cc *FileSafeIntegrationContent) MissingField() string {
+	if cc.Name == "" {
+		return "title"
 	}
 
-	return err
-}
-
-func (c FileSafeIntegration) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *FileSafeIntegration) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c FileSafeIntegration) GetContent() Content {
-	return &c.Content
-}
-
-func (c *FileSafeIntegration) SetContent(cc Content) {
-	c.Content = *cc.(*FileSafeIntegrationContent)
-}
-
-func (c FileSafeIntegration) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c FileSafeIntegration) GetUUID() string {
-	return c.UUID
-}
-
-func (c FileSafeIntegration) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *FileSafeIntegration) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c FileSafeIntegration) GetContentType() string {
-	return c.ContentType
-}
-
-func (c FileSafeIntegration) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *FileSafeIntegration) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c FileSafeIntegration) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *FileSafeIntegration) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c FileSafeIntegration) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *FileSafeIntegration) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c FileSafeIntegration) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *FileSafeIntegration) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *FileSafeIntegration) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c FileSafeIntegration) GetContentSize() int {
-	return c.ContentSize
-}
-
-func (c *FileSafeIntegration) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *FileSafeIntegrationContent) AssociateItems(newItems []string) {
@@ -12,6 +12,8 @@ import (
 type ItemFilters struct {
 	MatchAny bool
 	Filters  []Filter
+	// Query, if set, is applied before Filters and is parsed with ParseQuery
+	Query *Query
 }
 
 type Filter struct {
@@ -39,6 +41,14 @@ func (f *ItemFilters) CompileRegexFilters() error {
 }
 
 func (i *Items) Filter(f ItemFilters) {
+	if f.Query != nil {
+		i.FilterQuery(f.Query)
+
+		if len(f.Filters) == 0 {
+			return
+		}
+	}
+
 	var filtered Items
 
 	var tags Tags
@@ -323,6 +333,48 @@ func applyNoteTrashedFilter(f Filter, i Note, matchAny bool) (result, matchedAll
 	return result, matchedAll, done
 }
 
+// applyNoteFlagFilter matches a note attribute against a boolean value, with an empty value matching true.
+func applyNoteFlagFilter(f Filter, i Note, matchAny bool) (result, matchedAll, done bool) {
+	var isSet bool
+
+	switch strings.ToLower(f.Key) {
+	case "pinned":
+		isSet = i.IsPinned()
+	case "archived":
+		isSet = i.IsArchived()
+	case "locked":
+		isSet = i.IsLocked()
+	case "protected":
+		isSet = i.IsProtected()
+	case "starred":
+		isSet = i.IsStarred()
+	}
+
+	want := true
+	if f.Value != "" {
+		want, _ = strconv.ParseBool(f.Value)
+	}
+
+	matched := isSet == want
+	if f.Comparison == "!=" {
+		matched = !matched
+	}
+
+	if matched {
+		if matchAny {
+			return true, true, true
+		}
+
+		return result, true, false
+	}
+
+	if !matchAny {
+		return false, false, true
+	}
+
+	return result, false, false
+}
+
 func applyNoteTagTitleFilter(f Filter, i Note, tags Tags, matchAny bool) (result, matchedAll, done bool) {
 	var matchesTag bool
 
@@ -517,6 +569,11 @@ func applyNoteFilters(item Note, itemFilters ItemFilters, tags Tags) bool {
 				}
 				matchedAll = false
 			}
+		case "pinned", "archived", "locked", "protected", "starred": // note attributes
+			result, matchedAll, done = applyNoteFlagFilter(filter, item, itemFilters.MatchAny)
+			if done {
+				return result
+			}
 		case "deleted": // Deleted
 			isDel, _ := strconv.ParseBool(filter.Value)
 			if item.Deleted == isDel {
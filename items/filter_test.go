@@ -1,6 +1,7 @@
 package items
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/jonhadfield/gosn-v2/common"
@@ -591,3 +592,42 @@ func TestRegexFilterPerformanceWithPreCompilation(t *testing.T) {
 		require.True(t, res, "filter should match on iteration %d", i)
 	}
 }
+
+func TestFilterNoteByAttributes(t *testing.T) {
+	pinnedNote := createNote("Pinned", "example", "")
+	pinnedNote.SetPinned(true)
+
+	archivedNote := createNote("Archived", "example", "")
+	archivedNote.SetArchived(true)
+	archivedNote.SetLocked(true)
+
+	protectedNote := createNote("Protected", "example", "")
+	protectedNote.SetProtected(true)
+
+	for _, tc := range []struct {
+		key, comparison, value string
+		matches                []*Note
+	}{
+		{"Pinned", "==", "", []*Note{pinnedNote}},
+		{"pinned", "==", "true", []*Note{pinnedNote}},
+		{"Pinned", "==", "false", []*Note{archivedNote, protectedNote}},
+		{"Pinned", "!=", "true", []*Note{archivedNote, protectedNote}},
+		{"Archived", "==", "true", []*Note{archivedNote}},
+		{"Locked", "==", "true", []*Note{archivedNote}},
+		{"Protected", "==", "true", []*Note{protectedNote}},
+	} {
+		itemFilters := ItemFilters{
+			Filters: []Filter{{
+				Type:       common.SNItemTypeNote,
+				Key:        tc.key,
+				Comparison: tc.comparison,
+				Value:      tc.value,
+			}},
+		}
+
+		for _, n := range []*Note{pinnedNote, archivedNote, protectedNote} {
+			require.Equal(t, slices.Contains(tc.matches, n), applyNoteFilters(*n, itemFilters, nil),
+				"%s %s %q: %s", tc.key, tc.comparison, tc.value, n.Content.Title)
+		}
+	}
+}
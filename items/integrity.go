@@ -0,0 +1,312 @@
+package items
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/common"
+)
+
+// IntegrityIssueType categorises a problem found with the references between items.
+type IntegrityIssueType string
+
+const (
+	// IntegrityDanglingReference is a reference to an item that is missing or deleted.
+	IntegrityDanglingReference IntegrityIssueType = "dangling_reference"
+	// IntegrityWrongContentType is a reference whose content type does not match the item referenced,
+	// or a tag whose parent is not a tag.
+	IntegrityWrongContentType IntegrityIssueType = "wrong_content_type"
+	// IntegrityTagCycle is a tag nested within itself.
+	IntegrityTagCycle IntegrityIssueType = "tag_cycle"
+	// IntegrityDeletedTagReference is a deleted tag that still references notes.
+	IntegrityDeletedTagReference IntegrityIssueType = "deleted_tag_reference"
+	// IntegrityComponentMissingItem is a component referencing, or associated with, an item that is missing or deleted.
+	IntegrityComponentMissingItem IntegrityIssueType = "component_missing_item"
+	// IntegrityUnreferencedFile is a file that is not linked to any note. It is reported but not repaired.
+	IntegrityUnreferencedFile IntegrityIssueType = "unreferenced_file"
+)
+
+// IntegrityIssue is a problem found with the references of an item.
+type IntegrityIssue struct {
+	Type IntegrityIssueType
+	// UUID and ContentType identify the item with the problem.
+	UUID        string
+	ContentType string
+	// Reference is the reference with the problem, if any.
+	Reference ItemReference
+	// Cycle lists the UUIDs of the tags in a cycle, starting with the tag whose parent is removed by Repair.
+	Cycle []string
+	// Missing is true if the item referenced is not in the items checked, rather than deleted. As it may
+	// exist but not have been passed, the reference is reported but not repaired.
+	Missing bool
+}
+
+// Repairable returns true if Repair fixes the issue.
+func (i IntegrityIssue) Repairable() bool {
+	return i.Type != IntegrityUnreferencedFile && !i.Missing
+}
+
+func (i IntegrityIssue) String() string {
+	switch i.Type {
+	case IntegrityDanglingReference:
+		return fmt.Sprintf("%s %s references %s %s %s", i.ContentType, i.UUID, i.state(), i.Reference.ContentType, i.Reference.UUID)
+	case IntegrityWrongContentType:
+		return fmt.Sprintf("%s %s references %s as %s %s", i.ContentType, i.UUID, i.Reference.UUID, i.Reference.ReferenceType, i.Reference.ContentType)
+	case IntegrityTagCycle:
+		return fmt.Sprintf("tag %s is nested within itself: %s", i.UUID, strings.Join(i.Cycle, " -> "))
+	case IntegrityDeletedTagReference:
+		return fmt.Sprintf("deleted tag %s references %s %s", i.UUID, i.Reference.ContentType, i.Reference.UUID)
+	case IntegrityComponentMissingItem:
+		return fmt.Sprintf("component %s references %s item %s", i.UUID, i.state(), i.Reference.UUID)
+	case IntegrityUnreferencedFile:
+		return fmt.Sprintf("file %s is not linked to any note", i.UUID)
+	}
+
+	return fmt.Sprintf("%s: %s %s", i.Type, i.ContentType, i.UUID)
+}
+
+func (i IntegrityIssue) state() string {
+	if i.Missing {
+		return "missing"
+	}
+
+	return "deleted"
+}
+
+// IntegrityReport lists the problems found by CheckIntegrity.
+type IntegrityReport struct {
+	Issues []IntegrityIssue
+}
+
+// OK returns true if no problems were found.
+func (r IntegrityReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// CheckIntegrity checks that the references of the non-deleted items point to existing, non-deleted items of
+// the type referenced, that tags are not nested within themselves, that deleted tags no longer reference notes,
+// and that each file is linked to a note, either by the note referencing the file or the file referencing the note.
+// The items must include every item, such as all of those in the cache, as references to items that are not
+// included are reported as missing.
+func CheckIntegrity(i Items) IntegrityReport {
+	_, r := checkIntegrity(i, false)
+
+	return r
+}
+
+// Repair fixes the problems found by CheckIntegrity, updating the items in place, and returns the items
+// changed, with their update times set, ready to be synced. References to deleted items are removed, content
+// types corrected and cycles broken by moving a tag to the root. As with CheckIntegrity, the items must include
+// every item: references to items that are not included, and unreferenced files, are reported but left as they are.
+func Repair(i Items) (Items, IntegrityReport) {
+	return checkIntegrity(i, true)
+}
+
+func checkIntegrity(i Items, repair bool) (Items, IntegrityReport) {
+	var r IntegrityReport
+
+	byUUID := make(map[string]Item, len(i))
+
+	for _, x := range i {
+		if x != nil {
+			byUUID[x.GetUUID()] = x
+		}
+	}
+
+	existing := func(uuid string) (Item, bool) {
+		x, ok := byUUID[uuid]
+
+		return x, ok && !x.IsDeleted()
+	}
+
+	var changed Items
+
+	setChanged := func(x Item) {
+		if !slices.Contains(changed, x) {
+			changed = append(changed, x)
+		}
+	}
+
+	// files referenced by non-deleted notes
+	linkedFiles := make(map[string]bool)
+
+	for _, x := range i {
+		if x == nil {
+			continue
+		}
+
+		if x.IsDeleted() {
+			if t, ok := x.(*Tag); ok && checkDeletedTag(t, &r) && repair {
+				t.Content.SetReferences(nil)
+				setChanged(t)
+			}
+
+			continue
+		}
+
+		c := x.GetContent()
+		refs := c.References()
+
+		var kept ItemReferences
+
+		for _, ref := range refs {
+			issue := IntegrityIssue{UUID: x.GetUUID(), ContentType: x.GetContentType(), Reference: ref}
+
+			target, ok := existing(ref.UUID)
+
+			switch {
+			case !ok:
+				issue.Type = IntegrityDanglingReference
+				if x.GetContentType() == common.SNItemTypeComponent {
+					issue.Type = IntegrityComponentMissingItem
+				}
+
+				// only references to items known to be deleted are removed
+				if _, found := byUUID[ref.UUID]; !found {
+					issue.Missing = true
+					kept = append(kept, ref)
+				}
+			case ref.ReferenceType == TagToParentTagReferenceType && target.GetContentType() != common.SNItemTypeTag:
+				// the parent cannot be corrected, so the tag is moved to the root
+				issue.Type = IntegrityWrongContentType
+			case ref.ContentType != target.GetContentType():
+				issue.Type = IntegrityWrongContentType
+				ref.ContentType = target.GetContentType()
+				kept = append(kept, ref)
+			default:
+				kept = append(kept, ref)
+
+				if x.GetContentType() == common.SNItemTypeNote && ref.ContentType == common.SNItemTypeFile {
+					linkedFiles[ref.UUID] = true
+				}
+
+				continue
+			}
+
+			r.Issues = append(r.Issues, issue)
+		}
+
+		if repair && !slices.Equal(refs, kept) {
+			c.SetReferences(kept)
+			x.SetContent(c)
+			setChanged(x)
+		}
+
+		if cp, ok := x.(*Component); ok && checkComponentItems(cp, byUUID, &r, repair) {
+			setChanged(cp)
+		}
+	}
+
+	for _, cycle := range NewTagTree(i.Tags()).Cycles {
+		issue := IntegrityIssue{Type: IntegrityTagCycle, UUID: cycle[0].Tag.UUID, ContentType: common.SNItemTypeTag}
+		for _, n := range cycle {
+			issue.Cycle = append(issue.Cycle, n.Tag.UUID)
+		}
+
+		r.Issues = append(r.Issues, issue)
+
+		if t, ok := byUUID[issue.UUID].(*Tag); ok && repair {
+			t.Content.SetReferences(slices.DeleteFunc(slices.Clone(t.Content.ItemReferences), func(ref ItemReference) bool {
+				return ref.ReferenceType == TagToParentTagReferenceType
+			}))
+			t.Content.SetParentId("")
+			setChanged(t)
+		}
+	}
+
+	for _, x := range i {
+		f, ok := x.(*File)
+		if !ok || f.Deleted || linkedFiles[f.UUID] {
+			continue
+		}
+
+		if !slices.ContainsFunc(f.Content.ItemReferences, func(ref ItemReference) bool {
+			n, ok := existing(ref.UUID)
+
+			return ok && n.GetContentType() == common.SNItemTypeNote
+		}) {
+			r.Issues = append(r.Issues, IntegrityIssue{Type: IntegrityUnreferencedFile, UUID: f.UUID, ContentType: f.ContentType})
+		}
+	}
+
+	now := time.Now().UTC()
+
+	for _, x := range changed {
+		c := x.GetContent()
+		if u, ok := c.(interface{ SetUpdateTime(time.Time) }); ok {
+			u.SetUpdateTime(now)
+			x.SetContent(c)
+		}
+	}
+
+	return changed, r
+}
+
+// checkDeletedTag adds an issue for each note still referenced by the deleted tag, returning true if there are any.
+func checkDeletedTag(t *Tag, r *IntegrityReport) bool {
+	found := false
+
+	for _, ref := range t.Content.ItemReferences {
+		if ref.ReferenceType == TagToParentTagReferenceType {
+			continue
+		}
+
+		r.Issues = append(r.Issues, IntegrityIssue{
+			Type:        IntegrityDeletedTagReference,
+			UUID:        t.UUID,
+			ContentType: t.ContentType,
+			Reference:   ref,
+		})
+
+		found = true
+	}
+
+	return found
+}
+
+// checkComponentItems adds an issue for each missing or deleted item the component is associated or dissociated
+// with, removing those that are deleted if repairing, and returns true if the component was changed.
+func checkComponentItems(c *Component, byUUID map[string]Item, r *IntegrityReport, repair bool) bool {
+	changed := false
+
+	check := func(ids []string) []string {
+		var kept []string
+
+		for _, id := range ids {
+			x, found := byUUID[id]
+			if found && !x.IsDeleted() {
+				kept = append(kept, id)
+
+				continue
+			}
+
+			r.Issues = append(r.Issues, IntegrityIssue{
+				Type:        IntegrityComponentMissingItem,
+				UUID:        c.UUID,
+				ContentType: c.ContentType,
+				Reference:   ItemReference{UUID: id},
+				Missing:     !found,
+			})
+
+			// only items known to be deleted are removed
+			if !found {
+				kept = append(kept, id)
+			}
+		}
+
+		if repair && len(kept) != len(ids) {
+			changed = true
+
+			return kept
+		}
+
+		return ids
+	}
+
+	c.Content.AssociatedItemIds = check(c.Content.AssociatedItemIds)
+	c.Content.DissociatedItemIds = check(c.Content.DissociatedItemIds)
+
+	return changed
+}
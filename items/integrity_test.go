@@ -0,0 +1,148 @@
+package items
+
+import (
+	"testing"
+
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/stretchr/testify/require"
+)
+
+func integrityTestItems(t *testing.T) Items {
+	t.Helper()
+
+	note := createNote("Plan", "ship it", "n1")
+	note.Content.ItemReferences = ItemReferences{
+		{UUID: "gone", ContentType: common.SNItemTypeNote, ReferenceType: NoteToNoteReferenceType},
+		{UUID: "n3", ContentType: common.SNItemTypeNote, ReferenceType: NoteToNoteReferenceType},
+		{UUID: "n2", ContentType: common.SNItemTypeTag, ReferenceType: NoteToNoteReferenceType},
+		{UUID: "f2", ContentType: common.SNItemTypeFile},
+	}
+
+	linked := createNote("Linked", "", "n2")
+
+	deletedNote := createNote("Deleted", "", "n3")
+	deletedNote.Deleted = true
+
+	parent := func(uuid string) ItemReferences {
+		return ItemReferences{{UUID: uuid, ContentType: common.SNItemTypeTag, ReferenceType: TagToParentTagReferenceType}}
+	}
+
+	a, err := createTag("a", "t1", append(parent("t2"), ItemReference{UUID: "n1", ContentType: common.SNItemTypeNote}))
+	require.NoError(t, err)
+
+	b, err := createTag("b", "t2", parent("t1"))
+	require.NoError(t, err)
+
+	notTagParent, err := createTag("c", "t3", ItemReferences{{UUID: "n2", ContentType: common.SNItemTypeNote, ReferenceType: TagToParentTagReferenceType}})
+	require.NoError(t, err)
+
+	deletedTag, err := createTag("d", "t4", ItemReferences{{UUID: "n1", ContentType: common.SNItemTypeNote}, {UUID: "n3", ContentType: common.SNItemTypeNote}})
+	require.NoError(t, err)
+
+	deletedTag.Deleted = true
+
+	component := &Component{
+		ItemCommon: ItemCommon{UUID: "c1", ContentType: common.SNItemTypeComponent},
+		Content: ComponentContent{
+			ItemReferences:    ItemReferences{{UUID: "gone", ContentType: common.SNItemTypeNote}},
+			AssociatedItemIds: []string{"n1", "n3"},
+		},
+	}
+
+	unreferenced := &File{ItemCommon: ItemCommon{UUID: "f1", ContentType: common.SNItemTypeFile}}
+	referenced := &File{ItemCommon: ItemCommon{UUID: "f2", ContentType: common.SNItemTypeFile}}
+	referencing := &File{
+		ItemCommon: ItemCommon{UUID: "f3", ContentType: common.SNItemTypeFile},
+		Content:    FileContent{ItemReferences: ItemReferences{{UUID: "n2", ContentType: common.SNItemTypeNote, ReferenceType: "FileToNote"}}},
+	}
+
+	return Items{note, linked, deletedNote, a, b, notTagParent, deletedTag, component, unreferenced, referenced, referencing}
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	i := integrityTestItems(t)
+
+	r := CheckIntegrity(i)
+	require.False(t, r.OK())
+
+	var found []string
+	for _, issue := range r.Issues {
+		found = append(found, string(issue.Type)+" "+issue.UUID+" "+issue.Reference.UUID)
+	}
+
+	require.Equal(t, []string{
+		"dangling_reference n1 gone",
+		"dangling_reference n1 n3",
+		"wrong_content_type n1 n2",
+		"wrong_content_type t3 n2",
+		"deleted_tag_reference t4 n1",
+		"deleted_tag_reference t4 n3",
+		"component_missing_item c1 gone",
+		"component_missing_item c1 n3",
+		"tag_cycle t1 ",
+		"unreferenced_file f1 ",
+	}, found)
+
+	require.Equal(t, []string{"t1", "t2"}, r.Issues[8].Cycle)
+	require.Equal(t, "tag t1 is nested within itself: t1 -> t2", r.Issues[8].String())
+	require.False(t, r.Issues[9].Repairable())
+
+	// references to items that were not checked may not be dangling, so are not repaired
+	require.True(t, r.Issues[0].Missing)
+	require.False(t, r.Issues[0].Repairable())
+	require.Equal(t, "Note n1 references missing Note gone", r.Issues[0].String())
+	require.True(t, r.Issues[6].Missing)
+	require.False(t, r.Issues[1].Missing)
+	require.True(t, r.Issues[1].Repairable())
+	require.Equal(t, "Note n1 references deleted Note n3", r.Issues[1].String())
+	require.False(t, r.Issues[7].Missing)
+
+	// checking leaves the items unchanged
+	require.Len(t, i[0].(*Note).Content.ItemReferences, 4)
+}
+
+func TestRepair(t *testing.T) {
+	i := integrityTestItems(t)
+
+	changed, r := Repair(i)
+	require.Len(t, r.Issues, 10)
+
+	var uuids []string
+	for _, x := range changed {
+		uuids = append(uuids, x.GetUUID())
+	}
+
+	require.Equal(t, []string{"n1", "t3", "t4", "c1", "t1"}, uuids)
+
+	note := i[0].(*Note)
+	require.Equal(t, ItemReferences{
+		{UUID: "gone", ContentType: common.SNItemTypeNote, ReferenceType: NoteToNoteReferenceType},
+		{UUID: "n2", ContentType: common.SNItemTypeNote, ReferenceType: NoteToNoteReferenceType},
+		{UUID: "f2", ContentType: common.SNItemTypeFile},
+	}, note.Content.ItemReferences)
+
+	_, err := note.Content.GetUpdateTime()
+	require.NoError(t, err)
+
+	// the cycle is broken by moving the first tag to the root, keeping its notes
+	require.Empty(t, TagParentUUID(*i[3].(*Tag)))
+	require.Equal(t, "n1", i[3].(*Tag).Content.ItemReferences[0].UUID)
+	require.Equal(t, "t1", TagParentUUID(*i[4].(*Tag)))
+	require.Empty(t, i[5].(*Tag).Content.ItemReferences)
+	require.Empty(t, i[6].(*Tag).Content.ItemReferences)
+
+	component := i[7].(*Component)
+	require.Equal(t, ItemReferences{{UUID: "gone", ContentType: common.SNItemTypeNote}}, component.Content.ItemReferences)
+	require.Equal(t, []string{"n1"}, component.Content.AssociatedItemIds)
+
+	// only the missing references and unreferenced file remain
+	r = CheckIntegrity(i)
+	require.Len(t, r.Issues, 3)
+
+	for _, issue := range r.Issues {
+		require.False(t, issue.Repairable())
+	}
+
+	changed, _ = Repair(i)
+	require.Empty(t, changed)
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/crypto"
 	"github.com/jonhadfield/gosn-v2/log"
 	"github.com/jonhadfield/gosn-v2/session"
 )
@@ -16,19 +17,20 @@ func DecryptItem(e EncryptedItem, s *session.Session, iks []session.SessionItems
 		return o, fmt.Errorf("cannot decrypt deleted item: %s %s", e.ContentType, e.UUID)
 	}
 
-	var key string
+	var key crypto.SecretKey
 
 	ik := GetMatchingItem(e.GetItemsKeyID(), iks)
 
 	switch {
-	case ik.ItemsKey != "":
+	case len(ik.ItemsKey) > 0:
 		key = ik.ItemsKey
 	case IsEncryptedWithMasterKey(e.ContentType):
 		key = s.MasterKey
 	default:
 		if e.ItemsKeyID == "" {
 			log.DebugPrint(s.Debug, fmt.Sprintf("decryptItems | missing ItemsKeyID for content type: %s", e.ContentType), common.MaxDebugChars)
-			err = fmt.Errorf("encountered deleted: %t item %s of type %s without ItemsKeyID",
+			err = fmt.Errorf("%w: encountered deleted: %t item %s of type %s without ItemsKeyID",
+				crypto.ErrMalformedPayload,
 				e.Deleted,
 				e.UUID,
 				e.ContentType)
@@ -37,8 +39,9 @@ func DecryptItem(e EncryptedItem, s *session.Session, iks []session.SessionItems
 		}
 
 		key = GetMatchingItem(e.ItemsKeyID, s.ItemsKeys).ItemsKey
-		if key == "" {
-			err = fmt.Errorf("deleted: %t item %s of type %s cannot be decrypted as we're missing ItemsKey %s",
+		if len(key) == 0 {
+			err = fmt.Errorf("%w: deleted: %t item %s of type %s cannot be decrypted as we're missing ItemsKey %s",
+				ErrMissingItemsKey,
 				e.Deleted,
 				e.UUID,
 				e.ContentType,
@@ -48,7 +51,7 @@ func DecryptItem(e EncryptedItem, s *session.Session, iks []session.SessionItems
 		}
 	}
 
-	content, err := e.DecryptItemOnly(key)
+	content, err := e.decryptItemOnly(s, key)
 	if err != nil {
 		return
 	}
@@ -84,7 +87,7 @@ func DecryptItem(e EncryptedItem, s *session.Session, iks []session.SessionItems
 
 // DecryptAndParseItemKeys takes the master key and a list of EncryptedItemKeys
 // and returns a list of items keys.
-func DecryptAndParseItemKeys(mk string, eiks EncryptedItems) (iks []ItemsKey, err error) {
+func DecryptAndParseItemKeys(mk crypto.SecretKey, eiks EncryptedItems) (iks []ItemsKey, err error) {
 	for x := range eiks {
 		if eiks[x].ContentType != common.SNItemTypeItemsKey {
 			continue
@@ -100,6 +103,9 @@ func DecryptAndParseItemKeys(mk string, eiks EncryptedItems) (iks []ItemsKey, er
 		var f ItemsKey
 
 		err = json.Unmarshal(content, &f)
+		// wipe the decrypted items key now it has been parsed
+		crypto.SecretKey(content).Destroy()
+
 		if err != nil {
 			return iks, fmt.Errorf("DecryptAndParseItemsKeys | failed to unmarshall %w", err)
 		}
@@ -111,7 +117,7 @@ func DecryptAndParseItemKeys(mk string, eiks EncryptedItems) (iks []ItemsKey, er
 		f.CreatedAtTimestamp = eiks[x].CreatedAtTimestamp
 		f.CreatedAt = eiks[x].CreatedAt
 
-		if f.ItemsKey == "" {
+		if len(f.ItemsKey) == 0 {
 			continue
 		}
 
@@ -142,6 +148,19 @@ type decryptResult struct {
 
 // DecryptItems decrypts multiple items, using parallel processing for large batches
 func DecryptItems(s *session.Session, ei EncryptedItems, iks []session.SessionItemsKey) (o DecryptedItems, err error) {
+	return decryptItems(s, ei, iks, nil)
+}
+
+// DecryptItemsLenient decrypts multiple items in the same way as DecryptItems, but rather than
+// failing the batch, any items that cannot be decrypted are returned in the Quarantine.
+func DecryptItemsLenient(s *session.Session, ei EncryptedItems, iks []session.SessionItemsKey) (o DecryptedItems, q Quarantine) {
+	o, _ = decryptItems(s, ei, iks, &q)
+
+	return o, q
+}
+
+// decryptItems decrypts the items, adding failures to q if provided or otherwise returning the first error.
+func decryptItems(s *session.Session, ei EncryptedItems, iks []session.SessionItemsKey, q *Quarantine) (o DecryptedItems, err error) {
 	// Count non-deleted items
 	nonDeletedCount := 0
 	for _, e := range ei {
@@ -156,15 +175,15 @@ func DecryptItems(s *session.Session, ei EncryptedItems, iks []session.SessionIt
 
 	// For small batches, sequential is faster (avoids goroutine overhead)
 	if nonDeletedCount < DecryptionBatchThreshold {
-		return decryptItemsSequential(s, ei, iks)
+		return decryptItemsSequential(s, ei, iks, q)
 	}
 
 	// Parallel decryption for large batches
-	return decryptItemsParallel(s, ei, iks, nonDeletedCount)
+	return decryptItemsParallel(s, ei, iks, nonDeletedCount, q)
 }
 
 // decryptItemsSequential processes items one at a time (for small batches)
-func decryptItemsSequential(s *session.Session, ei EncryptedItems, iks []session.SessionItemsKey) (o DecryptedItems, err error) {
+func decryptItemsSequential(s *session.Session, ei EncryptedItems, iks []session.SessionItemsKey, q *Quarantine) (o DecryptedItems, err error) {
 	for _, e := range ei {
 		if e.Deleted {
 			continue
@@ -173,7 +192,14 @@ func decryptItemsSequential(s *session.Session, ei EncryptedItems, iks []session
 		var di DecryptedItem
 		di, err = DecryptItem(e, s, iks)
 		if err != nil {
-			return
+			if q == nil {
+				return
+			}
+
+			q.Add(e, err)
+			err = nil
+
+			continue
 		}
 
 		o = append(o, di)
@@ -182,8 +208,8 @@ func decryptItemsSequential(s *session.Session, ei EncryptedItems, iks []session
 	return o, nil
 }
 
-// decryptItemsParallel processes items concurrently using a worker pool
-func decryptItemsParallel(s *session.Session, ei EncryptedItems, iks []session.SessionItemsKey, nonDeletedCount int) (o DecryptedItems, err error) {
+// decryptItemsParallel processes items concurrently using worker pool
+func decryptItemsParallel(s *session.Session, ei EncryptedItems, iks []session.SessionItemsKey, nonDeletedCount int, q *Quarantine) (o DecryptedItems, err error) {
 	// Use number of CPUs as worker count
 	workers := runtime.NumCPU()
 	if workers > nonDeletedCount {
@@ -212,13 +238,13 @@ func decryptItemsParallel(s *session.Session, ei EncryptedItems, iks []session.S
 	}
 
 	// Queue all decryption jobs
-	jobIndex := 0
+	queued := make(EncryptedItems, 0, nonDeletedCount)
 	for _, e := range ei {
 		if e.Deleted {
 			continue
 		}
-		jobs <- decryptJob{item: e, index: jobIndex}
-		jobIndex++
+		jobs <- decryptJob{item: e, index: len(queued)}
+		queued = append(queued, e)
 	}
 	close(jobs)
 
@@ -230,32 +256,67 @@ func decryptItemsParallel(s *session.Session, ei EncryptedItems, iks []session.S
 
 	// Collect results in order
 	decrypted := make([]DecryptedItem, nonDeletedCount)
+	failures := make([]error, nonDeletedCount)
 	for result := range results {
 		if result.err != nil {
-			return nil, result.err
+			if q == nil {
+				return nil, result.err
+			}
+
+			failures[result.index] = result.err
+
+			continue
 		}
 		decrypted[result.index] = result.item
 	}
 
-	return decrypted, nil
+	if q == nil {
+		return decrypted, nil
+	}
+
+	o = make(DecryptedItems, 0, nonDeletedCount)
+	for x := range decrypted {
+		if failures[x] != nil {
+			q.Add(queued[x], failures[x])
+
+			continue
+		}
+
+		o = append(o, decrypted[x])
+	}
+
+	return o, nil
 }
 
 const (
 	noteContentSchemaName = "note"
 )
 
-func (ei EncryptedItem) DecryptItemOnly(key string) (content []byte, err error) {
-	var itemKey []byte
+func (ei EncryptedItem) DecryptItemOnly(key crypto.SecretKey) (content []byte, err error) {
+	return ei.decryptItemOnly(nil, key)
+}
 
-	itemKey, err = DecryptEncryptedItemKey(ei, key)
+// decryptItemOnly decrypts the item's content using the session's crypto Provider.
+func (ei EncryptedItem) decryptItemOnly(s *session.Session, key crypto.SecretKey) (content []byte, err error) {
+	hexItemKey, err := decryptPayload(s, ei.EncItemKey, key)
 	if err != nil {
 		return
 	}
 
-	return DecryptContent(ei, string(itemKey))
+	itemKey, err := crypto.DecodeSecretKey(hexItemKey)
+	clear(hexItemKey)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid item key for %s: %w", crypto.ErrMalformedPayload, ei.UUID, err)
+	}
+
+	// the item key is only needed to decrypt this item's content
+	defer itemKey.Destroy()
+
+	return decryptPayload(s, ei.Content, itemKey)
 }
 
-func (ei *EncryptedItem) Decrypt(mk string) (ik ItemsKey, err error) {
+func (ei *EncryptedItem) Decrypt(mk crypto.SecretKey) (ik ItemsKey, err error) {
 	if ei.ContentType != common.SNItemTypeItemsKey {
 		return ik, fmt.Errorf("item passed to decrypt is of type %s, expected SN|ItemsKey", ik.ContentType)
 	}
@@ -268,6 +329,8 @@ func (ei *EncryptedItem) Decrypt(mk string) (ik ItemsKey, err error) {
 	var f ItemsKey
 
 	err = json.Unmarshal(content, &f)
+	crypto.SecretKey(content).Destroy()
+
 	if err != nil {
 		return
 	}
@@ -2,7 +2,6 @@ package items
 
 import (
 	"encoding/base64"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 
@@ -51,12 +50,19 @@ func EncryptItemsKey(ik session.SessionItemsKey, s *session.Session, new bool) (
 
 	encryptedItem.CreatedAtTimestamp = ik.CreatedAtTimestamp
 
+	p := s.CryptoProvider()
+
 	// Generate random item encryption key
-	itemEncryptionKey := crypto.GenerateItemKey(64)
+	itemEncryptionKey, err := p.GenerateItemKey()
+	if err != nil {
+		return
+	}
+
+	defer itemEncryptionKey.Destroy()
 
 	var encryptedContent string
 
-	if ik.ItemsKey == "" {
+	if len(ik.ItemsKey) == 0 {
 		panic("attempting to encrypt empty items key")
 	}
 
@@ -76,28 +82,43 @@ func EncryptItemsKey(ik session.SessionItemsKey, s *session.Session, new bool) (
 		return
 	}
 
+	// the plaintext content holds the items key
+	defer clear(mContent)
+
 	// Create the auth data that will be used to authenticate the encrypted content
 	authData := auth.GenerateAuthData(common.SNItemTypeItemsKey, ik.UUID, s.KeyParams)
 
 	b64AuthData := base64.StdEncoding.EncodeToString([]byte(authData))
 	// Generate nonce
-	nonce := hex.EncodeToString(crypto.GenerateNonce())
+	nonce, err := p.GenerateNonce()
+	if err != nil {
+		return
+	}
 
-	encryptedContent, err = crypto.EncryptString(string(mContent), itemEncryptionKey, nonce, b64AuthData, 32)
+	encryptedContent, err = p.Encrypt(mContent, itemEncryptionKey, nonce, b64AuthData)
 	if err != nil {
 		return
 	}
 
 	// Create the Encrypted Items Key content element
-	contentStr := fmt.Sprintf("004:%s:%s:%s", nonce, encryptedContent, b64AuthData)
+	contentStr := fmt.Sprintf("%s:%s:%s:%s", p.Version(), nonce, encryptedContent, b64AuthData)
 
 	encryptedItem.Content = contentStr
-	nonce = hex.EncodeToString(crypto.GenerateNonce())
+
+	nonce, err = p.GenerateNonce()
+	if err != nil {
+		return
+	}
 
 	// Encrypt the item encryption key with the master key
 	var encryptedContentKey string
-	encryptedContentKey, err = crypto.EncryptString(itemEncryptionKey, s.MasterKey, nonce, b64AuthData, 32)
-	encItemKey := fmt.Sprintf("004:%s:%s:%s", nonce, encryptedContentKey, b64AuthData)
+
+	encryptedContentKey, err = encryptItemKey(p, itemEncryptionKey, s.MasterKey, nonce, b64AuthData)
+	if err != nil {
+		return
+	}
+
+	encItemKey := fmt.Sprintf("%s:%s:%s:%s", p.Version(), nonce, encryptedContentKey, b64AuthData)
 	encryptedItem.EncItemKey = encItemKey
 
 	switch {
@@ -117,7 +138,7 @@ func EncryptItemsKey(ik session.SessionItemsKey, s *session.Session, new bool) (
 }
 
 func EncryptItem(item Item, ik session.SessionItemsKey, session *session.Session) (encryptedItem EncryptedItem, err error) {
-	var contentEncryptionKey string
+	var contentEncryptionKey crypto.SecretKey
 
 	if ik.UUID == "" {
 		panic("in EncryptItem with invalid items key (missing UUID)")
@@ -135,9 +156,14 @@ func EncryptItem(item Item, ik session.SessionItemsKey, session *session.Session
 	encryptedItem.Deleted = item.IsDeleted()
 	encryptedItem.UpdatedAtTimestamp = item.GetUpdatedAtTimestamp()
 	encryptedItem.CreatedAtTimestamp = item.GetCreatedAtTimestamp()
+	p := session.CryptoProvider()
 	// Generate Item Key
-	itemKey := crypto.GenerateItemKey(64)
-	// fmt.Printf("GENERATED ITEM KEY: %s\n", itemKey)
+	itemKey, err := p.GenerateItemKey()
+	if err != nil {
+		return
+	}
+
+	defer itemKey.Destroy()
 	// get Item Encryption Key
 	itemEncryptionKey := itemKey
 	// encrypt Item content
@@ -146,24 +172,37 @@ func EncryptItem(item Item, ik session.SessionItemsKey, session *session.Session
 	mContent, _ := json.Marshal(item.GetContent())
 	authData := auth.GenerateAuthData(item.GetContentType(), item.GetUUID(), session.KeyParams)
 	b64AuthData := base64.StdEncoding.EncodeToString([]byte(authData))
-	nonce := hex.EncodeToString(crypto.GenerateNonce())
 
-	encryptedContent, err = crypto.EncryptString(string(mContent), itemEncryptionKey, nonce, b64AuthData, 32)
+	nonce, err := p.GenerateNonce()
+	if err != nil {
+		return
+	}
+
+	encryptedContent, err = p.Encrypt(mContent, itemEncryptionKey, nonce, b64AuthData)
 	if err != nil {
 		return
 	}
 
-	content := fmt.Sprintf("004:%s:%s:%s", nonce, encryptedContent, b64AuthData)
+	content := fmt.Sprintf("%s:%s:%s:%s", p.Version(), nonce, encryptedContent, b64AuthData)
 	encryptedItem.Content = content
 	// encrypt content encryption key
 	var encryptedContentKey string
-	encryptedContentKey, err = crypto.EncryptString(itemEncryptionKey, contentEncryptionKey, nonce, b64AuthData, 32)
-	encItemKey := fmt.Sprintf("004:%s:%s:%s", nonce, encryptedContentKey, b64AuthData)
+	encryptedContentKey, err = encryptItemKey(p, itemEncryptionKey, contentEncryptionKey, nonce, b64AuthData)
+	encItemKey := fmt.Sprintf("%s:%s:%s:%s", p.Version(), nonce, encryptedContentKey, b64AuthData)
 	encryptedItem.EncItemKey = encItemKey
 
 	return encryptedItem, err
 }
 
+// encryptItemKey encrypts an item key with the items key or master key. The protocol
+// encrypts the hex encoding of the item key, which is wiped once encrypted.
+func encryptItemKey(p crypto.Provider, itemKey, key crypto.SecretKey, nonce, authenticatedData string) (string, error) {
+	hexItemKey := itemKey.AppendHex(nil)
+	defer clear(hexItemKey)
+
+	return p.Encrypt(hexItemKey, key, nonce, authenticatedData)
+}
+
 type AuthData struct {
 	Kp struct {
 		Identifier  string `json:"identifier"`
@@ -178,7 +217,7 @@ type AuthData struct {
 }
 
 func (di DecryptedItem) Encrypt(ik ItemsKey, session *session.Session) (encryptedItem EncryptedItem, err error) {
-	var contentEncryptionKey string
+	var contentEncryptionKey crypto.SecretKey
 
 	if ik.UUID == "" {
 		panic("in EncryptItem with invalid items key (missing UUID)")
@@ -195,28 +234,41 @@ func (di DecryptedItem) Encrypt(ik ItemsKey, session *session.Session) (encrypte
 	encryptedItem.Deleted = di.Deleted
 	encryptedItem.UpdatedAtTimestamp = di.UpdatedAtTimestamp
 	encryptedItem.CreatedAtTimestamp = di.CreatedAtTimestamp
+	p := session.CryptoProvider()
 	// Generate Item Key
-	itemEncryptionKey := crypto.GenerateItemKey(32)
+	itemEncryptionKey, err := p.GenerateItemKey()
+	if err != nil {
+		return
+	}
+
+	defer itemEncryptionKey.Destroy()
 
 	mContent := []byte(di.Content)
 
 	authData := auth.GenerateAuthData(di.ContentType, di.UUID, session.KeyParams)
 
 	b64AuthData := base64.StdEncoding.EncodeToString([]byte(authData))
-	nonce := hex.EncodeToString(crypto.GenerateNonce())
 
-	encryptedContent, err := crypto.EncryptString(string(mContent), itemEncryptionKey, nonce, b64AuthData, 32)
+	nonce, err := p.GenerateNonce()
+	if err != nil {
+		return
+	}
+
+	encryptedContent, err := p.Encrypt(mContent, itemEncryptionKey, nonce, b64AuthData)
 	if err != nil {
 		return
 	}
 
-	encryptedItem.Content = fmt.Sprintf("004:%s:%s:%s", nonce, encryptedContent, b64AuthData)
+	encryptedItem.Content = fmt.Sprintf("%s:%s:%s:%s", p.Version(), nonce, encryptedContent, b64AuthData)
 	// generate nonce
-	nonce = hex.EncodeToString(crypto.GenerateNonce())
+	nonce, err = p.GenerateNonce()
+	if err != nil {
+		return
+	}
 	// encrypt content encryption key
 	var encryptedContentKey string
-	encryptedContentKey, err = crypto.EncryptString(itemEncryptionKey, contentEncryptionKey, nonce, b64AuthData, 32)
-	encItemKey := fmt.Sprintf("004:%s:%s:%s", nonce, encryptedContentKey, b64AuthData)
+	encryptedContentKey, err = encryptItemKey(p, itemEncryptionKey, contentEncryptionKey, nonce, b64AuthData)
+	encItemKey := fmt.Sprintf("%s:%s:%s:%s", p.Version(), nonce, encryptedContentKey, b64AuthData)
 	encryptedItem.EncItemKey = encItemKey
 
 	return encryptedItem, err
@@ -0,0 +1,60 @@
+package items
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jonhadfield/gosn-v2/crypto"
+	"github.com/jonhadfield/gosn-v2/crypto/vectors"
+	"github.com/jonhadfield/gosn-v2/session"
+	"github.com/stretchr/testify/require"
+)
+
+type countingProvider struct {
+	crypto.XChaCha20Provider
+	version  string
+	encrypts *int
+}
+
+func (p countingProvider) Version() string {
+	return p.version
+}
+
+func (p countingProvider) Encrypt(plainText []byte, key crypto.SecretKey, nonce, authenticatedData string) (string, error) {
+	*p.encrypts++
+
+	return p.XChaCha20Provider.Encrypt(plainText, key, nonce, authenticatedData)
+}
+
+func TestEncryptDecryptItemWithProvider(t *testing.T) {
+	var encrypts int
+
+	s := &session.Session{
+		Crypto: countingProvider{
+			XChaCha20Provider: crypto.XChaCha20Provider{Rand: bytes.NewReader(bytes.Repeat([]byte{1}, 1024))},
+			version:           "test-005",
+			encrypts:          &encrypts,
+		},
+	}
+	ik := session.SessionItemsKey{
+		UUID:     "0d1f8c5a-6b1e-4c52-9a3f-3f7c2b8e9a10",
+		ItemsKey: mustSecretKey(vectors.Items004[1].Key),
+	}
+
+	note := createNote("title", "text", "")
+
+	ei, err := EncryptItem(note, ik, s)
+	require.NoError(t, err)
+	require.Equal(t, 2, encrypts)
+	require.True(t, strings.HasPrefix(ei.Content, "test-005:"))
+	require.True(t, strings.HasPrefix(ei.EncItemKey, "test-005:"))
+
+	di, err := DecryptItem(ei, s, []session.SessionItemsKey{ik})
+	require.NoError(t, err)
+	require.Contains(t, di.Content, `"title":"title"`)
+
+	// the payload version is not registered so cannot be decrypted without the session's provider
+	_, err = DecryptItem(ei, &session.Session{}, []session.SessionItemsKey{ik})
+	require.Error(t, err)
+}
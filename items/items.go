@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"os"
 	"slices"
@@ -59,7 +60,7 @@ var syncMutex sync.Mutex
 
 type EncryptedItems []EncryptedItem
 
-func (ei EncryptedItems) DecryptAndParseItemsKeys(mk string, debug bool) (o []session.SessionItemsKey, err error) {
+func (ei EncryptedItems) DecryptAndParseItemsKeys(mk crypto.SecretKey, debug bool) (o []session.SessionItemsKey, err error) {
 	log.DebugPrint(debug, fmt.Sprintf("DecryptAndParseItemsKeys | encrypted items to check: %d", len(ei)), common.MaxDebugChars)
 
 	if len(ei) == 0 {
@@ -85,11 +86,11 @@ func (ei EncryptedItems) DecryptAndParseItemsKeys(mk string, debug bool) (o []se
 	for _, e := range ei {
 		if e.ContentType == common.SNItemTypeItemsKey && !e.Deleted {
 			if e.UUID == "" {
-				panic("DecryptAndParseItemsKeys | items key has no uuid")
+				return nil, fmt.Errorf("DecryptAndParseItemsKeys | %w: items key has no uuid", crypto.ErrMalformedPayload)
 			}
 
 			if e.EncItemKey == "" {
-				panic(fmt.Sprintf("DecryptAndParseItemsKeys | items key uuid: %s has no encrypted item key", e.UUID))
+				return nil, fmt.Errorf("DecryptAndParseItemsKeys | %w: items key uuid: %s has no encrypted item key", crypto.ErrMalformedPayload, e.UUID)
 			}
 
 			eiks = append(eiks, e)
@@ -169,7 +170,7 @@ func (ei *EncryptedItems) Validate() error {
 	return err
 }
 
-func ReEncryptItem(ei EncryptedItem, decryptionItemsKey session.SessionItemsKey, newItemsKey ItemsKey, newMasterKey string, s *session.Session) (o EncryptedItem, err error) {
+func ReEncryptItem(ei EncryptedItem, decryptionItemsKey session.SessionItemsKey, newItemsKey ItemsKey, newMasterKey crypto.SecretKey, s *session.Session) (o EncryptedItem, err error) {
 	log.DebugPrint(s.Debug, fmt.Sprintf("ReEncrypt | item to re-encrypt %s %s", ei.ContentType, ei.UUID), common.MaxDebugChars)
 
 	var di DecryptedItem
@@ -184,7 +185,7 @@ func ReEncryptItem(ei EncryptedItem, decryptionItemsKey session.SessionItemsKey,
 	return di.Encrypt(newItemsKey, s)
 }
 
-func (ei EncryptedItems) ReEncrypt(s *session.Session, decryptionItemsKey session.SessionItemsKey, newItemsKey ItemsKey, newMasterKey string) (o EncryptedItems, err error) {
+func (ei EncryptedItems) ReEncrypt(s *session.Session, decryptionItemsKey session.SessionItemsKey, newItemsKey ItemsKey, newMasterKey crypto.SecretKey) (o EncryptedItems, err error) {
 	log.DebugPrint(s.Debug, fmt.Sprintf("ReEncrypt | items: %d", len(ei)), common.MaxDebugChars)
 
 	var di DecryptedItems
@@ -507,19 +508,12 @@ func makeSyncRequest(session *session.Session, reqBody []byte) (responseBody []b
 	}
 	request.Header.Set(common.HeaderContentType, common.SNAPIContentType)
 
-	// For cookie-based authentication (tokens starting with "2:"), set Cookie header manually
-	// because Go's cookie jar doesn't properly handle the Partitioned attribute
-	accessParts := strings.Split(session.AccessToken, ":")
-	isCookieBased := len(accessParts) >= 2 && accessParts[0] == "2"
+	// cookie-based sessions send both the Cookie and Authorization headers
+	session.SetAuthHeaders(request.Header)
 
-	if isCookieBased && session.AccessTokenCookie != "" {
-		// For cookie-based auth, send BOTH Cookie and Authorization headers
-		request.Header.Set("Cookie", session.AccessTokenCookie)
-		request.Header.Set("Authorization", "Bearer "+session.AccessToken)
+	if session.IsCookieBased() && session.AccessTokenCookie != "" {
 		log.DebugPrint(session.Debug, "Using cookie-based authentication (Cookie + Authorization headers)", common.MaxDebugChars)
 	} else {
-		// For header-based auth, send Authorization header only
-		request.Header.Set("Authorization", "Bearer "+session.AccessToken)
 		log.DebugPrint(session.Debug, "Using header-based authentication (Authorization header only)", common.MaxDebugChars)
 	}
 
@@ -879,9 +873,26 @@ type ItemReference struct {
 }
 
 type OrgStandardNotesSNDetail struct {
-	ClientUpdatedAt    string `json:"client_updated_at"`
-	PrefersPlainEditor bool   `json:"prefersPlainEditor"`
-	Pinned             bool   `json:"pinned"`
+	ClientUpdatedAt    string        `json:"client_updated_at"`
+	PrefersPlainEditor bool          `json:"prefersPlainEditor"`
+	Pinned             bool          `json:"pinned"`
+	Archived           bool          `json:"archived,omitempty"`
+	Locked             bool          `json:"locked,omitempty"`
+	Unknown            UnknownFields `json:"-"`
+}
+
+func (cc *OrgStandardNotesSNDetail) UnmarshalJSON(data []byte) (err error) {
+	type alias OrgStandardNotesSNDetail
+
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
+
+	return err
+}
+
+func (cc OrgStandardNotesSNDetail) MarshalJSON() ([]byte, error) {
+	type alias OrgStandardNotesSNDetail
+
+	return encodeWithUnknown(alias(cc), cc.Unknown)
 }
 
 type OrgStandardNotesSNComponentsDetail map[string]interface{}
@@ -889,11 +900,57 @@ type OrgStandardNotesSNComponentsDetail map[string]interface{}
 type AppDataContent struct {
 	OrgStandardNotesSN           OrgStandardNotesSNDetail           `json:"org.standardnotes.sn"`
 	OrgStandardNotesSNComponents OrgStandardNotesSNComponentsDetail `json:"org.standardnotes.sn.components,omitempty"`
+	Unknown                      UnknownFields                      `json:"-"`
+}
+
+func (cc *AppDataContent) UnmarshalJSON(data []byte) (err error) {
+	type alias AppDataContent
+
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
+
+	return err
+}
+
+func (cc AppDataContent) MarshalJSON() ([]byte, error) {
+	type alias AppDataContent
+
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
+
+func (cc AppDataContent) copy() AppDataContent {
+	cc.OrgStandardNotesSN.Unknown = maps.Clone(cc.OrgStandardNotesSN.Unknown)
+	cc.OrgStandardNotesSNComponents = maps.Clone(cc.OrgStandardNotesSNComponents)
+	cc.Unknown = maps.Clone(cc.Unknown)
+
+	return cc
 }
 
 type NoteAppDataContent struct {
 	OrgStandardNotesSN           OrgStandardNotesSNDetail           `json:"org.standardnotes.sn"`
 	OrgStandardNotesSNComponents OrgStandardNotesSNComponentsDetail `json:"org.standardnotes.sn.components,omitempty"`
+	Unknown                      UnknownFields                      `json:"-"`
+}
+
+func (cc *NoteAppDataContent) UnmarshalJSON(data []byte) (err error) {
+	type alias NoteAppDataContent
+
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
+
+	return err
+}
+
+func (cc NoteAppDataContent) MarshalJSON() ([]byte, error) {
+	type alias NoteAppDataContent
+
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
+
+func (cc NoteAppDataContent) copy() NoteAppDataContent {
+	cc.OrgStandardNotesSN.Unknown = maps.Clone(cc.OrgStandardNotesSN.Unknown)
+	cc.OrgStandardNotesSNComponents = maps.Clone(cc.OrgStandardNotesSNComponents)
+	cc.Unknown = maps.Clone(cc.Unknown)
+
+	return cc
 }
 
 type TagContent struct {
@@ -903,8 +960,10 @@ type TagContent struct {
 	IconString     string         `json:"iconString,omitempty"` // Icon for the tag (emoji or icon name)
 	Expanded       bool           `json:"expanded,omitempty"`   // Whether tag is expanded in UI
 	ParentId       string         `json:"parentId,omitempty"`   // Parent tag for nested tags
+	Starred        bool           `json:"starred,omitempty"`    // Whether tag is shown in favourites
 	// Missing attributes from official Standard Notes
-	Preferences interface{} `json:"preferences,omitempty"` // TagPreferences object
+	Preferences interface{}   `json:"preferences,omitempty"` // TagPreferences object
+	Unknown     UnknownFields `json:"-"`
 }
 
 func removeStringFromSlice(inSt string, inSl []string) []string {
@@ -926,336 +985,34 @@ func (i Items) UUIDs() []string {
 }
 
 func ParseItem(di DecryptedItem) (p Item, err error) {
-	var pi Item
-
-	switch di.ContentType {
-	case common.SNItemTypeItemsKey:
+	if di.ContentType == common.SNItemTypeItemsKey {
 		// TODO: To be implemented separately so we don't parse as a normal item and,
 		// most importantly, don't return as a normal Item
-	case common.SNItemTypeNote:
-		pi = parseNote(di)
-	case common.SNItemTypeTag:
-		pi = parseTag(di)
-	case common.SNItemTypeComponent:
-		pi = parseComponent(di)
-	case common.SNItemTypeTheme:
-		pi = parseTheme(di)
-	case common.SNItemTypePrivileges:
-		pi = parsePrivileges(di)
-	case common.SNItemTypeExtension:
-		pi = parseExtension(di)
-	case common.SNItemTypeSFExtension:
-		pi = parseSFExtension(di)
-	case common.SNItemTypeSFMFA:
-		pi = parseSFMFA(di)
-	case common.SNItemTypeSmartTag:
-		pi = parseSmartView(di)
-	case common.SNItemTypeFileSafeFileMetaData:
-		pi = parseFileSafeFileMetadata(di)
-	case common.SNItemTypeFileSafeIntegration:
-		pi = parseFileSafeIntegration(di)
-	case common.SNItemTypeUserPreferences:
-		pi = parseUserPreferences(di)
-	case common.SNItemTypeExtensionRepo:
-		pi = parseExtensionRepo(di)
-	case common.SNItemTypeFileSafeCredentials:
-		pi = parseFileSafeCredentials(di)
-	case common.SNItemTypeFile:
-		pi = parseFile(di)
-	case common.SNItemTypeTrustedContact:
-		pi = parseTrustedContact(di)
-	case common.SNItemTypeVaultListing:
-		pi = parseVaultListing(di)
-	case common.SNItemTypeKeySystemRootKey:
-		pi = parseKeySystemRootKey(di)
-	case common.SNItemTypeKeySystemItemsKey:
-		pi = parseKeySystemItemsKey(di)
-	default:
-		return nil, fmt.Errorf("unhandled type1 '%s' %s", di.ContentType, di.Content)
+		return nil, nil
 	}
 
-	return pi, err
+	return getContentModel(di.ContentType).parse(di)
 }
 
 func (di *DecryptedItems) Parse() (p Items, err error) {
 	for _, i := range *di {
-		var pi Item
-
-		switch i.ContentType {
-		case common.SNItemTypeItemsKey:
+		if i.ContentType == common.SNItemTypeItemsKey {
 			// TODO: To be implemented separately so we don't parse as a normal item and,
 			// most importantly, don't return as a normal Item
 			continue
-		case common.SNItemTypeNote:
-			pi = parseNote(i)
-		case common.SNItemTypeTag:
-			pi = parseTag(i)
-		case common.SNItemTypeComponent:
-			pi = parseComponent(i)
-		case common.SNItemTypeTheme:
-			pi = parseTheme(i)
-		case common.SNItemTypePrivileges:
-			pi = parsePrivileges(i)
-		case common.SNItemTypeExtension:
-			pi = parseExtension(i)
-		case common.SNItemTypeSFExtension:
-			pi = parseSFExtension(i)
-		case common.SNItemTypeSFMFA:
-			pi = parseSFMFA(i)
-		case common.SNItemTypeSmartTag:
-			pi = parseSmartView(i)
-		case common.SNItemTypeFileSafeFileMetaData:
-			pi = parseFileSafeFileMetadata(i)
-		case common.SNItemTypeFileSafeIntegration:
-			pi = parseFileSafeIntegration(i)
-		case common.SNItemTypeUserPreferences:
-			pi = parseUserPreferences(i)
-		case common.SNItemTypeExtensionRepo:
-			pi = parseExtensionRepo(i)
-		case common.SNItemTypeFileSafeCredentials:
-			pi = parseFileSafeCredentials(i)
-		case common.SNItemTypeFile:
-			pi = parseFile(i)
-		case common.SNItemTypeTrustedContact:
-			pi = parseTrustedContact(i)
-		case common.SNItemTypeVaultListing:
-			pi = parseVaultListing(i)
-		case common.SNItemTypeKeySystemRootKey:
-			pi = parseKeySystemRootKey(i)
-		case common.SNItemTypeKeySystemItemsKey:
-			pi = parseKeySystemItemsKey(i)
-		default:
-			return nil, fmt.Errorf("unhandled type2 '%s' %s", i.ContentType, i.Content)
-		}
-
-		p = append(p, pi)
-	}
-
-	return p, err
-}
-
-func processContentModel(contentType, input string) (output Content, err error) {
-	// identify content model
-	// try and unmarshall Item
-	switch contentType {
-	case common.SNItemTypeNote:
-		var nc NoteContent
-
-		if err = json.Unmarshal([]byte(input), &nc); err != nil {
-			err = fmt.Errorf("processContentModel note | %w", err)
-
-			return output, err
-		}
-
-		return &nc, nil
-	case common.SNItemTypeTag:
-		var tc TagContent
-		if err = json.Unmarshal([]byte(input), &tc); err != nil {
-			err = fmt.Errorf("processContentModel tag | %w", err)
-
-			return output, err
-		}
-
-		return &tc, nil
-	case common.SNItemTypeComponent:
-		var cc ComponentContent
-		if err = json.Unmarshal([]byte(input), &cc); err != nil {
-			err = fmt.Errorf("processContentModel component | %w", err)
-
-			return
-		}
-
-		return &cc, nil
-	case common.SNItemTypeTheme:
-		var tc ThemeContent
-		if err = json.Unmarshal([]byte(input), &tc); err != nil {
-			err = fmt.Errorf("processContentModel theme | %w", err)
-
-			return
-		}
-
-		return &tc, nil
-	case common.SNItemTypePrivileges:
-		var pc PrivilegesContent
-		if err = json.Unmarshal([]byte(input), &pc); err != nil {
-			if err = json.Unmarshal([]byte(input), &pc); err != nil {
-				err = fmt.Errorf("processContentModel privileges | %w", err)
-
-				return
-			}
-		}
-
-		return &pc, nil
-	case common.SNItemTypeExtension:
-		var ec ExtensionContent
-		if err = json.Unmarshal([]byte(input), &ec); err != nil {
-			err = fmt.Errorf("processContentModel extension | %w", err)
-
-			return
-		}
-
-		return &ec, nil
-	case common.SNItemTypeSFExtension:
-		var sfe SFExtensionContent
-
-		if len(input) > 0 {
-			if err = json.Unmarshal([]byte(input), &sfe); err != nil {
-				err = fmt.Errorf("processContentModel sf extension | %w", err)
-
-				return
-			}
-		}
-
-		return &sfe, nil
-	case common.SNItemTypeSFMFA:
-		var sfm SFMFAContent
-
-		if len(input) > 0 {
-			if err = json.Unmarshal([]byte(input), &sfm); err != nil {
-				err = fmt.Errorf("processContentModel sf mfa | %w", err)
-
-				return
-			}
 		}
 
-		return &sfm, nil
-	case common.SNItemTypeSmartTag:
-		var sv SmartViewContent
-
-		if len(input) > 0 {
-			if err = json.Unmarshal([]byte(input), &sv); err != nil {
-				err = fmt.Errorf("processContentModel smart view | %w", err)
-
-				return
-			}
-		}
-
-		return &sv, nil
-
-	case common.SNItemTypeFileSafeFileMetaData:
-		var fsfm FileSafeFileMetaDataContent
-
-		if len(input) > 0 {
-			if err = json.Unmarshal([]byte(input), &fsfm); err != nil {
-				err = fmt.Errorf("processContentModel sf metadata | %w", err)
-
-				return
-			}
-		}
-
-		return &fsfm, nil
-
-	case common.SNItemTypeFileSafeIntegration:
-		var fsi FileSafeIntegrationContent
-
-		if len(input) > 0 {
-			if err = json.Unmarshal([]byte(input), &fsi); err != nil {
-				err = fmt.Errorf("processContentModel fs integration | %w", err)
-
-				return
-			}
-		}
-
-		return &fsi, nil
-	case common.SNItemTypeUserPreferences:
-		var upc UserPreferencesContent
-
-		if len(input) > 0 {
-			if err = json.Unmarshal([]byte(input), &upc); err != nil {
-				err = fmt.Errorf("processContentModel user preferences | %w", err)
-
-				return
-			}
-		}
-
-		return &upc, nil
-	case common.SNItemTypeExtensionRepo:
-		var erc ExtensionRepoContent
-
-		if len(input) > 0 {
-			if err = json.Unmarshal([]byte(input), &erc); err != nil {
-				err = fmt.Errorf("processContentModel extension repo | %w", err)
-
-				return
-			}
-		}
-
-		return &erc, nil
-	case common.SNItemTypeFileSafeCredentials:
-		var fsc FileSafeCredentialsContent
-
-		if len(input) > 0 {
-			if err = json.Unmarshal([]byte(input), &fsc); err != nil {
-				err = fmt.Errorf("processContentModel fs credentials | %w", err)
-
-				return
-			}
-		}
-
-		return &fsc, nil
-	case common.SNItemTypeFile:
-		var fc FileContent
-
-		if len(input) > 0 {
-			if err = json.Unmarshal([]byte(input), &fc); err != nil {
-				err = fmt.Errorf("processContentModel file | %w", err)
-
-				return
-			}
-		}
-
-		return &fc, nil
-	case common.SNItemTypeTrustedContact:
-		var tc TrustedContactContent
-
-		if len(input) > 0 {
-			if err = json.Unmarshal([]byte(input), &tc); err != nil {
-				err = fmt.Errorf("processContentModel trusted contact | %w", err)
-
-				return
-			}
-		}
-
-		return &tc, nil
-	case common.SNItemTypeVaultListing:
-		var vl VaultListingContent
-
-		if len(input) > 0 {
-			if err = json.Unmarshal([]byte(input), &vl); err != nil {
-				err = fmt.Errorf("processContentModel vault listing | %w", err)
-
-				return
-			}
-		}
-
-		return &vl, nil
-	case common.SNItemTypeKeySystemRootKey:
-		var ksrk KeySystemRootKeyContent
-
-		if len(input) > 0 {
-			if err = json.Unmarshal([]byte(input), &ksrk); err != nil {
-				err = fmt.Errorf("processContentModel key system root key | %w", err)
-
-				return
-			}
-		}
-
-		return &ksrk, nil
-	case common.SNItemTypeKeySystemItemsKey:
-		var ksik KeySystemItemsKeyContent
-
-		if len(input) > 0 {
-			if err = json.Unmarshal([]byte(input), &ksik); err != nil {
-				err = fmt.Errorf("processContentModel key system items key | %w", err)
+		var pi Item
 
-				return
-			}
+		pi, err = getContentModel(i.ContentType).parse(i)
+		if err != nil {
+			return nil, fmt.Errorf("Parse | %w", err)
 		}
 
-		return &ksik, nil
-	default:
-		return nil, fmt.Errorf("unexpected type '%s'", contentType)
+		p = append(p, pi)
 	}
+
+	return p, err
 }
 
 func (ei *EncryptedItems) DeDupe() {
@@ -1887,15 +1644,28 @@ func DedupeItemsKeys(itemsKeys []ItemsKey) (output []ItemsKey) {
 	return output
 }
 
-func DecryptEncryptedItemKey(e EncryptedItem, encryptionKey string) (itemKey []byte, err error) {
-	_, nonce, cipherText, authData := crypto.SplitContent(e.EncItemKey)
-	return crypto.DecryptCipherText(cipherText, encryptionKey, nonce, authData)
+// decryptPayload decrypts an encrypted protocol string using the Provider for its protocol version.
+// The session's Provider is preferred, with the registered Providers used if the session is nil.
+func decryptPayload(s *session.Session, in string, encryptionKey crypto.SecretKey) ([]byte, error) {
+	version, nonce, cipherText, authData, err := crypto.SplitContent(in)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.CryptoProviderFor(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Decrypt(cipherText, encryptionKey, nonce, authData)
 }
 
-func DecryptContent(e EncryptedItem, encryptionKey string) (content []byte, err error) {
-	_, nonce, cipherText, authData := crypto.SplitContent(e.Content)
+func DecryptEncryptedItemKey(e EncryptedItem, encryptionKey crypto.SecretKey) (itemKey []byte, err error) {
+	return decryptPayload(nil, e.EncItemKey, encryptionKey)
+}
 
-	content, err = crypto.DecryptCipherText(cipherText, encryptionKey, nonce, authData)
+func DecryptContent(e EncryptedItem, encryptionKey crypto.SecretKey) (content []byte, err error) {
+	content, err = decryptPayload(nil, e.Content, encryptionKey)
 	if err != nil {
 		return
 	}
@@ -1915,7 +1685,10 @@ func DecryptContent(e EncryptedItem, encryptionKey string) (content []byte, err
 }
 
 func CreateItemsKey() (ItemsKey, error) {
-	ik := NewItemsKey()
+	ik, err := NewItemsKey()
+	if err != nil {
+		return ItemsKey{}, err
+	}
 	// creating an items key is done during registration or when exporting, in which case it will always be default
 	// ik.Default = true
 	// ik.Content.Default = true
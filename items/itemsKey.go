@@ -2,11 +2,12 @@ package items
 
 import (
 	crand "crypto/rand"
-	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/crypto"
 )
 
 // Encrypted ItemsKey has content
@@ -29,11 +30,11 @@ type ItemsKey struct {
 	// Following attributes set from:
 	// - the unmarshalled content, post decryption
 	// - creation of a new ItemsKey
-	ItemsKey       string         `json:"itemsKey"`
-	Version        string         `json:"version"`
-	ItemReferences ItemReferences `json:"references"`
-	AppData        AppDataContent `json:"appData"`
-	Default        bool           `json:"isDefault"`
+	ItemsKey       crypto.SecretKey `json:"itemsKey"`
+	Version        string           `json:"version"`
+	ItemReferences ItemReferences   `json:"references"`
+	AppData        AppDataContent   `json:"appData"`
+	Default        bool             `json:"isDefault"`
 	// Following attibute set only for the purpose of marshaling a new ItemsKey when encrypting
 	Content     ItemsKeyContent `json:"content"`
 	ContentSize int
@@ -50,11 +51,11 @@ type ItemsKeyEncKey struct {
 }
 
 type ItemsKeyContent struct {
-	ItemsKey       string         `json:"itemsKey"`
-	Version        string         `json:"version"`
-	ItemReferences ItemReferences `json:"references"`
-	AppData        AppDataContent `json:"appData"`
-	Default        bool           `json:"isDefault"`
+	ItemsKey       crypto.SecretKey `json:"itemsKey"`
+	Version        string           `json:"version"`
+	ItemReferences ItemReferences   `json:"references"`
+	AppData        AppDataContent   `json:"appData"`
+	Default        bool             `json:"isDefault"`
 }
 
 func (i ItemsKeyContent) References() ItemReferences {
@@ -86,8 +87,19 @@ type EIT struct {
 // Version: ItemReferences:[] AppData:{OrgStandardNotesSN:{ClientUpdatedAt: PrefersPlainEditor:false}} Default:true
 // Content:{ItemsKey:9835761f3c4d3a9db97593564f766790e1bdada329e9c578e491f85c2b2686ab Version:004 ItemReferences:[] AppData:{OrgStandardNotesSN:{ClientUpdatedAt: PrefersPlainEditor:false}} Default:true} ContentSize:0}
 
-// NewItemsKey returns an Item of type ItemsKey without content.
-func NewItemsKey() ItemsKey {
+// generateItemsKey returns a new random items key.
+func generateItemsKey() (crypto.SecretKey, error) {
+	itemKey := make(crypto.SecretKey, crypto.KeySize)
+
+	if _, err := crand.Read(itemKey); err != nil {
+		return nil, fmt.Errorf("failed to generate items key: %w", err)
+	}
+
+	return itemKey, nil
+}
+
+// NewItemsKey returns an Item of type ItemsKey with newly generated key content.
+func NewItemsKey() (ItemsKey, error) {
 	now := time.Now().UTC().Format(common.TimeLayout)
 
 	var c ItemsKey
@@ -97,31 +109,15 @@ func NewItemsKey() ItemsKey {
 	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
 	c.UUID = GenUUID()
 
-	// TODO: generate items key content
-	itemKeyBytes := make([]byte, 64)
-
-	_, err := crand.Read(itemKeyBytes)
+	content, err := NewItemsKeyContent()
 	if err != nil {
-		panic(err)
+		return ItemsKey{}, err
 	}
 
-	itemKey := hex.EncodeToString(itemKeyBytes)
-	// get Item Encryption Key
-	val := itemKey[:len(itemKey)/2]
-
-	c.ItemsKey = val
-
-	content := NewItemsKeyContent()
-	content.ItemsKey = val
+	c.ItemsKey = content.ItemsKey
 	c.Content = *content
 
-	// ItemsKey       string         `json:"itemsKey"`
-	//	Version        string         `json:"version"`
-	//	ItemReferences ItemReferences `json:"references"`
-	//	AppData        AppDataContent `json:"appData"`
-	//	Default        bool           `json:"isDefault"`
-
-	return c
+	return c, nil
 }
 
 func (i ItemsKeyContent) MarshalJSON() ([]byte, error) {
@@ -140,8 +136,8 @@ func (i ItemsKeyContent) MarshalJSON() ([]byte, error) {
 	return json.Marshal(a)
 }
 
-// NewItemsKeyContent returns an empty ItemsKey content instance.
-func NewItemsKeyContent() *ItemsKeyContent {
+// NewItemsKeyContent returns ItemsKey content with a newly generated key.
+func NewItemsKeyContent() (*ItemsKeyContent, error) {
 	c := &ItemsKeyContent{}
 	c.Version = common.DefaultSNVersion
 	// we only create default keys as the only time we generate is:
@@ -149,20 +145,16 @@ func NewItemsKeyContent() *ItemsKeyContent {
 	// - during export (we re-encrypt everything, so this is not only the default, but also the only one)
 	c.Default = true
 
-	itemKeyBytes := make([]byte, 64)
-
-	_, err := crand.Read(itemKeyBytes)
+	itemKey, err := generateItemsKey()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	itemKey := hex.EncodeToString(itemKeyBytes)
-
-	c.ItemsKey = itemKey[:len(itemKey)/2]
+	c.ItemsKey = itemKey
 
 	// not setting references or app data as we don't currently need them
 
-	return c
+	return c, nil
 }
 
 type EncItemKey struct {
@@ -276,7 +276,8 @@ func TestReEncrypt(t *testing.T) {
 	itr, err := EncryptItem(tag, testSession.DefaultItemsKey, testSession)
 	require.NoError(t, err)
 
-	nik := NewItemsKey()
+	nik, err := NewItemsKey()
+	require.NoError(t, err)
 	nie, err := ReEncryptItem(itr, testSession.DefaultItemsKey, nik, testSession.MasterKey, testSession)
 	require.NoError(t, err)
 	require.Equal(t, nie.UUID, itr.UUID)
@@ -460,7 +461,13 @@ func TestEncryptDecryptItemWithItemsKey(t *testing.T) {
 
 	for _, dItem := range di {
 		if dItem.ContentType == common.SNItemTypeNote {
-			dn = *parseNote(dItem).(*Note)
+			var pn Item
+
+			pn, err = parseNote(dItem)
+			require.NoError(t, err)
+
+			dn = *pn.(*Note)
+
 			break
 		}
 	}
@@ -1407,7 +1414,7 @@ func TestDecryptItemKey(t *testing.T) {
 	var ik ItemsKey
 	err = json.Unmarshal(dIKeyContent, &ik)
 	require.NoError(t, err)
-	require.Equal(t, "366df581a789de771a1613d7d0289bbaff7bf4249a7dd15e458a12c361cb7b73", ik.ItemsKey)
+	require.Equal(t, "366df581a789de771a1613d7d0289bbaff7bf4249a7dd15e458a12c361cb7b73", ik.ItemsKey.Hex())
 }
 
 // func TestRegisterExportImport(t *testing.T) {
@@ -2004,3 +2011,49 @@ func TestDecryptItemKey(t *testing.T) {
 // 	require.NotEmpty(t, dn.CreatedAt)
 // 	require.False(t, dn.Deleted)
 // }
+
+func TestNoteAttributesContentLocations(t *testing.T) {
+	note := createNote("attributes", "text", "")
+	note.SetPinned(true)
+	note.SetArchived(true)
+	note.SetLocked(true)
+	note.SetProtected(true)
+	note.SetStarred(true)
+	require.True(t, note.Pinned && note.Archived && note.Locked && note.Protected && note.Starred)
+
+	b, err := json.Marshal(note.Content)
+	require.NoError(t, err)
+
+	var content map[string]any
+	require.NoError(t, json.Unmarshal(b, &content))
+	require.Equal(t, true, content["protected"])
+	require.Equal(t, true, content["starred"])
+
+	appData := content["appData"].(map[string]any)["org.standardnotes.sn"].(map[string]any)
+	require.Equal(t, true, appData["pinned"])
+	require.Equal(t, true, appData["archived"])
+	require.Equal(t, true, appData["locked"])
+
+	pn, err := parseNote(DecryptedItem{
+		UUID:        note.UUID,
+		ContentType: common.SNItemTypeNote,
+		Content:     string(b),
+		CreatedAt:   note.CreatedAt,
+		UpdatedAt:   note.CreatedAt,
+	})
+	require.NoError(t, err)
+
+	parsed := pn.(*Note)
+	require.True(t, parsed.IsPinned() && parsed.IsArchived() && parsed.IsLocked() && parsed.IsProtected() && parsed.IsStarred())
+	require.True(t, parsed.Pinned && parsed.Archived && parsed.Locked && parsed.Protected && parsed.Starred)
+	require.False(t, parsed.Content.GetTrashed())
+
+	parsed.SetArchived(false)
+	require.False(t, parsed.IsArchived())
+	require.False(t, parsed.Archived)
+
+	tag, err := createTag("favourite", "", nil)
+	require.NoError(t, err)
+	tag.SetStarred(true)
+	require.True(t, tag.IsStarred())
+}
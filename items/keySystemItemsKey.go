@@ -2,35 +2,11 @@ package items
 
 import (
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parseKeySystemItemsKey(i DecryptedItem) Item {
-	c := KeySystemItemsKey{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		c.Content = *content.(*KeySystemItemsKeyContent)
-	}
-
-	return &c
-}
-
 // KeySystemItemsKeyContent represents a key system items key for advanced encryption
 type KeySystemItemsKeyContent struct {
 	Version           string         `json:"version"`           // ProtocolVersion
@@ -39,56 +15,32 @@ type KeySystemItemsKeyContent struct {
 	RootKeyToken      string         `json:"rootKeyToken"`
 	ItemReferences    ItemReferences `json:"references"`
 	AppData           AppDataContent `json:"appData"`
+	Unknown           UnknownFields  `json:"-"`
 }
 
-type KeySystemItemsKey struct {
-	ItemCommon
-	Content KeySystemItemsKeyContent
-}
-
-func (c KeySystemItemsKey) IsDefault() bool {
-	return false
-}
+func (cc *KeySystemItemsKeyContent) UnmarshalJSON(data []byte) (err error) {
+	type alias KeySystemItemsKeyContent
 
-func (i Items) KeySystemItemsKeys() (c KeySystemItemsKeys) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeKeySystemItemsKey {
-			keySystemItemsKey := x.(*KeySystemItemsKey)
-			c = append(c, *keySystemItemsKey)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *KeySystemItemsKeys) DeDupe() {
-	var encountered []string
+func (cc KeySystemItemsKeyContent) MarshalJSON() ([]byte, error) {
+	type alias KeySystemItemsKeyContent
 
-	var deDuped KeySystemItemsKeys
-
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-		encountered = append(encountered, i.UUID)
-	}
+type KeySystemItemsKey = TypedItem[KeySystemItemsKeyContent, *KeySystemItemsKeyContent]
 
-	*c = deDuped
+func (i Items) KeySystemItemsKeys() (c KeySystemItemsKeys) {
+	return ItemsOfType[KeySystemItemsKeyContent](i)
 }
 
 // NewKeySystemItemsKey returns an Item of type KeySystemItemsKey without content.
 func NewKeySystemItemsKey() KeySystemItemsKey {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c KeySystemItemsKey
-
-	c.ContentType = common.SNItemTypeKeySystemItemsKey
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[KeySystemItemsKeyContent](common.SNItemTypeKeySystemItemsKey)
 }
 
 // NewKeySystemItemsKeyContent returns an empty KeySystemItemsKey content instance.
@@ -99,129 +51,21 @@ func NewKeySystemItemsKeyContent() *KeySystemItemsKeyContent {
 	return c
 }
 
-type KeySystemItemsKeys []KeySystemItemsKey
-
-func (c KeySystemItemsKeys) Validate() error {
-	var updatedTime time.Time
-
-	var err error
-
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Version == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing version: \"%s\"",
-					item.ContentType, item.UUID)
-			case item.Content.ItemsKey == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing itemsKey: \"%s\"",
-					item.ContentType, item.UUID)
-			case item.Content.RootKeyToken == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing rootKeyToken: \"%s\"",
-					item.ContentType, item.UUID)
-			case item.Content.CreationTimestamp == 0:
-				err = fmt.Errorf("failed to create \"%s\" due to missing creationTimestamp: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
-	}
+type KeySystemItemsKeys = TypedItems[KeySystemItemsKeyContent, *KeySystemItemsKeyContent]
 
-	return err
-}
-
-func (c KeySystemItemsKey) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *KeySystemItemsKey) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c KeySystemItemsKey) GetContent() Content {
-	return &c.Content
-}
-
-func (c *KeySystemItemsKey) SetContent(cc Content) {
-	c.Content = *cc.(*KeySystemItemsKeyContent)
-}
-
-func (c KeySystemItemsKey) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c KeySystemItemsKey) GetUUID() string {
-	return c.UUID
-}
-
-func (c KeySystemItemsKey) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *KeySystemItemsKey) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c KeySystemItemsKey) GetContentType() string {
-	return c.ContentType
-}
-
-func (c KeySystemItemsKey) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *KeySystemItemsKey) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c KeySystemItemsKey) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *KeySystemItemsKey) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c KeySystemItemsKey) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *KeySystemItemsKey) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c KeySystemItemsKey) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *KeySystemItemsKey) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *KeySystemItemsKey) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c KeySystemItemsKey) GetContentSize() int {
-	return c.ContentSize
-}
+func (cc *KeySystemItemsKeyContent) MissingField() string {
+	switch {
+	case cc.Version == "":
+		return "version"
+	case cc.ItemsKey == "":
+		return "itemsKey"
+	case cc.RootKeyToken == "":
+		return "rootKeyToken"
+	case cc.CreationTimestamp == 0:
+		return "creationTimestamp"
+	}
 
-func (c *KeySystemItemsKey) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *KeySystemItemsKeyContent) GetUpdateTime() (time.Time, error) {
@@ -2,35 +2,11 @@ package items
 
 import (
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parseKeySystemRootKey(i DecryptedItem) Item {
-	c := KeySystemRootKey{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		c.Content = *content.(*KeySystemRootKeyContent)
-	}
-
-	return &c
-}
-
 // KeySystemRootKeyContent represents a key system root key for advanced encryption
 type KeySystemRootKeyContent struct {
 	KeyParams        interface{}    `json:"keyParams"`        // KeySystemRootKeyParamsInterface
@@ -40,56 +16,32 @@ type KeySystemRootKeyContent struct {
 	Token            string         `json:"token"`
 	ItemReferences   ItemReferences `json:"references"`
 	AppData          AppDataContent `json:"appData"`
+	Unknown          UnknownFields  `json:"-"`
 }
 
-type KeySystemRootKey struct {
-	ItemCommon
-	Content KeySystemRootKeyContent
-}
-
-func (c KeySystemRootKey) IsDefault() bool {
-	return false
-}
+func (cc *KeySystemRootKeyContent) UnmarshalJSON(data []byte) (err error) {
+	type alias KeySystemRootKeyContent
 
-func (i Items) KeySystemRootKeys() (c KeySystemRootKeys) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeKeySystemRootKey {
-			keySystemRootKey := x.(*KeySystemRootKey)
-			c = append(c, *keySystemRootKey)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *KeySystemRootKeys) DeDupe() {
-	var encountered []string
+func (cc KeySystemRootKeyContent) MarshalJSON() ([]byte, error) {
+	type alias KeySystemRootKeyContent
 
-	var deDuped KeySystemRootKeys
-
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-		encountered = append(encountered, i.UUID)
-	}
+type KeySystemRootKey = TypedItem[KeySystemRootKeyContent, *KeySystemRootKeyContent]
 
-	*c = deDuped
+func (i Items) KeySystemRootKeys() (c KeySystemRootKeys) {
+	return ItemsOfType[KeySystemRootKeyContent](i)
 }
 
 // NewKeySystemRootKey returns an Item of type KeySystemRootKey without content.
 func NewKeySystemRootKey() KeySystemRootKey {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c KeySystemRootKey
-
-	c.ContentType = common.SNItemTypeKeySystemRootKey
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[KeySystemRootKeyContent](common.SNItemTypeKeySystemRootKey)
 }
 
 // NewKeySystemRootKeyContent returns an empty KeySystemRootKey content instance.
@@ -100,126 +52,19 @@ func NewKeySystemRootKeyContent() *KeySystemRootKeyContent {
 	return c
 }
 
-type KeySystemRootKeys []KeySystemRootKey
-
-func (c KeySystemRootKeys) Validate() error {
-	var updatedTime time.Time
-
-	var err error
-
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.SystemIdentifier == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing systemIdentifier: \"%s\"",
-					item.ContentType, item.UUID)
-			case item.Content.Key == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing key: \"%s\"",
-					item.ContentType, item.UUID)
-			case item.Content.Token == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing token: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
-	}
+type KeySystemRootKeys = TypedItems[KeySystemRootKeyContent, *KeySystemRootKeyContent]
 
-	return err
-}
-
-func (c KeySystemRootKey) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *KeySystemRootKey) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c KeySystemRootKey) GetContent() Content {
-	return &c.Content
-}
-
-func (c *KeySystemRootKey) SetContent(cc Content) {
-	c.Content = *cc.(*KeySystemRootKeyContent)
-}
-
-func (c KeySystemRootKey) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c KeySystemRootKey) GetUUID() string {
-	return c.UUID
-}
-
-func (c KeySystemRootKey) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *KeySystemRootKey) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c KeySystemRootKey) GetContentType() string {
-	return c.ContentType
-}
-
-func (c KeySystemRootKey) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *KeySystemRootKey) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c KeySystemRootKey) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *KeySystemRootKey) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c KeySystemRootKey) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *KeySystemRootKey) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c KeySystemRootKey) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *KeySystemRootKey) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *KeySystemRootKey) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c KeySystemRootKey) GetContentSize() int {
-	return c.ContentSize
-}
+func (cc *KeySystemRootKeyContent) MissingField() string {
+	switch {
+	case cc.SystemIdentifier == "":
+		return "systemIdentifier"
+	case cc.Key == "":
+		return "key"
+	case cc.Token == "":
+		return "token"
+	}
 
-func (c *KeySystemRootKey) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *KeySystemRootKeyContent) GetUpdateTime() (time.Time, error) {
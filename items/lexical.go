@@ -0,0 +1,749 @@
+package items
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// SuperNoteType is the note type of notes written with the Super editor, whose text is Lexical JSON.
+	SuperNoteType = "super"
+	// SuperEditorIdentifier is the editor identifier of notes written with the Super editor.
+	SuperEditorIdentifier = "com.standardnotes.super-editor"
+)
+
+// superPreviewLength is the maximum length, in runes, of the plain text preview of a Super note.
+const superPreviewLength = 250
+
+// ErrInvalidLexical is returned when a Super note's text is not valid Lexical editor state.
+var ErrInvalidLexical = errors.New("invalid lexical editor state")
+
+// text format flags used by Lexical text nodes
+const (
+	lexicalBold = 1 << iota
+	lexicalItalic
+	lexicalStrikethrough
+	lexicalUnderline
+	lexicalCode
+	lexicalSubscript
+	lexicalSuperscript
+	lexicalHighlight
+)
+
+// lexicalNode is a node of Lexical editor state, with the fields of the node types that are converted.
+type lexicalNode struct {
+	Type        string        `json:"type"`
+	Children    []lexicalNode `json:"children"`
+	Text        string        `json:"text"`
+	Format      any           `json:"format"`
+	Tag         string        `json:"tag"`
+	ListType    string        `json:"listType"`
+	Start       int           `json:"start"`
+	Checked     bool          `json:"checked"`
+	Language    string        `json:"language"`
+	URL         string        `json:"url"`
+	HeaderState int           `json:"headerState"`
+	ItemUUID    string        `json:"itemUuid"`
+}
+
+// textFormat returns the format flags of a text node. Element nodes use format for alignment.
+func (n lexicalNode) textFormat() int {
+	if f, ok := n.Format.(float64); ok {
+		return int(f)
+	}
+
+	return 0
+}
+
+func (n lexicalNode) isInline() bool {
+	switch n.Type {
+	case "text", "code-highlight", "hashtag", "tab", "linebreak", "link", "autolink", superBubbleNodeType:
+		return true
+	}
+
+	return false
+}
+
+func parseLexical(text string) (lexicalNode, error) {
+	if strings.TrimSpace(text) == "" {
+		return lexicalNode{Type: "root"}, nil
+	}
+
+	var state struct {
+		Root lexicalNode `json:"root"`
+	}
+
+	if err := json.Unmarshal([]byte(text), &state); err != nil {
+		return lexicalNode{}, fmt.Errorf("%w: %w", ErrInvalidLexical, err)
+	}
+
+	if state.Root.Type != "root" {
+		return lexicalNode{}, fmt.Errorf("%w: missing root node", ErrInvalidLexical)
+	}
+
+	return state.Root, nil
+}
+
+// LexicalToMarkdown converts Lexical editor state, as stored in the text of a Super note, to Markdown.
+// Links to other items are written as [[uuid]].
+func LexicalToMarkdown(text string) (string, error) {
+	root, err := parseLexical(text)
+	if err != nil {
+		return "", err
+	}
+
+	return markdownBlocks(root.Children), nil
+}
+
+// LexicalToHTML converts Lexical editor state, as stored in the text of a Super note, to HTML.
+func LexicalToHTML(text string) (string, error) {
+	root, err := parseLexical(text)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+
+	for _, c := range root.Children {
+		sb.WriteString(htmlBlock(c))
+	}
+
+	return sb.String(), nil
+}
+
+// LexicalToPlainText converts Lexical editor state, as stored in the text of a Super note, to plain text
+// with a line for each paragraph, heading, list item and table row.
+func LexicalToPlainText(text string) (string, error) {
+	root, err := parseLexical(text)
+	if err != nil {
+		return "", err
+	}
+
+	return plainBlocks(root.Children), nil
+}
+
+// IsSuper returns true if the note was written with the Super editor, so its text is Lexical editor state.
+func (noteContent NoteContent) IsSuper() bool {
+	return noteContent.NoteType == SuperNoteType
+}
+
+// GetMarkdown returns the text of a Super note converted to Markdown, or the text of any other note unchanged.
+func (noteContent NoteContent) GetMarkdown() (string, error) {
+	if !noteContent.IsSuper() {
+		return noteContent.Text, nil
+	}
+
+	return LexicalToMarkdown(noteContent.Text)
+}
+
+// SetSuperMarkdown converts the Markdown to Lexical editor state and sets it as the text of
+// a Super note, updating the note type, editor and previews.
+func (noteContent *NoteContent) SetSuperMarkdown(md string) {
+	noteContent.Text = MarkdownToLexical(md)
+	noteContent.NoteType = SuperNoteType
+	noteContent.EditorIdentifier = SuperEditorIdentifier
+
+	// the state was generated so is known to be valid
+	_ = noteContent.UpdateSuperPreviews()
+}
+
+// UpdateSuperPreviews sets the plain text and HTML previews of a Super note from its text.
+func (noteContent *NoteContent) UpdateSuperPreviews() error {
+	root, err := parseLexical(noteContent.Text)
+	if err != nil {
+		return err
+	}
+
+	plain := []rune(strings.Join(strings.Fields(plainBlocks(root.Children)), " "))
+	if len(plain) > superPreviewLength {
+		plain = append(plain[:superPreviewLength-1], '…')
+	}
+
+	var sb strings.Builder
+	for _, c := range root.Children {
+		sb.WriteString(htmlBlock(c))
+	}
+
+	noteContent.PreviewPlain = string(plain)
+	noteContent.PreviewHtml = sb.String()
+
+	return nil
+}
+
+// NewSuperNote returns a Super note with text converted from Markdown.
+func NewSuperNote(title, md string, references ItemReferences) (Note, error) {
+	note, err := NewNote(title, "", references)
+	if err != nil {
+		return note, err
+	}
+
+	note.Content.SetSuperMarkdown(md)
+
+	return note, nil
+}
+
+// Markdown
+
+var (
+	markdownBlockStartRE = regexp.MustCompile(`^([ \t]*)(#|>|[-+](?:[ \t]|$)|\d+[.)](?:[ \t]|$))`)
+	markdownUUIDRE       = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+func markdownBlocks(nodes []lexicalNode) string {
+	var blocks []string
+
+	for _, n := range nodes {
+		if b := markdownBlock(n); strings.TrimSpace(b) != "" {
+			blocks = append(blocks, b)
+		}
+	}
+
+	return strings.Join(blocks, "\n\n")
+}
+
+func markdownBlock(n lexicalNode) string {
+	switch n.Type {
+	case "heading":
+		level, _ := strconv.Atoi(strings.TrimPrefix(n.Tag, "h"))
+		level = min(max(level, 1), 6)
+
+		return strings.Repeat("#", level) + " " + strings.ReplaceAll(markdownInline(n.Children), "\n", " ")
+	case "quote":
+		return "> " + strings.ReplaceAll(markdownInline(n.Children), "\n", "\n> ")
+	case "list":
+		return markdownList(n, "")
+	case "code":
+		code := plainCode(n.Children)
+
+		fence := "```"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+
+		return fence + n.Language + "\n" + code + "\n" + fence
+	case "horizontalrule":
+		return "---"
+	case "table":
+		return markdownTable(n)
+	}
+
+	if allInline(n.Children) {
+		return escapeMarkdownBlockStart(markdownInline(n.Children))
+	}
+
+	return markdownBlocks(n.Children)
+}
+
+func allInline(nodes []lexicalNode) bool {
+	for _, n := range nodes {
+		if !n.isInline() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// escapeMarkdownBlockStart escapes characters at the start of each line that would otherwise begin a block.
+func escapeMarkdownBlockStart(s string) string {
+	lines := strings.Split(s, "\n")
+
+	for x, line := range lines {
+		m := markdownBlockStartRE.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+
+		// escape the final character of the marker, e.g. "#", "-" or the "." of "1."
+		marker := strings.TrimRight(line[m[4]:m[5]], " \t")
+		pos := m[4] + len(marker) - 1
+		lines[x] = line[:pos] + `\` + line[pos:]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func markdownList(n lexicalNode, indent string) string {
+	var lines []string
+
+	number := max(n.Start, 1)
+
+	for _, item := range n.Children {
+		var inline []lexicalNode
+
+		var nested []lexicalNode
+
+		for _, c := range item.Children {
+			if c.Type == "list" {
+				nested = append(nested, c)
+			} else {
+				inline = append(inline, c)
+			}
+		}
+
+		marker := "- "
+
+		switch n.ListType {
+		case "number":
+			marker = strconv.Itoa(number) + ". "
+		case "check":
+			marker = "- [ ] "
+			if item.Checked {
+				marker = "- [x] "
+			}
+		}
+
+		nestedIndent := indent + strings.Repeat(" ", len(marker))
+		if n.ListType == "check" {
+			nestedIndent = indent + "  "
+		}
+
+		if len(inline) > 0 || len(nested) == 0 {
+			text := escapeMarkdownBlockStart(markdownInline(inline))
+			lines = append(lines, indent+marker+strings.ReplaceAll(text, "\n", "\n"+indent+strings.Repeat(" ", len(marker))))
+			number++
+		}
+
+		for _, l := range nested {
+			lines = append(lines, markdownList(l, nestedIndent))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func markdownTable(n lexicalNode) string {
+	var rows [][]string
+
+	columns := 0
+
+	for _, row := range n.Children {
+		var cells []string
+
+		for _, cell := range row.Children {
+			text := strings.ReplaceAll(plainInlineBlocks(cell.Children, markdownInline), "\n", " ")
+			cells = append(cells, strings.ReplaceAll(text, "|", `\|`))
+		}
+
+		columns = max(columns, len(cells))
+		rows = append(rows, cells)
+	}
+
+	if len(rows) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	writeRow := func(cells []string) {
+		sb.WriteString("|")
+
+		for x := range columns {
+			cell := ""
+			if x < len(cells) {
+				cell = cells[x]
+			}
+
+			sb.WriteString(" " + cell + " |")
+		}
+
+		sb.WriteString("\n")
+	}
+
+	writeRow(rows[0])
+	sb.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+
+	for _, r := range rows[1:] {
+		writeRow(r)
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// plainInlineBlocks renders the inline content of each block with render, one block per line.
+func plainInlineBlocks(nodes []lexicalNode, render func([]lexicalNode) string) string {
+	if allInline(nodes) {
+		return render(nodes)
+	}
+
+	var lines []string
+
+	for _, n := range nodes {
+		if n.isInline() {
+			lines = append(lines, render([]lexicalNode{n}))
+		} else {
+			lines = append(lines, plainInlineBlocks(n.Children, render))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func markdownInline(nodes []lexicalNode) string {
+	var sb strings.Builder
+
+	for _, n := range nodes {
+		switch n.Type {
+		case "text", "code-highlight", "hashtag":
+			sb.WriteString(markdownText(n.Text, n.textFormat()))
+		case "tab":
+			sb.WriteString("\t")
+		case "linebreak":
+			sb.WriteString("\n")
+		case "link", "autolink":
+			url := strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(n.URL)
+			sb.WriteString("[" + markdownInline(n.Children) + "](" + url + ")")
+		case superBubbleNodeType:
+			sb.WriteString("[[" + n.ItemUUID + "]]")
+		default:
+			sb.WriteString(markdownInline(n.Children))
+		}
+	}
+
+	return sb.String()
+}
+
+func markdownText(text string, format int) string {
+	core := strings.TrimFunc(text, unicode.IsSpace)
+	if core == "" {
+		return text
+	}
+
+	leading := text[:strings.Index(text, core)]
+	trailing := text[len(leading)+len(core):]
+
+	if format&lexicalCode != 0 {
+		fence := "`"
+		for strings.Contains(core, fence) {
+			fence += "`"
+		}
+
+		if strings.HasPrefix(core, "`") || strings.HasSuffix(core, "`") {
+			core = " " + core + " "
+		}
+
+		core = fence + core + fence
+	} else {
+		core = escapeMarkdown(core)
+	}
+
+	for _, f := range []struct {
+		flag  int
+		delim string
+	}{
+		{lexicalStrikethrough, "~~"},
+		{lexicalItalic, "*"},
+		{lexicalBold, "**"},
+		{lexicalHighlight, "=="},
+	} {
+		if format&f.flag != 0 {
+			core = f.delim + core + f.delim
+		}
+	}
+
+	return escapeMarkdown(leading) + core + escapeMarkdown(trailing)
+}
+
+// escapeMarkdown escapes characters that would otherwise be interpreted as inline Markdown.
+func escapeMarkdown(s string) string {
+	var sb strings.Builder
+
+	runes := []rune(s)
+
+	for x, r := range runes {
+		switch r {
+		case '\\', '`', '*', '_', '[', ']':
+			sb.WriteRune('\\')
+		case '~', '=':
+			// only doubled characters are delimiters
+			if (x > 0 && runes[x-1] == r) || (x+1 < len(runes) && runes[x+1] == r) {
+				sb.WriteRune('\\')
+			}
+		case '<':
+			if x+1 < len(runes) && unicode.IsLetter(runes[x+1]) {
+				sb.WriteRune('\\')
+			}
+		}
+
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+// HTML
+
+func htmlBlock(n lexicalNode) string {
+	switch n.Type {
+	case "paragraph":
+		return "<p>" + htmlInline(n.Children) + "</p>"
+	case "heading":
+		tag := "h1"
+		if level, err := strconv.Atoi(strings.TrimPrefix(n.Tag, "h")); err == nil && level >= 1 && level <= 6 {
+			tag = n.Tag
+		}
+
+		return "<" + tag + ">" + htmlInline(n.Children) + "</" + tag + ">"
+	case "quote":
+		return "<blockquote>" + htmlInline(n.Children) + "</blockquote>"
+	case "list":
+		return htmlList(n)
+	case "code":
+		class := ""
+		if n.Language != "" {
+			class = ` class="language-` + html.EscapeString(n.Language) + `"`
+		}
+
+		return "<pre><code" + class + ">" + html.EscapeString(plainCode(n.Children)) + "</code></pre>"
+	case "horizontalrule":
+		return "<hr>"
+	case "table":
+		var sb strings.Builder
+
+		sb.WriteString("<table>")
+
+		for _, row := range n.Children {
+			sb.WriteString("<tr>")
+
+			for _, cell := range row.Children {
+				tag := "td"
+				if cell.HeaderState != 0 {
+					tag = "th"
+				}
+
+				content := plainInlineBlocks(cell.Children, htmlInline)
+				sb.WriteString("<" + tag + ">" + strings.ReplaceAll(content, "\n", "<br>") + "</" + tag + ">")
+			}
+
+			sb.WriteString("</tr>")
+		}
+
+		sb.WriteString("</table>")
+
+		return sb.String()
+	}
+
+	if allInline(n.Children) {
+		if len(n.Children) == 0 {
+			return ""
+		}
+
+		return "<p>" + htmlInline(n.Children) + "</p>"
+	}
+
+	var sb strings.Builder
+	for _, c := range n.Children {
+		sb.WriteString(htmlBlock(c))
+	}
+
+	return sb.String()
+}
+
+func htmlList(n lexicalNode) string {
+	open, closing := "<ul>", "</ul>"
+
+	switch n.ListType {
+	case "number":
+		open, closing = "<ol>", "</ol>"
+		if n.Start > 1 {
+			open = fmt.Sprintf(`<ol start="%d">`, n.Start)
+		}
+	case "check":
+		open = `<ul class="checklist">`
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(open)
+
+	for _, item := range n.Children {
+		sb.WriteString("<li>")
+
+		if n.ListType == "check" && !(len(item.Children) == 1 && item.Children[0].Type == "list") {
+			if item.Checked {
+				sb.WriteString(`<input type="checkbox" checked disabled> `)
+			} else {
+				sb.WriteString(`<input type="checkbox" disabled> `)
+			}
+		}
+
+		for _, c := range item.Children {
+			if c.Type == "list" {
+				sb.WriteString(htmlList(c))
+			} else {
+				sb.WriteString(htmlInline([]lexicalNode{c}))
+			}
+		}
+
+		sb.WriteString("</li>")
+	}
+
+	sb.WriteString(closing)
+
+	return sb.String()
+}
+
+func htmlInline(nodes []lexicalNode) string {
+	var sb strings.Builder
+
+	for _, n := range nodes {
+		switch n.Type {
+		case "text", "code-highlight", "hashtag":
+			s := html.EscapeString(n.Text)
+			format := n.textFormat()
+
+			for _, f := range []struct {
+				flag int
+				tag  string
+			}{
+				{lexicalCode, "code"},
+				{lexicalSubscript, "sub"},
+				{lexicalSuperscript, "sup"},
+				{lexicalUnderline, "u"},
+				{lexicalStrikethrough, "s"},
+				{lexicalItalic, "em"},
+				{lexicalBold, "strong"},
+				{lexicalHighlight, "mark"},
+			} {
+				if format&f.flag != 0 {
+					s = "<" + f.tag + ">" + s + "</" + f.tag + ">"
+				}
+			}
+
+			sb.WriteString(s)
+		case "tab":
+			sb.WriteString("\t")
+		case "linebreak":
+			sb.WriteString("<br>")
+		case "link", "autolink":
+			sb.WriteString(`<a href="` + html.EscapeString(safeURL(n.URL)) + `">` + htmlInline(n.Children) + "</a>")
+		case superBubbleNodeType:
+			uuid := html.EscapeString(n.ItemUUID)
+			sb.WriteString(`<span data-item-uuid="` + uuid + `">[[` + uuid + `]]</span>`)
+		default:
+			sb.WriteString(htmlInline(n.Children))
+		}
+	}
+
+	return sb.String()
+}
+
+// safeURL prevents links from executing script when HTML is rendered.
+func safeURL(url string) string {
+	scheme, _, ok := strings.Cut(strings.ToLower(strings.TrimSpace(url)), ":")
+	if ok && (scheme == "javascript" || scheme == "vbscript" || scheme == "data") {
+		return "#"
+	}
+
+	return url
+}
+
+// plain text
+
+func plainBlocks(nodes []lexicalNode) string {
+	var lines []string
+
+	for _, n := range nodes {
+		if b := plainBlock(n); b != "" {
+			lines = append(lines, b)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func plainBlock(n lexicalNode) string {
+	switch n.Type {
+	case "code":
+		return plainCode(n.Children)
+	case "horizontalrule":
+		return ""
+	case "list":
+		var lines []string
+
+		for _, item := range n.Children {
+			var inline []lexicalNode
+
+			for _, c := range item.Children {
+				if c.Type == "list" {
+					if len(inline) > 0 {
+						lines = append(lines, plainInline(inline))
+						inline = nil
+					}
+
+					lines = append(lines, plainBlock(c))
+				} else {
+					inline = append(inline, c)
+				}
+			}
+
+			if len(inline) > 0 {
+				lines = append(lines, plainInline(inline))
+			}
+		}
+
+		return strings.Join(lines, "\n")
+	case "table":
+		var rows []string
+
+		for _, row := range n.Children {
+			var cells []string
+			for _, cell := range row.Children {
+				cells = append(cells, strings.ReplaceAll(plainInlineBlocks(cell.Children, plainInline), "\n", " "))
+			}
+
+			rows = append(rows, strings.Join(cells, "\t"))
+		}
+
+		return strings.Join(rows, "\n")
+	}
+
+	if allInline(n.Children) {
+		return plainInline(n.Children)
+	}
+
+	return plainBlocks(n.Children)
+}
+
+func plainInline(nodes []lexicalNode) string {
+	var sb strings.Builder
+
+	for _, n := range nodes {
+		switch n.Type {
+		case "text", "code-highlight", "hashtag":
+			sb.WriteString(n.Text)
+		case "tab":
+			sb.WriteString("\t")
+		case "linebreak":
+			sb.WriteString("\n")
+		default:
+			sb.WriteString(plainInline(n.Children))
+		}
+	}
+
+	return sb.String()
+}
+
+func plainCode(nodes []lexicalNode) string {
+	var sb strings.Builder
+
+	for _, n := range nodes {
+		switch n.Type {
+		case "linebreak":
+			sb.WriteString("\n")
+		case "tab":
+			sb.WriteString("\t")
+		default:
+			sb.WriteString(n.Text)
+			sb.WriteString(plainCode(n.Children))
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,549 @@
+package items
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	mdHeadingRE  = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdRuleRE     = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdFenceRE    = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	mdListItemRE = regexp.MustCompile(`^([ \t]*)([-*+]|\d{1,9}[.)])(?:[ \t]+(.*))?$`)
+	mdCheckRE    = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+(.*))?$`)
+	mdQuoteRE    = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	mdTableSepRE = regexp.MustCompile(`^[ \t]*\|?(?:[ \t]*:?-+:?[ \t]*\|)*[ \t]*:?-+:?[ \t]*\|?[ \t]*$`)
+	mdAutolinkRE = regexp.MustCompile(`^<((?:https?://|mailto:)[^>\s]+)>`)
+)
+
+// MarkdownToLexical converts Markdown to Lexical editor state for the text of a Super note. Headings,
+// paragraphs, quotes, bulleted, numbered and check lists, fenced code blocks, tables, horizontal rules,
+// links, and bold, italic, strikethrough, highlighted (==) and code text are supported. Links written
+// as [[uuid]] become links to the item with that UUID.
+func MarkdownToLexical(md string) string {
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+
+	b, _ := json.Marshal(map[string]any{"root": lexicalElement("root", parseMarkdownBlocks(lines))})
+
+	return string(b)
+}
+
+func lexicalElement(nodeType string, children []any) map[string]any {
+	if children == nil {
+		children = []any{}
+	}
+
+	return map[string]any{
+		"children":  children,
+		"direction": "ltr",
+		"format":    "",
+		"indent":    0,
+		"type":      nodeType,
+		"version":   1,
+	}
+}
+
+func lexicalText(nodeType, text string, format int) map[string]any {
+	return map[string]any{
+		"detail":  0,
+		"format":  format,
+		"mode":    "normal",
+		"style":   "",
+		"text":    text,
+		"type":    nodeType,
+		"version": 1,
+	}
+}
+
+func lexicalLineBreak() map[string]any {
+	return map[string]any{"type": "linebreak", "version": 1}
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// indentWidth returns the width of the line's leading whitespace, with tabs counting as four spaces.
+func indentWidth(ws string) int {
+	return len(strings.ReplaceAll(ws, "\t", "    "))
+}
+
+func isTableStart(lines []string, i int) bool {
+	return i+1 < len(lines) && strings.Contains(lines[i], "|") &&
+		strings.Contains(lines[i+1], "|") && mdTableSepRE.MatchString(lines[i+1])
+}
+
+// startsBlock returns true if the line begins a block other than a paragraph.
+func startsBlock(lines []string, i int) bool {
+	line := lines[i]
+
+	return mdFenceRE.MatchString(line) || mdHeadingRE.MatchString(line) || mdRuleRE.MatchString(line) ||
+		mdQuoteRE.MatchString(line) || mdListItemRE.MatchString(line) || isTableStart(lines, i)
+}
+
+func parseMarkdownBlocks(lines []string) []any {
+	var blocks []any
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case isBlank(line):
+			i++
+		case mdFenceRE.MatchString(line):
+			var block any
+			block, i = parseMarkdownCode(lines, i)
+			blocks = append(blocks, block)
+		case mdHeadingRE.MatchString(line):
+			m := mdHeadingRE.FindStringSubmatch(line)
+			heading := lexicalElement("heading", parseMarkdownInline(m[2], 0))
+			heading["tag"] = "h" + strconv.Itoa(len(m[1]))
+			blocks = append(blocks, heading)
+			i++
+		case mdRuleRE.MatchString(line):
+			blocks = append(blocks, map[string]any{"type": "horizontalrule", "version": 1})
+			i++
+		case isTableStart(lines, i):
+			var block any
+			block, i = parseMarkdownTable(lines, i)
+			blocks = append(blocks, block)
+		case mdQuoteRE.MatchString(line):
+			var text []string
+			for ; i < len(lines) && mdQuoteRE.MatchString(lines[i]); i++ {
+				text = append(text, strings.TrimSpace(mdQuoteRE.FindStringSubmatch(lines[i])[1]))
+			}
+
+			blocks = append(blocks, lexicalElement("quote", parseMarkdownInline(strings.Join(text, "\n"), 0)))
+		case mdListItemRE.MatchString(line):
+			var lists []any
+			lists, i = parseMarkdownLists(lines, i)
+			blocks = append(blocks, lists...)
+		default:
+			var text []string
+			for ; i < len(lines) && !isBlank(lines[i]) && (len(text) == 0 || !startsBlock(lines, i)); i++ {
+				text = append(text, strings.TrimSpace(lines[i]))
+			}
+
+			blocks = append(blocks, lexicalElement("paragraph", parseMarkdownInline(strings.Join(text, "\n"), 0)))
+		}
+	}
+
+	return blocks
+}
+
+func parseMarkdownCode(lines []string, i int) (any, int) {
+	m := mdFenceRE.FindStringSubmatch(lines[i])
+	fence := m[1]
+
+	var code []string
+
+	for i++; i < len(lines); i++ {
+		if t := strings.TrimSpace(lines[i]); strings.HasPrefix(t, fence) && strings.Trim(t, fence[:1]) == "" {
+			i++
+
+			break
+		}
+
+		code = append(code, lines[i])
+	}
+
+	var children []any
+
+	for x, line := range code {
+		if x > 0 {
+			children = append(children, lexicalLineBreak())
+		}
+
+		if line != "" {
+			children = append(children, lexicalText("code-highlight", line, 0))
+		}
+	}
+
+	block := lexicalElement("code", children)
+	block["language"] = nil
+
+	if m[2] != "" {
+		block["language"] = m[2]
+	}
+
+	return block, i
+}
+
+// splitTableRow splits a table row into its cells, ignoring escaped pipes.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+
+	start := 0
+
+	for x := 0; x < len(line); x++ {
+		switch line[x] {
+		case '\\':
+			x++
+		case '|':
+			cells = append(cells, strings.TrimSpace(line[start:x]))
+			start = x + 1
+		}
+	}
+
+	return append(cells, strings.TrimSpace(line[start:]))
+}
+
+func parseMarkdownTable(lines []string, i int) (any, int) {
+	header := splitTableRow(lines[i])
+	rows := [][]string{header}
+
+	for i += 2; i < len(lines) && !isBlank(lines[i]) && strings.Contains(lines[i], "|"); i++ {
+		rows = append(rows, splitTableRow(lines[i]))
+	}
+
+	var rowNodes []any
+
+	for r, row := range rows {
+		var cells []any
+
+		for c := range header {
+			text := ""
+			if c < len(row) {
+				text = row[c]
+			}
+
+			cell := lexicalElement("tablecell", []any{lexicalElement("paragraph", parseMarkdownInline(text, 0))})
+			cell["colSpan"] = 1
+			cell["rowSpan"] = 1
+			cell["backgroundColor"] = nil
+			cell["headerState"] = 0
+
+			if r == 0 {
+				cell["headerState"] = 1
+			}
+
+			cells = append(cells, cell)
+		}
+
+		rowNodes = append(rowNodes, lexicalElement("tablerow", cells))
+	}
+
+	return lexicalElement("table", rowNodes), i
+}
+
+type markdownListItem struct {
+	indent   int
+	listType string
+	number   int
+	checked  bool
+	text     string
+}
+
+// parseMarkdownLists parses consecutive list items, returning a list for each change of list type.
+func parseMarkdownLists(lines []string, i int) ([]any, int) {
+	var listItems []markdownListItem
+
+	for ; i < len(lines); i++ {
+		line := lines[i]
+
+		if isBlank(line) {
+			// a blank line only continues the list if followed by another item
+			if i+1 < len(lines) && mdListItemRE.MatchString(lines[i+1]) && !mdRuleRE.MatchString(lines[i+1]) {
+				continue
+			}
+
+			break
+		}
+
+		m := mdListItemRE.FindStringSubmatch(line)
+		if m == nil || mdRuleRE.MatchString(line) {
+			// continuation of the previous item
+			if len(listItems) == 0 || startsBlock(lines, i) {
+				break
+			}
+
+			listItems[len(listItems)-1].text += "\n" + strings.TrimSpace(line)
+
+			continue
+		}
+
+		item := markdownListItem{indent: indentWidth(m[1]), listType: "bullet", text: m[3]}
+
+		if n, err := strconv.Atoi(strings.TrimRight(m[2], ".)")); err == nil {
+			item.listType = "number"
+			item.number = n
+		} else if cm := mdCheckRE.FindStringSubmatch(m[3]); cm != nil {
+			item.listType = "check"
+			item.checked = cm[1] != " "
+			item.text = cm[2]
+		}
+
+		listItems = append(listItems, item)
+	}
+
+	var lists []any
+
+	for pos := 0; pos < len(listItems); {
+		var list any
+		list, pos = buildMarkdownList(listItems, pos)
+		lists = append(lists, list)
+	}
+
+	return lists, i
+}
+
+// buildMarkdownList builds a list from the items at the indent of the first, nesting more indented items.
+func buildMarkdownList(listItems []markdownListItem, pos int) (any, int) {
+	first := listItems[pos]
+
+	var children []any
+
+	value := 1
+
+	for pos < len(listItems) {
+		item := listItems[pos]
+
+		if item.indent < first.indent {
+			break
+		}
+
+		if item.indent > first.indent {
+			var nested any
+			nested, pos = buildMarkdownList(listItems, pos)
+
+			holder := lexicalElement("listitem", []any{nested})
+			holder["value"] = value
+			children = append(children, holder)
+
+			continue
+		}
+
+		if item.listType != first.listType {
+			break
+		}
+
+		li := lexicalElement("listitem", parseMarkdownInline(item.text, 0))
+		li["value"] = value
+
+		if item.listType == "check" {
+			li["checked"] = item.checked
+		}
+
+		children = append(children, li)
+		value++
+		pos++
+	}
+
+	list := lexicalElement("list", children)
+	list["listType"] = first.listType
+	list["start"] = max(first.number, 1)
+	list["tag"] = "ul"
+
+	if first.listType == "number" {
+		list["tag"] = "ol"
+	}
+
+	return list, pos
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isASCIIAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+var markdownDelimiters = []struct {
+	delim string
+	flag  int
+}{
+	{"**", lexicalBold},
+	{"__", lexicalBold},
+	{"~~", lexicalStrikethrough},
+	{"==", lexicalHighlight},
+	{"*", lexicalItalic},
+	{"_", lexicalItalic},
+}
+
+// parseMarkdownInline converts inline Markdown to Lexical text, link and line break nodes.
+func parseMarkdownInline(s string, format int) []any {
+	nodes := []any{}
+
+	var buf strings.Builder
+
+	flush := func() {
+		if buf.Len() > 0 {
+			nodes = append(nodes, lexicalText("text", buf.String(), format))
+			buf.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			buf.WriteByte(s[i+1])
+			i += 2
+
+			continue
+		case c == '\n':
+			flush()
+			nodes = append(nodes, lexicalLineBreak())
+			i++
+
+			continue
+		case c == '`':
+			n := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+			fence := s[i : i+n]
+
+			if end := strings.Index(s[i+n:], fence); end >= 0 {
+				code := s[i+n : i+n+end]
+				if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+					code = code[1 : len(code)-1]
+				}
+
+				flush()
+				nodes = append(nodes, lexicalText("text", code, format|lexicalCode))
+				i += n + end + n
+
+				continue
+			}
+
+			buf.WriteString(fence)
+			i += n
+
+			continue
+		case strings.HasPrefix(s[i:], "[["):
+			if end := strings.Index(s[i+2:], "]]"); end >= 0 && markdownUUIDRE.MatchString(s[i+2:i+2+end]) {
+				flush()
+				nodes = append(nodes, map[string]any{"type": superBubbleNodeType, "itemUuid": s[i+2 : i+2+end], "version": 1})
+				i += end + 4
+
+				continue
+			}
+		case c == '[':
+			if text, url, n := parseMarkdownLink(s[i:]); n > 0 {
+				flush()
+
+				link := lexicalElement("link", parseMarkdownInline(text, format))
+				link["url"] = url
+				link["rel"] = "noreferrer"
+				link["target"] = nil
+				link["title"] = nil
+				nodes = append(nodes, link)
+				i += n
+
+				continue
+			}
+		case c == '<':
+			if m := mdAutolinkRE.FindStringSubmatch(s[i:]); m != nil {
+				flush()
+
+				link := lexicalElement("link", []any{lexicalText("text", m[1], format)})
+				link["url"] = m[1]
+				link["rel"] = "noreferrer"
+				link["target"] = nil
+				link["title"] = nil
+				nodes = append(nodes, link)
+				i += len(m[0])
+
+				continue
+			}
+		}
+
+		if n, flag := markdownDelimiter(s, i, format); n > 0 {
+			flush()
+
+			format ^= flag
+			i += n
+
+			continue
+		}
+
+		buf.WriteByte(c)
+		i++
+	}
+
+	flush()
+
+	return nodes
+}
+
+// markdownDelimiter returns the length and flag of an emphasis delimiter at s[i] that opens or closes formatting.
+func markdownDelimiter(s string, i int, format int) (int, int) {
+	for _, d := range markdownDelimiters {
+		if !strings.HasPrefix(s[i:], d.delim) {
+			continue
+		}
+
+		end := i + len(d.delim)
+
+		// underscores within words are not delimiters
+		if d.delim[0] == '_' && ((i > 0 && isASCIIAlnum(s[i-1])) && (end < len(s) && isASCIIAlnum(s[end]))) {
+			return 0, 0
+		}
+
+		if format&d.flag != 0 {
+			// closing
+			if i > 0 && s[i-1] != ' ' {
+				return len(d.delim), d.flag
+			}
+
+			return 0, 0
+		}
+
+		// opening, which requires a following non-space character and a closing delimiter
+		if end < len(s) && s[end] != ' ' && strings.Contains(s[end+1:], d.delim) {
+			return len(d.delim), d.flag
+		}
+
+		return 0, 0
+	}
+
+	return 0, 0
+}
+
+// parseMarkdownLink parses a [text](url) link at the start of s, returning its length, or 0 if there is none.
+func parseMarkdownLink(s string) (text, url string, n int) {
+	depth := 0
+
+	for x := 0; x < len(s); x++ {
+		switch s[x] {
+		case '\\':
+			x++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+
+			if x+1 >= len(s) || s[x+1] != '(' {
+				return "", "", 0
+			}
+
+			end := strings.IndexByte(s[x+2:], ')')
+			if end < 0 {
+				return "", "", 0
+			}
+
+			dest := strings.Fields(s[x+2 : x+2+end])
+			if len(dest) == 0 {
+				return "", "", 0
+			}
+
+			return s[1:x], strings.Trim(dest[0], "<>"), x + 3 + end
+		case '\n':
+			return "", "", 0
+		}
+	}
+
+	return "", "", 0
+}
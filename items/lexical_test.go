@@ -0,0 +1,186 @@
+package items
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// superTestState is Lexical editor state as written by the Super editor.
+const superTestState = `{"root":{"children":[
+{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"Plan","type":"text","version":1}],"direction":"ltr","format":"","indent":0,"type":"heading","version":1,"tag":"h2"},
+{"children":[
+	{"detail":0,"format":0,"mode":"normal","style":"","text":"Some ","type":"text","version":1},
+	{"detail":0,"format":1,"mode":"normal","style":"","text":"bold","type":"text","version":1},
+	{"detail":0,"format":0,"mode":"normal","style":"","text":", ","type":"text","version":1},
+	{"detail":0,"format":2,"mode":"normal","style":"","text":"italic ","type":"text","version":1},
+	{"detail":0,"format":16,"mode":"normal","style":"","text":"code","type":"text","version":1},
+	{"detail":0,"format":0,"mode":"normal","style":"","text":" and a ","type":"text","version":1},
+	{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"link","type":"text","version":1}],"direction":"ltr","format":"","indent":0,"type":"link","version":1,"rel":"noreferrer","target":null,"title":null,"url":"https://example.com/a b"},
+	{"type":"linebreak","version":1},
+	{"detail":0,"format":0,"mode":"normal","style":"","text":"1. not a list <b> *","type":"text","version":1}
+],"direction":"ltr","format":"","indent":0,"type":"paragraph","version":1},
+{"children":[
+	{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"one","type":"text","version":1}],"direction":"ltr","format":"","indent":0,"type":"listitem","version":1,"value":1},
+	{"children":[{"children":[
+		{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"nested","type":"text","version":1}],"direction":"ltr","format":"","indent":1,"type":"listitem","version":1,"value":1}
+	],"direction":"ltr","format":"","indent":0,"type":"list","version":1,"listType":"bullet","start":1,"tag":"ul"}],"direction":"ltr","format":"","indent":0,"type":"listitem","version":1,"value":2},
+	{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"two","type":"text","version":1}],"direction":"ltr","format":"","indent":0,"type":"listitem","version":1,"value":2}
+],"direction":"ltr","format":"","indent":0,"type":"list","version":1,"listType":"number","start":1,"tag":"ol"},
+{"children":[
+	{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"done","type":"text","version":1}],"direction":"ltr","format":"","indent":0,"type":"listitem","version":1,"value":1,"checked":true},
+	{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"todo","type":"text","version":1}],"direction":"ltr","format":"","indent":0,"type":"listitem","version":1,"value":2,"checked":false}
+],"direction":"ltr","format":"","indent":0,"type":"list","version":1,"listType":"check","start":1,"tag":"ul"},
+{"children":[
+	{"detail":0,"format":0,"mode":"normal","style":"","text":"fmt.Println(\"hi\")","type":"code-highlight","version":1},
+	{"type":"linebreak","version":1},
+	{"detail":0,"format":0,"mode":"normal","style":"","text":"return","type":"code-highlight","version":1,"highlightType":"keyword"}
+],"direction":"ltr","format":"","indent":0,"type":"code","version":1,"language":"go"},
+{"children":[
+	{"children":[
+		{"children":[{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"Name","type":"text","version":1}],"direction":"ltr","format":"","indent":0,"type":"paragraph","version":1}],"direction":"ltr","format":"","indent":0,"type":"tablecell","version":1,"colSpan":1,"rowSpan":1,"backgroundColor":null,"headerState":1},
+		{"children":[{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"Value","type":"text","version":1}],"direction":"ltr","format":"","indent":0,"type":"paragraph","version":1}],"direction":"ltr","format":"","indent":0,"type":"tablecell","version":1,"colSpan":1,"rowSpan":1,"backgroundColor":null,"headerState":1}
+	],"direction":"ltr","format":"","indent":0,"type":"tablerow","version":1},
+	{"children":[
+		{"children":[{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"a|b","type":"text","version":1}],"direction":"ltr","format":"","indent":0,"type":"paragraph","version":1}],"direction":"ltr","format":"","indent":0,"type":"tablecell","version":1,"colSpan":1,"rowSpan":1,"backgroundColor":null,"headerState":0},
+		{"children":[{"children":[],"direction":null,"format":"","indent":0,"type":"paragraph","version":1}],"direction":"ltr","format":"","indent":0,"type":"tablecell","version":1,"colSpan":1,"rowSpan":1,"backgroundColor":null,"headerState":0}
+	],"direction":"ltr","format":"","indent":0,"type":"tablerow","version":1}
+],"direction":"ltr","format":"","indent":0,"type":"table","version":1},
+{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"quoted","type":"text","version":1}],"direction":"ltr","format":"","indent":0,"type":"quote","version":1},
+{"type":"horizontalrule","version":1},
+{"children":[{"type":"snbubble","itemUuid":"3f1b6bd6-8b8a-4b7e-9f44-8b8d1a6c0c11","version":1}],"direction":null,"format":"","indent":0,"type":"paragraph","version":1},
+{"children":[],"direction":null,"format":"","indent":0,"type":"paragraph","version":1}
+],"direction":"ltr","format":"","indent":0,"type":"root","version":1}}`
+
+const superTestMarkdown = "## Plan\n\n" +
+	"Some **bold**, *italic* `code` and a [link](https://example.com/a%20b)\n" +
+	"1\\. not a list \\<b> \\*\n\n" +
+	"1. one\n   - nested\n2. two\n\n" +
+	"- [x] done\n- [ ] todo\n\n" +
+	"```go\nfmt.Println(\"hi\")\nreturn\n```\n\n" +
+	"| Name | Value |\n| --- | --- |\n| a\\|b |  |\n\n" +
+	"> quoted\n\n" +
+	"---\n\n" +
+	"[[3f1b6bd6-8b8a-4b7e-9f44-8b8d1a6c0c11]]"
+
+func TestLexicalToMarkdown(t *testing.T) {
+	md, err := LexicalToMarkdown(superTestState)
+	require.NoError(t, err)
+	require.Equal(t, superTestMarkdown, md)
+
+	md, err = LexicalToMarkdown("")
+	require.NoError(t, err)
+	require.Empty(t, md)
+
+	_, err = LexicalToMarkdown("not json")
+	require.ErrorIs(t, err, ErrInvalidLexical)
+
+	_, err = LexicalToMarkdown(`{"editorState":{}}`)
+	require.ErrorIs(t, err, ErrInvalidLexical)
+}
+
+func TestLexicalToHTML(t *testing.T) {
+	h, err := LexicalToHTML(superTestState)
+	require.NoError(t, err)
+	require.Equal(t, `<h2>Plan</h2>`+
+		`<p>Some <strong>bold</strong>, <em>italic </em><code>code</code> and a <a href="https://example.com/a b">link</a><br>1. not a list &lt;b&gt; *</p>`+
+		`<ol><li>one</li><li><ul><li>nested</li></ul></li><li>two</li></ol>`+
+		`<ul class="checklist"><li><input type="checkbox" checked disabled> done</li><li><input type="checkbox" disabled> todo</li></ul>`+
+		`<pre><code class="language-go">fmt.Println(&#34;hi&#34;)`+"\n"+`return</code></pre>`+
+		`<table><tr><th>Name</th><th>Value</th></tr><tr><td>a|b</td><td></td></tr></table>`+
+		`<blockquote>quoted</blockquote>`+
+		`<hr>`+
+		`<p><span data-item-uuid="3f1b6bd6-8b8a-4b7e-9f44-8b8d1a6c0c11">[[3f1b6bd6-8b8a-4b7e-9f44-8b8d1a6c0c11]]</span></p>`+
+		`<p></p>`, h)
+
+	h, err = LexicalToHTML(MarkdownToLexical("[x](javascript:alert(1))"))
+	require.NoError(t, err)
+	require.Equal(t, `<p><a href="#">x</a>)</p>`, h)
+}
+
+func TestLexicalToPlainText(t *testing.T) {
+	plain, err := LexicalToPlainText(superTestState)
+	require.NoError(t, err)
+	require.Equal(t, "Plan\nSome bold, italic code and a link\n1. not a list <b> *\none\nnested\ntwo\ndone\ntodo\n"+
+		"fmt.Println(\"hi\")\nreturn\nName\tValue\na|b\t\nquoted", plain)
+}
+
+func TestMarkdownToLexicalRoundTrip(t *testing.T) {
+	state := MarkdownToLexical(superTestMarkdown)
+
+	md, err := LexicalToMarkdown(state)
+	require.NoError(t, err)
+	require.Equal(t, superTestMarkdown, md)
+
+	for _, in := range []string{
+		"# Heading\n\nA paragraph with ~~strike~~, ==highlight== and ***both***",
+		"1. a\n2. b\n   1. c\n   2. d\n3. e\n\n- bullet",
+		"3. starts at three\n4. four",
+		"- [ ] top\n  - [x] nested check",
+		"````\ncode with ``` fence\n````",
+		"[**bold link**](https://example.com)",
+		`Not a \[\[wiki link\]\] and \[brackets\]`,
+		"| a |\n| --- |\n| **b** |",
+	} {
+		md, err = LexicalToMarkdown(MarkdownToLexical(in))
+		require.NoError(t, err, in)
+		require.Equal(t, in, md, in)
+	}
+}
+
+func TestMarkdownToLexical(t *testing.T) {
+	var state struct {
+		Root lexicalNode `json:"root"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(MarkdownToLexical("Title\n---\n\n* a\n+ b\n\n<https://example.com>\n\n~~~\nx\n~~~")), &state))
+
+	root := state.Root
+	require.Equal(t, "root", root.Type)
+	require.Len(t, root.Children, 5)
+
+	// setext headings are not supported, so are read as a paragraph followed by a rule
+	require.Equal(t, "paragraph", root.Children[0].Type)
+	require.Equal(t, "horizontalrule", root.Children[1].Type)
+
+	list := root.Children[2]
+	require.Equal(t, "list", list.Type)
+	require.Equal(t, "bullet", list.ListType)
+	require.Len(t, list.Children, 2)
+
+	link := root.Children[3].Children[0]
+	require.Equal(t, "link", link.Type)
+	require.Equal(t, "https://example.com", link.URL)
+
+	code := root.Children[4]
+	require.Equal(t, "code", code.Type)
+	require.Equal(t, "x", code.Children[0].Text)
+
+	// delimiters within words or surrounded by spaces are literal
+	plain, err := LexicalToPlainText(MarkdownToLexical("snake_case and 2 * 3 * 4"))
+	require.NoError(t, err)
+	require.Equal(t, "snake_case and 2 * 3 * 4", plain)
+}
+
+func TestNoteSuperMarkdown(t *testing.T) {
+	note, err := NewSuperNote("Super", "# Hello\n\n- [ ] item", nil)
+	require.NoError(t, err)
+	require.True(t, note.Content.IsSuper())
+	require.Equal(t, SuperEditorIdentifier, note.Content.EditorIdentifier)
+	require.Equal(t, "Hello item", note.Content.PreviewPlain)
+	require.Equal(t, `<h1>Hello</h1><ul class="checklist"><li><input type="checkbox" disabled> item</li></ul>`, note.Content.PreviewHtml)
+
+	md, err := note.Content.GetMarkdown()
+	require.NoError(t, err)
+	require.Equal(t, "# Hello\n\n- [ ] item", md)
+
+	plain := createNote("Plain", "# not converted", "")
+	md, err = plain.Content.GetMarkdown()
+	require.NoError(t, err)
+	require.Equal(t, "# not converted", md)
+
+	plain.Content.Text = "{"
+	plain.Content.NoteType = SuperNoteType
+	require.ErrorIs(t, plain.Content.UpdateSuperPreviews(), ErrInvalidLexical)
+}
@@ -0,0 +1,324 @@
+package items
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jonhadfield/gosn-v2/common"
+)
+
+const (
+	// NoteToNoteReferenceType is the reference type a note uses to link to another note.
+	NoteToNoteReferenceType = "NoteToNote"
+	// superBubbleNodeType is the Lexical node type the Super editor uses to link to another item.
+	superBubbleNodeType = "snbubble"
+)
+
+// LinkSource describes where a link between notes was found.
+type LinkSource string
+
+const (
+	// LinkSourceReference is a link found in a note's ItemReferences.
+	LinkSourceReference LinkSource = "reference"
+	// LinkSourceWikiLink is a [[Title]] or [[uuid]] link found in a note's text.
+	LinkSourceWikiLink LinkSource = "wikilink"
+	// LinkSourceSuper is a note link embedded in a Super note.
+	LinkSourceSuper LinkSource = "super"
+)
+
+// wikiLinkRE matches [[target]], [[target|alias]] and [[target#heading]] links.
+var wikiLinkRE = regexp.MustCompile(`\[\[([^\[\]\n]+?)\]\]`)
+
+// Link is a link from one note to another. Broken links have an empty To.
+type Link struct {
+	From string `json:"from"`
+	To   string `json:"to,omitempty"`
+	// Target is the text of a wiki link, or the UUID referenced, as written in the source note.
+	Target string     `json:"target"`
+	Source LinkSource `json:"source"`
+}
+
+// LinkGraph is the graph of links between notes, found in their references and text.
+type LinkGraph struct {
+	notes    Notes
+	byUUID   map[string]*Note
+	outgoing map[string][]Link
+	incoming map[string][]Link
+	broken   []Link
+}
+
+// LinkGraph returns the graph of links between the items' notes.
+func (i Items) LinkGraph() *LinkGraph {
+	return NewLinkGraph(i.Notes())
+}
+
+// NewLinkGraph returns the graph of links between the non-deleted notes provided.
+// Wiki links are resolved by UUID and then by case-insensitive title, with the first
+// note provided taking precedence where titles are shared.
+func NewLinkGraph(notes Notes) *LinkGraph {
+	g := &LinkGraph{
+		byUUID:   make(map[string]*Note, len(notes)),
+		outgoing: make(map[string][]Link),
+		incoming: make(map[string][]Link),
+	}
+
+	byTitle := make(map[string]*Note)
+
+	for x := range notes {
+		if notes[x].Deleted {
+			continue
+		}
+
+		n := &notes[x]
+		g.notes = append(g.notes, *n)
+		g.byUUID[n.UUID] = n
+
+		title := strings.ToLower(strings.TrimSpace(n.Content.Title))
+		if _, ok := byTitle[title]; !ok && title != "" {
+			byTitle[title] = n
+		}
+	}
+
+	for _, n := range g.notes {
+		seen := make(map[Link]bool)
+
+		for _, l := range noteLinks(n) {
+			target, ok := g.byUUID[l.Target]
+			if !ok && l.Source == LinkSourceWikiLink {
+				target, ok = byTitle[strings.ToLower(l.Target)]
+			}
+
+			if ok {
+				if target.UUID == n.UUID {
+					continue
+				}
+
+				l.To = target.UUID
+			}
+
+			if seen[l] {
+				continue
+			}
+
+			seen[l] = true
+
+			if l.To == "" {
+				g.broken = append(g.broken, l)
+			} else {
+				g.incoming[l.To] = append(g.incoming[l.To], l)
+			}
+
+			g.outgoing[n.UUID] = append(g.outgoing[n.UUID], l)
+		}
+	}
+
+	return g
+}
+
+// noteLinks returns the unresolved links from the note's references and text.
+func noteLinks(n Note) []Link {
+	var links []Link
+
+	for _, ref := range n.Content.ItemReferences {
+		if ref.ContentType == common.SNItemTypeNote {
+			links = append(links, Link{From: n.UUID, Target: ref.UUID, Source: LinkSourceReference})
+		}
+	}
+
+	text := n.Content.Text
+
+	if isSuperNote(n.Content) {
+		var root any
+		if err := json.Unmarshal([]byte(text), &root); err == nil {
+			var sb strings.Builder
+
+			walkLexical(root, func(node map[string]any) {
+				switch node["type"] {
+				case superBubbleNodeType:
+					if uuid, ok := node["itemUuid"].(string); ok && uuid != "" {
+						links = append(links, Link{From: n.UUID, Target: uuid, Source: LinkSourceSuper})
+					}
+				case "text":
+					if s, ok := node["text"].(string); ok {
+						sb.WriteString(s)
+					}
+				case "linebreak", "paragraph":
+					sb.WriteString("\n")
+				}
+			})
+
+			text = sb.String()
+		}
+	}
+
+	for _, m := range wikiLinkRE.FindAllStringSubmatch(text, -1) {
+		target, _, _ := strings.Cut(m[1], "|")
+		target, _, _ = strings.Cut(target, "#")
+
+		if target = strings.TrimSpace(target); target != "" {
+			links = append(links, Link{From: n.UUID, Target: target, Source: LinkSourceWikiLink})
+		}
+	}
+
+	return links
+}
+
+// isSuperNote returns true if the note's text is Lexical JSON written by the Super editor.
+func isSuperNote(c NoteContent) bool {
+	if c.IsSuper() {
+		return true
+	}
+
+	text := strings.TrimSpace(c.Text)
+
+	return strings.HasPrefix(text, "{") && strings.Contains(text, `"root"`)
+}
+
+// walkLexical calls fn for each node in decoded Lexical JSON, depth first.
+func walkLexical(v any, fn func(node map[string]any)) {
+	switch x := v.(type) {
+	case map[string]any:
+		if _, ok := x["type"]; ok {
+			fn(x)
+		}
+
+		if root, ok := x["root"]; ok {
+			walkLexical(root, fn)
+		}
+
+		if children, ok := x["children"]; ok {
+			walkLexical(children, fn)
+		}
+	case []any:
+		for _, c := range x {
+			walkLexical(c, fn)
+		}
+	}
+}
+
+// Notes returns the notes in the graph in the order they were provided.
+func (g *LinkGraph) Notes() Notes {
+	return g.notes
+}
+
+// Outgoing returns the links from the note, including broken links.
+func (g *LinkGraph) Outgoing(uuid string) []Link {
+	return g.outgoing[uuid]
+}
+
+// Incoming returns the links to the note from other notes, i.e. its backlinks.
+func (g *LinkGraph) Incoming(uuid string) []Link {
+	return g.incoming[uuid]
+}
+
+// Broken returns the links whose target could not be found.
+func (g *LinkGraph) Broken() []Link {
+	return g.broken
+}
+
+// Orphans returns the notes with no links to or from other notes.
+func (g *LinkGraph) Orphans() Notes {
+	var orphans Notes
+
+	for _, n := range g.notes {
+		if len(g.incoming[n.UUID]) > 0 {
+			continue
+		}
+
+		linked := false
+
+		for _, l := range g.outgoing[n.UUID] {
+			if l.To != "" {
+				linked = true
+
+				break
+			}
+		}
+
+		if !linked {
+			orphans = append(orphans, n)
+		}
+	}
+
+	return orphans
+}
+
+// Links returns every link in the graph, ordered by source note.
+func (g *LinkGraph) Links() []Link {
+	var links []Link
+
+	for _, n := range g.notes {
+		links = append(links, g.outgoing[n.UUID]...)
+	}
+
+	return links
+}
+
+type linkGraphNode struct {
+	UUID  string `json:"uuid"`
+	Title string `json:"title"`
+}
+
+// MarshalJSON encodes the graph as its nodes, with their UUIDs and titles, and links.
+func (g *LinkGraph) MarshalJSON() ([]byte, error) {
+	nodes := make([]linkGraphNode, 0, len(g.notes))
+	for _, n := range g.notes {
+		nodes = append(nodes, linkGraphNode{UUID: n.UUID, Title: n.Content.Title})
+	}
+
+	links := g.Links()
+	if links == nil {
+		links = []Link{}
+	}
+
+	return json.Marshal(struct {
+		Nodes []linkGraphNode `json:"nodes"`
+		Links []Link          `json:"links"`
+	}{nodes, links})
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// DOT returns the graph in Graphviz DOT format. Broken links point to dashed nodes labelled with their target.
+func (g *LinkGraph) DOT() string {
+	var sb strings.Builder
+
+	sb.WriteString("digraph notes {\n")
+
+	for _, n := range g.notes {
+		sb.WriteString(fmt.Sprintf("\t%s [label=%s];\n", dotQuote(n.UUID), dotQuote(n.Content.Title)))
+	}
+
+	missing := make(map[string]bool)
+
+	for _, l := range g.broken {
+		if !missing[l.Target] {
+			missing[l.Target] = true
+			sb.WriteString(fmt.Sprintf("\t%s [label=%s, style=dashed];\n", dotQuote("missing:"+l.Target), dotQuote(l.Target)))
+		}
+	}
+
+	edges := make(map[[2]string]bool)
+
+	for _, l := range g.Links() {
+		to, attrs := l.To, ""
+		if to == "" {
+			to, attrs = "missing:"+l.Target, " [style=dashed]"
+		}
+
+		if edges[[2]string{l.From, to}] {
+			continue
+		}
+
+		edges[[2]string{l.From, to}] = true
+		sb.WriteString(fmt.Sprintf("\t%s -> %s%s;\n", dotQuote(l.From), dotQuote(to), attrs))
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
@@ -0,0 +1,99 @@
+package items
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/stretchr/testify/require"
+)
+
+func linkGraphTestItems() Items {
+	index := createNote("Index", "See [[Kubernetes]], [[rfc|the RFC]] and [[Missing page#intro]].", "index")
+	index.Content.ItemReferences = ItemReferences{
+		{UUID: "rfc", ContentType: common.SNItemTypeNote, ReferenceType: NoteToNoteReferenceType},
+		{UUID: "gone", ContentType: common.SNItemTypeNote, ReferenceType: NoteToNoteReferenceType},
+		{UUID: "tag", ContentType: common.SNItemTypeTag},
+	}
+
+	k8s := createNote("kubernetes", "", "k8s")
+	k8s.Content.NoteType = SuperNoteType
+	k8s.Content.Text = `{"root":{"type":"root","children":[
+		{"type":"paragraph","children":[{"type":"text","text":"Back to [[index]]"},{"type":"snbubble","itemUuid":"rfc","version":1}]},
+		{"type":"paragraph","children":[{"type":"text","text":"self [[kubernetes]]"}]}
+	]}}`
+
+	deleted := createNote("Deleted", "[[Index]]", "deleted")
+	deleted.Deleted = true
+
+	return Items{
+		index,
+		k8s,
+		createNote("RFC", "", "rfc"),
+		createNote("Lonely", "[[Lonely]] [[]]", "lonely"),
+		deleted,
+	}
+}
+
+func TestLinkGraph(t *testing.T) {
+	g := linkGraphTestItems().LinkGraph()
+	require.Len(t, g.Notes(), 4)
+
+	require.Equal(t, []Link{
+		{From: "index", To: "rfc", Target: "rfc", Source: LinkSourceReference},
+		{From: "index", Target: "gone", Source: LinkSourceReference},
+		{From: "index", To: "k8s", Target: "Kubernetes", Source: LinkSourceWikiLink},
+		{From: "index", To: "rfc", Target: "rfc", Source: LinkSourceWikiLink},
+		{From: "index", Target: "Missing page", Source: LinkSourceWikiLink},
+	}, g.Outgoing("index"))
+
+	require.Equal(t, []Link{
+		{From: "k8s", To: "rfc", Target: "rfc", Source: LinkSourceSuper},
+		{From: "k8s", To: "index", Target: "index", Source: LinkSourceWikiLink},
+	}, g.Outgoing("k8s"))
+
+	require.Equal(t, []Link{{From: "k8s", To: "index", Target: "index", Source: LinkSourceWikiLink}}, g.Incoming("index"))
+	require.Len(t, g.Incoming("rfc"), 3)
+	require.Empty(t, g.Incoming("lonely"))
+
+	require.Equal(t, []Link{
+		{From: "index", Target: "gone", Source: LinkSourceReference},
+		{From: "index", Target: "Missing page", Source: LinkSourceWikiLink},
+	}, g.Broken())
+
+	orphans := g.Orphans()
+	require.Len(t, orphans, 1)
+	require.Equal(t, "lonely", orphans[0].UUID)
+}
+
+func TestLinkGraphExport(t *testing.T) {
+	g := Items{
+		createNote(`Say "hi"`, "[[b]] [[nowhere]]", "a"),
+		createNote("B", "[[a]]", "b"),
+	}.LinkGraph()
+
+	require.Equal(t, `digraph notes {
+	"a" [label="Say \"hi\""];
+	"b" [label="B"];
+	"missing:nowhere" [label="nowhere", style=dashed];
+	"a" -> "b";
+	"a" -> "missing:nowhere" [style=dashed];
+	"b" -> "a";
+}
+`, g.DOT())
+
+	b, err := json.Marshal(g)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"nodes": [{"uuid":"a","title":"Say \"hi\""},{"uuid":"b","title":"B"}],
+		"links": [
+			{"from":"a","to":"b","target":"b","source":"wikilink"},
+			{"from":"a","target":"nowhere","source":"wikilink"},
+			{"from":"b","to":"a","target":"a","source":"wikilink"}
+		]
+	}`, string(b))
+
+	b, err = json.Marshal(Items{}.LinkGraph())
+	require.NoError(t, err)
+	require.JSONEq(t, `{"nodes":[],"links":[]}`, string(b))
+}
@@ -0,0 +1,554 @@
+package items
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jonhadfield/gosn-v2/common"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	// MarkdownFileExtension is the extension of the files written by ExportMarkdown.
+	MarkdownFileExtension = ".md"
+	// markdownFrontMatterDelimiter opens and closes the YAML front matter of an exported note.
+	markdownFrontMatterDelimiter = "---"
+	// maxMarkdownFileNameLength is the maximum length, in bytes, of a file or folder name
+	// derived from a title, leaving room for a de-duplicating suffix and the extension.
+	maxMarkdownFileNameLength = 200
+	untitledMarkdownFileName  = "Untitled"
+)
+
+var (
+	// ErrInvalidFrontMatter is returned when a Markdown file's front matter cannot be parsed.
+	ErrInvalidFrontMatter = errors.New("invalid front matter")
+	// ErrDuplicateMarkdownUUID is returned when more than one Markdown file has the same UUID.
+	ErrDuplicateMarkdownUUID = errors.New("uuid found in more than one file")
+)
+
+// MarkdownFrontMatter is the YAML front matter written at the start of each exported note.
+type MarkdownFrontMatter struct {
+	UUID  string `yaml:"uuid,omitempty"`
+	Title string `yaml:"title,omitempty"`
+	// Tags are the paths of the note's tags, e.g. "work/projects/alpha".
+	Tags     []string  `yaml:"tags,omitempty"`
+	Created  time.Time `yaml:"created,omitempty"`
+	Updated  time.Time `yaml:"updated,omitempty"`
+	Pinned   bool      `yaml:"pinned,omitempty"`
+	Archived bool      `yaml:"archived,omitempty"`
+	Editor   string    `yaml:"editor,omitempty"`
+}
+
+// MarkdownFile is a note read from, or to be written to, a Markdown file.
+type MarkdownFile struct {
+	// Path is relative to the export directory and uses forward slashes.
+	Path        string
+	FrontMatter MarkdownFrontMatter
+	// HasTags is true if the front matter specifies the note's tags, even if there are none.
+	HasTags bool
+	Body    string
+}
+
+// ExportMarkdown writes each of the items' non-deleted, non-trashed notes to dir as
+// <tag path>/<title>.md, with the note's metadata in YAML front matter. Notes are placed
+// in the folder of the first of their tags, ordered by path, and all tags are listed in the
+// front matter. Super notes are converted to Markdown.
+// Files previously exported for the notes are replaced, and other files are left untouched.
+func (i Items) ExportMarkdown(dir string) error {
+	files, err := i.MarkdownFiles()
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("ExportMarkdown | %w", err)
+	}
+
+	existing, err := ReadMarkdownDir(dir)
+	if err != nil {
+		return fmt.Errorf("ExportMarkdown | %w", err)
+	}
+
+	exported := make(map[string]bool, len(files))
+	for _, f := range files {
+		exported[f.Path] = true
+	}
+
+	notes := make(map[string]bool)
+	for _, n := range i.Notes() {
+		notes[n.UUID] = true
+	}
+
+	// remove files exported for the notes before they were renamed or retagged
+	for _, f := range existing {
+		if notes[f.FrontMatter.UUID] && !exported[f.Path] {
+			if err = os.Remove(filepath.Join(dir, filepath.FromSlash(f.Path))); err != nil {
+				return fmt.Errorf("ExportMarkdown | %w", err)
+			}
+		}
+	}
+
+	for _, f := range files {
+		b, err := f.Marshal()
+		if err != nil {
+			return fmt.Errorf("ExportMarkdown | %w", err)
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(f.Path))
+
+		if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return fmt.Errorf("ExportMarkdown | %w", err)
+		}
+
+		if err = os.WriteFile(path, b, 0o600); err != nil {
+			return fmt.Errorf("ExportMarkdown | %w", err)
+		}
+	}
+
+	return nil
+}
+
+// MarkdownFiles returns the files ExportMarkdown writes for the items' notes, without writing them.
+func (i Items) MarkdownFiles() ([]MarkdownFile, error) {
+	tt := i.TagTree()
+
+	noteTags := make(map[string][]*TagNode)
+
+	tt.Walk(func(n *TagNode) {
+		for _, ref := range n.Tag.Content.ItemReferences {
+			if ref.ContentType == common.SNItemTypeNote {
+				noteTags[ref.UUID] = append(noteTags[ref.UUID], n)
+			}
+		}
+	})
+
+	var files []MarkdownFile
+
+	used := make(map[string]bool)
+
+	for _, n := range i.Notes() {
+		if n.Deleted || n.Content.GetTrashed() {
+			continue
+		}
+
+		body, err := n.Content.GetMarkdown()
+		if err != nil {
+			return nil, fmt.Errorf("MarkdownFiles | %s | %w", n.UUID, err)
+		}
+
+		tags := noteTags[n.UUID]
+		slices.SortStableFunc(tags, func(a, b *TagNode) int {
+			return strings.Compare(a.Path(), b.Path())
+		})
+
+		fm := MarkdownFrontMatter{
+			UUID:     n.UUID,
+			Title:    n.Content.Title,
+			Created:  noteCreated(n),
+			Updated:  noteUpdated(n),
+			Pinned:   n.IsPinned(),
+			Archived: n.IsArchived(),
+			Editor:   n.Content.EditorIdentifier,
+		}
+
+		var folder string
+
+		for x, t := range tags {
+			if x == 0 {
+				folder = markdownFolder(t)
+			}
+
+			fm.Tags = append(fm.Tags, t.Path())
+		}
+
+		files = append(files, MarkdownFile{
+			Path:        uniqueMarkdownPath(folder, n.Content.Title, used),
+			FrontMatter: fm,
+			HasTags:     true,
+			Body:        body,
+		})
+	}
+
+	return files, nil
+}
+
+func noteCreated(n Note) time.Time {
+	if n.CreatedAtTimestamp > 0 {
+		return time.UnixMicro(n.CreatedAtTimestamp).UTC()
+	}
+
+	created, _ := time.Parse(common.TimeLayout, n.CreatedAt)
+
+	return created
+}
+
+func noteUpdated(n Note) time.Time {
+	if updated, err := n.Content.GetUpdateTime(); err == nil {
+		return updated.UTC()
+	}
+
+	if n.UpdatedAtTimestamp > 0 {
+		return time.UnixMicro(n.UpdatedAtTimestamp).UTC()
+	}
+
+	return time.Time{}
+}
+
+// markdownFolder returns the folder for a tag, with a path segment for each of its ancestors.
+func markdownFolder(n *TagNode) string {
+	var segments []string
+
+	for x := n; x != nil; x = x.Parent {
+		segments = append(segments, markdownFileName(x.Tag.Content.Title))
+	}
+
+	slices.Reverse(segments)
+
+	return strings.Join(segments, "/")
+}
+
+// markdownFileName returns the title with characters that are not valid in file names replaced.
+func markdownFileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+
+		return r
+	}, title)
+
+	// leading dots would hide the file and trailing dots and spaces are dropped by Windows
+	name = strings.Trim(name, ". ")
+
+	if len(name) > maxMarkdownFileNameLength {
+		cut := maxMarkdownFileNameLength
+		for cut > 0 && !utf8.RuneStart(name[cut]) {
+			cut--
+		}
+
+		name = strings.TrimRight(name[:cut], ". ")
+	}
+
+	if name == "" {
+		return untitledMarkdownFileName
+	}
+
+	return name
+}
+
+// uniqueMarkdownPath returns the path for a note in folder, adding a numeric suffix if a
+// file with the same name, ignoring case, has already been used.
+func uniqueMarkdownPath(folder, title string, used map[string]bool) string {
+	name := markdownFileName(title)
+
+	for x := 1; ; x++ {
+		file := name
+		if x > 1 {
+			file = fmt.Sprintf("%s (%d)", name, x)
+		}
+
+		path := file + MarkdownFileExtension
+		if folder != "" {
+			path = folder + "/" + path
+		}
+
+		if !used[strings.ToLower(path)] {
+			used[strings.ToLower(path)] = true
+
+			return path
+		}
+	}
+}
+
+// Marshal returns the file's front matter and body.
+func (f MarkdownFile) Marshal() ([]byte, error) {
+	fm, err := yaml.Marshal(f.FrontMatter)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+
+	b.WriteString(markdownFrontMatterDelimiter + "\n")
+	b.Write(fm)
+	b.WriteString(markdownFrontMatterDelimiter + "\n\n")
+	b.WriteString(f.Body)
+
+	// always end with a newline, which ParseMarkdownFile removes, so that the body is read back unchanged
+	if f.Body != "" {
+		b.WriteString("\n")
+	}
+
+	return b.Bytes(), nil
+}
+
+// ParseMarkdownFile parses a Markdown file with optional YAML front matter. The path is
+// relative to the export directory and, if the front matter does not include a title,
+// the file name is used.
+func ParseMarkdownFile(path string, b []byte) (MarkdownFile, error) {
+	f := MarkdownFile{Path: filepath.ToSlash(path)}
+
+	text := strings.ReplaceAll(string(b), "\r\n", "\n")
+	text = strings.TrimPrefix(text, "\ufeff")
+
+	if rest, ok := strings.CutPrefix(text, markdownFrontMatterDelimiter+"\n"); ok {
+		fm, body, found := strings.Cut(rest, "\n"+markdownFrontMatterDelimiter+"\n")
+		if !found {
+			fm, found = strings.CutSuffix(rest, "\n"+markdownFrontMatterDelimiter)
+		}
+
+		if !found {
+			return f, fmt.Errorf("%w: %s: front matter is not closed", ErrInvalidFrontMatter, f.Path)
+		}
+
+		if err := yaml.Unmarshal([]byte(fm), &f.FrontMatter); err != nil {
+			return f, fmt.Errorf("%w: %s: %w", ErrInvalidFrontMatter, f.Path, err)
+		}
+
+		var keys map[string]any
+		if err := yaml.Unmarshal([]byte(fm), &keys); err == nil {
+			_, f.HasTags = keys["tags"]
+		}
+
+		text = strings.TrimPrefix(body, "\n")
+	}
+
+	// remove only the newline added by Marshal, so that any others are part of the body
+	f.Body = strings.TrimSuffix(text, "\n")
+
+	if strings.TrimSpace(f.FrontMatter.Title) == "" {
+		f.FrontMatter.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	return f, nil
+}
+
+// ReadMarkdownDir returns the Markdown files in dir and its sub-directories, ignoring
+// hidden files and directories such as .git.
+func ReadMarkdownDir(dir string) ([]MarkdownFile, error) {
+	var files []MarkdownFile
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), MarkdownFileExtension) {
+			return nil
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		f, err := ParseMarkdownFile(rel, b)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, f)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ReadMarkdownDir | %w", err)
+	}
+
+	return files, nil
+}
+
+// ImportMarkdown reads the Markdown files in dir, as written by ExportMarkdown, and returns
+// the notes and tags that need to be created or updated to match them.
+// See ImportMarkdownFiles for how the files are matched to the existing items.
+func ImportMarkdown(dir string, existing Items) (Items, error) {
+	files, err := ReadMarkdownDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return ImportMarkdownFiles(files, existing)
+}
+
+// ImportMarkdownFiles returns the notes and tags that need to be created or updated so
+// that the existing items match the files.
+//
+// Notes are matched by the UUID in their front matter, and files without one, or whose
+// note no longer exists, create new notes. A note's tags are taken from its front matter
+// or, if the front matter does not list them, from the folder containing the file. Tags
+// are found by path and any that are missing are created, along with their parents.
+// Tags that reference a note no longer listed in its tags have the reference removed.
+// Notes are only returned if their title, text, tags, pinned or archived state, or editor changed.
+func ImportMarkdownFiles(files []MarkdownFile, existing Items) (Items, error) {
+	notes := make(map[string]*Note)
+
+	for _, n := range existing.Notes() {
+		if !n.Deleted {
+			notes[n.UUID] = &n
+		}
+	}
+
+	imp := NewTagPaths(existing)
+
+	seen := make(map[string]string)
+
+	var updated Items
+
+	for _, f := range files {
+		fm := f.FrontMatter
+
+		if fm.UUID != "" {
+			if path, ok := seen[fm.UUID]; ok {
+				return nil, fmt.Errorf("%w: %s: %s and %s", ErrDuplicateMarkdownUUID, fm.UUID, path, f.Path)
+			}
+
+			seen[fm.UUID] = f.Path
+		}
+
+		tagPaths := fm.Tags
+		if !f.HasTags {
+			tagPaths = nil
+			if folder := filepath.ToSlash(filepath.Dir(filepath.FromSlash(f.Path))); folder != "." {
+				tagPaths = []string{folder}
+			}
+		}
+
+		var tags []*Tag
+
+		for _, path := range tagPaths {
+			t, err := imp.Tag(path)
+			if err != nil {
+				return nil, fmt.Errorf("ImportMarkdownFiles | %s | %w", f.Path, err)
+			}
+
+			tags = append(tags, t)
+		}
+
+		n, ok := notes[fm.UUID]
+
+		var noteChanged bool
+
+		if !ok {
+			note, err := NewNote(fm.Title, "", nil)
+			if err != nil {
+				return nil, fmt.Errorf("ImportMarkdownFiles | %s | %w", f.Path, err)
+			}
+
+			if fm.UUID != "" {
+				note.UUID = fm.UUID
+			}
+
+			if !fm.Created.IsZero() {
+				note.CreatedAt = fm.Created.UTC().Format(common.TimeLayout)
+				note.CreatedAtTimestamp = fm.Created.UnixMicro()
+			}
+
+			n = &note
+			noteChanged = true
+		}
+
+		if applyMarkdownFile(n, f) {
+			noteChanged = true
+		}
+
+		// reference the note from its tags and remove references from any others
+		want := make(map[string]bool)
+
+		for _, t := range tags {
+			want[t.UUID] = true
+
+			imp.Reference(t, n)
+		}
+
+		imp.tree.Walk(func(node *TagNode) {
+			if want[node.Tag.UUID] {
+				return
+			}
+
+			t := imp.get(node)
+
+			refs := slices.DeleteFunc(slices.Clone(t.Content.ItemReferences), func(ref ItemReference) bool {
+				return ref.UUID == n.UUID
+			})
+
+			if len(refs) != len(t.Content.ItemReferences) {
+				t.Content.SetReferences(refs)
+				imp.changed[t.UUID] = true
+			}
+		})
+
+		if noteChanged {
+			n.Content.SetUpdateTime(time.Now().UTC())
+			updated = append(updated, n)
+		}
+	}
+
+	// tags first, so that a note's tags are synced along with it
+	var changedTags Items
+
+	for _, t := range imp.Changed() {
+		changedTags = append(changedTags, &t)
+	}
+
+	return append(changedTags, updated...), nil
+}
+
+// applyMarkdownFile updates the note from the file, returning true if anything changed.
+func applyMarkdownFile(n *Note, f MarkdownFile) bool {
+	fm := f.FrontMatter
+	changed := false
+
+	if n.Content.Title != fm.Title {
+		n.Content.SetTitle(fm.Title)
+		changed = true
+	}
+
+	if fm.Editor != "" && fm.Editor != n.Content.EditorIdentifier && fm.Editor != SuperEditorIdentifier {
+		n.Content.EditorIdentifier = fm.Editor
+		changed = true
+	}
+
+	if n.Content.IsSuper() || fm.Editor == SuperEditorIdentifier {
+		if md, err := n.Content.GetMarkdown(); err != nil || md != f.Body || !n.Content.IsSuper() {
+			n.Content.SetSuperMarkdown(f.Body)
+			changed = true
+		}
+	} else if n.Content.Text != f.Body {
+		n.Content.Text = f.Body
+		changed = true
+	}
+
+	if n.IsPinned() != fm.Pinned {
+		n.SetPinned(fm.Pinned)
+		changed = true
+	}
+
+	if n.IsArchived() != fm.Archived {
+		n.SetArchived(fm.Archived)
+		changed = true
+	}
+
+	return changed
+}
@@ -0,0 +1,223 @@
+package items
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func markdownTestItems(t *testing.T) Items {
+	t.Helper()
+
+	pinned := createNote("Plan: Q1/Q2", "# Plan\n\nship it", "n1")
+	pinned.SetPinned(true)
+	pinned.CreatedAtTimestamp = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).UnixMicro()
+
+	super, err := NewSuperNote("Super", "**bold** text", nil)
+	require.NoError(t, err)
+	super.UUID = "n4"
+
+	trashed := createNote("Trashed", "gone", "n5")
+	trashed.Content.SetTrashed(true)
+
+	return Items{
+		childTag(t, "work", "work", ""),
+		childTag(t, "projects", "projects", "work", noteRef("n1")),
+		childTag(t, "alpha", "alpha", "projects", noteRef("n2"), noteRef("n1")),
+		childTag(t, "home", "home", "", noteRef("n3")),
+		pinned,
+		createNote("two", "second", "n2"),
+		createNote("Two", "untagged", "n3b"),
+		createNote("two", "also untagged", "n3c"),
+		createNote("three", "", "n3"),
+		&super,
+		trashed,
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	dir := t.TempDir()
+	all := markdownTestItems(t)
+
+	// a stale file for a renamed note, and an unrelated file
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "old name.md"), []byte("---\nuuid: n2\n---\n\nold"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Notes"), 0o600))
+
+	require.NoError(t, all.ExportMarkdown(dir))
+
+	var paths []string
+
+	require.NoError(t, filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if !d.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+
+		return err
+	}))
+
+	require.ElementsMatch(t, []string{
+		"README.md",
+		"work/projects/Plan- Q1-Q2.md",
+		"work/projects/alpha/two.md",
+		"Two.md",
+		"two (2).md",
+		"home/three.md",
+		"Super.md",
+	}, paths)
+
+	b, err := os.ReadFile(filepath.Join(dir, "work", "projects", "Plan- Q1-Q2.md"))
+	require.NoError(t, err)
+
+	updated, err := all[4].(*Note).Content.GetUpdateTime()
+	require.NoError(t, err)
+
+	require.Equal(t, "---\n"+
+		"uuid: n1\n"+
+		"title: 'Plan: Q1/Q2'\n"+
+		"tags:\n"+
+		"    - work/projects\n"+
+		"    - work/projects/alpha\n"+
+		"created: 2024-01-02T03:04:05Z\n"+
+		"updated: "+updated.UTC().Format(time.RFC3339Nano)+"\n"+
+		"pinned: true\n"+
+		"---\n\n"+
+		"# Plan\n\nship it\n", string(b))
+
+	b, err = os.ReadFile(filepath.Join(dir, "Super.md"))
+	require.NoError(t, err)
+	require.Contains(t, string(b), "editor: "+SuperEditorIdentifier+"\n")
+	require.Contains(t, string(b), "\n\n**bold** text\n")
+}
+
+func TestImportMarkdownRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	all := append(markdownTestItems(t),
+		createNote("trailing newline", "line one\n", "r1"),
+		createNote("blank lines", "\nline one\n\n", "r2"),
+		createNote("newline", "\n", "r3"),
+	)
+
+	require.NoError(t, all.ExportMarkdown(dir))
+
+	// nothing has changed so there is nothing to sync
+	changed, err := ImportMarkdown(dir, all)
+	require.NoError(t, err)
+	require.Empty(t, changed)
+}
+
+func TestMarkdownFileRoundTrip(t *testing.T) {
+	for _, body := range []string{"", "text", "line one\n", "\n", "\n\nindented\n\n\n", "---\nnot front matter"} {
+		b, err := MarkdownFile{FrontMatter: MarkdownFrontMatter{Title: "title"}, Body: body}.Marshal()
+		require.NoError(t, err)
+
+		f, err := ParseMarkdownFile("title.md", b)
+		require.NoError(t, err)
+		require.Equal(t, body, f.Body, "%q", body)
+	}
+}
+
+func TestImportMarkdown(t *testing.T) {
+	dir := t.TempDir()
+	all := markdownTestItems(t)
+
+	require.NoError(t, all.ExportMarkdown(dir))
+
+	// edit a note and move it from alpha to a new nested tag
+	path := filepath.Join(dir, "work", "projects", "alpha", "two.md")
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	f, err := ParseMarkdownFile("work/projects/alpha/two.md", b)
+	require.NoError(t, err)
+	require.True(t, f.HasTags)
+	require.Equal(t, []string{"work/projects/alpha"}, f.FrontMatter.Tags)
+
+	f.Body = "edited"
+	f.FrontMatter.Tags = []string{"work/projects/gamma/delta"}
+	f.FrontMatter.Archived = true
+	b, err = f.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, b, 0o600))
+
+	// a new note without front matter is tagged by its folder
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "home", "New idea.md"), []byte("an idea\n"), 0o600))
+
+	// edit a Super note's Markdown
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Super.md"), []byte("---\nuuid: n4\ntitle: Super\neditor: "+SuperEditorIdentifier+"\n---\n\n- [x] done\n"), 0o600))
+
+	// hidden directories are ignored
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "ignored.md"), []byte("ignored"), 0o600))
+
+	changed, err := ImportMarkdown(dir, all)
+	require.NoError(t, err)
+
+	byTitle := map[string]Item{}
+	for _, i := range changed {
+		switch c := i.GetContent().(type) {
+		case *TagContent:
+			byTitle["tag:"+c.Title] = i
+		case *NoteContent:
+			byTitle[c.Title] = i
+		}
+	}
+
+	require.Len(t, changed, 7, byTitle)
+
+	two := byTitle["two"].(*Note)
+	require.Equal(t, "n2", two.UUID)
+	require.Equal(t, "edited", two.Content.Text)
+	require.True(t, two.IsArchived())
+
+	idea := byTitle["New idea"].(*Note)
+	require.Equal(t, "an idea", idea.Content.Text)
+
+	super := byTitle["Super"].(*Note)
+	require.True(t, super.Content.IsSuper())
+	md, err := super.Content.GetMarkdown()
+	require.NoError(t, err)
+	require.Equal(t, "- [x] done", md)
+
+	// the note is no longer referenced by alpha, and the idea is referenced by home
+	alpha := byTitle["tag:alpha"].(*Tag)
+	require.False(t, slices.ContainsFunc(alpha.Content.ItemReferences, func(ref ItemReference) bool { return ref.UUID == "n2" }))
+
+	home := byTitle["tag:home"].(*Tag)
+	require.True(t, slices.ContainsFunc(home.Content.ItemReferences, func(ref ItemReference) bool { return ref.UUID == idea.UUID }))
+
+	gamma := byTitle["tag:gamma"].(*Tag)
+	require.Equal(t, "projects", TagParentUUID(*gamma))
+
+	delta := byTitle["tag:delta"].(*Tag)
+	require.Equal(t, gamma.UUID, TagParentUUID(*delta))
+	require.Equal(t, ItemReference{UUID: "n2", ContentType: "Note"}, delta.Content.ItemReferences[1])
+
+	// the existing items are not modified
+	require.Equal(t, "second", all[5].(*Note).Content.Text)
+	require.True(t, slices.ContainsFunc(all[2].(*Tag).Content.ItemReferences, func(ref ItemReference) bool { return ref.UUID == "n2" }))
+}
+
+func TestImportMarkdownErrors(t *testing.T) {
+	_, err := ParseMarkdownFile("a.md", []byte("---\nuuid: a\n"))
+	require.ErrorIs(t, err, ErrInvalidFrontMatter)
+
+	_, err = ParseMarkdownFile("a.md", []byte("---\nuuid: [a\n---\n"))
+	require.ErrorIs(t, err, ErrInvalidFrontMatter)
+
+	_, err = ImportMarkdownFiles([]MarkdownFile{
+		{Path: "a.md", FrontMatter: MarkdownFrontMatter{UUID: "a", Title: "a"}},
+		{Path: "b.md", FrontMatter: MarkdownFrontMatter{UUID: "a", Title: "b"}},
+	}, nil)
+	require.ErrorIs(t, err, ErrDuplicateMarkdownUUID)
+
+	f, err := ParseMarkdownFile("dir/Title.md", []byte("\ufeffbody\r\n"))
+	require.NoError(t, err)
+	require.False(t, f.HasTags)
+	require.Equal(t, "Title", f.FrontMatter.Title)
+	require.Equal(t, "body", f.Body)
+}
@@ -1,8 +1,11 @@
 package items
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 	"time"
@@ -22,27 +25,24 @@ func (n Note) IsDefault() bool {
 
 var _ Item = &Note{}
 
-func parseNote(i DecryptedItem) Item {
+func parseNote(i DecryptedItem) (Item, error) {
 	n := Note{}
 
 	if err := populateItemCommon(&n.ItemCommon, i); err != nil {
-		panic(err)
+		return nil, fmt.Errorf("parseNote | %s: %w", i.UUID, err)
 	}
 
-	var err error
-
 	if !n.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
+		content, err := processContentModel(i.ContentType, i.Content)
 		if err != nil {
-			panic(err)
+			return nil, fmt.Errorf("parseNote | %s: %w", i.UUID, err)
 		}
 
 		n.Content = *content.(*NoteContent)
+		n.syncFlags()
 	}
 
-	return &n
+	return &n, nil
 }
 
 func (i Items) Notes() (n Notes) {
@@ -238,17 +238,21 @@ func (n *Note) SetContentSize(s int) {
 func (noteContent NoteContent) MarshalJSON() ([]byte, error) {
 	type Alias NoteContent
 
-	a := struct {
-		Alias
-	}{
-		Alias: (Alias)(noteContent),
-	}
+	a := (Alias)(noteContent)
 
 	if a.ItemReferences == nil {
 		a.ItemReferences = ItemReferences{}
 	}
 
-	return json.Marshal(a)
+	return encodeWithUnknown(a, noteContent.Unknown)
+}
+
+func (noteContent *NoteContent) UnmarshalJSON(data []byte) (err error) {
+	type Alias NoteContent
+
+	noteContent.Unknown, err = decodeWithUnknown(data, (*Alias)(noteContent))
+
+	return err
 }
 
 type NoteContent struct {
@@ -263,9 +267,12 @@ type NoteContent struct {
 	EditorIdentifier     string             `json:"editorIdentifier"`
 	Trashed              *bool              `json:"trashed,omitempty"`
 	HidePreview          bool               `json:"hidePreview,omitempty"`
+	Protected            bool               `json:"protected,omitempty"`
+	Starred              bool               `json:"starred,omitempty"`
 	// Missing attributes from official Standard Notes
 	EditorWidth          string             `json:"editorWidth,omitempty"`
 	AuthorizedForListed  bool               `json:"authorizedForListed,omitempty"`
+	Unknown              UnknownFields      `json:"-"`
 }
 
 func (noteContent NoteContent) ToAdvancedCheckList() (AdvancedChecklist, error) {
@@ -297,23 +304,23 @@ func (noteContent NoteContent) ToTaskList() (Tasklist, error) {
 		return Tasklist{}, fmt.Errorf("note is not a task list")
 	}
 
-	var taskList Tasklist
-
-	tasks, err := NoteTextToTasks(noteContent.Text)
-	if err != nil {
-		return Tasklist{}, err
-	}
+	taskList := ParseTasklistText(noteContent.Text)
 
 	taskList.Title = noteContent.Title
 	if noteContent.Trashed != nil {
 		taskList.Trashed = *noteContent.Trashed
 	}
 
-	taskList.Tasks = tasks
-
 	return taskList, nil
 }
 
+// SetTaskList sets the text of the note to the tasks of the task list, as written by the Checklist editor.
+// Text read with ToTaskList is written back unchanged if the tasks are.
+func (noteContent *NoteContent) SetTaskList(taskList Tasklist) {
+	noteContent.EditorIdentifier = SimpleTaskEditorNoteType
+	noteContent.Text = taskList.Text()
+}
+
 func (noteContent *NoteContent) GetUpdateTime() (time.Time, error) {
 	if noteContent.AppData.OrgStandardNotesSN.ClientUpdatedAt == "" {
 		return time.Time{}, fmt.Errorf("ClientUpdatedAt not set")
@@ -427,12 +434,27 @@ func (n Note) Equals(e Note) bool {
 	return true
 }
 
+// ContentHash returns a hash of the note's title and text that ignores line endings and surrounding
+// whitespace, so that notes with the same content can be found whatever their UUIDs.
+func (n Note) ContentHash() string {
+	normalise := func(s string) string {
+		return strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+	}
+
+	sum := sha256.Sum256([]byte(normalise(n.Content.Title) + "\n" + normalise(n.Content.Text)))
+
+	return hex.EncodeToString(sum[:])
+}
+
 func (noteContent NoteContent) Copy() NoteContent {
-	res := NoteContent{
-		Title:          noteContent.Title,
-		Text:           noteContent.Text,
-		ItemReferences: noteContent.ItemReferences,
-		AppData:        noteContent.AppData,
+	res := noteContent
+	res.ItemReferences = slices.Clone(noteContent.ItemReferences)
+	res.AppData = noteContent.AppData.copy()
+	res.Unknown = maps.Clone(noteContent.Unknown)
+
+	if noteContent.Trashed != nil {
+		trashed := *noteContent.Trashed
+		res.Trashed = &trashed
 	}
 
 	return res
@@ -452,7 +474,7 @@ func (n Note) Copy() Note {
 }
 
 func (noteContent *NoteContent) GetTrashed() bool {
-	return *noteContent.Trashed
+	return noteContent.Trashed != nil && *noteContent.Trashed
 }
 
 func (noteContent *NoteContent) SetTrashed(t bool) {
@@ -488,3 +510,68 @@ func (noteContent NoteContent) GetHidePreview() bool {
 func (noteContent *NoteContent) SetHidePreview(hidePreview bool) {
 	noteContent.HidePreview = hidePreview
 }
+
+// syncFlags copies the attributes held in the note's content to ItemCommon.
+func (n *Note) syncFlags() {
+	n.Pinned = n.Content.AppData.OrgStandardNotesSN.Pinned
+	n.Archived = n.Content.AppData.OrgStandardNotesSN.Archived
+	n.Locked = n.Content.AppData.OrgStandardNotesSN.Locked
+	n.Protected = n.Content.Protected
+	n.Starred = n.Content.Starred
+	n.Trashed = n.Content.GetTrashed()
+}
+
+// IsPinned returns whether the note is pinned to the top of the notes list.
+func (n Note) IsPinned() bool {
+	return n.Content.AppData.OrgStandardNotesSN.Pinned
+}
+
+// SetPinned sets whether the note is pinned to the top of the notes list.
+func (n *Note) SetPinned(pinned bool) {
+	n.Content.AppData.OrgStandardNotesSN.Pinned = pinned
+	n.syncFlags()
+}
+
+// IsArchived returns whether the note is archived.
+func (n Note) IsArchived() bool {
+	return n.Content.AppData.OrgStandardNotesSN.Archived
+}
+
+// SetArchived sets whether the note is archived.
+func (n *Note) SetArchived(archived bool) {
+	n.Content.AppData.OrgStandardNotesSN.Archived = archived
+	n.syncFlags()
+}
+
+// IsLocked returns whether editing of the note is disabled.
+func (n Note) IsLocked() bool {
+	return n.Content.AppData.OrgStandardNotesSN.Locked
+}
+
+// SetLocked sets whether editing of the note is disabled.
+func (n *Note) SetLocked(locked bool) {
+	n.Content.AppData.OrgStandardNotesSN.Locked = locked
+	n.syncFlags()
+}
+
+// IsProtected returns whether the note requires authentication to be viewed.
+func (n Note) IsProtected() bool {
+	return n.Content.Protected
+}
+
+// SetProtected sets whether the note requires authentication to be viewed.
+func (n *Note) SetProtected(protected bool) {
+	n.Content.Protected = protected
+	n.syncFlags()
+}
+
+// IsStarred returns whether the note is starred.
+func (n Note) IsStarred() bool {
+	return n.Content.Starred
+}
+
+// SetStarred sets whether the note is starred.
+func (n *Note) SetStarred(starred bool) {
+	n.Content.Starred = starred
+	n.syncFlags()
+}
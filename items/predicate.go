@@ -0,0 +1,683 @@
+package items
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/common"
+)
+
+// ErrInvalidPredicate is matched by errors returned when a smart view predicate cannot be parsed or evaluated.
+var ErrInvalidPredicate = errors.New("invalid predicate")
+
+// PredicateOperator is the operator of a Predicate.
+type PredicateOperator string
+
+const (
+	PredicateEqual          PredicateOperator = "="
+	PredicateNotEqual       PredicateOperator = "!="
+	PredicateLessThan       PredicateOperator = "<"
+	PredicateGreaterThan    PredicateOperator = ">"
+	PredicateLessOrEqual    PredicateOperator = "<="
+	PredicateGreaterOrEqual PredicateOperator = ">="
+	PredicateStartsWith     PredicateOperator = "startsWith"
+	PredicateIn             PredicateOperator = "in"
+	PredicateMatches        PredicateOperator = "matches"
+	PredicateIncludes       PredicateOperator = "includes"
+	PredicateAnd            PredicateOperator = "and"
+	PredicateOr             PredicateOperator = "or"
+	PredicateNot            PredicateOperator = "not"
+)
+
+// Predicate is a smart view predicate in the Standard Notes PredicateJsonForm.
+//
+// Value holds a []Predicate for the and and or operators, a Predicate for not, and
+// either a Predicate or a literal for includes. For all other operators it holds the
+// literal to compare the item's value at Keypath with. String values of the form
+// "<n>.<unit>.ago", where unit is hours, days, weeks, months or years, are relative dates.
+type Predicate struct {
+	Keypath  string            `json:"keypath,omitempty"`
+	Operator PredicateOperator `json:"operator"`
+	Value    any               `json:"value"`
+}
+
+// UnmarshalJSON decodes both the object form of a predicate and the legacy
+// array form of ["keypath", "operator", value].
+func (p *Predicate) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Keypath  string            `json:"keypath"`
+		Operator PredicateOperator `json:"operator"`
+		Value    json.RawMessage   `json:"value"`
+	}
+
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '[' {
+		var args []json.RawMessage
+		if err := json.Unmarshal(b, &args); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidPredicate, err)
+		}
+
+		if len(args) != 3 {
+			return fmt.Errorf("%w: expected [keypath, operator, value] but got %d arguments", ErrInvalidPredicate, len(args))
+		}
+
+		if err := json.Unmarshal(args[0], &raw.Keypath); err != nil {
+			return fmt.Errorf("%w: keypath: %w", ErrInvalidPredicate, err)
+		}
+
+		if err := json.Unmarshal(args[1], &raw.Operator); err != nil {
+			return fmt.Errorf("%w: operator: %w", ErrInvalidPredicate, err)
+		}
+
+		raw.Value = args[2]
+	} else if err := json.Unmarshal(b, &raw); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPredicate, err)
+	}
+
+	p.Keypath = raw.Keypath
+	p.Operator = raw.Operator
+	p.Value = nil
+
+	if len(raw.Value) == 0 {
+		return nil
+	}
+
+	switch raw.Operator {
+	case PredicateAnd, PredicateOr:
+		var ps []Predicate
+		if err := json.Unmarshal(raw.Value, &ps); err != nil {
+			return err
+		}
+
+		p.Value = ps
+	case PredicateNot:
+		var np Predicate
+		if err := json.Unmarshal(raw.Value, &np); err != nil {
+			return err
+		}
+
+		p.Value = np
+	default:
+		var v any
+		if err := json.Unmarshal(raw.Value, &v); err != nil {
+			return fmt.Errorf("%w: value: %w", ErrInvalidPredicate, err)
+		}
+
+		// includes may test related items against a nested predicate
+		if _, isObject := v.(map[string]any); isObject && raw.Operator == PredicateIncludes {
+			var np Predicate
+			if err := json.Unmarshal(raw.Value, &np); err != nil {
+				return err
+			}
+
+			p.Value = np
+
+			return nil
+		}
+
+		p.Value = v
+	}
+
+	return nil
+}
+
+// ParsePredicate converts a predicate, as decoded into an interface{} from an item's content, into a Predicate.
+func ParsePredicate(in any) (p Predicate, err error) {
+	switch v := in.(type) {
+	case nil:
+		return p, fmt.Errorf("%w: predicate is empty", ErrInvalidPredicate)
+	case Predicate:
+		p = v
+	case *Predicate:
+		p = *v
+	default:
+		var b []byte
+
+		if b, err = json.Marshal(in); err != nil {
+			return p, fmt.Errorf("%w: %w", ErrInvalidPredicate, err)
+		}
+
+		if err = json.Unmarshal(b, &p); err != nil {
+			return p, err
+		}
+	}
+
+	return p, p.Validate()
+}
+
+// Validate checks the predicate, and any nested predicates, can be evaluated.
+func (p Predicate) Validate() error {
+	switch p.Operator {
+	case PredicateAnd, PredicateOr:
+		ps, ok := p.Value.([]Predicate)
+		if !ok || len(ps) == 0 {
+			return fmt.Errorf("%w: %s requires a list of predicates", ErrInvalidPredicate, p.Operator)
+		}
+
+		for _, np := range ps {
+			if err := np.Validate(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case PredicateNot:
+		np, ok := p.Value.(Predicate)
+		if !ok {
+			return fmt.Errorf("%w: not requires a predicate", ErrInvalidPredicate)
+		}
+
+		return np.Validate()
+	case PredicateEqual, PredicateNotEqual, PredicateLessThan, PredicateGreaterThan,
+		PredicateLessOrEqual, PredicateGreaterOrEqual, PredicateStartsWith:
+	case PredicateIn:
+		if _, ok := p.Value.([]any); !ok {
+			return fmt.Errorf("%w: in requires a list of values", ErrInvalidPredicate)
+		}
+	case PredicateMatches:
+		pattern, ok := p.Value.(string)
+		if !ok {
+			return fmt.Errorf("%w: matches requires a regular expression", ErrInvalidPredicate)
+		}
+
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidPredicate, err)
+		}
+	case PredicateIncludes:
+		if np, ok := p.Value.(Predicate); ok {
+			if err := np.Validate(); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown operator %q", ErrInvalidPredicate, p.Operator)
+	}
+
+	if p.Keypath == "" {
+		return fmt.Errorf("%w: %s requires a keypath", ErrInvalidPredicate, p.Operator)
+	}
+
+	if s, ok := p.Value.(string); ok && strings.HasSuffix(s, ".ago") {
+		if _, err := relativeDate(s, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// relativeDate converts a date such as "7.days.ago" into a time relative to now.
+func relativeDate(s string, now time.Time) (time.Time, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 || parts[2] != "ago" {
+		return time.Time{}, fmt.Errorf("%w: invalid relative date %q", ErrInvalidPredicate, s)
+	}
+
+	n, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid relative date %q", ErrInvalidPredicate, s)
+	}
+
+	switch parts[1] {
+	case "hours":
+		return now.Add(-time.Duration(n) * time.Hour), nil
+	case "days":
+		return now.AddDate(0, 0, -n), nil
+	case "weeks":
+		return now.AddDate(0, 0, -7*n), nil
+	case "months":
+		return now.AddDate(0, -n, 0), nil
+	case "years":
+		return now.AddDate(-n, 0, 0), nil
+	default:
+		return time.Time{}, fmt.Errorf("%w: unknown unit in relative date %q", ErrInvalidPredicate, s)
+	}
+}
+
+// predicateContext holds the state shared when evaluating a predicate against many items.
+type predicateContext struct {
+	all     Items
+	now     time.Time
+	tags    map[string]Items
+	content map[string]map[string]any
+}
+
+func newPredicateContext(all Items) *predicateContext {
+	return &predicateContext{all: all, now: time.Now(), content: make(map[string]map[string]any)}
+}
+
+// contentFor returns the item's content as decoded from JSON.
+func (c *predicateContext) contentFor(item Item) map[string]any {
+	if m, ok := c.content[item.GetUUID()]; ok {
+		return m
+	}
+
+	m := contentMap(item)
+	c.content[item.GetUUID()] = m
+
+	return m
+}
+
+// tagsFor returns the tags that reference the item.
+func (c *predicateContext) tagsFor(uuid string) Items {
+	if c.tags == nil {
+		c.tags = make(map[string]Items)
+
+		for _, x := range c.all {
+			if x.GetContentType() != common.SNItemTypeTag || x.IsDeleted() {
+				continue
+			}
+
+			for _, ref := range x.GetContent().References() {
+				// a nested tag's reference to its parent does not tag the parent
+				if ref.ReferenceType == TagToParentTagReferenceType {
+					continue
+				}
+
+				c.tags[ref.UUID] = append(c.tags[ref.UUID], x)
+			}
+		}
+	}
+
+	return c.tags[uuid]
+}
+
+// Evaluate returns true if the item matches the predicate. allItems is used to resolve
+// relationships, such as the tags keypath, and may be nil if none are required.
+func (p Predicate) Evaluate(item Item, allItems Items) bool {
+	return p.evaluate(item, newPredicateContext(allItems))
+}
+
+func (p Predicate) evaluate(item Item, c *predicateContext) bool {
+	switch p.Operator {
+	case PredicateAnd:
+		ps, _ := p.Value.([]Predicate)
+		for _, np := range ps {
+			if !np.evaluate(item, c) {
+				return false
+			}
+		}
+
+		return len(ps) > 0
+	case PredicateOr:
+		ps, _ := p.Value.([]Predicate)
+
+		return slices.ContainsFunc(ps, func(np Predicate) bool {
+			return np.evaluate(item, c)
+		})
+	case PredicateNot:
+		np, ok := p.Value.(Predicate)
+
+		return ok && !np.evaluate(item, c)
+	}
+
+	v := keypathValue(item, p.Keypath, c)
+
+	switch p.Operator {
+	case PredicateEqual:
+		return predicateEqual(v, p.Value, c.now)
+	case PredicateNotEqual:
+		return !predicateEqual(v, p.Value, c.now)
+	case PredicateLessThan:
+		cmp, ok := predicateCompare(v, p.Value, c.now)
+
+		return ok && cmp < 0
+	case PredicateGreaterThan:
+		cmp, ok := predicateCompare(v, p.Value, c.now)
+
+		return ok && cmp > 0
+	case PredicateLessOrEqual:
+		cmp, ok := predicateCompare(v, p.Value, c.now)
+
+		return ok && cmp <= 0
+	case PredicateGreaterOrEqual:
+		cmp, ok := predicateCompare(v, p.Value, c.now)
+
+		return ok && cmp >= 0
+	case PredicateStartsWith:
+		s, ok := v.(string)
+		prefix, pok := p.Value.(string)
+
+		return ok && pok && strings.HasPrefix(s, prefix)
+	case PredicateIn:
+		values, _ := p.Value.([]any)
+
+		return slices.ContainsFunc(values, func(x any) bool {
+			return predicateEqual(v, x, c.now)
+		})
+	case PredicateMatches:
+		s, ok := v.(string)
+		pattern, pok := p.Value.(string)
+		if !ok || !pok {
+			return false
+		}
+
+		re, err := regexp.Compile(pattern)
+
+		return err == nil && re.MatchString(s)
+	case PredicateIncludes:
+		return predicateIncludes(v, p.Value, c)
+	default:
+		return false
+	}
+}
+
+func predicateIncludes(v, value any, c *predicateContext) bool {
+	switch x := v.(type) {
+	case string:
+		s, ok := value.(string)
+
+		return ok && strings.Contains(x, s)
+	case Items:
+		if np, ok := value.(Predicate); ok {
+			return slices.ContainsFunc(x, func(related Item) bool {
+				return np.evaluate(related, c)
+			})
+		}
+
+		s, ok := value.(string)
+
+		return ok && slices.ContainsFunc(x, func(related Item) bool {
+			return related.GetUUID() == s
+		})
+	case []string:
+		s, ok := value.(string)
+
+		return ok && slices.Contains(x, s)
+	case []any:
+		return slices.ContainsFunc(x, func(e any) bool {
+			return predicateEqual(e, value, c.now)
+		})
+	default:
+		return false
+	}
+}
+
+// predicateTime converts a predicate value into a time if it is a date or relative date.
+func predicateTime(v any, now time.Time) (time.Time, bool) {
+	switch x := v.(type) {
+	case time.Time:
+		return x, true
+	case string:
+		if strings.HasSuffix(x, ".ago") {
+			t, err := relativeDate(x, now)
+
+			return t, err == nil
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, x)
+
+		return t, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+func predicateNumber(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case int:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	default:
+		return 0, false
+	}
+}
+
+func predicateEqual(v, value any, now time.Time) bool {
+	if t, ok := v.(time.Time); ok {
+		pt, pok := predicateTime(value, now)
+
+		return pok && t.Equal(pt)
+	}
+
+	if n, ok := predicateNumber(v); ok {
+		pn, pok := predicateNumber(value)
+
+		return pok && n == pn
+	}
+
+	switch x := v.(type) {
+	case nil:
+		return value == nil
+	case string, bool:
+		return x == value
+	default:
+		return false
+	}
+}
+
+// predicateCompare returns -1, 0 or 1 as v is less than, equal to or greater than value.
+// False is returned if the values cannot be compared.
+func predicateCompare(v, value any, now time.Time) (int, bool) {
+	if t, ok := v.(time.Time); ok {
+		pt, pok := predicateTime(value, now)
+		if !pok {
+			return 0, false
+		}
+
+		return t.Compare(pt), true
+	}
+
+	if n, ok := predicateNumber(v); ok {
+		pn, pok := predicateNumber(value)
+		if !pok {
+			return 0, false
+		}
+
+		switch {
+		case n < pn:
+			return -1, true
+		case n > pn:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+
+	s, ok := v.(string)
+	ps, pok := value.(string)
+	if !ok || !pok {
+		return 0, false
+	}
+
+	return strings.Compare(s, ps), true
+}
+
+// flagKeypaths are boolean attributes that are false unless set.
+var flagKeypaths = []string{"pinned", "archived", "locked", "starred", "protected", "trashed"}
+
+func parseItemTime(s string, ts int64) any {
+	if ts != 0 {
+		return time.UnixMicro(ts).UTC()
+	}
+
+	if t, err := time.Parse(common.TimeLayout, s); err == nil {
+		return t
+	}
+
+	return nil
+}
+
+// keypathValue returns the value of the item at the keypath, using the same names as the official clients.
+// Dates are returned as time.Time and content attributes as decoded from JSON.
+func keypathValue(item Item, keypath string, c *predicateContext) any {
+	switch keypath {
+	case "uuid":
+		return item.GetUUID()
+	case "content_type":
+		return item.GetContentType()
+	case "created_at":
+		return parseItemTime(item.GetCreatedAt(), item.GetCreatedAtTimestamp())
+	case "updated_at", "serverUpdatedAt":
+		return parseItemTime(item.GetUpdatedAt(), item.GetUpdatedAtTimestamp())
+	case "duplicate_of":
+		return item.GetDuplicateOf()
+	case "deleted":
+		return item.IsDeleted()
+	case "tags":
+		return c.tagsFor(item.GetUUID())
+	case "tagsStrings":
+		var titles []string
+
+		for _, t := range c.tagsFor(item.GetUUID()) {
+			if tag, ok := t.(*Tag); ok {
+				titles = append(titles, tag.Content.Title)
+			}
+		}
+
+		return titles
+	}
+
+	content := c.contentFor(item)
+
+	if keypath == "userModifiedDate" {
+		if s, ok := appDataValue(content, "client_updated_at").(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return t
+			}
+		}
+
+		return parseItemTime(item.GetUpdatedAt(), item.GetUpdatedAtTimestamp())
+	}
+
+	if v, found := lookupKeypath(content, strings.TrimPrefix(keypath, "content.")); found {
+		return v
+	}
+
+	if v := appDataValue(content, keypath); v != nil {
+		return v
+	}
+
+	if slices.Contains(flagKeypaths, keypath) {
+		return false
+	}
+
+	return nil
+}
+
+// contentMap returns the item's content as decoded from JSON.
+func contentMap(item Item) map[string]any {
+	content := item.GetContent()
+	if content == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(content)
+	if err != nil {
+		return nil
+	}
+
+	var m map[string]any
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil
+	}
+
+	return m
+}
+
+// appDataValue returns the value of a key in the content's Standard Notes app data domain.
+func appDataValue(content map[string]any, key string) any {
+	v, _ := lookupKeypath(content, "appData")
+
+	appData, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	domain, ok := appData["org.standardnotes.sn"].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	return domain[key]
+}
+
+// lookupKeypath returns the value at a dotted keypath. As keys such as "org.standardnotes.sn"
+// contain dots themselves, the longest key matching the start of the keypath is used.
+func lookupKeypath(m map[string]any, keypath string) (any, bool) {
+	keys := strings.Split(keypath, ".")
+
+	for n := len(keys); n > 0; n-- {
+		v, ok := m[strings.Join(keys[:n], ".")]
+		if !ok {
+			continue
+		}
+
+		if n == len(keys) {
+			return v, true
+		}
+
+		if mv, isMap := v.(map[string]any); isMap {
+			if v, ok = lookupKeypath(mv, strings.Join(keys[n:], ".")); ok {
+				return v, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// ParsePredicate returns the smart view's predicate.
+func (cc SmartViewContent) ParsePredicate() (Predicate, error) {
+	return ParsePredicate(cc.Predicate)
+}
+
+// ApplySmartView returns the notes and files that the smart view shows, in their existing order.
+// As in the official clients, trashed and archived items are excluded unless the predicate
+// refers to the trashed or archived keypath respectively.
+func (i Items) ApplySmartView(sv SmartView) (Items, error) {
+	p, err := sv.Content.ParsePredicate()
+	if err != nil {
+		return nil, fmt.Errorf("ApplySmartView | %s | %w", sv.Content.Title, err)
+	}
+
+	includeTrashed := p.refersTo("trashed")
+	includeArchived := p.refersTo("archived")
+	c := newPredicateContext(i)
+
+	var o Items
+
+	for _, x := range i {
+		if x.IsDeleted() || (x.GetContentType() != common.SNItemTypeNote && x.GetContentType() != common.SNItemTypeFile) {
+			continue
+		}
+
+		if (!includeTrashed && keypathValue(x, "trashed", c) == true) ||
+			(!includeArchived && keypathValue(x, "archived", c) == true) {
+			continue
+		}
+
+		if p.evaluate(x, c) {
+			o = append(o, x)
+		}
+	}
+
+	return o, nil
+}
+
+// refersTo returns true if the predicate, or any nested predicate, tests the keypath.
+func (p Predicate) refersTo(keypath string) bool {
+	if p.Keypath == keypath {
+		return true
+	}
+
+	switch v := p.Value.(type) {
+	case Predicate:
+		return v.refersTo(keypath)
+	case []Predicate:
+		return slices.ContainsFunc(v, func(np Predicate) bool {
+			return np.refersTo(keypath)
+		})
+	}
+
+	return false
+}
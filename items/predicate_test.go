@@ -0,0 +1,160 @@
+package items
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/stretchr/testify/require"
+)
+
+func predicateTestItems(t *testing.T) (Items, map[string]*Note) {
+	t.Helper()
+
+	now := time.Now().UTC()
+
+	recent := createNote("Recent meeting", "agenda", "recent")
+	recent.Content.SetUpdateTime(now.Add(-2 * time.Hour))
+
+	old := createNote("Old meeting", "minutes", "old")
+	old.Content.SetUpdateTime(now.AddDate(0, 0, -10))
+	old.CreatedAtTimestamp = now.AddDate(0, 0, -30).UnixMicro()
+
+	pinned := createNote("Shopping", "milk", "pinned")
+	pinned.Content.AppData.OrgStandardNotesSN.Pinned = true
+
+	trashed := createNote("Recent rubbish", "", "trashed")
+	isTrashed := true
+	trashed.Content.Trashed = &isTrashed
+
+	work, err := createTag("work", "work-tag", ItemReferences{{UUID: "recent", ContentType: common.SNItemTypeNote}})
+	require.NoError(t, err)
+
+	return Items{recent, old, pinned, trashed, work}, map[string]*Note{
+		"recent":  recent,
+		"old":     old,
+		"pinned":  pinned,
+		"trashed": trashed,
+	}
+}
+
+func smartViewWithPredicate(t *testing.T, predicate string) SmartView {
+	t.Helper()
+
+	sv := NewSmartView()
+	sv.Content = *NewSmartViewContent()
+	sv.Content.Title = "test"
+	require.NoError(t, json.Unmarshal([]byte(predicate), &sv.Content.Predicate))
+
+	return sv
+}
+
+func TestPredicateUnmarshal(t *testing.T) {
+	var p Predicate
+	require.NoError(t, json.Unmarshal([]byte(`{"operator":"and","value":[
+		{"keypath":"title","operator":"startsWith","value":"Recent"},
+		["pinned","=",false],
+		{"operator":"not","value":{"keypath":"text","operator":"=","value":""}},
+		{"keypath":"tags","operator":"includes","value":{"keypath":"title","operator":"=","value":"work"}}
+	]}`), &p))
+	require.NoError(t, p.Validate())
+
+	ps := p.Value.([]Predicate)
+	require.Len(t, ps, 4)
+	require.Equal(t, Predicate{Keypath: "pinned", Operator: PredicateEqual, Value: false}, ps[1])
+	require.Equal(t, Predicate{Keypath: "text", Operator: PredicateEqual, Value: ""}, ps[2].Value)
+	require.Equal(t, Predicate{Keypath: "title", Operator: PredicateEqual, Value: "work"}, ps[3].Value)
+
+	// round trip through the object form
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+
+	var rt Predicate
+	require.NoError(t, json.Unmarshal(b, &rt))
+	require.Equal(t, p, rt)
+}
+
+func TestParsePredicateInvalid(t *testing.T) {
+	for name, in := range map[string]string{
+		"unknown operator":   `{"keypath":"title","operator":"~","value":"a"}`,
+		"missing keypath":    `{"operator":"=","value":"a"}`,
+		"empty and":          `{"operator":"and","value":[]}`,
+		"bad regex":          `{"keypath":"title","operator":"matches","value":"("}`,
+		"in without list":    `{"keypath":"title","operator":"in","value":"a"}`,
+		"bad relative date":  `{"keypath":"created_at","operator":">","value":"1.fortnights.ago"}`,
+		"wrong arg count":    `["title","="]`,
+		"invalid nested not": `{"operator":"not","value":{"operator":"=","value":1}}`,
+	} {
+		var raw any
+		require.NoError(t, json.Unmarshal([]byte(in), &raw), name)
+
+		_, err := ParsePredicate(raw)
+		require.ErrorIs(t, err, ErrInvalidPredicate, name)
+	}
+
+	_, err := ParsePredicate(nil)
+	require.ErrorIs(t, err, ErrInvalidPredicate)
+}
+
+func TestPredicateEvaluate(t *testing.T) {
+	all, notes := predicateTestItems(t)
+
+	for _, tc := range []struct {
+		predicate string
+		matches   []string
+	}{
+		{`{"keypath":"title","operator":"=","value":"Shopping"}`, []string{"pinned"}},
+		{`{"keypath":"title","operator":"!=","value":"Shopping"}`, []string{"recent", "old", "trashed"}},
+		{`{"keypath":"pinned","operator":"=","value":true}`, []string{"pinned"}},
+		{`{"keypath":"trashed","operator":"=","value":false}`, []string{"recent", "old", "pinned"}},
+		{`{"keypath":"title","operator":"startsWith","value":"Recent"}`, []string{"recent", "trashed"}},
+		{`{"keypath":"title","operator":"matches","value":"(?i)MEETING$"}`, []string{"recent", "old"}},
+		{`{"keypath":"text","operator":"includes","value":"ilk"}`, []string{"pinned"}},
+		{`{"keypath":"uuid","operator":"in","value":["old","pinned"]}`, []string{"old", "pinned"}},
+		{`{"keypath":"userModifiedDate","operator":">","value":"1.days.ago"}`, []string{"pinned", "recent", "trashed"}},
+		{`{"keypath":"userModifiedDate","operator":"<","value":"1.weeks.ago"}`, []string{"old"}},
+		{`{"keypath":"created_at","operator":"<=","value":"3.weeks.ago"}`, []string{"old"}},
+		{`{"keypath":"tagsStrings","operator":"includes","value":"work"}`, []string{"recent"}},
+		{`{"keypath":"tags","operator":"includes","value":{"keypath":"title","operator":"=","value":"work"}}`, []string{"recent"}},
+		{`{"operator":"or","value":[["title","=","Shopping"],["title","=","Old meeting"]]}`, []string{"old", "pinned"}},
+		{`{"operator":"and","value":[["title","matches","meeting"],{"operator":"not","value":["uuid","=","old"]}]}`, []string{"recent"}},
+		{`["appData.org.standardnotes.sn.pinned","=",true]`, []string{"pinned"}},
+	} {
+		var raw any
+		require.NoError(t, json.Unmarshal([]byte(tc.predicate), &raw))
+
+		p, err := ParsePredicate(raw)
+		require.NoError(t, err, tc.predicate)
+
+		for uuid, note := range notes {
+			require.Equal(t, slices.Contains(tc.matches, uuid), p.Evaluate(note, all), "%s %s", tc.predicate, uuid)
+		}
+	}
+}
+
+func TestApplySmartView(t *testing.T) {
+	all, _ := predicateTestItems(t)
+
+	// trashed notes are excluded unless the predicate refers to them
+	sv := smartViewWithPredicate(t, `{"keypath":"userModifiedDate","operator":">","value":"1.days.ago"}`)
+	its, err := all.ApplySmartView(sv)
+	require.NoError(t, err)
+	require.Equal(t, []string{"recent", "pinned"}, its.UUIDs())
+
+	sv = smartViewWithPredicate(t, `{"keypath":"trashed","operator":"=","value":true}`)
+	its, err = all.ApplySmartView(sv)
+	require.NoError(t, err)
+	require.Equal(t, []string{"trashed"}, its.UUIDs())
+
+	// tags are not returned even if they match
+	sv = smartViewWithPredicate(t, `{"keypath":"title","operator":"matches","value":"."}`)
+	its, err = all.ApplySmartView(sv)
+	require.NoError(t, err)
+	require.Equal(t, []string{"recent", "old", "pinned"}, its.UUIDs())
+
+	sv = smartViewWithPredicate(t, `{"keypath":"title","operator":"~","value":"."}`)
+	_, err = all.ApplySmartView(sv)
+	require.ErrorIs(t, err, ErrInvalidPredicate)
+}
@@ -2,35 +2,11 @@ package items
 
 import (
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parsePrivileges(i DecryptedItem) Item {
-	c := Privileges{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		c.Content = *content.(*PrivilegesContent)
-	}
-
-	return &c
-}
-
 type PrivilegesContent struct {
 	ItemReferences     ItemReferences `json:"references"`
 	AppData            AppDataContent `json:"appData"`
@@ -38,56 +14,32 @@ type PrivilegesContent struct {
 	DissociatedItemIds []string       `json:"disassociatedItemIds"`
 	AssociatedItemIds  []string       `json:"associatedItemIds"`
 	Active             interface{}    `json:"active"`
+	Unknown            UnknownFields  `json:"-"`
 }
 
-type Privileges struct {
-	ItemCommon
-	Content PrivilegesContent
-}
+func (cc *PrivilegesContent) UnmarshalJSON(data []byte) (err error) {
+	type alias PrivilegesContent
 
-func (c Privileges) IsDefault() bool {
-	return false
-}
-
-func (i Items) Privileges() (c PrivilegesN) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypePrivileges {
-			component := x.(*Privileges)
-			c = append(c, *component)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *PrivilegesN) DeDupe() {
-	var encountered []string
-
-	var deDuped PrivilegesN
+func (cc PrivilegesContent) MarshalJSON() ([]byte, error) {
+	type alias PrivilegesContent
 
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-		encountered = append(encountered, i.UUID)
-	}
+type Privileges = TypedItem[PrivilegesContent, *PrivilegesContent]
 
-	*c = deDuped
+func (i Items) Privileges() (c PrivilegesN) {
+	return ItemsOfType[PrivilegesContent](i)
 }
 
 // NewPrivileges returns an Item of type Privileges without content.
 func NewPrivileges() Privileges {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c Privileges
-
-	c.ContentType = common.SNItemTypePrivileges
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[PrivilegesContent](common.SNItemTypePrivileges)
 }
 
 // NewTagContent returns an empty Tag content instance.
@@ -98,120 +50,14 @@ func NewPrivilegesContent() *PrivilegesContent {
 	return c
 }
 
-type PrivilegesN []Privileges
-
-func (c PrivilegesN) Validate() error {
-	var updatedTime time.Time
-
-	var err error
+type PrivilegesN = TypedItems[PrivilegesContent, *PrivilegesContent]
 
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing title: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
+func (cc *PrivilegesContent) MissingField() string {
+	if cc.Name == "" {
+		return "title"
 	}
 
-	return err
-}
-
-func (c Privileges) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *Privileges) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c Privileges) GetContent() Content {
-	return &c.Content
-}
-
-func (c *Privileges) SetContent(cc Content) {
-	c.Content = *cc.(*PrivilegesContent)
-}
-
-func (c Privileges) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c Privileges) GetUUID() string {
-	return c.UUID
-}
-
-func (c Privileges) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *Privileges) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c Privileges) GetContentType() string {
-	return c.ContentType
-}
-
-func (c Privileges) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *Privileges) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c Privileges) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *Privileges) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c Privileges) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *Privileges) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c Privileges) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *Privileges) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *Privileges) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c Privileges) GetContentSize() int {
-	return c.ContentSize
-}
-
-func (c *Privileges) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *PrivilegesContent) AssociateItems(newItems []string) {
@@ -0,0 +1,203 @@
+package items
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/crypto"
+	"github.com/jonhadfield/gosn-v2/log"
+	"github.com/jonhadfield/gosn-v2/session"
+)
+
+var (
+	// ErrNoItemsKeys is returned when items need to be decrypted or encrypted but the session holds no items keys.
+	ErrNoItemsKeys = errors.New("no items keys available")
+	// ErrMissingItemsKey is returned when an item is encrypted with an items key that is not available.
+	ErrMissingItemsKey = errors.New("items key not available")
+	// ErrInvalidContent is returned when decrypted content cannot be parsed.
+	ErrInvalidContent = errors.New("invalid item content")
+)
+
+// QuarantineReason categorises why an item could not be processed.
+type QuarantineReason string
+
+const (
+	QuarantineMalformedPayload     QuarantineReason = "malformed_payload"     // encrypted payload is structurally invalid
+	QuarantineMissingItemsKey      QuarantineReason = "missing_items_key"     // items key used to encrypt the item is not available
+	QuarantineAuthenticationFailed QuarantineReason = "authentication_failed" // wrong key, or payload has been altered
+	QuarantineInvalidContent       QuarantineReason = "invalid_content"       // decrypted content cannot be parsed
+	QuarantineUnknown              QuarantineReason = "unknown"
+)
+
+func quarantineReason(err error) QuarantineReason {
+	switch {
+	case errors.Is(err, crypto.ErrMalformedPayload):
+		return QuarantineMalformedPayload
+	case errors.Is(err, ErrMissingItemsKey):
+		return QuarantineMissingItemsKey
+	case errors.Is(err, crypto.ErrAuthenticationFailed):
+		return QuarantineAuthenticationFailed
+	case errors.Is(err, ErrInvalidContent):
+		return QuarantineInvalidContent
+	default:
+		return QuarantineUnknown
+	}
+}
+
+// QuarantinedItem is an item that was set aside because it could not be processed.
+type QuarantinedItem struct {
+	UUID        string
+	ContentType string
+	ItemsKeyID  string
+	Reason      QuarantineReason
+	Err         error
+}
+
+// Quarantine lists items set aside so that the remaining items can still be processed.
+type Quarantine []QuarantinedItem
+
+// Add records an item that could not be processed.
+func (q *Quarantine) Add(ei EncryptedItem, err error) {
+	*q = append(*q, QuarantinedItem{
+		UUID:        ei.UUID,
+		ContentType: ei.ContentType,
+		ItemsKeyID:  ei.ItemsKeyID,
+		Reason:      quarantineReason(err),
+		Err:         err,
+	})
+}
+
+// Retryable returns the items quarantined due to a missing items key where that key is now available.
+func (q Quarantine) Retryable(iks []session.SessionItemsKey) (r Quarantine) {
+	for _, qi := range q {
+		if qi.Reason != QuarantineMissingItemsKey {
+			continue
+		}
+
+		if len(GetMatchingItem(qi.ItemsKeyID, iks).ItemsKey) > 0 {
+			r = append(r, qi)
+		}
+	}
+
+	return r
+}
+
+// UUIDs returns the UUIDs of the quarantined items.
+func (q Quarantine) UUIDs() []string {
+	uuids := make([]string, 0, len(q))
+
+	for _, qi := range q {
+		uuids = append(uuids, qi.UUID)
+	}
+
+	return uuids
+}
+
+// CheckProcessable returns an error matching crypto.ErrMalformedPayload if the item is
+// structurally unable to be stored or decrypted, e.g. as a result of corruption.
+func (ei EncryptedItem) CheckProcessable() error {
+	if ei.UUID == "" {
+		return fmt.Errorf("%w: %s item is missing uuid", crypto.ErrMalformedPayload, ei.ContentType)
+	}
+
+	// deleted items are returned by the server without content
+	if ei.Deleted {
+		return nil
+	}
+
+	if _, _, _, _, err := crypto.SplitContent(ei.Content); err != nil {
+		return fmt.Errorf("%s %s content | %w", ei.ContentType, ei.UUID, err)
+	}
+
+	if _, _, _, _, err := crypto.SplitContent(ei.EncItemKey); err != nil {
+		return fmt.Errorf("%s %s enc_item_key | %w", ei.ContentType, ei.UUID, err)
+	}
+
+	if ei.ItemsKeyID == "" && !(ei.ContentType == common.SNItemTypeItemsKey || strings.HasPrefix(ei.ContentType, "SF")) {
+		return fmt.Errorf("%w: %s %s is missing items key id", crypto.ErrMalformedPayload, ei.ContentType, ei.UUID)
+	}
+
+	return nil
+}
+
+// Quarantine returns the items that can be processed, setting aside any that fail CheckProcessable.
+func (ei EncryptedItems) Quarantine() (ok EncryptedItems, q Quarantine) {
+	if len(ei) == 0 {
+		return ei, nil
+	}
+
+	ok = make(EncryptedItems, 0, len(ei))
+
+	for _, e := range ei {
+		if err := e.CheckProcessable(); err != nil {
+			q.Add(e, err)
+
+			continue
+		}
+
+		ok = append(ok, e)
+	}
+
+	return ok, q
+}
+
+// parseItemLenient parses the decrypted item, returning an error matching ErrInvalidContent
+// if its content cannot be parsed.
+func parseItemLenient(di DecryptedItem) (Item, error) {
+	p, err := ParseItem(di)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidContent, err)
+	}
+
+	return p, nil
+}
+
+// ParseLenient parses the decrypted items in the same way as Parse, but rather than
+// failing the batch, any items that cannot be parsed are returned in the Quarantine.
+func (di DecryptedItems) ParseLenient() (p Items, q Quarantine) {
+	for _, i := range di {
+		// items keys are not returned as normal items
+		if i.ContentType == common.SNItemTypeItemsKey {
+			continue
+		}
+
+		pi, err := parseItemLenient(i)
+		if err != nil {
+			q = append(q, QuarantinedItem{
+				UUID:        i.UUID,
+				ContentType: i.ContentType,
+				ItemsKeyID:  i.ItemsKeyID,
+				Reason:      quarantineReason(err),
+				Err:         err,
+			})
+
+			continue
+		}
+
+		p = append(p, pi)
+	}
+
+	return p, q
+}
+
+// DecryptAndParseLenient decrypts and parses the items in the same way as DecryptAndParse, but
+// returns whatever can be processed along with a Quarantine listing the items that could not.
+func (ei EncryptedItems) DecryptAndParseLenient(s *session.Session) (o Items, q Quarantine) {
+	log.DebugPrint(s.Debug, fmt.Sprintf("DecryptAndParseLenient | items: %d", len(ei)), common.MaxDebugChars)
+
+	ok, q := ei.Quarantine()
+
+	di, dq := DecryptItemsLenient(s, ok, []session.SessionItemsKey{})
+	q = append(q, dq...)
+
+	o, pq := di.ParseLenient()
+	q = append(q, pq...)
+
+	for _, qi := range q {
+		log.DebugPrint(s.Debug, fmt.Sprintf("DecryptAndParseLenient | quarantined %s %s: %s", qi.ContentType, qi.UUID, qi.Reason), common.MaxDebugChars)
+	}
+
+	return o, q
+}
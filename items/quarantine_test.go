@@ -0,0 +1,239 @@
+package items
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/crypto"
+	"github.com/jonhadfield/gosn-v2/crypto/vectors"
+	"github.com/jonhadfield/gosn-v2/session"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckProcessable(t *testing.T) {
+	for _, ei := range vectorEncryptedItems() {
+		require.NoError(t, ei.CheckProcessable())
+	}
+
+	require.NoError(t, EncryptedItem{UUID: "a", ContentType: common.SNItemTypeNote, Deleted: true}.CheckProcessable())
+
+	note := vectorEncryptedItems()[1]
+
+	truncated := note
+	truncated.Content = "004:abc"
+
+	noEncItemKey := note
+	noEncItemKey.EncItemKey = ""
+
+	noItemsKeyID := note
+	noItemsKeyID.ItemsKeyID = ""
+
+	noUUID := note
+	noUUID.UUID = ""
+
+	for name, ei := range map[string]EncryptedItem{
+		"truncated content":    truncated,
+		"missing enc item key": noEncItemKey,
+		"missing items key id": noItemsKeyID,
+		"missing uuid":         noUUID,
+	} {
+		require.ErrorIs(t, ei.CheckProcessable(), crypto.ErrMalformedPayload, name)
+	}
+}
+
+func TestQuarantine(t *testing.T) {
+	eis := vectorEncryptedItems()
+
+	corrupt := eis[1]
+	corrupt.UUID = "corrupt"
+	corrupt.Content = "004:abc"
+
+	ok, q := append(eis, corrupt).Quarantine()
+	require.Len(t, ok, len(eis))
+	require.Len(t, q, 1)
+	require.Equal(t, []string{"corrupt"}, q.UUIDs())
+	require.Equal(t, common.SNItemTypeNote, q[0].ContentType)
+	require.ErrorIs(t, q[0].Err, crypto.ErrMalformedPayload)
+
+	ok, q = EncryptedItems{}.Quarantine()
+	require.Empty(t, ok)
+	require.Nil(t, q)
+}
+
+func TestDecryptMalformedItemReturnsError(t *testing.T) {
+	ik := session.SessionItemsKey{UUID: "ik", ItemsKey: mustSecretKey(vectors.Items004[1].Key)}
+	ei := EncryptedItem{
+		UUID:        "a",
+		ContentType: common.SNItemTypeNote,
+		ItemsKeyID:  "ik",
+		Content:     "004",
+		EncItemKey:  "004:abc",
+	}
+
+	_, err := DecryptItem(ei, &session.Session{}, []session.SessionItemsKey{ik})
+	require.ErrorIs(t, err, crypto.ErrMalformedPayload)
+}
+
+func TestNewItemsKey(t *testing.T) {
+	ik, err := NewItemsKey()
+	require.NoError(t, err)
+	require.Len(t, ik.ItemsKey, crypto.KeySize)
+	require.Equal(t, ik.ItemsKey, ik.Content.ItemsKey)
+	require.NotEmpty(t, ik.UUID)
+	require.Equal(t, common.SNItemTypeItemsKey, ik.ContentType)
+
+	ik2, err := NewItemsKey()
+	require.NoError(t, err)
+	require.NotEqual(t, ik.ItemsKey, ik2.ItemsKey)
+}
+
+func lenientTestItems(t *testing.T, s *session.Session, good int) EncryptedItems {
+	t.Helper()
+
+	ik := s.ItemsKeys[0]
+	wrongKey := session.SessionItemsKey{UUID: ik.UUID, ItemsKey: mustSecretKey(vectors.Items004[0].Key)}
+
+	var eis EncryptedItems
+
+	for x := 0; x < good; x++ {
+		ei, err := EncryptItem(createNote(fmt.Sprintf("note %d", x), "text", ""), ik, s)
+		require.NoError(t, err)
+
+		eis = append(eis, ei)
+	}
+
+	missingKey, err := EncryptItem(createNote("missing key", "text", "missing-key"), ik, s)
+	require.NoError(t, err)
+
+	missingKey.ItemsKeyID = "unknown-items-key"
+
+	wrongKeyItem, err := EncryptItem(createNote("wrong key", "text", "wrong-key"), wrongKey, s)
+	require.NoError(t, err)
+
+	corrupt := eis[0]
+	corrupt.UUID = "corrupt"
+	corrupt.Content = "004:abc"
+
+	// insert failures amongst the good items to check ordering is preserved
+	return slices.Concat(EncryptedItems{missingKey}, eis[:good/2], EncryptedItems{wrongKeyItem, corrupt}, eis[good/2:])
+}
+
+func TestDecryptAndParseLenient(t *testing.T) {
+	ik := session.SessionItemsKey{UUID: "0d1f8c5a-6b1e-4c52-9a3f-3f7c2b8e9a10", ItemsKey: mustSecretKey(vectors.Items004[1].Key)}
+	s := &session.Session{ItemsKeys: []session.SessionItemsKey{ik}, DefaultItemsKey: ik}
+
+	for _, good := range []int{2, DecryptionBatchThreshold + 10} {
+		eis := lenientTestItems(t, s, good)
+
+		_, err := eis.DecryptAndParse(s)
+		require.Error(t, err)
+
+		its, q := eis.DecryptAndParseLenient(s)
+		require.Len(t, its, good)
+		require.Equal(t, "note 0", its[0].(*Note).Content.Title)
+		require.Equal(t, fmt.Sprintf("note %d", good-1), its[good-1].(*Note).Content.Title)
+
+		require.Len(t, q, 3)
+		reasons := map[string]QuarantineReason{}
+		for _, qi := range q {
+			require.Equal(t, common.SNItemTypeNote, qi.ContentType)
+			require.Error(t, qi.Err)
+			reasons[qi.UUID] = qi.Reason
+		}
+
+		require.Equal(t, map[string]QuarantineReason{
+			"corrupt":     QuarantineMalformedPayload,
+			"missing-key": QuarantineMissingItemsKey,
+			"wrong-key":   QuarantineAuthenticationFailed,
+		}, reasons)
+		require.Equal(t, []string{"corrupt", "missing-key", "wrong-key"}, q.UUIDs())
+	}
+}
+
+func TestDecryptItemsLenientPreservesOrder(t *testing.T) {
+	ik := session.SessionItemsKey{UUID: "0d1f8c5a-6b1e-4c52-9a3f-3f7c2b8e9a10", ItemsKey: mustSecretKey(vectors.Items004[1].Key)}
+	s := &session.Session{ItemsKeys: []session.SessionItemsKey{ik}, DefaultItemsKey: ik}
+
+	eis, _ := lenientTestItems(t, s, DecryptionBatchThreshold+10).Quarantine()
+
+	di, q := DecryptItemsLenient(s, eis, nil)
+	require.Len(t, di, DecryptionBatchThreshold+10)
+	require.Equal(t, []string{"missing-key", "wrong-key"}, q.UUIDs())
+	require.Equal(t, "unknown-items-key", q[0].ItemsKeyID)
+	require.Equal(t, ik.UUID, q[1].ItemsKeyID)
+}
+
+func TestParseLenient(t *testing.T) {
+	good := DecryptedItem{
+		UUID:        "good",
+		ContentType: common.SNItemTypeNote,
+		Content:     `{"title":"good","text":"text"}`,
+		CreatedAt:   "2024-01-01T00:00:00.000Z",
+		UpdatedAt:   "2024-01-01T00:00:00.000Z",
+	}
+
+	badJSON := good
+	badJSON.UUID = "bad-json"
+	badJSON.Content = "{not json"
+
+	unknownType := good
+	unknownType.UUID = "unknown-type"
+	unknownType.ContentType = "Unknown"
+
+	itemsKey := good
+	itemsKey.UUID = "items-key"
+	itemsKey.ContentType = common.SNItemTypeItemsKey
+
+	its, q := DecryptedItems{badJSON, good, unknownType, itemsKey}.ParseLenient()
+	require.Len(t, its, 2)
+	require.Equal(t, "good", its[0].GetUUID())
+	require.Equal(t, []string{"bad-json"}, q.UUIDs())
+
+	// content types that are not known are passed through rather than quarantined
+	require.IsType(t, &UnknownItem{}, its[1])
+
+	for _, qi := range q {
+		require.Equal(t, QuarantineInvalidContent, qi.Reason)
+		require.ErrorIs(t, qi.Err, ErrInvalidContent)
+	}
+}
+
+func TestParseInvalidContent(t *testing.T) {
+	for _, ct := range []string{common.SNItemTypeNote, common.SNItemTypeTag, common.SNItemTypeTheme} {
+		bad := DecryptedItem{
+			UUID:        "bad",
+			ContentType: ct,
+			Content:     "{not json",
+			CreatedAt:   "2024-01-01T00:00:00.000Z",
+			UpdatedAt:   "2024-01-01T00:00:00.000Z",
+		}
+
+		_, err := ParseItem(bad)
+		require.Error(t, err, ct)
+
+		_, err = (&DecryptedItems{bad}).Parse()
+		require.Error(t, err, ct)
+
+		badTime := bad
+		badTime.Content = "{}"
+		badTime.CreatedAt = "yesterday"
+
+		_, err = ParseItem(badTime)
+		require.Error(t, err, ct)
+	}
+}
+
+func TestQuarantineRetryable(t *testing.T) {
+	q := Quarantine{
+		{UUID: "a", ItemsKeyID: "ik1", Reason: QuarantineMissingItemsKey},
+		{UUID: "b", ItemsKeyID: "ik2", Reason: QuarantineMissingItemsKey},
+		{UUID: "c", ItemsKeyID: "ik1", Reason: QuarantineAuthenticationFailed},
+	}
+
+	require.Empty(t, q.Retryable(nil))
+
+	r := q.Retryable([]session.SessionItemsKey{{UUID: "ik1", ItemsKey: crypto.SecretKey("key")}})
+	require.Equal(t, []string{"a"}, r.UUIDs())
+}
@@ -0,0 +1,776 @@
+package items
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrInvalidQuery is matched by errors returned when a query cannot be parsed.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Query is a parsed search expression, for example:
+//
+//	type:note tag:work updated>2024-01-01 (title~"^RFC" OR text:kubernetes) -trashed
+//
+// Terms are separated by spaces and combined with AND unless separated by OR, and may be
+// grouped with parentheses and negated with - or NOT. A term is either field, operator and
+// value, a flag such as pinned, or a word or quoted phrase to find in the title or text.
+//
+// Fields:
+//
+//	type       content type, e.g. note, tag or SN|Component
+//	uuid       item UUID
+//	title      title
+//	text       text
+//	editor     note editor identifier
+//	tag        tag title or path, e.g. work/projects, including nested tags
+//	created    created_at date
+//	updated    updated_at date
+//	modified   date the user last modified the content
+//	size       content size in bytes
+//	refs       UUID of a referenced item, or the number of references
+//	is         flag: pinned, archived, locked, protected, starred, trashed or deleted
+//
+// Operators:
+//
+//	:          contains (case-insensitive) for text, same day for dates, equals otherwise
+//	= !=       equals, not equals
+//	~          matches the regular expression
+//	< <= > >=  compares dates and numbers
+//
+// Dates may be specified as 2006-01-02, RFC 3339, or relative such as 7.days.ago.
+type Query struct {
+	Root QueryNode
+}
+
+// QueryNode is a node in a parsed Query.
+type QueryNode interface {
+	match(item Item, c *queryContext) bool
+	String() string
+}
+
+// QueryAnd matches items matching all of its nodes.
+type QueryAnd struct {
+	Nodes []QueryNode
+}
+
+// QueryOr matches items matching any of its nodes.
+type QueryOr struct {
+	Nodes []QueryNode
+}
+
+// QueryNot matches items that do not match its node.
+type QueryNot struct {
+	Node QueryNode
+}
+
+// QueryTerm matches a single field against a value. Terms without a field search the title and text.
+type QueryTerm struct {
+	Filter
+	date     time.Time
+	dateOnly bool
+	number   int
+	isNumber bool
+}
+
+var queryFlags = []string{"pinned", "archived", "locked", "protected", "starred", "trashed", "deleted"}
+
+var queryFieldAliases = map[string]string{
+	"created_at":   "created",
+	"updated_at":   "updated",
+	"references":   "refs",
+	"content_type": "type",
+}
+
+var queryFieldOperators = map[string][]string{
+	"":         {":"},
+	"type":     {":", "=", "!=", "~"},
+	"uuid":     {":", "=", "!="},
+	"title":    {":", "=", "!=", "~"},
+	"text":     {":", "=", "!=", "~"},
+	"editor":   {":", "=", "!=", "~"},
+	"tag":      {":", "=", "!=", "~"},
+	"created":  {":", "=", "!=", "<", "<=", ">", ">="},
+	"updated":  {":", "=", "!=", "<", "<=", ">", ">="},
+	"modified": {":", "=", "!=", "<", "<=", ">", ">="},
+	"size":     {":", "=", "!=", "<", "<=", ">", ">="},
+	"refs":     {":", "=", "!=", "<", "<=", ">", ">="},
+	"is":       {":", "=", "!="},
+}
+
+func (n QueryAnd) String() string {
+	return joinQueryNodes(n.Nodes, " AND ")
+}
+
+func (n QueryOr) String() string {
+	return joinQueryNodes(n.Nodes, " OR ")
+}
+
+func joinQueryNodes(nodes []QueryNode, sep string) string {
+	var s []string
+	for _, n := range nodes {
+		s = append(s, n.String())
+	}
+
+	return "(" + strings.Join(s, sep) + ")"
+}
+
+func (n QueryNot) String() string {
+	return "NOT " + n.Node.String()
+}
+
+func (t QueryTerm) String() string {
+	if t.Key == "" {
+		return strconv.Quote(t.Value)
+	}
+
+	return t.Key + t.Comparison + strconv.Quote(t.Value)
+}
+
+func (n QueryAnd) match(item Item, c *queryContext) bool {
+	for _, x := range n.Nodes {
+		if !x.match(item, c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (n QueryOr) match(item Item, c *queryContext) bool {
+	return slices.ContainsFunc(n.Nodes, func(x QueryNode) bool {
+		return x.match(item, c)
+	})
+}
+
+func (n QueryNot) match(item Item, c *queryContext) bool {
+	return !n.Node.match(item, c)
+}
+
+// String returns the query in a canonical form with explicit grouping.
+func (q *Query) String() string {
+	if q == nil || q.Root == nil {
+		return ""
+	}
+
+	return q.Root.String()
+}
+
+// ParseQuery parses a search expression into a Query.
+func ParseQuery(s string) (*Query, error) {
+	tokens, err := lexQuery(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+
+	if len(tokens) == 0 {
+		return &Query{Root: QueryAnd{}}, nil
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q at position %d", ErrInvalidQuery, p.tokens[p.pos].text, p.tokens[p.pos].offset)
+	}
+
+	return &Query{Root: root}, nil
+}
+
+// Match returns true if the item matches the query. allItems is used to resolve tags and may be nil.
+func (q *Query) Match(item Item, allItems Items) bool {
+	return q.Root.match(item, newQueryContext(allItems))
+}
+
+// FilterQuery removes items that do not match the query.
+func (i *Items) FilterQuery(q *Query) {
+	c := newQueryContext(*i)
+
+	var filtered Items
+
+	for _, x := range *i {
+		if q.Root.match(x, c) {
+			filtered = append(filtered, x)
+		}
+	}
+
+	*i = filtered
+}
+
+type queryTokenType int
+
+const (
+	queryTokenTerm queryTokenType = iota
+	queryTokenOpen
+	queryTokenClose
+	queryTokenNot
+	queryTokenAnd
+	queryTokenOr
+)
+
+type queryToken struct {
+	typ    queryTokenType
+	text   string
+	offset int
+	term   QueryTerm
+}
+
+func isQueryFieldRune(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
+
+func isQueryOperatorStart(r rune) bool {
+	return strings.ContainsRune(":=!~<>", r)
+}
+
+// lexQuery splits the query into tokens, parsing each term.
+func lexQuery(s string) (tokens []queryToken, err error) {
+	rs := []rune(s)
+
+	for pos := 0; pos < len(rs); {
+		r := rs[pos]
+
+		switch {
+		case unicode.IsSpace(r):
+			pos++
+		case r == '(':
+			tokens = append(tokens, queryToken{typ: queryTokenOpen, text: "(", offset: pos})
+			pos++
+		case r == ')':
+			tokens = append(tokens, queryToken{typ: queryTokenClose, text: ")", offset: pos})
+			pos++
+		case r == '-' && pos+1 < len(rs) && !unicode.IsSpace(rs[pos+1]):
+			tokens = append(tokens, queryToken{typ: queryTokenNot, text: "-", offset: pos})
+			pos++
+		default:
+			var t queryToken
+
+			if t, pos, err = lexQueryTerm(rs, pos); err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, t)
+		}
+	}
+
+	return tokens, nil
+}
+
+// lexQueryValue reads a quoted or bare value.
+func lexQueryValue(rs []rune, pos int) (string, int, error) {
+	if pos < len(rs) && rs[pos] == '"' {
+		start := pos
+
+		var sb strings.Builder
+
+		for pos++; pos < len(rs); pos++ {
+			switch rs[pos] {
+			case '\\':
+				// only quotes and backslashes are escaped so regular expressions can be written as is
+				if pos+1 < len(rs) && (rs[pos+1] == '"' || rs[pos+1] == '\\') {
+					pos++
+				}
+
+				sb.WriteRune(rs[pos])
+			case '"':
+				return sb.String(), pos + 1, nil
+			default:
+				sb.WriteRune(rs[pos])
+			}
+		}
+
+		return "", pos, fmt.Errorf("%w: unterminated quote at position %d", ErrInvalidQuery, start)
+	}
+
+	start := pos
+	for pos < len(rs) && !unicode.IsSpace(rs[pos]) && rs[pos] != '(' && rs[pos] != ')' {
+		pos++
+	}
+
+	return string(rs[start:pos]), pos, nil
+}
+
+func lexQueryTerm(rs []rune, pos int) (queryToken, int, error) {
+	start := pos
+
+	// field followed by an operator
+	end := pos
+	for end < len(rs) && isQueryFieldRune(rs[end]) {
+		end++
+	}
+
+	if end > pos && end < len(rs) && isQueryOperatorStart(rs[end]) {
+		field := strings.ToLower(string(rs[pos:end]))
+
+		op := string(rs[end])
+		if end+1 < len(rs) && rs[end+1] == '=' && op != ":" && op != "=" && op != "~" {
+			op += "="
+		}
+
+		value, next, err := lexQueryValue(rs, end+len(op))
+		if err != nil {
+			return queryToken{}, next, err
+		}
+
+		term, err := newQueryTerm(field, op, value)
+		if err != nil {
+			return queryToken{}, next, fmt.Errorf("%w at position %d", err, start)
+		}
+
+		return queryToken{typ: queryTokenTerm, text: string(rs[start:next]), offset: start, term: term}, next, nil
+	}
+
+	quoted := rs[pos] == '"'
+
+	value, next, err := lexQueryValue(rs, pos)
+	if err != nil {
+		return queryToken{}, next, err
+	}
+
+	t := queryToken{typ: queryTokenTerm, text: string(rs[start:next]), offset: start}
+
+	switch {
+	case !quoted && value == "AND":
+		t.typ = queryTokenAnd
+	case !quoted && value == "OR":
+		t.typ = queryTokenOr
+	case !quoted && value == "NOT":
+		t.typ = queryTokenNot
+	case !quoted && slices.Contains(queryFlags, strings.ToLower(value)):
+		t.term, err = newQueryTerm("is", ":", value)
+	default:
+		t.term, err = newQueryTerm("", ":", value)
+	}
+
+	return t, next, err
+}
+
+// newQueryTerm validates a term and prepares its value for matching.
+func newQueryTerm(field, op, value string) (t QueryTerm, err error) {
+	if alias, ok := queryFieldAliases[field]; ok {
+		field = alias
+	}
+
+	if slices.Contains(queryFlags, field) {
+		// pinned:true is equivalent to is:pinned and pinned:false to -is:pinned
+		b, perr := strconv.ParseBool(value)
+		if perr != nil || (op != ":" && op != "=" && op != "!=") {
+			return t, fmt.Errorf("%w: %s requires true or false", ErrInvalidQuery, field)
+		}
+
+		if op == "!=" {
+			b = !b
+		}
+
+		op = "="
+		if !b {
+			op = "!="
+		}
+
+		field, value = "is", field
+	}
+
+	ops, ok := queryFieldOperators[field]
+	if !ok {
+		return t, fmt.Errorf("%w: unknown field %q", ErrInvalidQuery, field)
+	}
+
+	if !slices.Contains(ops, op) {
+		return t, fmt.Errorf("%w: operator %q cannot be used with %s", ErrInvalidQuery, op, field)
+	}
+
+	t.Filter = Filter{Type: "Item", Key: field, Comparison: op, Value: value}
+
+	switch field {
+	case "is":
+		t.Value = strings.ToLower(value)
+		if !slices.Contains(queryFlags, t.Value) {
+			return t, fmt.Errorf("%w: unknown flag %q", ErrInvalidQuery, value)
+		}
+	case "created", "updated", "modified":
+		if t.date, t.dateOnly, err = parseQueryDate(value); err != nil {
+			return t, err
+		}
+	case "size":
+		if t.number, err = strconv.Atoi(value); err != nil {
+			return t, fmt.Errorf("%w: size must be a number", ErrInvalidQuery)
+		}
+
+		t.isNumber = true
+	case "refs":
+		if n, nerr := strconv.Atoi(value); nerr == nil {
+			t.number, t.isNumber = n, true
+		} else if op != ":" && op != "=" && op != "!=" {
+			return t, fmt.Errorf("%w: refs must be compared with a number", ErrInvalidQuery)
+		}
+	}
+
+	if op == "~" {
+		// reuse the Filter's compiled regex so that the pattern is only compiled once
+		if t.compiledRE, err = regexp.Compile(value); err != nil {
+			return t, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+	}
+
+	return t, nil
+}
+
+func parseQueryDate(value string) (time.Time, bool, error) {
+	if strings.HasSuffix(value, ".ago") {
+		t, err := relativeDate(value, time.Now())
+		if err != nil {
+			return t, false, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+
+		return t, false, nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, false, nil
+	}
+
+	return time.Time{}, false, fmt.Errorf("%w: invalid date %q", ErrInvalidQuery, value)
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, false
+	}
+
+	return p.tokens[p.pos], true
+}
+
+func (p *queryParser) parseOr() (QueryNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []QueryNode{first}
+
+	for {
+		t, ok := p.peek()
+		if !ok || t.typ != queryTokenOr {
+			break
+		}
+
+		p.pos++
+
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, next)
+	}
+
+	if len(nodes) == 1 {
+		return first, nil
+	}
+
+	return QueryOr{Nodes: nodes}, nil
+}
+
+func (p *queryParser) parseAnd() (QueryNode, error) {
+	var nodes []QueryNode
+
+	for {
+		t, ok := p.peek()
+		if !ok || t.typ == queryTokenOr || t.typ == queryTokenClose {
+			break
+		}
+
+		if t.typ == queryTokenAnd {
+			if len(nodes) == 0 {
+				return nil, fmt.Errorf("%w: unexpected AND at position %d", ErrInvalidQuery, t.offset)
+			}
+
+			p.pos++
+
+			continue
+		}
+
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	switch len(nodes) {
+	case 0:
+		if t, ok := p.peek(); ok {
+			return nil, fmt.Errorf("%w: unexpected %q at position %d", ErrInvalidQuery, t.text, t.offset)
+		}
+
+		return nil, fmt.Errorf("%w: unexpected end of query", ErrInvalidQuery)
+	case 1:
+		return nodes[0], nil
+	default:
+		return QueryAnd{Nodes: nodes}, nil
+	}
+}
+
+func (p *queryParser) parseUnary() (QueryNode, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected end of query", ErrInvalidQuery)
+	}
+
+	switch t.typ {
+	case queryTokenNot:
+		p.pos++
+
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return QueryNot{Node: n}, nil
+	case queryTokenOpen:
+		p.pos++
+
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if c, ok := p.peek(); !ok || c.typ != queryTokenClose {
+			return nil, fmt.Errorf("%w: missing closing parenthesis for position %d", ErrInvalidQuery, t.offset)
+		}
+
+		p.pos++
+
+		return n, nil
+	case queryTokenTerm:
+		p.pos++
+
+		return t.term, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected %q at position %d", ErrInvalidQuery, t.text, t.offset)
+	}
+}
+
+// queryContext holds the state shared when matching a query against many items.
+type queryContext struct {
+	*predicateContext
+	tree     *TagTree
+	tagUUIDs map[string]map[string]bool
+}
+
+func newQueryContext(all Items) *queryContext {
+	return &queryContext{predicateContext: newPredicateContext(all), tagUUIDs: make(map[string]map[string]bool)}
+}
+
+// taggedWith returns the UUIDs of the tags with the title or path, and the tags nested within them.
+func (c *queryContext) taggedWith(value string) map[string]bool {
+	if uuids, ok := c.tagUUIDs[value]; ok {
+		return uuids
+	}
+
+	if c.tree == nil {
+		c.tree = c.all.TagTree()
+	}
+
+	uuids := make(map[string]bool)
+
+	add := func(n *TagNode) {
+		uuids[n.Tag.UUID] = true
+		for _, d := range n.Descendants() {
+			uuids[d.Tag.UUID] = true
+		}
+	}
+
+	if strings.Contains(value, TagPathSeparator) {
+		if n, err := c.tree.Find(value); err == nil {
+			add(n)
+		}
+	} else {
+		c.tree.Walk(func(n *TagNode) {
+			if strings.EqualFold(n.Tag.Content.Title, value) {
+				add(n)
+			}
+		})
+	}
+
+	c.tagUUIDs[value] = uuids
+
+	return uuids
+}
+
+type titled interface {
+	GetTitle() string
+}
+
+type texted interface {
+	GetText() string
+}
+
+func queryTitle(item Item) string {
+	if t, ok := item.GetContent().(titled); ok {
+		return t.GetTitle()
+	}
+
+	return ""
+}
+
+func queryText(item Item) string {
+	if t, ok := item.GetContent().(texted); ok {
+		return t.GetText()
+	}
+
+	return ""
+}
+
+func (t QueryTerm) matchString(s string) bool {
+	switch t.Comparison {
+	case ":":
+		return strings.Contains(strings.ToLower(s), strings.ToLower(t.Value))
+	case "=":
+		return s == t.Value
+	case "!=":
+		return s != t.Value
+	case "~":
+		return t.compiledRE.MatchString(s)
+	default:
+		return false
+	}
+}
+
+func (t QueryTerm) matchNumber(n int) bool {
+	switch t.Comparison {
+	case ":", "=":
+		return n == t.number
+	case "!=":
+		return n != t.number
+	case "<":
+		return n < t.number
+	case "<=":
+		return n <= t.number
+	case ">":
+		return n > t.number
+	case ">=":
+		return n >= t.number
+	default:
+		return false
+	}
+}
+
+func (t QueryTerm) matchDate(v any) bool {
+	d, ok := v.(time.Time)
+	if !ok {
+		return false
+	}
+
+	// a date without a time covers the whole day
+	start, end := t.date, t.date
+	if t.dateOnly {
+		end = start.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	switch t.Comparison {
+	case ":", "=":
+		return !d.Before(start) && !d.After(end)
+	case "!=":
+		return d.Before(start) || d.After(end)
+	case "<":
+		return d.Before(start)
+	case "<=":
+		return !d.After(end)
+	case ">":
+		return d.After(end)
+	case ">=":
+		return !d.Before(start)
+	default:
+		return false
+	}
+}
+
+func (t QueryTerm) match(item Item, c *queryContext) bool {
+	switch t.Key {
+	case "":
+		return t.matchString(queryTitle(item)) || t.matchString(queryText(item))
+	case "type":
+		ct := item.GetContentType()
+		if t.Comparison == ":" {
+			return strings.EqualFold(ct, t.Value) || strings.EqualFold(strings.TrimPrefix(ct, "SN|"), t.Value)
+		}
+
+		return t.matchString(ct)
+	case "uuid":
+		if t.Comparison == ":" {
+			return item.GetUUID() == t.Value
+		}
+
+		return t.matchString(item.GetUUID())
+	case "title":
+		return t.matchString(queryTitle(item))
+	case "text":
+		return t.matchString(queryText(item))
+	case "editor":
+		note, ok := item.(*Note)
+
+		return ok && t.matchString(note.Content.EditorIdentifier)
+	case "tag":
+		tags := c.tagsFor(item.GetUUID())
+
+		switch t.Comparison {
+		case "~":
+			return slices.ContainsFunc(tags, func(tag Item) bool {
+				return t.compiledRE.MatchString(queryTitle(tag))
+			})
+		default:
+			uuids := c.taggedWith(t.Value)
+			tagged := slices.ContainsFunc(tags, func(tag Item) bool {
+				return uuids[tag.GetUUID()]
+			})
+
+			return tagged == (t.Comparison != "!=")
+		}
+	case "created":
+		return t.matchDate(keypathValue(item, "created_at", c.predicateContext))
+	case "updated":
+		return t.matchDate(keypathValue(item, "updated_at", c.predicateContext))
+	case "modified":
+		return t.matchDate(keypathValue(item, "userModifiedDate", c.predicateContext))
+	case "size":
+		return t.matchNumber(item.GetContentSize())
+	case "refs":
+		refs := item.GetContent().References()
+		if t.isNumber {
+			return t.matchNumber(len(refs))
+		}
+
+		found := slices.ContainsFunc(refs, func(ref ItemReference) bool {
+			return ref.UUID == t.Value
+		})
+
+		return found == (t.Comparison != "!=")
+	case "is":
+		set := keypathValue(item, t.Value, c.predicateContext) == true
+
+		return set == (t.Comparison != "!=")
+	default:
+		return false
+	}
+}
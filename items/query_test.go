@@ -0,0 +1,146 @@
+package items
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	for in, want := range map[string]string{
+		`type:note tag:work updated>2024-01-01 (title~"^RFC" OR text:kubernetes) -trashed`: `(type:"note" AND tag:"work" AND updated>"2024-01-01" AND (title~"^RFC" OR text:"kubernetes") AND NOT is:"trashed")`,
+		`a OR b c`:                    `("a" OR ("b" AND "c"))`,
+		`a AND NOT (b OR "c d")`:      `("a" AND NOT ("b" OR "c d"))`,
+		`pinned:false`:                `is!="pinned"`,
+		`size>=100 refs<2`:            `(size>="100" AND refs<"2")`,
+		`title~"\d+ \"quoted\""`:      `title~"\\d+ \"quoted\""`,
+		`created_at<=7.days.ago`:      `created<="7.days.ago"`,
+		`-tag:work/projects`:          `NOT tag:"work/projects"`,
+		`  `:                          `()`,
+		`content_type="SN|Component"`: `type="SN|Component"`,
+		`Title:Meeting`:               `title:"Meeting"`,
+	} {
+		q, err := ParseQuery(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, q.String(), in)
+	}
+}
+
+func TestParseQueryInvalid(t *testing.T) {
+	for _, in := range []string{
+		`(title:a`,
+		`title:a)`,
+		`OR a`,
+		`a OR`,
+		`AND a`,
+		`unknown:a`,
+		`size>big`,
+		`title<a`,
+		`title~"("`,
+		`created>yesterday`,
+		`is:red`,
+		`pinned:maybe`,
+		`title:"unterminated`,
+		`refs>uuid`,
+	} {
+		_, err := ParseQuery(in)
+		require.ErrorIs(t, err, ErrInvalidQuery, in)
+	}
+}
+
+func queryTestItems(t *testing.T) Items {
+	t.Helper()
+
+	rfc := createNote("RFC 1234", "about kubernetes", "rfc")
+	rfc.UpdatedAtTimestamp = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC).UnixMicro()
+	rfc.ContentSize = 500
+
+	k8s := createNote("Notes", "Kubernetes operators", "k8s")
+	k8s.UpdatedAtTimestamp = time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC).UnixMicro()
+	k8s.ContentSize = 50
+	k8s.Content.EditorIdentifier = "org.standardnotes.code-editor"
+	k8s.Content.ItemReferences = ItemReferences{{UUID: "rfc", ContentType: common.SNItemTypeNote}}
+
+	trashed := createNote("RFC draft", "", "trashed")
+	trashed.UpdatedAtTimestamp = time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC).UnixMicro()
+	trashed.Content.SetTrashed(true)
+
+	home := createNote("Shopping", "milk", "home")
+	home.UpdatedAtTimestamp = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).UnixMicro()
+	home.SetPinned(true)
+
+	return Items{
+		rfc, k8s, trashed, home,
+		childTag(t, "work", "work", "", noteRef("trashed")),
+		childTag(t, "projects", "projects", "work", noteRef("rfc"), noteRef("k8s")),
+		childTag(t, "home", "home-tag", "", noteRef("home")),
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	all := queryTestItems(t)
+
+	for in, want := range map[string][]string{
+		`type:note tag:work updated>2024-01-01 (title~"^RFC" OR text:kubernetes) -trashed`: {"rfc"},
+		`type:note tag:work updated>=2024-01-01 text:kubernetes`:                           {"rfc", "k8s"},
+		`updated:2024-01-01`:                     {"k8s"},
+		`updated<2024-01-01`:                     {"home"},
+		`updated<=2024-01-01 type:note`:          {"k8s", "home"},
+		`updated>2024-06-01T00:00:00Z type:note`: {"rfc", "trashed"},
+		`kubernetes`:                             {"rfc", "k8s"},
+		`"rfc draft"`:                            {"trashed"},
+		`trashed`:                                {"trashed"},
+		`is:pinned OR size>100`:                  {"rfc", "home"},
+		`pinned:false type:note -trashed`:        {"rfc", "k8s"},
+		`refs:rfc`:                               {"k8s", "projects"},
+		`refs>1 type:tag`:                        {"projects"},
+		`type:tag tag:home`:                      {},
+		`tag:work/projects`:                      {"rfc", "k8s"},
+		`tag!=work type:note`:                    {"home"},
+		`tag~"^pro"`:                             {"rfc", "k8s"},
+		`type:tag title:o`:                       {"work", "projects", "home-tag"},
+		`uuid=home OR uuid:work`:                 {"home", "work"},
+		`editor:code -is:deleted`:                {"k8s"},
+	} {
+		q, err := ParseQuery(in)
+		require.NoError(t, err, in)
+
+		filtered := append(Items{}, all...)
+		filtered.FilterQuery(q)
+
+		matched := []string{}
+		for _, x := range filtered {
+			matched = append(matched, x.GetUUID())
+		}
+
+		require.Equal(t, want, matched, in)
+	}
+}
+
+func TestQueryMatchWithoutItems(t *testing.T) {
+	note := createNote("RFC", "text", "")
+
+	q, err := ParseQuery(`title~"^RFC" -tag:work`)
+	require.NoError(t, err)
+	require.True(t, q.Match(note, nil))
+}
+
+func TestFilterWithQuery(t *testing.T) {
+	all := queryTestItems(t)
+
+	q, err := ParseQuery(`type:note -trashed`)
+	require.NoError(t, err)
+
+	filtered := append(Items{}, all...)
+	filtered.Filter(ItemFilters{Query: q})
+	require.Equal(t, []string{"rfc", "k8s", "home"}, filtered.UUIDs())
+
+	filtered = append(Items{}, all...)
+	filtered.Filter(ItemFilters{
+		Query:   q,
+		Filters: []Filter{{Type: common.SNItemTypeNote, Key: "Title", Comparison: "contains", Value: "RFC"}},
+	})
+	require.Equal(t, []string{"rfc"}, filtered.UUIDs())
+}
@@ -0,0 +1,415 @@
+package items
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/log"
+	"github.com/jonhadfield/gosn-v2/session"
+)
+
+// ErrRevisionNotFound is returned when the server does not have the requested revision.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Revision describes a version of an item kept by the server.
+type Revision struct {
+	UUID        string `json:"uuid"`
+	ItemUUID    string `json:"item_uuid"`
+	ContentType string `json:"content_type"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+	// RequiredRole is the subscription role needed to retrieve the revision, if any.
+	RequiredRole string `json:"required_role,omitempty"`
+}
+
+// revisionPayload is a revision's encrypted content as returned by the server.
+type revisionPayload struct {
+	Revision
+	Content    string  `json:"content"`
+	ItemsKeyID string  `json:"items_key_id"`
+	EncItemKey string  `json:"enc_item_key"`
+	AuthHash   *string `json:"auth_hash"`
+}
+
+// ListRevisions returns the revisions the server has kept of an item, newest first.
+func ListRevisions(s *session.Session, itemUUID string) ([]Revision, error) {
+	body, err := revisionsRequest(s, http.MethodGet, itemUUID, "")
+	if err != nil {
+		return nil, fmt.Errorf("ListRevisions | %w", err)
+	}
+
+	// current servers wrap the list, older servers return it as is
+	var wrapped struct {
+		Revisions []Revision `json:"revisions"`
+	}
+
+	var revisions []Revision
+
+	if err = json.Unmarshal(body, &wrapped); err == nil {
+		revisions = wrapped.Revisions
+	} else if err = json.Unmarshal(body, &revisions); err != nil {
+		return nil, fmt.Errorf("ListRevisions | %w", err)
+	}
+
+	for x := range revisions {
+		if revisions[x].ItemUUID == "" {
+			revisions[x].ItemUUID = itemUUID
+		}
+	}
+
+	slices.SortStableFunc(revisions, func(a, b Revision) int {
+		return strings.Compare(b.CreatedAt, a.CreatedAt)
+	})
+
+	return revisions, nil
+}
+
+// GetRevision retrieves a revision of an item and decrypts it with the matching items key.
+// The item returned has the UUID of the item, not the revision.
+func GetRevision(s *session.Session, itemUUID, revisionUUID string) (Item, error) {
+	body, err := revisionsRequest(s, http.MethodGet, itemUUID, revisionUUID)
+	if err != nil {
+		return nil, fmt.Errorf("GetRevision | %w", err)
+	}
+
+	var wrapped struct {
+		Revision *revisionPayload `json:"revision"`
+	}
+
+	var rp revisionPayload
+
+	if err = json.Unmarshal(body, &wrapped); err == nil && wrapped.Revision != nil {
+		rp = *wrapped.Revision
+	} else if err = json.Unmarshal(body, &rp); err != nil {
+		return nil, fmt.Errorf("GetRevision | %w", err)
+	}
+
+	if rp.Content == "" {
+		return nil, fmt.Errorf("GetRevision | %w: %s", ErrRevisionNotFound, revisionUUID)
+	}
+
+	// the content is authenticated with the item's UUID
+	e := EncryptedItem{
+		UUID:        itemUUID,
+		ItemsKeyID:  rp.ItemsKeyID,
+		Content:     rp.Content,
+		ContentType: rp.ContentType,
+		EncItemKey:  rp.EncItemKey,
+		CreatedAt:   rp.CreatedAt,
+		UpdatedAt:   rp.UpdatedAt,
+		AuthHash:    rp.AuthHash,
+	}
+
+	if t, err := time.Parse(common.TimeLayout, rp.CreatedAt); err == nil {
+		e.CreatedAtTimestamp = t.UnixMicro()
+	}
+
+	if t, err := time.Parse(common.TimeLayout, rp.UpdatedAt); err == nil {
+		e.UpdatedAtTimestamp = t.UnixMicro()
+	}
+
+	di, err := DecryptItem(e, s, s.ItemsKeys)
+	if err != nil {
+		return nil, fmt.Errorf("GetRevision | %w", err)
+	}
+
+	i, err := ParseItem(di)
+	if err != nil {
+		return nil, fmt.Errorf("GetRevision | %w", err)
+	}
+
+	return i, nil
+}
+
+// RestoreRevision writes the content of a revision back to the item as a new update, and syncs it.
+// The current version of the item is required so that the update is not treated as a conflict.
+func RestoreRevision(s *session.Session, current Item, revisionUUID string) (Item, error) {
+	revision, err := GetRevision(s, current.GetUUID(), revisionUUID)
+	if err != nil {
+		return nil, fmt.Errorf("RestoreRevision | %w", err)
+	}
+
+	if revision.GetContentType() != current.GetContentType() {
+		return nil, fmt.Errorf("RestoreRevision | revision is a %s but the item is a %s", revision.GetContentType(), current.GetContentType())
+	}
+
+	restored := restoredItem(current, revision, time.Now().UTC())
+
+	ik := GetMatchingItem(current.GetItemsKeyID(), s.ItemsKeys)
+	if ik.UUID == "" {
+		ik = s.DefaultItemsKey
+	}
+
+	e, err := EncryptItem(restored, ik, s)
+	if err != nil {
+		return nil, fmt.Errorf("RestoreRevision | %w", err)
+	}
+
+	so, err := Sync(SyncInput{Session: s, Items: EncryptedItems{e}})
+	if err != nil {
+		return nil, fmt.Errorf("RestoreRevision | %w", err)
+	}
+
+	if err = so.checkSaved(restored.GetUUID()); err != nil {
+		return nil, fmt.Errorf("RestoreRevision | %w", err)
+	}
+
+	return restored, nil
+}
+
+// restoredItem returns the revision with the current item's timestamps, and its content marked as updated.
+func restoredItem(current, revision Item, now time.Time) Item {
+	revision.SetUUID(current.GetUUID())
+	revision.SetCreatedAt(current.GetCreatedAt())
+	revision.SetCreatedAtTimestamp(current.GetCreatedAtTimestamp())
+	revision.SetUpdatedAt(current.GetUpdatedAt())
+	revision.SetUpdatedAtTimestamp(current.GetUpdatedAtTimestamp())
+	revision.SetDeleted(false)
+
+	if c, ok := revision.GetContent().(interface{ SetUpdateTime(time.Time) }); ok {
+		c.SetUpdateTime(now)
+	}
+
+	return revision
+}
+
+func revisionsRequest(s *session.Session, method, itemUUID, revisionUUID string) ([]byte, error) {
+	if s.HTTPClient == nil {
+		s.HTTPClient = common.NewHTTPClient()
+	}
+
+	server := s.Server
+	if server == "" {
+		server = common.APIServer
+	}
+
+	u := server + fmt.Sprintf(common.RevisionsPath, url.PathEscape(itemUUID))
+	if revisionUUID != "" {
+		u += "/" + url.PathEscape(revisionUUID)
+	}
+
+	req, err := retryablehttp.NewRequest(method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set(common.HeaderContentType, common.SNAPIContentType)
+	s.SetAuthHeaders(req.Header)
+
+	start := time.Now()
+	resp, err := s.HTTPClient.Do(req)
+	log.DebugPrint(s.Debug, fmt.Sprintf("revisions | %s %s | request took: %+v", method, u, time.Since(start)), common.MaxDebugChars)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrRevisionNotFound, strings.TrimPrefix(u, server))
+	case resp.StatusCode >= http.StatusBadRequest:
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("%s: %s", resp.Status, apiErr.Error.Message)
+		}
+
+		return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	return body, nil
+}
+
+// DiffOp is the change made to a line of text.
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = " "
+	DiffInsert DiffOp = "+"
+	DiffDelete DiffOp = "-"
+)
+
+// DiffLine is a line of text and whether it was kept, added or removed.
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// NoteContentDiff is the difference between two versions of a note.
+type NoteContentDiff struct {
+	OldTitle string
+	NewTitle string
+	// Lines are the lines of the text, with those added and removed. The Markdown of Super notes is compared.
+	Lines []DiffLine
+}
+
+// DiffNoteContent compares two versions of a note, such as two revisions.
+func DiffNoteContent(before, after NoteContent) NoteContentDiff {
+	return NoteContentDiff{
+		OldTitle: before.Title,
+		NewTitle: after.Title,
+		Lines:    diffLines(diffText(before), diffText(after)),
+	}
+}
+
+func diffText(c NoteContent) []string {
+	text := c.Text
+
+	if c.IsSuper() {
+		if md, err := c.GetMarkdown(); err == nil {
+			text = md
+		}
+	}
+
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
+
+// Changed returns true if the title or text differ.
+func (d NoteContentDiff) Changed() bool {
+	return d.OldTitle != d.NewTitle || slices.ContainsFunc(d.Lines, func(l DiffLine) bool { return l.Op != DiffEqual })
+}
+
+// String returns the differences in the style of a unified diff, with every line of the text.
+func (d NoteContentDiff) String() string {
+	var sb strings.Builder
+
+	if d.OldTitle != d.NewTitle {
+		sb.WriteString("- title: " + d.OldTitle + "\n+ title: " + d.NewTitle + "\n")
+	}
+
+	for _, l := range d.Lines {
+		sb.WriteString(string(l.Op) + " " + l.Text + "\n")
+	}
+
+	return sb.String()
+}
+
+// diffLines returns the shortest set of changes that turns a into b.
+func diffLines(a, b []string) []DiffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var out []DiffLine
+
+	for _, l := range a[:prefix] {
+		out = append(out, DiffLine{Op: DiffEqual, Text: l})
+	}
+
+	out = append(out, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+
+	for _, l := range a[len(a)-suffix:] {
+		out = append(out, DiffLine{Op: DiffEqual, Text: l})
+	}
+
+	return out
+}
+
+// myersDiff implements Myers' O(ND) difference algorithm.
+func myersDiff(a, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	if n+m == 0 {
+		return nil
+	}
+
+	offset := n + m
+	v := make([]int, 2*offset+2)
+
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(v))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// walk back from the end to find the path taken
+	var rev []DiffLine
+
+	x, y := n, m
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+			prevK = k + 1
+		}
+
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			rev = append(rev, DiffLine{Op: DiffEqual, Text: a[x-1]})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				rev = append(rev, DiffLine{Op: DiffInsert, Text: b[y-1]})
+			} else {
+				rev = append(rev, DiffLine{Op: DiffDelete, Text: a[x-1]})
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	slices.Reverse(rev)
+
+	return rev
+}
@@ -0,0 +1,218 @@
+package items
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/crypto/vectors"
+	"github.com/jonhadfield/gosn-v2/session"
+	"github.com/stretchr/testify/require"
+)
+
+func revisionTestServer(t *testing.T, routes map[string]any) *session.Session {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		body, ok := routes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		require.NoError(t, json.NewEncoder(w).Encode(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	ik := session.SessionItemsKey{UUID: "ik1", ItemsKey: mustSecretKey(vectors.Items004[1].Key)}
+
+	return &session.Session{
+		Server:       srv.URL,
+		AccessToken:  "token",
+		RefreshToken: "refresh",
+		MasterKey:    mustSecretKey(vectors.Items004[1].Key),
+		// expirations are in milliseconds
+		AccessExpiration:  time.Now().Add(time.Hour).UnixMilli(),
+		RefreshExpiration: time.Now().Add(time.Hour).UnixMilli(),
+		ItemsKeys:         []session.SessionItemsKey{ik},
+		DefaultItemsKey:   ik,
+	}
+}
+
+func TestListRevisions(t *testing.T) {
+	s := revisionTestServer(t, map[string]any{
+		"/v1/items/n1/revisions": map[string]any{"revisions": []Revision{
+			{UUID: "r1", ContentType: "Note", CreatedAt: "2024-01-01T00:00:00.000Z", UpdatedAt: "2024-01-01T00:00:00.000Z"},
+			{UUID: "r2", ContentType: "Note", CreatedAt: "2024-02-01T00:00:00.000Z", UpdatedAt: "2024-02-01T00:00:00.000Z"},
+		}},
+		// older servers return the list as is
+		"/v1/items/n2/revisions": []Revision{{UUID: "r3", ContentType: "Note", RequiredRole: "PLUS_USER"}},
+	})
+
+	revisions, err := ListRevisions(s, "n1")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	require.Equal(t, "r2", revisions[0].UUID)
+	require.Equal(t, "n1", revisions[0].ItemUUID)
+
+	revisions, err = ListRevisions(s, "n2")
+	require.NoError(t, err)
+	require.Equal(t, []Revision{{UUID: "r3", ItemUUID: "n2", ContentType: "Note", RequiredRole: "PLUS_USER"}}, revisions)
+
+	_, err = ListRevisions(s, "missing")
+	require.ErrorIs(t, err, ErrRevisionNotFound)
+}
+
+func TestGetRevision(t *testing.T) {
+	s := revisionTestServer(t, nil)
+
+	old := createNote("Plan", "ship it", "n1")
+
+	e, err := EncryptItem(old, s.DefaultItemsKey, s)
+	require.NoError(t, err)
+
+	s = revisionTestServer(t, map[string]any{
+		"/v1/items/n1/revisions/r1": map[string]any{"revision": map[string]any{
+			"uuid":         "r1",
+			"item_uuid":    "n1",
+			"content":      e.Content,
+			"content_type": e.ContentType,
+			"items_key_id": e.ItemsKeyID,
+			"enc_item_key": e.EncItemKey,
+			"created_at":   "2024-01-01T00:00:00.000Z",
+			"updated_at":   "2024-01-02T00:00:00.000Z",
+		}},
+	})
+
+	i, err := GetRevision(s, "n1", "r1")
+	require.NoError(t, err)
+
+	revision := i.(*Note)
+	require.Equal(t, "n1", revision.UUID)
+	require.Equal(t, "Plan", revision.Content.Title)
+	require.Equal(t, "ship it", revision.Content.Text)
+	require.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC).UnixMicro(), revision.UpdatedAtTimestamp)
+
+	_, err = GetRevision(s, "n1", "r2")
+	require.ErrorIs(t, err, ErrRevisionNotFound)
+
+	// the revision is restored with the current item's timestamps so it is not a conflict
+	current := createNote("Plan", "overwritten", "n1")
+	current.UpdatedAtTimestamp = 42
+
+	restored := restoredItem(current, revision, time.Now().UTC()).(*Note)
+	require.Equal(t, int64(42), restored.UpdatedAtTimestamp)
+	require.Equal(t, "ship it", restored.Content.Text)
+
+	diff := DiffNoteContent(current.Content, restored.Content)
+	require.True(t, diff.Changed())
+	require.Equal(t, "- overwritten\n+ ship it\n", diff.String())
+}
+
+func TestRestoreRevision(t *testing.T) {
+	s := revisionTestServer(t, nil)
+
+	old := createNote("Plan", "ship it", "n1")
+
+	e, err := EncryptItem(old, s.DefaultItemsKey, s)
+	require.NoError(t, err)
+
+	revisionRoute := map[string]any{"revision": map[string]any{
+		"uuid":         "r1",
+		"item_uuid":    "n1",
+		"content":      e.Content,
+		"content_type": e.ContentType,
+		"items_key_id": e.ItemsKeyID,
+		"enc_item_key": e.EncItemKey,
+		"created_at":   "2024-01-01T00:00:00.000Z",
+		"updated_at":   "2024-01-02T00:00:00.000Z",
+	}}
+
+	current := createNote("Plan", "overwritten", "n1")
+	current.ItemsKeyID = s.DefaultItemsKey.UUID
+	current.CreatedAtTimestamp = 41
+	current.UpdatedAtTimestamp = 42
+
+	s = revisionTestServer(t, map[string]any{
+		"/v1/items/n1/revisions/r1": revisionRoute,
+		"/v1/items": map[string]any{"data": map[string]any{
+			"saved_items": []map[string]any{{"uuid": "n1", "content_type": "Note"}},
+			"sync_token":  "token",
+		}},
+	})
+
+	restored, err := RestoreRevision(s, current, "r1")
+	require.NoError(t, err)
+
+	note := restored.(*Note)
+	require.Equal(t, "n1", note.UUID)
+	require.Equal(t, "ship it", note.Content.Text)
+	require.Equal(t, int64(41), note.CreatedAtTimestamp)
+	require.Equal(t, int64(42), note.UpdatedAtTimestamp)
+	require.False(t, note.Deleted)
+
+	updated, err := note.Content.GetUpdateTime()
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), updated, time.Minute)
+
+	// the restore is not reported as saved if the server returns it as read-only
+	s = revisionTestServer(t, map[string]any{
+		"/v1/items/n1/revisions/r1": revisionRoute,
+		"/v1/items": map[string]any{"data": map[string]any{
+			"conflicts":  []map[string]any{{"type": ConflictTypeReadOnly, "unsaved_item": map[string]any{"uuid": "n1", "content_type": "Note"}}},
+			"sync_token": "token",
+		}},
+	})
+
+	_, err = RestoreRevision(s, current, "r1")
+	require.ErrorIs(t, err, session.ErrReadOnlySession)
+}
+
+func TestSyncOutputCheckSaved(t *testing.T) {
+	so := SyncOutput{
+		Conflicts: ConflictedItems{{Type: ConflictTypeUUIDError, ServerItem: EncryptedItem{UUID: "n2"}}},
+		ReadOnly:  ConflictedItems{{Type: ConflictTypeReadOnly, UnsavedItem: EncryptedItem{UUID: "n3"}}},
+	}
+
+	require.NoError(t, so.checkSaved("n1"))
+	require.ErrorIs(t, so.checkSaved("n1", "n2"), ErrItemNotSaved)
+	require.ErrorIs(t, so.checkSaved("n3"), session.ErrReadOnlySession)
+}
+
+func TestDiffNoteContent(t *testing.T) {
+	before := createNote("Plan", "a\nb\nc\nd\ne", "n1")
+	after := createNote("Plan v2", "a\nc\nd\nx\ne\nf", "n1")
+
+	diff := DiffNoteContent(before.Content, after.Content)
+	require.True(t, diff.Changed())
+	require.Equal(t, "- title: Plan\n+ title: Plan v2\n"+
+		"  a\n"+
+		"- b\n"+
+		"  c\n"+
+		"  d\n"+
+		"+ x\n"+
+		"  e\n"+
+		"+ f\n", diff.String())
+
+	require.False(t, DiffNoteContent(before.Content, before.Content).Changed())
+
+	// Super notes are compared as Markdown
+	superBefore, err := NewSuperNote("Super", "- [ ] one", nil)
+	require.NoError(t, err)
+
+	superAfter, err := NewSuperNote("Super", "- [x] one", nil)
+	require.NoError(t, err)
+
+	require.Equal(t, []DiffLine{
+		{Op: DiffDelete, Text: "- [ ] one"},
+		{Op: DiffInsert, Text: "- [x] one"},
+	}, DiffNoteContent(superBefore.Content, superAfter.Content).Lines)
+
+	require.Equal(t, []DiffLine{{Op: DiffInsert, Text: "new"}}, diffLines(nil, []string{"new"}))
+	require.Empty(t, diffLines(nil, nil))
+}
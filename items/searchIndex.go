@@ -0,0 +1,584 @@
+package items
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidSearchQuery is returned when a full-text search query cannot be parsed.
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+const (
+	// bm25K1 and bm25B are the standard BM25 term frequency saturation and length normalisation parameters.
+	bm25K1 = 1.2
+	bm25B  = 0.75
+	// searchTitleBoost is the weight of a term occurring in a note's title relative to its text.
+	searchTitleBoost = 3
+	// DefaultSnippetLength is the approximate length, in runes, of a search result's snippet.
+	DefaultSnippetLength = 160
+	// DefaultHighlightStart and DefaultHighlightEnd surround matched terms in search result snippets.
+	DefaultHighlightStart = "<mark>"
+	DefaultHighlightEnd   = "</mark>"
+)
+
+// SearchDocument is the searchable content of a note.
+type SearchDocument struct {
+	UUID               string `json:"uuid"`
+	Title              string `json:"title"`
+	Text               string `json:"text"`
+	Trashed            bool   `json:"trashed,omitempty"`
+	UpdatedAtTimestamp int64  `json:"updated_at_timestamp"`
+}
+
+// NewSearchDocument returns the searchable content of the note. The text of Super notes is converted to plain text.
+func NewSearchDocument(n Note) SearchDocument {
+	text := n.Content.Text
+
+	if n.Content.IsSuper() {
+		if plain, err := LexicalToPlainText(text); err == nil {
+			text = plain
+		}
+	}
+
+	return SearchDocument{
+		UUID:               n.UUID,
+		Title:              n.Content.Title,
+		Text:               text,
+		Trashed:            n.Content.GetTrashed(),
+		UpdatedAtTimestamp: n.UpdatedAtTimestamp,
+	}
+}
+
+// searchToken is a normalised term and its byte offsets within the source string.
+type searchToken struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits s into lower case runs of letters and digits.
+func tokenize(s string) []searchToken {
+	var tokens []searchToken
+
+	start := -1
+
+	for i, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+
+			continue
+		}
+
+		if start >= 0 {
+			tokens = append(tokens, searchToken{term: strings.ToLower(s[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, searchToken{term: strings.ToLower(s[start:]), start: start, end: len(s)})
+	}
+
+	return tokens
+}
+
+type indexedDocument struct {
+	SearchDocument
+	// titleLength is the number of title tokens. Text tokens are positioned after a gap
+	// so that phrases cannot span the title and text.
+	titleLength int
+	length      int
+	terms       []string
+}
+
+// SearchIndex is an in-memory inverted index over the titles and text of notes, supporting
+// term, prefix and phrase queries ranked with BM25. It is safe for concurrent use.
+type SearchIndex struct {
+	mu sync.RWMutex
+	// postings maps each term to the positions it occurs at in each document
+	postings    map[string]map[string][]int
+	docs        map[string]*indexedDocument
+	totalLength int
+	// sortedTerms is the vocabulary in order, for prefix queries, and is rebuilt when nil
+	sortedTerms []string
+}
+
+// NewSearchIndex returns an empty SearchIndex.
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{
+		postings: make(map[string]map[string][]int),
+		docs:     make(map[string]*indexedDocument),
+	}
+}
+
+// Add indexes the notes, replacing any existing entries with the same UUIDs.
+// Deleted notes are removed from the index.
+func (idx *SearchIndex) Add(notes ...Note) {
+	docs := make([]SearchDocument, 0, len(notes))
+
+	for _, n := range notes {
+		if n.Deleted {
+			idx.Remove(n.UUID)
+
+			continue
+		}
+
+		docs = append(docs, NewSearchDocument(n))
+	}
+
+	idx.AddDocuments(docs...)
+}
+
+// AddDocuments indexes the documents, replacing any existing entries with the same UUIDs.
+func (idx *SearchIndex) AddDocuments(docs ...SearchDocument) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, d := range docs {
+		idx.remove(d.UUID)
+
+		doc := &indexedDocument{SearchDocument: d}
+		positions := make(map[string][]int)
+
+		titleTokens := tokenize(d.Title)
+		for p, t := range titleTokens {
+			positions[t.term] = append(positions[t.term], p)
+		}
+
+		doc.titleLength = len(titleTokens)
+
+		textTokens := tokenize(d.Text)
+		for p, t := range textTokens {
+			positions[t.term] = append(positions[t.term], doc.titleLength+1+p)
+		}
+
+		doc.length = doc.titleLength + len(textTokens)
+
+		for term, pos := range positions {
+			docPostings, ok := idx.postings[term]
+			if !ok {
+				docPostings = make(map[string][]int)
+				idx.postings[term] = docPostings
+				idx.sortedTerms = nil
+			}
+
+			docPostings[d.UUID] = pos
+			doc.terms = append(doc.terms, term)
+		}
+
+		idx.docs[d.UUID] = doc
+		idx.totalLength += doc.length
+	}
+}
+
+// Remove removes the notes with the specified UUIDs from the index.
+func (idx *SearchIndex) Remove(uuids ...string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, uuid := range uuids {
+		idx.remove(uuid)
+	}
+}
+
+func (idx *SearchIndex) remove(uuid string) {
+	doc, ok := idx.docs[uuid]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.terms {
+		delete(idx.postings[term], uuid)
+
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+			idx.sortedTerms = nil
+		}
+	}
+
+	idx.totalLength -= doc.length
+	delete(idx.docs, uuid)
+}
+
+// Len returns the number of notes indexed.
+func (idx *SearchIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.docs)
+}
+
+// Document returns the indexed content of the note with the specified UUID.
+func (idx *SearchIndex) Document(uuid string) (SearchDocument, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	doc, ok := idx.docs[uuid]
+	if !ok {
+		return SearchDocument{}, false
+	}
+
+	return doc.SearchDocument, true
+}
+
+// searchClause is a single term, prefix or phrase that a document must match.
+type searchClause struct {
+	terms  []string
+	prefix bool
+}
+
+// parseSearchQuery splits a query into clauses. Quoted strings are phrases, a trailing *
+// makes the last term a prefix, and a word containing punctuation, such as "e-mail", is
+// treated as a phrase of its parts.
+func parseSearchQuery(query string) ([]searchClause, error) {
+	var clauses []searchClause
+
+	add := func(s string, phrase bool) {
+		prefix := !phrase && strings.HasSuffix(s, "*")
+
+		var terms []string
+		for _, t := range tokenize(s) {
+			terms = append(terms, t.term)
+		}
+
+		if len(terms) > 0 {
+			clauses = append(clauses, searchClause{terms: terms, prefix: prefix})
+		}
+	}
+
+	for rest := strings.TrimSpace(query); rest != ""; rest = strings.TrimSpace(rest) {
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated phrase in %q", ErrInvalidSearchQuery, query)
+			}
+
+			add(rest[1:end+1], true)
+			rest = rest[end+2:]
+
+			continue
+		}
+
+		end := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+		if end < 0 {
+			end = len(rest)
+		}
+
+		add(rest[:end], false)
+		rest = rest[end:]
+	}
+
+	if len(clauses) == 0 {
+		return nil, fmt.Errorf("%w: no terms in %q", ErrInvalidSearchQuery, query)
+	}
+
+	return clauses, nil
+}
+
+// SearchOptions control the results returned by SearchIndex.Search.
+type SearchOptions struct {
+	// Limit is the maximum number of results to return. All results are returned if zero.
+	Limit int
+	// IncludeTrashed returns trashed notes, which are otherwise excluded.
+	IncludeTrashed bool
+	// SnippetLength is the approximate length, in runes, of each snippet. DefaultSnippetLength is used if zero.
+	SnippetLength int
+	// HighlightStart and HighlightEnd surround matched terms in snippets. If both are empty, DefaultHighlightStart
+	// and DefaultHighlightEnd are used and the snippet is HTML, with the note's text escaped. Otherwise the
+	// note's text is included as is.
+	HighlightStart string
+	HighlightEnd   string
+}
+
+// SearchResult is a note matching a search query.
+type SearchResult struct {
+	UUID  string
+	Title string
+	Score float64
+	// Snippet is an extract of the note's text with matched terms highlighted. It is escaped HTML
+	// unless custom highlight markers were specified in SearchOptions.
+	Snippet string
+}
+
+// Search returns the notes matching every term, prefix (term*) and "quoted phrase" in the query,
+// ordered by BM25 relevance.
+func (idx *SearchIndex) Search(query string, opts SearchOptions) ([]SearchResult, error) {
+	clauses, err := parseSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	idx.mu.Lock()
+	if idx.sortedTerms == nil {
+		idx.sortedTerms = idx.vocabulary()
+	}
+	idx.mu.Unlock()
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var candidates map[string]float64
+
+	matched := make(map[string]bool)
+
+	for _, c := range clauses {
+		scores := idx.matchClause(c, matched)
+
+		if candidates == nil {
+			candidates = scores
+		} else {
+			for uuid, score := range candidates {
+				if s, ok := scores[uuid]; ok {
+					candidates[uuid] = score + s
+				} else {
+					delete(candidates, uuid)
+				}
+			}
+		}
+
+		if len(candidates) == 0 {
+			return nil, nil
+		}
+	}
+
+	results := make([]SearchResult, 0, len(candidates))
+
+	for uuid, score := range candidates {
+		doc := idx.docs[uuid]
+		if doc.Trashed && !opts.IncludeTrashed {
+			continue
+		}
+
+		results = append(results, SearchResult{UUID: uuid, Title: doc.Title, Score: score})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+
+		if results[i].Title != results[j].Title {
+			return results[i].Title < results[j].Title
+		}
+
+		return results[i].UUID < results[j].UUID
+	})
+
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+
+	// snippets are only generated for the results returned
+	for x := range results {
+		results[x].Snippet = snippet(idx.docs[results[x].UUID].Text, matched, opts)
+	}
+
+	return results, nil
+}
+
+// matchClause returns the BM25 score of each document matching the clause, and adds the terms
+// matched to matched so they can be highlighted.
+func (idx *SearchIndex) matchClause(c searchClause, matched map[string]bool) map[string]float64 {
+	last := len(c.terms) - 1
+
+	lastTerms := []string{c.terms[last]}
+	if c.prefix {
+		lastTerms = idx.termsWithPrefix(c.terms[last])
+	}
+
+	scores := make(map[string]float64)
+
+	for _, lastTerm := range lastTerms {
+		terms := append(slices.Clone(c.terms[:last]), lastTerm)
+
+		for uuid := range idx.postings[terms[0]] {
+			if !idx.containsPhrase(uuid, terms) {
+				continue
+			}
+
+			for _, term := range terms {
+				scores[uuid] += idx.bm25(term, uuid)
+			}
+		}
+
+		for _, term := range terms {
+			matched[term] = true
+		}
+	}
+
+	return scores
+}
+
+// vocabulary returns the indexed terms in order.
+func (idx *SearchIndex) vocabulary() []string {
+	terms := make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		terms = append(terms, term)
+	}
+
+	slices.Sort(terms)
+
+	return terms
+}
+
+// termsWithPrefix returns the indexed terms beginning with prefix.
+func (idx *SearchIndex) termsWithPrefix(prefix string) []string {
+	sorted := idx.sortedTerms
+	if sorted == nil {
+		// the index changed since the vocabulary was sorted
+		sorted = idx.vocabulary()
+	}
+
+	start := sort.SearchStrings(sorted, prefix)
+
+	var terms []string
+
+	for _, term := range sorted[start:] {
+		if !strings.HasPrefix(term, prefix) {
+			break
+		}
+
+		terms = append(terms, term)
+	}
+
+	return terms
+}
+
+// containsPhrase returns true if the terms occur consecutively in the document.
+func (idx *SearchIndex) containsPhrase(uuid string, terms []string) bool {
+	if len(terms) == 1 {
+		return len(idx.postings[terms[0]][uuid]) > 0
+	}
+
+	for _, start := range idx.postings[terms[0]][uuid] {
+		found := true
+
+		for offset, term := range terms[1:] {
+			if _, ok := slices.BinarySearch(idx.postings[term][uuid], start+offset+1); !ok {
+				found = false
+
+				break
+			}
+		}
+
+		if found {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (idx *SearchIndex) bm25(term, uuid string) float64 {
+	docPostings := idx.postings[term]
+	doc := idx.docs[uuid]
+
+	var tf float64
+
+	for _, p := range docPostings[uuid] {
+		if p < doc.titleLength {
+			tf += searchTitleBoost
+		} else {
+			tf++
+		}
+	}
+
+	n := float64(len(idx.docs))
+	df := float64(len(docPostings))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+	avgLength := float64(idx.totalLength) / n
+	if avgLength == 0 {
+		avgLength = 1
+	}
+
+	return idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(doc.length)/avgLength))
+}
+
+// snippet returns an extract of text around the first matched term with each matched term highlighted.
+func snippet(text string, matched map[string]bool, opts SearchOptions) string {
+	length := opts.SnippetLength
+	if length <= 0 {
+		length = DefaultSnippetLength
+	}
+
+	// the text is only escaped when producing HTML with the default markers
+	escape := func(s string) string { return s }
+
+	hlStart, hlEnd := opts.HighlightStart, opts.HighlightEnd
+	if hlStart == "" && hlEnd == "" {
+		hlStart, hlEnd = DefaultHighlightStart, DefaultHighlightEnd
+		escape = html.EscapeString
+	}
+
+	tokens := tokenize(text)
+
+	first := slices.IndexFunc(tokens, func(t searchToken) bool { return matched[t.term] })
+
+	// start a third of the way into the snippet before the first match, at a word boundary
+	start := 0
+	if first >= 0 {
+		start = tokens[first].start
+		for x := first; x >= 0 && utf8.RuneCountInString(text[tokens[x].start:tokens[first].start]) <= length/3; x-- {
+			start = tokens[x].start
+		}
+	}
+
+	end := len(text)
+	if utf8.RuneCountInString(text[start:]) > length {
+		end = start
+		for _, t := range tokens {
+			if t.start < start {
+				continue
+			}
+
+			if utf8.RuneCountInString(text[start:t.end]) > length {
+				break
+			}
+
+			end = t.end
+		}
+
+		if end == start {
+			// a single token longer than the snippet
+			end = start + len(string([]rune(text[start:])[:length]))
+		}
+	}
+
+	var sb strings.Builder
+
+	if start > 0 {
+		sb.WriteString("…")
+	}
+
+	pos := start
+
+	for _, t := range tokens {
+		if t.start < start || t.end > end || !matched[t.term] {
+			continue
+		}
+
+		sb.WriteString(escape(text[pos:t.start]))
+		sb.WriteString(hlStart)
+		sb.WriteString(escape(text[t.start:t.end]))
+		sb.WriteString(hlEnd)
+		pos = t.end
+	}
+
+	sb.WriteString(escape(text[pos:end]))
+
+	if end < len(text) {
+		sb.WriteString("…")
+	}
+
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
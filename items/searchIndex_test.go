@@ -0,0 +1,177 @@
+package items
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func searchTestIndex() *SearchIndex {
+	trashed := createNote("Kubernetes rubbish", "kubernetes", "trashed")
+	trashed.Content.SetTrashed(true)
+
+	idx := NewSearchIndex()
+	idx.Add(
+		*createNote("Kubernetes operators", "Writing an operator for kubernetes clusters.", "k8s"),
+		*createNote("Meeting notes", "Discussed the kubernetes migration and the e-mail rollout.", "meeting"),
+		*createNote("Shopping", "milk, bread, operating manual", "shopping"),
+		*trashed,
+	)
+
+	return idx
+}
+
+func searchUUIDs(results []SearchResult) []string {
+	uuids := []string{}
+	for _, r := range results {
+		uuids = append(uuids, r.UUID)
+	}
+
+	return uuids
+}
+
+func TestSearchIndexQueries(t *testing.T) {
+	idx := searchTestIndex()
+	require.Equal(t, 4, idx.Len())
+
+	for query, want := range map[string][]string{
+		// a title match ranks above a text match
+		`kubernetes`:              {"k8s", "meeting"},
+		`KUBERNETES migration`:    {"meeting"},
+		`oper*`:                   {"k8s", "shopping"},
+		`"kubernetes migration"`:  {"meeting"},
+		`"migration kubernetes"`:  {},
+		`"notes discussed"`:       {},
+		`e-mail`:                  {"meeting"},
+		`mail`:                    {"meeting"},
+		`rubbish`:                 {},
+		`missing`:                 {},
+		`zzz*`:                    {},
+		`"kubernetes clusters" *`: {"k8s"},
+	} {
+		results, err := idx.Search(query, SearchOptions{})
+		require.NoError(t, err, query)
+		require.Equal(t, want, searchUUIDs(results), query)
+	}
+
+	results, err := idx.Search("rubbish", SearchOptions{IncludeTrashed: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"trashed"}, searchUUIDs(results))
+
+	super, err := NewSuperNote("Super", "**kubernetes** in a table:\n\n| root |\n| --- |", nil)
+	require.NoError(t, err)
+	idx.Add(super)
+
+	// Super notes are indexed as plain text rather than Lexical JSON
+	results, err = idx.Search("root", SearchOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{super.UUID}, searchUUIDs(results))
+	require.Equal(t, "kubernetes in a table: <mark>root</mark>", results[0].Snippet)
+	idx.Remove(super.UUID)
+
+	results, err = idx.Search("kubernetes", SearchOptions{Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, []string{"k8s"}, searchUUIDs(results))
+
+	for _, query := range []string{``, `  `, `"unterminated`, `***`} {
+		_, err = idx.Search(query, SearchOptions{})
+		require.ErrorIs(t, err, ErrInvalidSearchQuery, query)
+	}
+}
+
+func TestSearchIndexUpdate(t *testing.T) {
+	idx := searchTestIndex()
+
+	updated := createNote("Shopping", "eggs", "shopping")
+	idx.Add(*updated)
+	require.Equal(t, 4, idx.Len())
+
+	results, err := idx.Search("milk", SearchOptions{})
+	require.NoError(t, err)
+	require.Empty(t, results)
+
+	results, err = idx.Search("eggs", SearchOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"shopping"}, searchUUIDs(results))
+
+	idx.Remove("k8s")
+
+	results, err = idx.Search("oper*", SearchOptions{})
+	require.NoError(t, err)
+	require.Empty(t, results)
+
+	deleted := createNote("Meeting notes", "", "meeting")
+	deleted.Deleted = true
+	idx.Add(*deleted)
+	require.Equal(t, 2, idx.Len())
+
+	_, ok := idx.Document("meeting")
+	require.False(t, ok)
+
+	doc, ok := idx.Document("shopping")
+	require.True(t, ok)
+	require.Equal(t, "eggs", doc.Text)
+}
+
+func TestSearchIndexSnippets(t *testing.T) {
+	idx := searchTestIndex()
+
+	results, err := idx.Search(`"kubernetes migration"`, SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "Discussed the <mark>kubernetes</mark> <mark>migration</mark> and the e-mail rollout.", results[0].Snippet)
+
+	results, err = idx.Search("oper*", SearchOptions{HighlightStart: "**", HighlightEnd: "**"})
+	require.NoError(t, err)
+	require.Equal(t, "Writing an **operator** for kubernetes clusters.", results[0].Snippet)
+	require.Equal(t, "milk, bread, **operating** manual", results[1].Snippet)
+
+	long := createNote("Long", strings.Repeat("lorem ipsum ", 50)+"needle "+strings.Repeat("dolor sit ", 50), "long")
+	idx.Add(*long)
+
+	results, err = idx.Search("needle", SearchOptions{SnippetLength: 40})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.True(t, strings.HasPrefix(results[0].Snippet, "…"), results[0].Snippet)
+	require.True(t, strings.HasSuffix(results[0].Snippet, "…"), results[0].Snippet)
+	require.Contains(t, results[0].Snippet, "<mark>needle</mark>")
+	require.LessOrEqual(t, len([]rune(results[0].Snippet)), 40+2+len("<mark></mark>"))
+
+	// the note's text is escaped in HTML snippets, but not when using custom markers
+	html := createNote("HTML", `Inline <script>alert("xss")</script> & escaping`, "html")
+	idx.Add(*html)
+
+	results, err = idx.Search("escaping", SearchOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "Inline &lt;script&gt;alert(&#34;xss&#34;)&lt;/script&gt; &amp; <mark>escaping</mark>", results[0].Snippet)
+
+	results, err = idx.Search("escaping", SearchOptions{HighlightStart: "[", HighlightEnd: "]"})
+	require.NoError(t, err)
+	require.Equal(t, `Inline <script>alert("xss")</script> & [escaping]`, results[0].Snippet)
+}
+
+func BenchmarkSearchIndex(b *testing.B) {
+	words := strings.Fields("alpha beta gamma delta epsilon zeta eta theta iota kappa lambda mu nu xi omicron pi rho sigma tau upsilon")
+
+	idx := NewSearchIndex()
+
+	for x := range 20000 {
+		var sb strings.Builder
+		for y := range 200 {
+			sb.WriteString(words[(x*7+y*y)%len(words)])
+			sb.WriteString(fmt.Sprintf("%d ", (x+y)%500))
+		}
+
+		idx.Add(*createNote(fmt.Sprintf("note %d", x), sb.String(), fmt.Sprintf("uuid-%d", x)))
+	}
+
+	b.ResetTimer()
+
+	for range b.N {
+		if _, err := idx.Search(`"gamma1 delta2" eps*`, SearchOptions{Limit: 20}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
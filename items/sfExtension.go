@@ -2,37 +2,11 @@ package items
 
 import (
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parseSFExtension(i DecryptedItem) Item {
-	c := SFExtension{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		if content != nil {
-			c.Content = *content.(*SFExtensionContent)
-		}
-	}
-
-	return &c
-}
-
 type SFExtensionContent struct {
 	ItemReferences     ItemReferences `json:"references"`
 	AppData            AppDataContent `json:"appData"`
@@ -40,56 +14,32 @@ type SFExtensionContent struct {
 	DissociatedItemIds []string       `json:"disassociatedItemIds"`
 	AssociatedItemIds  []string       `json:"associatedItemIds"`
 	Active             interface{}    `json:"active"`
+	Unknown            UnknownFields  `json:"-"`
 }
 
-type SFExtension struct {
-	ItemCommon
-	Content SFExtensionContent
-}
-
-func (c SFExtension) IsDefault() bool {
-	return false
-}
+func (cc *SFExtensionContent) UnmarshalJSON(data []byte) (err error) {
+	type alias SFExtensionContent
 
-func (i Items) SFExtension() (c SFExtensions) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeSFExtension {
-			component := x.(*SFExtension)
-			c = append(c, *component)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *SFExtensions) DeDupe() {
-	var encountered []string
+func (cc SFExtensionContent) MarshalJSON() ([]byte, error) {
+	type alias SFExtensionContent
 
-	var deDuped SFExtensions
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+type SFExtension = TypedItem[SFExtensionContent, *SFExtensionContent]
 
-		encountered = append(encountered, i.UUID)
-	}
-
-	*c = deDuped
+func (i Items) SFExtension() (c SFExtensions) {
+	return ItemsOfType[SFExtensionContent](i)
 }
 
 // NewSFExtension returns an Item of type SFExtension without content.
 func NewSFExtension() SFExtension {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c SFExtension
-
-	c.ContentType = common.SNItemTypeSFExtension
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[SFExtensionContent](common.SNItemTypeSFExtension)
 }
 
 // NewSFExtensionContent returns an empty Tag content instance.
@@ -100,120 +50,14 @@ func NewSFExtensionContent() *SFExtensionContent {
 	return c
 }
 
-type SFExtensions []SFExtension
-
-func (c SFExtensions) Validate() error {
-	var updatedTime time.Time
+type SFExtensions = TypedItems[SFExtensionContent, *SFExtensionContent]
 
-	var err error
-
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing title: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
+func (cc *SFExtensionContent) MissingField() string {
+	if cc.Name == "" {
+		return "title"
 	}
 
-	return err
-}
-
-func (c SFExtension) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *SFExtension) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c SFExtension) GetContent() Content {
-	return &c.Content
-}
-
-func (c *SFExtension) SetContent(cc Content) {
-	c.Content = *cc.(*SFExtensionContent)
-}
-
-func (c SFExtension) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c SFExtension) GetUUID() string {
-	return c.UUID
-}
-
-func (c SFExtension) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *SFExtension) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c SFExtension) GetContentType() string {
-	return c.ContentType
-}
-
-func (c SFExtension) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *SFExtension) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c SFExtension) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *SFExtension) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c SFExtension) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *SFExtension) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c SFExtension) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *SFExtension) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *SFExtension) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c SFExtension) GetContentSize() int {
-	return c.ContentSize
-}
-
-func (c *SFExtension) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *SFExtensionContent) AssociateItems(newItems []string) {
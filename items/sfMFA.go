@@ -2,37 +2,11 @@ package items
 
 import (
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parseSFMFA(i DecryptedItem) Item {
-	c := SFMFA{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		if content != nil {
-			c.Content = *content.(*SFMFAContent)
-		}
-	}
-
-	return &c
-}
-
 type SFMFAContent struct {
 	ItemReferences     ItemReferences `json:"references"`
 	AppData            AppDataContent `json:"appData"`
@@ -40,56 +14,32 @@ type SFMFAContent struct {
 	DissociatedItemIds []string       `json:"disassociatedItemIds"`
 	AssociatedItemIds  []string       `json:"associatedItemIds"`
 	Active             interface{}    `json:"active"`
+	Unknown            UnknownFields  `json:"-"`
 }
 
-type SFMFA struct {
-	ItemCommon
-	Content SFMFAContent
-}
-
-func (c SFMFA) IsDefault() bool {
-	return false
-}
+func (cc *SFMFAContent) UnmarshalJSON(data []byte) (err error) {
+	type alias SFMFAContent
 
-func (i Items) SFMFA() (c SFMFAs) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeSFMFA {
-			component := x.(*SFMFA)
-			c = append(c, *component)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *SFMFAs) DeDupe() {
-	var encountered []string
+func (cc SFMFAContent) MarshalJSON() ([]byte, error) {
+	type alias SFMFAContent
 
-	var deDuped SFMFAs
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+type SFMFA = TypedItem[SFMFAContent, *SFMFAContent]
 
-		encountered = append(encountered, i.UUID)
-	}
-
-	*c = deDuped
+func (i Items) SFMFA() (c SFMFAs) {
+	return ItemsOfType[SFMFAContent](i)
 }
 
 // NewSFMFA returns an Item of type SFMFA without content.
 func NewSFMFA() SFMFA {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c SFMFA
-
-	c.ContentType = common.SNItemTypeSFMFA
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[SFMFAContent](common.SNItemTypeSFMFA)
 }
 
 // NewTagContent returns an empty Tag content instance.
@@ -100,120 +50,14 @@ func NewSFMFAContent() *SFMFAContent {
 	return c
 }
 
-type SFMFAs []SFMFA
-
-func (c SFMFAs) Validate() error {
-	var updatedTime time.Time
+type SFMFAs = TypedItems[SFMFAContent, *SFMFAContent]
 
-	var err error
-
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing title: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
+func (cc *SFMFAContent) MissingField() string {
+	if cc.Name == "" {
+		return "title"
 	}
 
-	return err
-}
-
-func (c SFMFA) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *SFMFA) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c SFMFA) GetContent() Content {
-	return &c.Content
-}
-
-func (c *SFMFA) SetContent(cc Content) {
-	c.Content = *cc.(*SFMFAContent)
-}
-
-func (c SFMFA) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c SFMFA) GetUUID() string {
-	return c.UUID
-}
-
-func (c SFMFA) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *SFMFA) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c SFMFA) GetContentType() string {
-	return c.ContentType
-}
-
-func (c SFMFA) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *SFMFA) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c SFMFA) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *SFMFA) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c SFMFA) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *SFMFA) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c SFMFA) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *SFMFA) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *SFMFA) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c SFMFA) GetContentSize() int {
-	return c.ContentSize
-}
-
-func (c *SFMFA) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *SFMFAContent) AssociateItems(newItems []string) {
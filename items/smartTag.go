@@ -2,35 +2,11 @@ package items
 
 import (
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parseSmartTag(i DecryptedItem) Item {
-	c := SmartTag{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		c.Content = *content.(*SmartTagContent)
-	}
-
-	return &c
-}
-
 type SmartTagContent struct {
 	ItemReferences     ItemReferences `json:"references"`
 	AppData            AppDataContent `json:"appData"`
@@ -38,56 +14,32 @@ type SmartTagContent struct {
 	DissociatedItemIds []string       `json:"disassociatedItemIds"`
 	AssociatedItemIds  []string       `json:"associatedItemIds"`
 	Active             interface{}    `json:"active"`
+	Unknown            UnknownFields  `json:"-"`
 }
 
-type SmartTag struct {
-	ItemCommon
-	Content SmartTagContent
-}
+func (cc *SmartTagContent) UnmarshalJSON(data []byte) (err error) {
+	type alias SmartTagContent
 
-func (c SmartTag) IsDefault() bool {
-	return false
-}
-
-func (i Items) SmartTag() (c SmartTags) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeSmartTag {
-			component := x.(*SmartTag)
-			c = append(c, *component)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *SmartTags) DeDupe() {
-	var encountered []string
-
-	var deDuped SmartTags
+func (cc SmartTagContent) MarshalJSON() ([]byte, error) {
+	type alias SmartTagContent
 
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-		encountered = append(encountered, i.UUID)
-	}
+type SmartTag = TypedItem[SmartTagContent, *SmartTagContent]
 
-	*c = deDuped
+func (i Items) SmartTag() (c SmartTags) {
+	return ItemsOfType[SmartTagContent](i)
 }
 
 // NewSmartTag returns an Item of type SmartTag without content.
 func NewSmartTag() SmartTag {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c SmartTag
-
-	c.ContentType = common.SNItemTypeSmartTag
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[SmartTagContent](common.SNItemTypeSmartTag)
 }
 
 // NewTagContent returns an empty Tag content instance.
@@ -98,120 +50,14 @@ func NewSmartTagContent() *SmartTagContent {
 	return c
 }
 
-type SmartTags []SmartTag
-
-func (c SmartTags) Validate() error {
-	var updatedTime time.Time
-
-	var err error
+type SmartTags = TypedItems[SmartTagContent, *SmartTagContent]
 
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing title: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
+func (cc *SmartTagContent) MissingField() string {
+	if cc.Name == "" {
+		return "title"
 	}
 
-	return err
-}
-
-func (c SmartTag) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *SmartTag) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c SmartTag) GetContent() Content {
-	return &c.Content
-}
-
-func (c *SmartTag) SetContent(cc Content) {
-	c.Content = *cc.(*SmartTagContent)
-}
-
-func (c SmartTag) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c SmartTag) GetUUID() string {
-	return c.UUID
-}
-
-func (c SmartTag) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *SmartTag) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c SmartTag) GetContentType() string {
-	return c.ContentType
-}
-
-func (c SmartTag) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *SmartTag) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c SmartTag) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *SmartTag) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c SmartTag) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *SmartTag) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c SmartTag) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *SmartTag) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *SmartTag) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c SmartTag) GetContentSize() int {
-	return c.ContentSize
-}
-
-func (c *SmartTag) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *SmartTagContent) AssociateItems(newItems []string) {
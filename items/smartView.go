@@ -1,36 +1,13 @@
 package items
 
 import (
-	"fmt"
-	"slices"
+	"encoding/json"
+	"maps"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parseSmartView(i DecryptedItem) Item {
-	c := SmartView{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		c.Content = *content.(*SmartViewContent)
-	}
-
-	return &c
-}
-
 // SmartViewContent extends TagContent with predicate support for smart filtering
 type SmartViewContent struct {
 	// Embed TagContent for all standard tag functionality
@@ -39,54 +16,51 @@ type SmartViewContent struct {
 	Predicate interface{} `json:"predicate"` // PredicateJsonForm for smart filtering
 }
 
-type SmartView struct {
-	ItemCommon
-	Content SmartViewContent
-}
+// UnmarshalJSON decodes the tag content and predicate. It is needed as the embedded TagContent's
+// method would otherwise be used, and the predicate lost.
+func (cc *SmartViewContent) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &cc.TagContent); err != nil {
+		return err
+	}
+
+	// the predicate is not a tag field so it is one of the tag's unknown fields
+	p, ok := cc.Unknown["predicate"]
+	if !ok {
+		return nil
+	}
 
-func (c SmartView) IsDefault() bool {
-	return false
+	delete(cc.Unknown, "predicate")
+
+	return json.Unmarshal(p, &cc.Predicate)
 }
 
-func (i Items) SmartViews() (c SmartViews) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeSmartTag {
-			smartView := x.(*SmartView)
-			c = append(c, *smartView)
-		}
+func (cc SmartViewContent) MarshalJSON() ([]byte, error) {
+	p, err := json.Marshal(cc.Predicate)
+	if err != nil {
+		return nil, err
 	}
 
-	return c
-}
+	tc := cc.TagContent
+	tc.Unknown = maps.Clone(tc.Unknown)
 
-func (c *SmartViews) DeDupe() {
-	var encountered []string
+	if tc.Unknown == nil {
+		tc.Unknown = make(UnknownFields)
+	}
 
-	var deDuped SmartViews
+	tc.Unknown["predicate"] = p
 
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+	return json.Marshal(tc)
+}
 
-		encountered = append(encountered, i.UUID)
-	}
+type SmartView = TypedItem[SmartViewContent, *SmartViewContent]
 
-	*c = deDuped
+func (i Items) SmartViews() (c SmartViews) {
+	return ItemsOfType[SmartViewContent](i)
 }
 
 // NewSmartView returns an Item of type SmartView without content.
 func NewSmartView() SmartView {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c SmartView
-
-	c.ContentType = common.SNItemTypeSmartTag
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[SmartViewContent](common.SNItemTypeSmartTag)
 }
 
 // NewSmartViewContent returns an empty SmartView content instance.
@@ -97,120 +71,14 @@ func NewSmartViewContent() *SmartViewContent {
 	return c
 }
 
-type SmartViews []SmartView
-
-func (c SmartViews) Validate() error {
-	var updatedTime time.Time
+type SmartViews = TypedItems[SmartViewContent, *SmartViewContent]
 
-	var err error
-
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Title == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing title: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
+func (cc *SmartViewContent) MissingField() string {
+	if cc.Title == "" {
+		return "title"
 	}
 
-	return err
-}
-
-func (c SmartView) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *SmartView) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c SmartView) GetContent() Content {
-	return &c.Content
-}
-
-func (c *SmartView) SetContent(cc Content) {
-	c.Content = *cc.(*SmartViewContent)
-}
-
-func (c SmartView) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c SmartView) GetUUID() string {
-	return c.UUID
-}
-
-func (c SmartView) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *SmartView) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c SmartView) GetContentType() string {
-	return c.ContentType
-}
-
-func (c SmartView) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *SmartView) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c SmartView) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *SmartView) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c SmartView) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *SmartView) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c SmartView) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *SmartView) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *SmartView) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c SmartView) GetContentSize() int {
-	return c.ContentSize
-}
-
-func (c *SmartView) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 // SmartViewContent delegates to embedded TagContent for most methods
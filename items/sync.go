@@ -3,6 +3,7 @@ package items
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
@@ -34,11 +35,13 @@ type SyncInput struct {
 // It contains slices of items based on their state
 // see: https://standardfile.org/ for state details
 type SyncOutput struct {
-	Items      EncryptedItems  // items new or modified since last sync
-	SavedItems EncryptedItems  // dirty items needing resolution
-	Unsaved    EncryptedItems  // items not saved during sync TODO: No longer needed? Replaced by Conflicts?
-	Conflicts  ConflictedItems // items not saved during sync due to significant difference in updated_time values. can be triggered by import where the server item has been updated since export.
-	SyncToken  string
+	Items       EncryptedItems  // items new or modified since last sync
+	SavedItems  EncryptedItems  // dirty items needing resolution
+	Unsaved     EncryptedItems  // items not saved during sync TODO: No longer needed? Replaced by Conflicts?
+	Conflicts   ConflictedItems // items not saved during sync due to significant difference in updated_time values. can be triggered by import where the server item has been updated since export.
+	ReadOnly    ConflictedItems // items rejected by the server as the session only has read access. these are not retried.
+	Quarantined Quarantine      // items returned by the server that could not be processed and have been excluded from Items
+	SyncToken   string
 
 	Cursor string
 }
@@ -60,6 +63,9 @@ const (
 	ConflictTypeInvalidItem = "invalid_server_item"
 )
 
+// ErrItemNotSaved is returned when an item passed to Sync is returned as a conflict instead of being saved.
+var ErrItemNotSaved = errors.New("item not saved")
+
 // encodeBufferPool provides reusable buffers for JSON encoding
 var encodeBufferPool = sync.Pool{
 	New: func() interface{} {
@@ -68,6 +74,22 @@ var encodeBufferPool = sync.Pool{
 	},
 }
 
+// splitReadOnlyConflicts separates conflicts caused by the session being read-only
+// as these cannot be resolved by re-syncing.
+func splitReadOnlyConflicts(conflicts ConflictedItems) (other, readOnly ConflictedItems) {
+	for _, conflict := range conflicts {
+		if conflict.Type == ConflictTypeReadOnly {
+			readOnly = append(readOnly, conflict)
+
+			continue
+		}
+
+		other = append(other, conflict)
+	}
+
+	return other, readOnly
+}
+
 // getConflictTypes returns a slice of conflict types for debugging
 func getConflictTypes(conflicts ConflictedItems) []string {
 	var types []string
@@ -77,6 +99,38 @@ func getConflictTypes(conflicts ConflictedItems) []string {
 	return types
 }
 
+// checkSaved returns an error if any of the items with the given UUIDs were returned as read-only or
+// unresolved conflicts, so callers that sync their own changes do not report them as saved.
+func (so SyncOutput) checkSaved(uuids ...string) error {
+	conflictUUID := func(c ConflictedItem) string {
+		if c.UnsavedItem.UUID != "" {
+			return c.UnsavedItem.UUID
+		}
+
+		return c.ServerItem.UUID
+	}
+
+	var readOnly int
+
+	for _, c := range so.ReadOnly {
+		if slices.Contains(uuids, conflictUUID(c)) {
+			readOnly++
+		}
+	}
+
+	if readOnly > 0 {
+		return &session.ReadOnlyError{Items: readOnly}
+	}
+
+	for _, c := range so.Conflicts {
+		if uuid := conflictUUID(c); slices.Contains(uuids, uuid) {
+			return fmt.Errorf("%w: %s returned as %s", ErrItemNotSaved, uuid, c.Type)
+		}
+	}
+
+	return nil
+}
+
 func syncItems(i SyncInput) (so SyncOutput, err error) {
 	giStart := time.Now()
 	defer func() {
@@ -174,14 +228,16 @@ func syncItems(i SyncInput) (so SyncOutput, err error) {
 	so.Items = sResp.Data.Items
 	so.Items.DeDupe()
 	so.Items.RemoveUnsupported()
+	so.Items, so.Quarantined = so.Items.Quarantine()
 	so.Unsaved = sResp.Data.Unsaved
 	so.Unsaved.DeDupe()
 	so.Unsaved.RemoveUnsupported()
 	so.SavedItems = sResp.Data.SavedItems
 	so.SavedItems.DeDupe()
 	so.SavedItems.RemoveUnsupported()
-	so.Conflicts = sResp.Data.Conflicts
+	so.Conflicts, so.ReadOnly = splitReadOnlyConflicts(sResp.Data.Conflicts)
 	so.Conflicts.DeDupe()
+	so.ReadOnly.DeDupe()
 	so.Cursor = sResp.Data.CursorToken
 	so.SyncToken = sResp.Data.SyncToken
 
@@ -192,6 +248,14 @@ func syncItems(i SyncInput) (so SyncOutput, err error) {
 		fmt.Sprintf("Sync | SN returned %d items, %d saved items, and %d conflicts, with syncToken %s",
 			len(so.Items), len(so.SavedItems), len(so.Conflicts), so.SyncToken), common.MaxDebugChars)
 
+	if len(so.ReadOnly) > 0 {
+		log.DebugPrint(i.Session.Debug, fmt.Sprintf("Sync | SN rejected %d items as the session is read-only", len(so.ReadOnly)), common.MaxDebugChars)
+	}
+
+	for _, q := range so.Quarantined {
+		log.DebugPrint(i.Session.Debug, fmt.Sprintf("Sync | quarantined item: %s", q.Err), common.MaxDebugChars)
+	}
+
 	return
 }
 
@@ -249,10 +313,21 @@ func Sync(input SyncInput) (output SyncOutput, err error) {
 	}
 	input.Items = filteredItems
 
+	if input.Session.IsLocked() {
+		return output, session.ErrSessionLocked
+	}
+
+	// refuse to push items with a read-only session rather than have the server reject them
+	if err = input.Session.CheckWritable(len(input.Items)); err != nil {
+		log.DebugPrint(input.Session.Debug, fmt.Sprintf("Sync | %s", err), common.MaxDebugChars)
+
+		return output, err
+	}
+
 	log.DebugPrint(input.Session.Debug, fmt.Sprintf("Sync | called with %d items and syncToken %s", len(input.Items), input.SyncToken), common.MaxDebugChars)
 	log.DebugPrint(input.Session.Debug, fmt.Sprintf("Sync | pre-sync default items key: %s", input.Session.DefaultItemsKey.UUID), common.MaxDebugChars)
 	// if items have been passed but no default items key exists then return error
-	if len(input.Items) > 0 && input.Session.DefaultItemsKey.ItemsKey == "" {
+	if len(input.Items) > 0 && len(input.Session.DefaultItemsKey.ItemsKey) == 0 {
 		err = fmt.Errorf("missing default items key in session")
 	}
 
@@ -310,6 +385,8 @@ func Sync(input SyncInput) (output SyncOutput, err error) {
 
 		// zero the conflicts as we've resolved them
 		processedOutput.Conflicts = nil
+		processedOutput.ReadOnly = append(processedOutput.ReadOnly, resyncOutput.ReadOnly...)
+		processedOutput.Quarantined = append(processedOutput.Quarantined, resyncOutput.Quarantined...)
 
 		processedOutput.Items = append(processedOutput.Items, resyncOutput.Items...)
 		processedOutput.SavedItems = append(processedOutput.SavedItems, resyncOutput.SavedItems...)
@@ -583,10 +660,10 @@ func processConflict(input SyncInput, conflict ConflictedItem, refReMap map[stri
 		}
 
 	case ConflictTypeReadOnly:
-		// Read-only error means we tried to modify something we shouldn't
-		// Keep the server version
-		log.DebugPrint(debug, "Sync | Read-only conflict, keeping server version", common.MaxDebugChars)
-		conflictedItem = conflict.ServerItem
+		// Read-only error means the session cannot write items, so re-syncing would fail again
+		// These are normally separated before reaching here and returned in SyncOutput.ReadOnly
+		log.DebugPrint(debug, "Sync | Read-only conflict, skipping item", common.MaxDebugChars)
+		return nil, nil
 
 	case ConflictTypeUUIDError, ConflictTypeInvalidItem:
 		// These are serious errors, log and skip
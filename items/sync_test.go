@@ -0,0 +1,66 @@
+package items
+
+import (
+	"testing"
+
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/session"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitReadOnlyConflicts(t *testing.T) {
+	conflicts := ConflictedItems{
+		{Type: ConflictTypeSync, ServerItem: EncryptedItem{UUID: "a"}},
+		{Type: ConflictTypeReadOnly, UnsavedItem: EncryptedItem{UUID: "b"}},
+		{Type: ConflictTypeUUID, UnsavedItem: EncryptedItem{UUID: "c"}},
+		{Type: ConflictTypeReadOnly, UnsavedItem: EncryptedItem{UUID: "d"}},
+	}
+
+	other, readOnly := splitReadOnlyConflicts(conflicts)
+	require.Len(t, other, 2)
+	require.Len(t, readOnly, 2)
+	require.Equal(t, "b", readOnly[0].UnsavedItem.UUID)
+	require.Equal(t, "d", readOnly[1].UnsavedItem.UUID)
+}
+
+func TestProcessReadOnlyConflictIsNotResynced(t *testing.T) {
+	input := SyncInput{Session: &session.Session{}}
+	conflict := ConflictedItem{
+		Type:        ConflictTypeReadOnly,
+		ServerItem:  EncryptedItem{UUID: "a", ContentType: common.SNItemTypeNote},
+		UnsavedItem: EncryptedItem{UUID: "a", ContentType: common.SNItemTypeNote},
+	}
+
+	toSync, err := processConflict(input, conflict, map[string]string{})
+	require.NoError(t, err)
+	require.Empty(t, toSync)
+}
+
+func TestSyncRefusesWritesWithReadOnlySession(t *testing.T) {
+	s := &session.Session{ReadOnly: true}
+	note, err := NewNote("title", "text", nil)
+	require.NoError(t, err)
+
+	_, err = Sync(SyncInput{
+		Session: s,
+		Items: EncryptedItems{{
+			UUID:        note.UUID,
+			ContentType: common.SNItemTypeNote,
+			Content:     "004:abc",
+		}},
+	})
+	require.ErrorIs(t, err, session.ErrReadOnlySession)
+
+	var roErr *session.ReadOnlyError
+	require.ErrorAs(t, err, &roErr)
+	require.Equal(t, 1, roErr.Items)
+	require.True(t, roErr.Requested)
+}
+
+func TestSyncRefusedWithLockedSession(t *testing.T) {
+	s := &session.Session{MasterKey: mustSecretKey("2396d6ac0bc70fe45db1d2bcf3daa522603e9c6fcc88dc933ce1a3a31bbc08ed")}
+	require.NoError(t, s.Lock())
+
+	_, err := Sync(SyncInput{Session: s})
+	require.ErrorIs(t, err, session.ErrSessionLocked)
+}
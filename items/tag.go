@@ -1,14 +1,13 @@
 package items
 
 import (
-	"encoding/json"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
-	"github.com/jonhadfield/gosn-v2/log"
 	"github.com/jonhadfield/gosn-v2/session"
 )
 
@@ -21,27 +20,24 @@ func (t Tag) IsDefault() bool {
 	return false
 }
 
-func parseTag(i DecryptedItem) Item {
+func parseTag(i DecryptedItem) (Item, error) {
 	t := Tag{}
 
 	if err := populateItemCommon(&t.ItemCommon, i); err != nil {
-		panic(err)
+		return nil, fmt.Errorf("parseTag | %s: %w", i.UUID, err)
 	}
 
-	var err error
-
 	if !t.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
+		content, err := processContentModel(i.ContentType, i.Content)
 		if err != nil {
-			log.Println("failed to decrypt item", t.GetContentType(), t.GetUUID())
+			return nil, fmt.Errorf("parseTag | %s: %w", i.UUID, err)
 		}
 
 		t.Content = *content.(*TagContent)
+		t.Starred = t.Content.Starred
 	}
 
-	return &t
+	return &t, nil
 }
 
 func (i Items) Tags() (t Tags) {
@@ -108,17 +104,21 @@ func NewTag(title string, refs ItemReferences) (tag Tag, err error) {
 func (tagContent TagContent) MarshalJSON() ([]byte, error) {
 	type Alias TagContent
 
-	a := struct {
-		Alias
-	}{
-		Alias: (Alias)(tagContent),
-	}
+	a := (Alias)(tagContent)
 
 	if a.ItemReferences == nil {
 		a.ItemReferences = ItemReferences{}
 	}
 
-	return json.Marshal(a)
+	return encodeWithUnknown(a, tagContent.Unknown)
+}
+
+func (tagContent *TagContent) UnmarshalJSON(data []byte) (err error) {
+	type Alias TagContent
+
+	tagContent.Unknown, err = decodeWithUnknown(data, (*Alias)(tagContent))
+
+	return err
 }
 
 // NewTagContent returns an empty Tag content instance.
@@ -331,11 +331,12 @@ func (tagContent TagContent) Equals(e TagContent) bool {
 }
 
 func (tagContent TagContent) Copy() TagContent {
-	return TagContent{
-		Title:          tagContent.Title,
-		AppData:        tagContent.AppData,
-		ItemReferences: tagContent.ItemReferences,
-	}
+	res := tagContent
+	res.ItemReferences = slices.Clone(tagContent.ItemReferences)
+	res.AppData = tagContent.AppData.copy()
+	res.Unknown = maps.Clone(tagContent.Unknown)
+
+	return res
 }
 
 func (t Tag) Copy() Tag {
@@ -389,6 +390,17 @@ func (tagContent *TagContent) SetParentId(parentId string) {
 	tagContent.ParentId = parentId
 }
 
+// IsStarred returns whether the tag is shown in favourites.
+func (t Tag) IsStarred() bool {
+	return t.Content.Starred
+}
+
+// SetStarred sets whether the tag is shown in favourites.
+func (t *Tag) SetStarred(starred bool) {
+	t.Content.Starred = starred
+	t.Starred = starred
+}
+
 // GetNoteReferences returns references to notes (alias for References method)
 func (tagContent TagContent) GetNoteReferences() ItemReferences {
 	return tagContent.References()
@@ -0,0 +1,488 @@
+package items
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/common"
+)
+
+// TagToParentTagReferenceType is the reference type a nested tag uses to reference its parent.
+const TagToParentTagReferenceType = "TagToParentTag"
+
+// TagPathSeparator separates the titles of nested tags in a path.
+const TagPathSeparator = "/"
+
+var (
+	// ErrTagNotFound is returned when a tag cannot be found in a TagTree.
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrAmbiguousTagPath is returned when more than one tag matches a path.
+	ErrAmbiguousTagPath = errors.New("tag path matches more than one tag")
+	// ErrTagCycle is returned when tags are, or would be, nested within themselves.
+	ErrTagCycle = errors.New("tag hierarchy contains a cycle")
+	// ErrOrphanedTag is returned when a tag's parent does not exist.
+	ErrOrphanedTag = errors.New("tag parent not found")
+)
+
+// TagNode is a tag and its position within a TagTree.
+type TagNode struct {
+	Tag      *Tag
+	Parent   *TagNode
+	Children []*TagNode
+}
+
+// Path returns the titles of the tag and its ancestors separated by TagPathSeparator, e.g. "work/projects/alpha".
+func (n *TagNode) Path() string {
+	var titles []string
+
+	for x := n; x != nil; x = x.Parent {
+		titles = append(titles, x.Tag.Content.Title)
+	}
+
+	slices.Reverse(titles)
+
+	return strings.Join(titles, TagPathSeparator)
+}
+
+// Descendants returns the node's children, their children, and so on, depth first.
+func (n *TagNode) Descendants() (d []*TagNode) {
+	for _, c := range n.Children {
+		d = append(d, c)
+		d = append(d, c.Descendants()...)
+	}
+
+	return d
+}
+
+// TagTree is the hierarchy of nested tags. Tags whose parent is missing, or which
+// are nested within themselves, are placed at the root and listed in Orphans and Cycles.
+type TagTree struct {
+	Roots   []*TagNode
+	Orphans []*TagNode
+	Cycles  [][]*TagNode
+	nodes   map[string]*TagNode
+}
+
+// TagParentUUID returns the UUID of the tag's parent, or an empty string if it is a root tag.
+func TagParentUUID(t Tag) string {
+	for _, ref := range t.Content.ItemReferences {
+		if ref.ReferenceType == TagToParentTagReferenceType {
+			return ref.UUID
+		}
+	}
+
+	return t.Content.GetParentId()
+}
+
+// TagTree returns the hierarchy of the items' tags.
+func (i Items) TagTree() *TagTree {
+	return NewTagTree(i.Tags())
+}
+
+// NewTagTree builds the hierarchy of the non-deleted tags provided.
+// The tags are copied, so changes are only reflected in the tags returned by the tree's methods.
+func NewTagTree(tags Tags) *TagTree {
+	tt := &TagTree{nodes: make(map[string]*TagNode, len(tags))}
+
+	var ordered []*TagNode
+
+	for x := range tags {
+		if tags[x].Deleted {
+			continue
+		}
+
+		t := tags[x]
+		t.Content.ItemReferences = slices.Clone(t.Content.ItemReferences)
+		n := &TagNode{Tag: &t}
+		tt.nodes[t.UUID] = n
+		ordered = append(ordered, n)
+	}
+
+	for _, n := range ordered {
+		parentUUID := TagParentUUID(*n.Tag)
+		if parentUUID == "" {
+			continue
+		}
+
+		parent, ok := tt.nodes[parentUUID]
+		if !ok {
+			tt.Orphans = append(tt.Orphans, n)
+
+			continue
+		}
+
+		n.Parent = parent
+	}
+
+	// break cycles so that every tag is reachable from a root
+	for _, n := range ordered {
+		if cycle := n.cycle(); cycle != nil {
+			tt.Cycles = append(tt.Cycles, cycle)
+			cycle[0].Parent = nil
+		}
+	}
+
+	for _, n := range ordered {
+		if n.Parent == nil {
+			tt.Roots = append(tt.Roots, n)
+
+			continue
+		}
+
+		n.Parent.Children = append(n.Parent.Children, n)
+	}
+
+	return tt
+}
+
+// cycle returns the nodes forming a cycle if the node is nested within itself.
+func (n *TagNode) cycle() []*TagNode {
+	var visited []*TagNode
+
+	for x := n; x != nil; x = x.Parent {
+		if i := slices.Index(visited, x); i >= 0 {
+			if i != 0 {
+				// n leads to a cycle but is not part of it
+				return nil
+			}
+
+			return visited
+		}
+
+		visited = append(visited, x)
+	}
+
+	return nil
+}
+
+// Validate returns errors matching ErrOrphanedTag and ErrTagCycle for any problems found building the tree.
+func (tt *TagTree) Validate() error {
+	var errs []error
+
+	for _, n := range tt.Orphans {
+		errs = append(errs, fmt.Errorf("%w: %s (%s) references parent %s", ErrOrphanedTag, n.Tag.Content.Title, n.Tag.UUID, TagParentUUID(*n.Tag)))
+	}
+
+	for _, cycle := range tt.Cycles {
+		var uuids []string
+		for _, n := range cycle {
+			uuids = append(uuids, n.Tag.UUID)
+		}
+
+		errs = append(errs, fmt.Errorf("%w: %s", ErrTagCycle, strings.Join(uuids, " -> ")))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Get returns the node for the tag with the specified UUID.
+func (tt *TagTree) Get(uuid string) (*TagNode, error) {
+	n, ok := tt.nodes[uuid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTagNotFound, uuid)
+	}
+
+	return n, nil
+}
+
+// Find returns the node at a path such as "work/projects/alpha".
+func (tt *TagTree) Find(path string) (*TagNode, error) {
+	var parent *TagNode
+
+	candidates := tt.Roots
+
+	for _, title := range strings.Split(strings.Trim(path, TagPathSeparator), TagPathSeparator) {
+		var matches []*TagNode
+
+		for _, c := range candidates {
+			if c.Tag.Content.Title == title {
+				matches = append(matches, c)
+			}
+		}
+
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("%w: %s", ErrTagNotFound, path)
+		case 1:
+			parent = matches[0]
+			candidates = parent.Children
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrAmbiguousTagPath, path)
+		}
+	}
+
+	return parent, nil
+}
+
+// Walk calls fn for each node, depth first, in the order the tags were provided.
+func (tt *TagTree) Walk(fn func(n *TagNode)) {
+	for _, r := range tt.Roots {
+		fn(r)
+
+		for _, d := range r.Descendants() {
+			fn(d)
+		}
+	}
+}
+
+func touchTag(t *Tag) {
+	t.Content.SetUpdateTime(time.Now().UTC())
+}
+
+// copyTag returns a copy of the node's tag that does not share its references.
+func (n *TagNode) copyTag() Tag {
+	t := *n.Tag
+	t.Content.ItemReferences = slices.Clone(t.Content.ItemReferences)
+
+	return t
+}
+
+// NewTagPaths returns a TagPaths for the tags in existing.
+func NewTagPaths(existing Items) *TagPaths {
+	return &TagPaths{
+		tree:    existing.TagTree(),
+		tags:    make(map[string]*Tag),
+		byPath:  make(map[string]*Tag),
+		changed: make(map[string]bool),
+	}
+}
+
+// TagPaths finds tags by path when importing notes, creating any that are missing along with their
+// parents, and tracks which tags were created or changed. Existing tags are copied before they are
+// changed, so the items they were found in are not modified.
+type TagPaths struct {
+	tree *TagTree
+	// tags are copies of the existing tags that may be changed
+	tags    map[string]*Tag
+	byPath  map[string]*Tag
+	created []*Tag
+	changed map[string]bool
+}
+
+func (imp *TagPaths) get(node *TagNode) *Tag {
+	t, ok := imp.tags[node.Tag.UUID]
+	if !ok {
+		c := node.copyTag()
+		t = &c
+		imp.tags[t.UUID] = t
+	}
+
+	return t
+}
+
+// Tag returns the tag at path, such as "work/projects", creating it and any missing parents.
+func (imp *TagPaths) Tag(path string) (*Tag, error) {
+	path = strings.Trim(path, TagPathSeparator)
+	if t, ok := imp.byPath[path]; ok {
+		return t, nil
+	}
+
+	node, err := imp.tree.Find(path)
+	if err == nil {
+		t := imp.get(node)
+		imp.byPath[path] = t
+
+		return t, nil
+	}
+
+	if !errors.Is(err, ErrTagNotFound) {
+		return nil, err
+	}
+
+	var parent *Tag
+
+	parentPath, title := "", path
+	if x := strings.LastIndex(path, TagPathSeparator); x >= 0 {
+		parentPath, title = path[:x], path[x+1:]
+
+		if parent, err = imp.Tag(parentPath); err != nil {
+			return nil, err
+		}
+	}
+
+	t, err := NewTag(title, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if parent != nil {
+		t.Content.SetReferences(ItemReferences{{
+			UUID:          parent.UUID,
+			ContentType:   common.SNItemTypeTag,
+			ReferenceType: TagToParentTagReferenceType,
+		}})
+		t.Content.SetParentId(parent.UUID)
+	}
+
+	imp.created = append(imp.created, &t)
+	imp.byPath[path] = &t
+
+	return &t, nil
+}
+
+// Reference adds a reference to the note to a tag returned by Tag, if it does not already have one.
+func (imp *TagPaths) Reference(t *Tag, n *Note) {
+	if !slices.ContainsFunc(t.Content.ItemReferences, func(ref ItemReference) bool { return ref.UUID == n.UUID }) {
+		UpdateItemRefs(UpdateItemRefsInput{Items: Items{t}, ToRef: Items{n}})
+		imp.changed[t.UUID] = true
+	}
+}
+
+// Changed returns the existing tags that were changed, followed by the tags that were created,
+// with their update times set.
+func (imp *TagPaths) Changed() Tags {
+	var tags Tags
+
+	imp.tree.Walk(func(node *TagNode) {
+		if imp.changed[node.Tag.UUID] {
+			t := imp.get(node)
+			touchTag(t)
+			tags = append(tags, *t)
+		}
+	})
+
+	for _, t := range imp.created {
+		touchTag(t)
+		tags = append(tags, *t)
+	}
+
+	return tags
+}
+
+// Move nests the tag under a new parent, or at the root if parentUUID is empty.
+// The updated tag is returned so it can be synced.
+func (tt *TagTree) Move(uuid, parentUUID string) (Tag, error) {
+	n, err := tt.Get(uuid)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	var parent *TagNode
+
+	if parentUUID != "" {
+		if parent, err = tt.Get(parentUUID); err != nil {
+			return Tag{}, err
+		}
+
+		for x := parent; x != nil; x = x.Parent {
+			if x == n {
+				return Tag{}, fmt.Errorf("%w: cannot move %s within itself", ErrTagCycle, n.Path())
+			}
+		}
+	}
+
+	refs := slices.DeleteFunc(n.Tag.Content.ItemReferences, func(ref ItemReference) bool {
+		return ref.ReferenceType == TagToParentTagReferenceType
+	})
+
+	if parent != nil {
+		refs = append(refs, ItemReference{
+			UUID:          parent.Tag.UUID,
+			ContentType:   common.SNItemTypeTag,
+			ReferenceType: TagToParentTagReferenceType,
+		})
+	}
+
+	n.Tag.Content.SetReferences(refs)
+	n.Tag.Content.SetParentId(parentUUID)
+	touchTag(n.Tag)
+
+	// detach from the current position
+	if n.Parent != nil {
+		n.Parent.Children = slices.DeleteFunc(n.Parent.Children, func(c *TagNode) bool { return c == n })
+	} else {
+		tt.Roots = slices.DeleteFunc(tt.Roots, func(c *TagNode) bool { return c == n })
+	}
+
+	tt.Orphans = slices.DeleteFunc(tt.Orphans, func(c *TagNode) bool { return c == n })
+
+	n.Parent = parent
+	if parent != nil {
+		parent.Children = append(parent.Children, n)
+	} else {
+		tt.Roots = append(tt.Roots, n)
+	}
+
+	return n.copyTag(), nil
+}
+
+// Rename changes the title of the tag, returning the updated tag so it can be synced.
+func (tt *TagTree) Rename(uuid, title string) (Tag, error) {
+	if strings.TrimSpace(title) == "" {
+		return Tag{}, fmt.Errorf("title cannot be empty")
+	}
+
+	if strings.Contains(title, TagPathSeparator) {
+		return Tag{}, fmt.Errorf("title cannot contain %q", TagPathSeparator)
+	}
+
+	n, err := tt.Get(uuid)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	n.Tag.Content.SetTitle(title)
+	touchTag(n.Tag)
+
+	return n.copyTag(), nil
+}
+
+// Delete removes the tag and all tags nested within it from the tree, returning
+// them marked as deleted so they can be synced. Notes are not deleted.
+func (tt *TagTree) Delete(uuid string) (Tags, error) {
+	n, err := tt.Get(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.Parent != nil {
+		n.Parent.Children = slices.DeleteFunc(n.Parent.Children, func(c *TagNode) bool { return c == n })
+	} else {
+		tt.Roots = slices.DeleteFunc(tt.Roots, func(c *TagNode) bool { return c == n })
+	}
+
+	var deleted Tags
+
+	for _, x := range append([]*TagNode{n}, n.Descendants()...) {
+		tt.Orphans = slices.DeleteFunc(tt.Orphans, func(c *TagNode) bool { return c == x })
+		delete(tt.nodes, x.Tag.UUID)
+
+		t := x.copyTag()
+		t.Deleted = true
+		deleted = append(deleted, t)
+	}
+
+	return deleted, nil
+}
+
+// Notes returns the non-deleted notes, from those provided, that are tagged with
+// the tag or any tag nested within it. Each note is returned once.
+func (tt *TagTree) Notes(uuid string, all Items) (Notes, error) {
+	n, err := tt.Get(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	tagged := make(map[string]bool)
+
+	for _, x := range append([]*TagNode{n}, n.Descendants()...) {
+		for _, ref := range x.Tag.Content.ItemReferences {
+			if ref.ContentType == common.SNItemTypeNote {
+				tagged[ref.UUID] = true
+			}
+		}
+	}
+
+	var notes Notes
+
+	for _, note := range all.Notes() {
+		if !note.Deleted && tagged[note.UUID] {
+			notes = append(notes, note)
+		}
+	}
+
+	return notes, nil
+}
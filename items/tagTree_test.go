@@ -0,0 +1,174 @@
+package items
+
+import (
+	"testing"
+
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/stretchr/testify/require"
+)
+
+func childTag(t *testing.T, title, uuid, parentUUID string, refs ...ItemReference) *Tag {
+	t.Helper()
+
+	if parentUUID != "" {
+		refs = append(refs, ItemReference{UUID: parentUUID, ContentType: common.SNItemTypeTag, ReferenceType: TagToParentTagReferenceType})
+	}
+
+	tag, err := createTag(title, uuid, refs)
+	require.NoError(t, err)
+
+	return tag
+}
+
+func noteRef(uuid string) ItemReference {
+	return ItemReference{UUID: uuid, ContentType: common.SNItemTypeNote}
+}
+
+func tagTreeTestItems(t *testing.T) Items {
+	t.Helper()
+
+	return Items{
+		childTag(t, "work", "work", ""),
+		childTag(t, "projects", "projects", "work", noteRef("n1")),
+		childTag(t, "alpha", "alpha", "projects", noteRef("n2"), noteRef("n1")),
+		childTag(t, "beta", "beta", "projects"),
+		childTag(t, "home", "home", "", noteRef("n3")),
+		createNote("one", "", "n1"),
+		createNote("two", "", "n2"),
+		createNote("three", "", "n3"),
+	}
+}
+
+func TestTagTreeFind(t *testing.T) {
+	tt := tagTreeTestItems(t).TagTree()
+	require.NoError(t, tt.Validate())
+	require.Len(t, tt.Roots, 2)
+
+	n, err := tt.Find("work/projects/alpha")
+	require.NoError(t, err)
+	require.Equal(t, "alpha", n.Tag.UUID)
+	require.Equal(t, "work/projects/alpha", n.Path())
+	require.Equal(t, "projects", n.Parent.Tag.UUID)
+
+	n, err = tt.Find("/work/projects/")
+	require.NoError(t, err)
+	require.Len(t, n.Children, 2)
+	require.Len(t, n.Descendants(), 2)
+
+	_, err = tt.Find("work/alpha")
+	require.ErrorIs(t, err, ErrTagNotFound)
+
+	var paths []string
+	tt.Walk(func(n *TagNode) { paths = append(paths, n.Path()) })
+	require.Equal(t, []string{"work", "work/projects", "work/projects/alpha", "work/projects/beta", "home"}, paths)
+}
+
+func TestTagTreeAmbiguousPath(t *testing.T) {
+	tt := Items{
+		childTag(t, "work", "w1", ""),
+		childTag(t, "work", "w2", ""),
+	}.TagTree()
+
+	_, err := tt.Find("work")
+	require.ErrorIs(t, err, ErrAmbiguousTagPath)
+}
+
+func TestTagTreeOrphansAndCycles(t *testing.T) {
+	tt := Items{
+		childTag(t, "orphan", "orphan", "missing"),
+		childTag(t, "a", "a", "b"),
+		childTag(t, "b", "b", "a"),
+		childTag(t, "c", "c", "b"),
+	}.TagTree()
+
+	require.Len(t, tt.Orphans, 1)
+	require.Equal(t, "orphan", tt.Orphans[0].Tag.UUID)
+	require.Len(t, tt.Cycles, 1)
+
+	err := tt.Validate()
+	require.ErrorIs(t, err, ErrOrphanedTag)
+	require.ErrorIs(t, err, ErrTagCycle)
+
+	// every tag is still reachable
+	var count int
+	tt.Walk(func(*TagNode) { count++ })
+	require.Equal(t, 4, count)
+}
+
+func TestTagTreeMove(t *testing.T) {
+	tt := tagTreeTestItems(t).TagTree()
+
+	moved, err := tt.Move("alpha", "home")
+	require.NoError(t, err)
+	require.Equal(t, "home", TagParentUUID(moved))
+	require.Contains(t, moved.Content.ItemReferences, noteRef("n2"))
+
+	n, err := tt.Find("home/alpha")
+	require.NoError(t, err)
+	require.Equal(t, "alpha", n.Tag.UUID)
+
+	projects, err := tt.Find("work/projects")
+	require.NoError(t, err)
+	require.Len(t, projects.Children, 1)
+
+	_, err = tt.Move("work", "beta")
+	require.ErrorIs(t, err, ErrTagCycle)
+
+	moved, err = tt.Move("alpha", "")
+	require.NoError(t, err)
+	require.Empty(t, TagParentUUID(moved))
+
+	_, err = tt.Find("alpha")
+	require.NoError(t, err)
+
+	_, err = tt.Move("alpha", "missing")
+	require.ErrorIs(t, err, ErrTagNotFound)
+}
+
+func TestTagTreeRename(t *testing.T) {
+	tt := tagTreeTestItems(t).TagTree()
+
+	renamed, err := tt.Rename("projects", "clients")
+	require.NoError(t, err)
+	require.Equal(t, "clients", renamed.Content.Title)
+
+	_, err = tt.Find("work/clients/alpha")
+	require.NoError(t, err)
+
+	_, err = tt.Rename("projects", "a/b")
+	require.Error(t, err)
+
+	_, err = tt.Rename("projects", " ")
+	require.Error(t, err)
+}
+
+func TestTagTreeDeleteAndNotes(t *testing.T) {
+	all := tagTreeTestItems(t)
+	tt := all.TagTree()
+
+	notes, err := tt.Notes("work", all)
+	require.NoError(t, err)
+	require.Equal(t, []string{"n1", "n2"}, []string{notes[0].UUID, notes[1].UUID})
+
+	notes, err = tt.Notes("beta", all)
+	require.NoError(t, err)
+	require.Empty(t, notes)
+
+	deleted, err := tt.Delete("projects")
+	require.NoError(t, err)
+	require.Len(t, deleted, 3)
+
+	for _, d := range deleted {
+		require.True(t, d.Deleted)
+	}
+
+	_, err = tt.Find("work/projects")
+	require.ErrorIs(t, err, ErrTagNotFound)
+
+	_, err = tt.Get("alpha")
+	require.ErrorIs(t, err, ErrTagNotFound)
+
+	work, err := tt.Find("work")
+	require.NoError(t, err)
+	require.Empty(t, work.Children)
+}
@@ -3,35 +3,11 @@ package items
 import (
 	"encoding/json"
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parseTheme(i DecryptedItem) Item {
-	c := Theme{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		c.Content = *content.(*ThemeContent)
-	}
-
-	return &c
-}
-
 type ThemeContent struct {
 	HostedURL          string          `json:"hosted_url"`
 	LocalURL           string          `json:"local_url"`
@@ -43,56 +19,32 @@ type ThemeContent struct {
 	DissociatedItemIds []string        `json:"disassociatedItemIds"`
 	AssociatedItemIds  []string        `json:"associatedItemIds"`
 	Active             interface{}     `json:"active"`
+	Unknown            UnknownFields   `json:"-"`
 }
 
-type Theme struct {
-	ItemCommon
-	Content ThemeContent
-}
-
-func (c Theme) IsDefault() bool {
-	return false
-}
+func (cc *ThemeContent) UnmarshalJSON(data []byte) (err error) {
+	type alias ThemeContent
 
-func (i Items) Themes() (c Themes) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeTheme {
-			component := x.(*Theme)
-			c = append(c, *component)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *Themes) DeDupe() {
-	var encountered []string
+func (cc ThemeContent) MarshalJSON() ([]byte, error) {
+	type alias ThemeContent
 
-	var deDuped Themes
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+type Theme = TypedItem[ThemeContent, *ThemeContent]
 
-		encountered = append(encountered, i.UUID)
-	}
-
-	*c = deDuped
+func (i Items) Themes() (c Themes) {
+	return ItemsOfType[ThemeContent](i)
 }
 
 // NewTheme returns an Item of type Theme without content.
 func NewTheme() Theme {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c Theme
-
-	c.ContentType = common.SNItemTypeTheme
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[ThemeContent](common.SNItemTypeTheme)
 }
 
 // NewTagContent returns an empty Tag content instance.
@@ -103,120 +55,14 @@ func NewThemeContent() *ThemeContent {
 	return c
 }
 
-type Themes []Theme
-
-func (c Themes) Validate() error {
-	var updatedTime time.Time
+type Themes = TypedItems[ThemeContent, *ThemeContent]
 
-	var err error
-
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing title: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
+func (cc *ThemeContent) MissingField() string {
+	if cc.Name == "" {
+		return "title"
 	}
 
-	return err
-}
-
-func (c Theme) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *Theme) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c Theme) GetContent() Content {
-	return &c.Content
-}
-
-func (c *Theme) SetContent(cc Content) {
-	c.Content = *cc.(*ThemeContent)
-}
-
-func (c Theme) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c Theme) GetUUID() string {
-	return c.UUID
-}
-
-func (c Theme) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *Theme) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c Theme) GetContentType() string {
-	return c.ContentType
-}
-
-func (c Theme) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *Theme) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c Theme) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *Theme) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c *Theme) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c Theme) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *Theme) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c Theme) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *Theme) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c Theme) GetContentSize() int {
-	return c.ContentSize
-}
-
-func (c *Theme) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *ThemeContent) AssociateItems(newItems []string) {
@@ -2,35 +2,11 @@ package items
 
 import (
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parseTrustedContact(i DecryptedItem) Item {
-	c := TrustedContact{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		c.Content = *content.(*TrustedContactContent)
-	}
-
-	return &c
-}
-
 // TrustedContactContent represents a trusted contact for collaboration
 type TrustedContactContent struct {
 	Name         string         `json:"name"`
@@ -39,56 +15,32 @@ type TrustedContactContent struct {
 	IsMe         bool           `json:"isMe"`
 	ItemReferences ItemReferences `json:"references"`
 	AppData      AppDataContent `json:"appData"`
+	Unknown      UnknownFields  `json:"-"`
 }
 
-type TrustedContact struct {
-	ItemCommon
-	Content TrustedContactContent
-}
-
-func (c TrustedContact) IsDefault() bool {
-	return false
-}
+func (cc *TrustedContactContent) UnmarshalJSON(data []byte) (err error) {
+	type alias TrustedContactContent
 
-func (i Items) TrustedContacts() (c TrustedContacts) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeTrustedContact {
-			trustedContact := x.(*TrustedContact)
-			c = append(c, *trustedContact)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *TrustedContacts) DeDupe() {
-	var encountered []string
+func (cc TrustedContactContent) MarshalJSON() ([]byte, error) {
+	type alias TrustedContactContent
 
-	var deDuped TrustedContacts
-
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-		encountered = append(encountered, i.UUID)
-	}
+type TrustedContact = TypedItem[TrustedContactContent, *TrustedContactContent]
 
-	*c = deDuped
+func (i Items) TrustedContacts() (c TrustedContacts) {
+	return ItemsOfType[TrustedContactContent](i)
 }
 
 // NewTrustedContact returns an Item of type TrustedContact without content.
 func NewTrustedContact() TrustedContact {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c TrustedContact
-
-	c.ContentType = common.SNItemTypeTrustedContact
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[TrustedContactContent](common.SNItemTypeTrustedContact)
 }
 
 // NewTrustedContactContent returns an empty TrustedContact content instance.
@@ -99,123 +51,17 @@ func NewTrustedContactContent() *TrustedContactContent {
 	return c
 }
 
-type TrustedContacts []TrustedContact
-
-func (c TrustedContacts) Validate() error {
-	var updatedTime time.Time
-
-	var err error
-
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing name: \"%s\"",
-					item.ContentType, item.UUID)
-			case item.Content.ContactUUID == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing contactUuid: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
-	}
+type TrustedContacts = TypedItems[TrustedContactContent, *TrustedContactContent]
 
-	return err
-}
-
-func (c TrustedContact) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *TrustedContact) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c TrustedContact) GetContent() Content {
-	return &c.Content
-}
-
-func (c *TrustedContact) SetContent(cc Content) {
-	c.Content = *cc.(*TrustedContactContent)
-}
-
-func (c TrustedContact) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c TrustedContact) GetUUID() string {
-	return c.UUID
-}
-
-func (c TrustedContact) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *TrustedContact) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c TrustedContact) GetContentType() string {
-	return c.ContentType
-}
-
-func (c TrustedContact) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *TrustedContact) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c TrustedContact) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *TrustedContact) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c TrustedContact) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *TrustedContact) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c TrustedContact) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *TrustedContact) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *TrustedContact) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c TrustedContact) GetContentSize() int {
-	return c.ContentSize
-}
+func (cc *TrustedContactContent) MissingField() string {
+	switch {
+	case cc.Name == "":
+		return "name"
+	case cc.ContactUUID == "":
+		return "contactUuid"
+	}
 
-func (c *TrustedContact) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *TrustedContactContent) GetUpdateTime() (time.Time, error) {
@@ -0,0 +1,227 @@
+package items
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/common"
+)
+
+// TypedItem is an item with content of type C, a pointer to which, P, implements Content. Item types
+// without behaviour of their own, such as Theme and Component, are instances of it, as are the
+// types of content added with RegisterContentType.
+type TypedItem[C any, P contentPointer[C]] struct {
+	ItemCommon
+	Content C
+}
+
+// TypedItems is a list of items with content of type C.
+type TypedItems[C any, P contentPointer[C]] []TypedItem[C, P]
+
+// RequiredFields is implemented by content that cannot be created without some of its fields set.
+type RequiredFields interface {
+	// MissingField returns the name of the first required field that is not set, or an empty string.
+	MissingField() string
+}
+
+func parseTypedItem[C any, P contentPointer[C]](i DecryptedItem) (Item, error) {
+	c := TypedItem[C, P]{}
+
+	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
+		return nil, fmt.Errorf("parseTypedItem | %s %s: %w", i.ContentType, i.UUID, err)
+	}
+
+	if !c.Deleted {
+		content, err := processContentModel(i.ContentType, i.Content)
+		if err != nil {
+			return nil, fmt.Errorf("parseTypedItem | %s %s: %w", i.ContentType, i.UUID, err)
+		}
+
+		p, ok := content.(P)
+		if !ok {
+			return nil, fmt.Errorf("parseTypedItem | %s %s: content is %T not %T", i.ContentType, i.UUID, content, p)
+		}
+
+		c.Content = *p
+	}
+
+	return &c, nil
+}
+
+// NewTypedItem returns an Item of the given content type without content.
+func NewTypedItem[C any, P contentPointer[C]](contentType string) TypedItem[C, P] {
+	now := time.Now().UTC()
+
+	var c TypedItem[C, P]
+
+	c.ContentType = contentType
+	c.CreatedAt = now.Format(common.TimeLayout)
+	c.CreatedAtTimestamp = now.UnixMicro()
+	c.UUID = GenUUID()
+
+	return c
+}
+
+// ItemsOfType returns the items with content of type C.
+func ItemsOfType[C any, P contentPointer[C]](i Items) (c TypedItems[C, P]) {
+	for _, x := range i {
+		if t, ok := x.(*TypedItem[C, P]); ok {
+			c = append(c, *t)
+		}
+	}
+
+	return c
+}
+
+func (c *TypedItems[C, P]) DeDupe() {
+	var encountered []string
+
+	var deDuped TypedItems[C, P]
+
+	for _, i := range *c {
+		if !slices.Contains(encountered, i.UUID) {
+			deDuped = append(deDuped, i)
+		}
+
+		encountered = append(encountered, i.UUID)
+	}
+
+	*c = deDuped
+}
+
+// Validate checks that items being added have an update time, a created at date, and any fields
+// their content requires.
+func (c TypedItems[C, P]) Validate() error {
+	var err error
+
+	for _, item := range c {
+		// validate content if being added
+		if item.Deleted {
+			continue
+		}
+
+		content := item.GetContent()
+
+		var updatedTime time.Time
+
+		if u, ok := content.(interface{ GetUpdateTime() (time.Time, error) }); ok {
+			updatedTime, err = u.GetUpdateTime()
+			if err != nil {
+				return err
+			}
+		}
+
+		var missing, title string
+
+		if r, ok := content.(RequiredFields); ok {
+			missing = r.MissingField()
+		}
+
+		if t, ok := content.(interface{ GetTitle() string }); ok {
+			title = t.GetTitle()
+		}
+
+		switch {
+		case missing != "":
+			err = fmt.Errorf("failed to create \"%s\" due to missing %s: \"%s\"",
+				item.ContentType, missing, item.UUID)
+		case updatedTime.IsZero():
+			err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
+				item.ContentType, title)
+		case item.CreatedAt == "":
+			err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
+				item.ContentType, title)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (c TypedItem[C, P]) IsDefault() bool {
+	return false
+}
+
+func (c TypedItem[C, P]) IsDeleted() bool {
+	return c.Deleted
+}
+
+func (c *TypedItem[C, P]) SetDeleted(d bool) {
+	c.Deleted = d
+}
+
+func (c TypedItem[C, P]) GetContent() Content {
+	return P(&c.Content)
+}
+
+func (c *TypedItem[C, P]) SetContent(cc Content) {
+	c.Content = *cc.(P)
+}
+
+func (c TypedItem[C, P]) GetItemsKeyID() string {
+	return c.ItemsKeyID
+}
+
+func (c TypedItem[C, P]) GetUUID() string {
+	return c.UUID
+}
+
+func (c TypedItem[C, P]) GetDuplicateOf() string {
+	return c.DuplicateOf
+}
+
+func (c *TypedItem[C, P]) SetUUID(u string) {
+	c.UUID = u
+}
+
+func (c TypedItem[C, P]) GetContentType() string {
+	return c.ContentType
+}
+
+func (c *TypedItem[C, P]) SetContentType(ct string) {
+	c.ContentType = ct
+}
+
+func (c TypedItem[C, P]) GetCreatedAt() string {
+	return c.CreatedAt
+}
+
+func (c *TypedItem[C, P]) SetCreatedAt(ca string) {
+	c.CreatedAt = ca
+}
+
+func (c TypedItem[C, P]) GetUpdatedAt() string {
+	return c.UpdatedAt
+}
+
+func (c *TypedItem[C, P]) SetUpdatedAt(ca string) {
+	c.UpdatedAt = ca
+}
+
+func (c TypedItem[C, P]) GetCreatedAtTimestamp() int64 {
+	return c.CreatedAtTimestamp
+}
+
+func (c *TypedItem[C, P]) SetCreatedAtTimestamp(ca int64) {
+	c.CreatedAtTimestamp = ca
+}
+
+func (c TypedItem[C, P]) GetUpdatedAtTimestamp() int64 {
+	return c.UpdatedAtTimestamp
+}
+
+func (c *TypedItem[C, P]) SetUpdatedAtTimestamp(ca int64) {
+	c.UpdatedAtTimestamp = ca
+}
+
+func (c TypedItem[C, P]) GetContentSize() int {
+	return c.ContentSize
+}
+
+func (c *TypedItem[C, P]) SetContentSize(s int) {
+	c.ContentSize = s
+}
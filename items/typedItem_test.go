@@ -0,0 +1,122 @@
+package items
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/crypto/vectors"
+	"github.com/jonhadfield/gosn-v2/session"
+	"github.com/stretchr/testify/require"
+)
+
+const snippetContentType = "Acme|Snippet"
+
+type snippetContent struct {
+	Language       string         `json:"language"`
+	Code           string         `json:"code"`
+	ItemReferences ItemReferences `json:"references"`
+	AppData        AppDataContent `json:"appData"`
+}
+
+func (cc snippetContent) References() ItemReferences {
+	return cc.ItemReferences
+}
+
+func (cc *snippetContent) SetReferences(refs ItemReferences) {
+	cc.ItemReferences = refs
+}
+
+func (cc *snippetContent) GetUpdateTime() (time.Time, error) {
+	if cc.AppData.OrgStandardNotesSN.ClientUpdatedAt == "" {
+		return time.Time{}, fmt.Errorf("ClientUpdatedAt not set")
+	}
+
+	return time.Parse(common.TimeLayout, cc.AppData.OrgStandardNotesSN.ClientUpdatedAt)
+}
+
+func (cc *snippetContent) MissingField() string {
+	if cc.Code == "" {
+		return "code"
+	}
+
+	return ""
+}
+
+func TestRegisterContentType(t *testing.T) {
+	require.NoError(t, RegisterContentType[snippetContent](snippetContentType))
+	t.Cleanup(func() {
+		contentModelsMutex.Lock()
+		delete(contentModels, snippetContentType)
+		contentModelsMutex.Unlock()
+	})
+
+	require.ErrorIs(t, RegisterContentType[snippetContent](snippetContentType), ErrContentTypeRegistered)
+	require.ErrorIs(t, RegisterContentType[snippetContent](common.SNItemTypeNote), ErrContentTypeRegistered)
+
+	ik := session.SessionItemsKey{UUID: "ik1", ItemsKey: mustSecretKey(vectors.Items004[1].Key)}
+	s := &session.Session{ItemsKeys: []session.SessionItemsKey{ik}, DefaultItemsKey: ik}
+
+	snippet := NewTypedItem[snippetContent](snippetContentType)
+	snippet.Content = snippetContent{Language: "go", Code: "fmt.Println()"}
+	snippet.Content.AppData.OrgStandardNotesSN.ClientUpdatedAt = "2024-01-01T00:00:00.000Z"
+	snippet.Content.SetReferences(ItemReferences{{UUID: "n1", ContentType: common.SNItemTypeNote}})
+
+	// the item is encrypted, decrypted and parsed like those built in
+	e, err := EncryptItem(&snippet, ik, s)
+	require.NoError(t, err)
+
+	di, err := DecryptItem(e, s, s.ItemsKeys)
+	require.NoError(t, err)
+
+	i, err := ParseItem(di)
+	require.NoError(t, err)
+
+	parsed := i.(*TypedItem[snippetContent, *snippetContent])
+	require.Equal(t, snippet.Content, parsed.Content)
+	require.Equal(t, "n1", parsed.GetContent().References()[0].UUID)
+
+	snippets := ItemsOfType[snippetContent](Items{parsed, createNote("Plan", "", "n1"), parsed})
+	require.Len(t, snippets, 2)
+
+	snippets.DeDupe()
+	require.Len(t, snippets, 1)
+	require.NoError(t, snippets.Validate())
+
+	snippets[0].Content.Code = ""
+	require.EqualError(t, snippets.Validate(),
+		fmt.Sprintf("failed to create \"%s\" due to missing code: \"%s\"", snippetContentType, snippet.UUID))
+
+	// without registration the content type is passed through
+	contentModelsMutex.Lock()
+	delete(contentModels, snippetContentType)
+	contentModelsMutex.Unlock()
+
+	i, err = ParseItem(di)
+	require.NoError(t, err)
+
+	b, err := json.Marshal(i.(*UnknownItem).Content)
+	require.NoError(t, err)
+	require.JSONEq(t, di.Content, string(b))
+}
+
+func TestTypedItemsValidate(t *testing.T) {
+	contact := NewTrustedContact()
+	contact.Content = *NewTrustedContactContent()
+	contact.Content.Name = "Bob"
+
+	require.EqualError(t, TrustedContacts{contact}.Validate(),
+		fmt.Sprintf("failed to create \"%s\" due to missing contactUuid: \"%s\"", common.SNItemTypeTrustedContact, contact.UUID))
+
+	contact.Content.ContactUUID = "c1"
+	require.NoError(t, TrustedContacts{contact}.Validate())
+
+	contact.CreatedAt = ""
+	require.EqualError(t, TrustedContacts{contact}.Validate(),
+		fmt.Sprintf("failed to create \"%s\" due to missing created at date: \"%s\"", common.SNItemTypeTrustedContact, contact.Content.GetTitle()))
+
+	theme := NewTheme()
+	require.EqualError(t, Themes{theme}.Validate(), "ClientUpdatedAt not set")
+}
@@ -0,0 +1,95 @@
+package items
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+)
+
+// UnknownFields are the fields of item content that gosn does not know about, such as those added by newer
+// versions of the Standard Notes apps. They are kept when content is decoded and written back when it is
+// encoded, so that items can be changed and synced without losing them.
+type UnknownFields map[string]json.RawMessage
+
+// decodeWithUnknown decodes the JSON object into known, which must not have its own UnmarshalJSON method,
+// and returns the fields that are not written when known is encoded. Fields that are known but omitted
+// as empty, such as an explicit false, are also returned so that they are written back as they were.
+func decodeWithUnknown(data []byte, known any) (UnknownFields, error) {
+	if err := json.Unmarshal(data, known); err != nil {
+		return nil, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil || len(all) == 0 {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(known)
+	if err != nil {
+		return nil, err
+	}
+
+	var written map[string]json.RawMessage
+	if err = json.Unmarshal(encoded, &written); err != nil {
+		return nil, err
+	}
+
+	var unknown UnknownFields
+
+	for k, v := range all {
+		if _, ok := written[k]; ok {
+			continue
+		}
+
+		if unknown == nil {
+			unknown = make(UnknownFields)
+		}
+
+		unknown[k] = v
+	}
+
+	return unknown, nil
+}
+
+// encodeWithUnknown encodes known, which must not have its own MarshalJSON method, as a JSON object
+// followed by the unknown fields it does not write itself, in order of name.
+func encodeWithUnknown(known any, unknown UnknownFields) ([]byte, error) {
+	encoded, err := json.Marshal(known)
+	if err != nil || len(unknown) == 0 {
+		return encoded, err
+	}
+
+	var written map[string]json.RawMessage
+	if err = json.Unmarshal(encoded, &written); err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(bytes.TrimSuffix(encoded, []byte("}")))
+
+	for _, k := range slices.Sorted(maps.Keys(unknown)) {
+		if _, ok := written[k]; ok {
+			continue
+		}
+
+		if len(written) > 0 {
+			buf.WriteByte(',')
+		}
+
+		name, _ := json.Marshal(k)
+		buf.Write(name)
+		buf.WriteByte(':')
+
+		if len(unknown[k]) == 0 {
+			buf.WriteString("null")
+		} else {
+			buf.Write(unknown[k])
+		}
+
+		written[k] = unknown[k]
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
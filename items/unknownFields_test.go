@@ -0,0 +1,169 @@
+package items
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/crypto/vectors"
+	"github.com/jonhadfield/gosn-v2/session"
+	"github.com/stretchr/testify/require"
+)
+
+// futureNoteContent is note content with fields added by a newer version of the apps, both at the
+// top level and within appData, and explicit values of fields that are omitted when empty.
+const futureNoteContent = `{
+	"title": "Plan",
+	"text": "ship it",
+	"references": [],
+	"appData": {
+		"org.standardnotes.sn": {"client_updated_at": "2024-01-01T00:00:00.000Z", "prefersPlainEditor": false, "pinned": false, "archived": false, "collapsedSections": ["a"]},
+		"org.standardnotes.sn.components": {"c1": {"x": 1}},
+		"org.standardnotes.future": {"enabled": true}
+	},
+	"noteType": "plain-text",
+	"editorIdentifier": "",
+	"preview_plain": "",
+	"preview_html": "",
+	"spellcheck": false,
+	"trashed": false,
+	"futureAttribute": {"nested": [1, 2, 3]},
+	"futureFlag": true
+}`
+
+func TestUnknownFieldsRoundTrip(t *testing.T) {
+	c, err := processContentModel(common.SNItemTypeNote, futureNoteContent)
+	require.NoError(t, err)
+
+	nc := c.(*NoteContent)
+	require.Equal(t, "Plan", nc.Title)
+	require.Equal(t, json.RawMessage(`{"nested": [1, 2, 3]}`), nc.Unknown["futureAttribute"])
+
+	// unchanged content is encoded as it was decoded
+	b, err := json.Marshal(nc)
+	require.NoError(t, err)
+	require.JSONEq(t, futureNoteContent, string(b))
+
+	// known fields that are changed replace those decoded
+	nc.Title = "Plan v2"
+	nc.SetTrashed(true)
+
+	n := Note{Content: *nc}
+	n.SetArchived(true)
+
+	b, err = json.Marshal(n.Content)
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(b, &got))
+	require.Equal(t, "Plan v2", got["title"])
+	require.Equal(t, true, got["trashed"])
+	require.Equal(t, map[string]any{"nested": []any{1.0, 2.0, 3.0}}, got["futureAttribute"])
+
+	appData := got["appData"].(map[string]any)
+	require.Equal(t, map[string]any{"enabled": true}, appData["org.standardnotes.future"])
+
+	sn := appData["org.standardnotes.sn"].(map[string]any)
+	require.Equal(t, true, sn["archived"])
+	require.Equal(t, []any{"a"}, sn["collapsedSections"])
+
+	// tags and smart views keep their fields too
+	tag := `{"title":"work","references":[],"appData":{"org.standardnotes.sn":{"client_updated_at":"","prefersPlainEditor":false,"pinned":false}},"futureIcon":"star"}`
+	view := `{"title":"todo","references":[],"appData":{"org.standardnotes.sn":{"client_updated_at":"","prefersPlainEditor":false,"pinned":false}},"predicate":{"keypath":"title","operator":"=","value":"todo"},"futureIcon":"star"}`
+
+	for _, tc := range []struct{ contentType, content string }{
+		{common.SNItemTypeTag, tag},
+		{common.SNItemTypeSmartTag, view},
+	} {
+		c, err = processContentModel(tc.contentType, tc.content)
+		require.NoError(t, err)
+
+		b, err = json.Marshal(c)
+		require.NoError(t, err)
+		require.JSONEq(t, tc.content, string(b), tc.contentType)
+	}
+
+	require.NotNil(t, c.(*SmartViewContent).Predicate)
+}
+
+func TestUnknownFieldsCopy(t *testing.T) {
+	c, err := processContentModel(common.SNItemTypeNote, futureNoteContent)
+	require.NoError(t, err)
+
+	note := Note{Content: *c.(*NoteContent)}
+	note.Content.SetTrashed(true)
+
+	dupe := note.Copy()
+
+	// changes to the copy are not made to the original
+	dupe.Content.SetTrashed(false)
+	dupe.Content.Unknown["futureFlag"] = json.RawMessage(`false`)
+	dupe.Content.AppData.Unknown["org.standardnotes.future"] = json.RawMessage(`{}`)
+	dupe.Content.AppData.OrgStandardNotesSN.Unknown["collapsedSections"] = json.RawMessage(`[]`)
+
+	b, err := json.Marshal(dupe.Content)
+	require.NoError(t, err)
+
+	want := strings.NewReplacer(
+		`"futureFlag": true`, `"futureFlag": false`,
+		`"org.standardnotes.future": {"enabled": true}`, `"org.standardnotes.future": {}`,
+		`"collapsedSections": ["a"]`, `"collapsedSections": []`,
+	).Replace(futureNoteContent)
+	require.JSONEq(t, want, string(b))
+
+	require.True(t, note.Content.GetTrashed())
+	require.Equal(t, json.RawMessage(`true`), note.Content.Unknown["futureFlag"])
+	require.Equal(t, json.RawMessage(`{"enabled": true}`), note.Content.AppData.Unknown["org.standardnotes.future"])
+	require.Equal(t, json.RawMessage(`["a"]`), note.Content.AppData.OrgStandardNotesSN.Unknown["collapsedSections"])
+
+	tag := `{"title":"work","references":[{"uuid":"a","content_type":"Note"}],"appData":{"org.standardnotes.sn":{"client_updated_at":"","prefersPlainEditor":false,"pinned":false}},"iconString":"star","expanded":true,"parentId":"b","starred":true,"preferences":{"sortBy":"title"},"futureIcon":"star"}`
+
+	c, err = processContentModel(common.SNItemTypeTag, tag)
+	require.NoError(t, err)
+
+	tagDupe := Tag{Content: *c.(*TagContent)}.Copy()
+
+	b, err = json.Marshal(tagDupe.Content)
+	require.NoError(t, err)
+	require.JSONEq(t, tag, string(b))
+}
+
+func TestUnknownItem(t *testing.T) {
+	ik := session.SessionItemsKey{UUID: "ik1", ItemsKey: mustSecretKey(vectors.Items004[1].Key)}
+	s := &session.Session{ItemsKeys: []session.SessionItemsKey{ik}, DefaultItemsKey: ik}
+
+	content := `{"references":[{"uuid":"n1","content_type":"Note"}],"futureSetting":{"a":[1,"b"]},"name":"future"}`
+
+	i, err := ParseItem(DecryptedItem{UUID: "u1", ContentType: "SN|Future", Content: content})
+	require.NoError(t, err)
+
+	u := i.(*UnknownItem)
+	require.Equal(t, "SN|Future", u.GetContentType())
+	require.Equal(t, "n1", u.Content.References()[0].UUID)
+
+	// the item survives being encrypted and decrypted
+	e, err := EncryptItem(u, ik, s)
+	require.NoError(t, err)
+
+	di, err := DecryptItem(e, s, s.ItemsKeys)
+	require.NoError(t, err)
+	require.JSONEq(t, content, di.Content)
+
+	// changes to references and update time keep the other fields
+	i, err = ParseItem(di)
+	require.NoError(t, err)
+
+	u = i.(*UnknownItem)
+	u.Content.SetReferences(nil)
+	u.Content.SetUpdateTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	b, err := json.Marshal(u.Content)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"appData": {"org.standardnotes.sn": {"client_updated_at": "2024-01-01T00:00:00.000Z", "prefersPlainEditor": false, "pinned": false}},
+		"futureSetting": {"a": [1, "b"]},
+		"name": "future"
+	}`, string(b))
+}
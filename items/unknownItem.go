@@ -0,0 +1,60 @@
+package items
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jonhadfield/gosn-v2/common"
+)
+
+// UnknownContent is the content of an item whose content type is not known to gosn. Only its references and
+// appData are decoded; its other fields are kept as they are so the item can be changed and synced without loss.
+type UnknownContent struct {
+	ItemReferences ItemReferences  `json:"references,omitempty"`
+	AppData        *AppDataContent `json:"appData,omitempty"`
+	Unknown        UnknownFields   `json:"-"`
+}
+
+func (cc *UnknownContent) UnmarshalJSON(data []byte) (err error) {
+	type alias UnknownContent
+
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
+
+	return err
+}
+
+func (cc UnknownContent) MarshalJSON() ([]byte, error) {
+	type alias UnknownContent
+
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
+
+func (cc UnknownContent) References() ItemReferences {
+	return cc.ItemReferences
+}
+
+func (cc *UnknownContent) SetReferences(refs ItemReferences) {
+	cc.ItemReferences = refs
+}
+
+func (cc *UnknownContent) GetUpdateTime() (time.Time, error) {
+	if cc.AppData == nil || cc.AppData.OrgStandardNotesSN.ClientUpdatedAt == "" {
+		return time.Time{}, fmt.Errorf("ClientUpdatedAt not set")
+	}
+
+	return time.Parse(common.TimeLayout, cc.AppData.OrgStandardNotesSN.ClientUpdatedAt)
+}
+
+func (cc *UnknownContent) SetUpdateTime(uTime time.Time) {
+	if cc.AppData == nil {
+		cc.AppData = &AppDataContent{}
+	}
+
+	cc.AppData.OrgStandardNotesSN.ClientUpdatedAt = uTime.Format(common.TimeLayout)
+}
+
+// UnknownItem is an item whose content type is not known to gosn, such as one added by a newer version of
+// the Standard Notes apps. It is passed through so that it is not lost when items are re-encrypted or exported.
+type UnknownItem = TypedItem[UnknownContent, *UnknownContent]
+
+var _ Item = &UnknownItem{}
@@ -2,35 +2,11 @@ package items
 
 import (
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parseUserPreferences(i DecryptedItem) Item {
-	c := UserPreferences{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		c.Content = *content.(*UserPreferencesContent)
-	}
-
-	return &c
-}
-
 func parseSNTime(s string) (t time.Time, err error) {
 	// if no time specified, then return zero time
 	if s == "" {
@@ -55,56 +31,32 @@ type UserPreferencesContent struct {
 	DissociatedItemIds []string               `json:"disassociatedItemIds,omitempty"`
 	AssociatedItemIds  []string               `json:"associatedItemIds,omitempty"`
 	Active             interface{}            `json:"active,omitempty"`
+	Unknown            UnknownFields          `json:"-"`
 }
 
-type UserPreferences struct {
-	ItemCommon
-	Content UserPreferencesContent
-}
+func (cc *UserPreferencesContent) UnmarshalJSON(data []byte) (err error) {
+	type alias UserPreferencesContent
 
-func (c UserPreferences) IsDefault() bool {
-	return false
-}
-
-func (i Items) UserPreferences() (c UserPreferenceses) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeUserPreferences {
-			component := x.(*UserPreferences)
-			c = append(c, *component)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *UserPreferenceses) DeDupe() {
-	var encountered []string
-
-	var deDuped UserPreferenceses
+func (cc UserPreferencesContent) MarshalJSON() ([]byte, error) {
+	type alias UserPreferencesContent
 
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-		encountered = append(encountered, i.UUID)
-	}
+type UserPreferences = TypedItem[UserPreferencesContent, *UserPreferencesContent]
 
-	*c = deDuped
+func (i Items) UserPreferences() (c UserPreferenceses) {
+	return ItemsOfType[UserPreferencesContent](i)
 }
 
 // NewUserPreferences returns an Item of type UserPreferences without content.
 func NewUserPreferences() UserPreferences {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c UserPreferences
-
-	c.ContentType = common.SNItemTypeUserPreferences
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[UserPreferencesContent](common.SNItemTypeUserPreferences)
 }
 
 // NewUserPreferencesContent returns an empty Tag content instance.
@@ -115,120 +67,14 @@ func NewUserPreferencesContent() *UserPreferencesContent {
 	return c
 }
 
-type UserPreferenceses []UserPreferences
-
-func (c UserPreferenceses) Validate() error {
-	var updatedTime time.Time
-
-	var err error
+type UserPreferenceses = TypedItems[UserPreferencesContent, *UserPreferencesContent]
 
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing title: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
+func (cc *UserPreferencesContent) MissingField() string {
+	if cc.Name == "" {
+		return "title"
 	}
 
-	return err
-}
-
-func (c UserPreferences) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *UserPreferences) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c UserPreferences) GetContent() Content {
-	return &c.Content
-}
-
-func (c *UserPreferences) SetContent(cc Content) {
-	c.Content = *cc.(*UserPreferencesContent)
-}
-
-func (c UserPreferences) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c UserPreferences) GetUUID() string {
-	return c.UUID
-}
-
-func (c UserPreferences) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *UserPreferences) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c UserPreferences) GetContentType() string {
-	return c.ContentType
-}
-
-func (c UserPreferences) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *UserPreferences) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c UserPreferences) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *UserPreferences) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c UserPreferences) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *UserPreferences) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c UserPreferences) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *UserPreferences) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *UserPreferences) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c UserPreferences) GetContentSize() int {
-	return c.ContentSize
-}
-
-func (c *UserPreferences) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *UserPreferencesContent) AssociateItems(newItems []string) {
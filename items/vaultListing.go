@@ -2,35 +2,11 @@ package items
 
 import (
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/jonhadfield/gosn-v2/common"
 )
 
-func parseVaultListing(i DecryptedItem) Item {
-	c := VaultListing{}
-
-	if err := populateItemCommon(&c.ItemCommon, i); err != nil {
-		panic(err)
-	}
-
-	var err error
-
-	if !c.Deleted {
-		var content Content
-
-		content, err = processContentModel(i.ContentType, i.Content)
-		if err != nil {
-			panic(err)
-		}
-
-		c.Content = *content.(*VaultListingContent)
-	}
-
-	return &c
-}
-
 // VaultListingContent represents a shared vault listing
 type VaultListingContent struct {
 	SystemIdentifier string         `json:"systemIdentifier"`
@@ -42,56 +18,32 @@ type VaultListingContent struct {
 	Sharing          interface{}    `json:"sharing,omitempty"` // VaultListingSharingInfo
 	ItemReferences   ItemReferences `json:"references"`
 	AppData          AppDataContent `json:"appData"`
+	Unknown          UnknownFields  `json:"-"`
 }
 
-type VaultListing struct {
-	ItemCommon
-	Content VaultListingContent
-}
-
-func (c VaultListing) IsDefault() bool {
-	return false
-}
+func (cc *VaultListingContent) UnmarshalJSON(data []byte) (err error) {
+	type alias VaultListingContent
 
-func (i Items) VaultListings() (c VaultListings) {
-	for _, x := range i {
-		if x.GetContentType() == common.SNItemTypeVaultListing {
-			vaultListing := x.(*VaultListing)
-			c = append(c, *vaultListing)
-		}
-	}
+	cc.Unknown, err = decodeWithUnknown(data, (*alias)(cc))
 
-	return c
+	return err
 }
 
-func (c *VaultListings) DeDupe() {
-	var encountered []string
+func (cc VaultListingContent) MarshalJSON() ([]byte, error) {
+	type alias VaultListingContent
 
-	var deDuped VaultListings
-
-	for _, i := range *c {
-		if !slices.Contains(encountered, i.UUID) {
-			deDuped = append(deDuped, i)
-		}
+	return encodeWithUnknown(alias(cc), cc.Unknown)
+}
 
-		encountered = append(encountered, i.UUID)
-	}
+type VaultListing = TypedItem[VaultListingContent, *VaultListingContent]
 
-	*c = deDuped
+func (i Items) VaultListings() (c VaultListings) {
+	return ItemsOfType[VaultListingContent](i)
 }
 
 // NewVaultListing returns an Item of type VaultListing without content.
 func NewVaultListing() VaultListing {
-	now := time.Now().UTC().Format(common.TimeLayout)
-
-	var c VaultListing
-
-	c.ContentType = common.SNItemTypeVaultListing
-	c.CreatedAt = now
-	c.CreatedAtTimestamp = time.Now().UTC().UnixMicro()
-	c.UUID = GenUUID()
-
-	return c
+	return NewTypedItem[VaultListingContent](common.SNItemTypeVaultListing)
 }
 
 // NewVaultListingContent returns an empty VaultListing content instance.
@@ -102,123 +54,17 @@ func NewVaultListingContent() *VaultListingContent {
 	return c
 }
 
-type VaultListings []VaultListing
-
-func (c VaultListings) Validate() error {
-	var updatedTime time.Time
-
-	var err error
-
-	for _, item := range c {
-		// validate content if being added
-		if !item.Deleted {
-			updatedTime, err = item.Content.GetUpdateTime()
-			if err != nil {
-				return err
-			}
-
-			switch {
-			case item.Content.Name == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing name: \"%s\"",
-					item.ContentType, item.UUID)
-			case item.Content.SystemIdentifier == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing systemIdentifier: \"%s\"",
-					item.ContentType, item.UUID)
-			case updatedTime.IsZero():
-				err = fmt.Errorf("failed to create \"%s\" due to missing content updated time: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			case item.CreatedAt == "":
-				err = fmt.Errorf("failed to create \"%s\" due to missing created at date: \"%s\"",
-					item.ContentType, item.Content.GetTitle())
-			}
-
-			if err != nil {
-				return err
-			}
-		}
-	}
+type VaultListings = TypedItems[VaultListingContent, *VaultListingContent]
 
-	return err
-}
-
-func (c VaultListing) IsDeleted() bool {
-	return c.Deleted
-}
-
-func (c *VaultListing) SetDeleted(d bool) {
-	c.Deleted = d
-}
-
-func (c VaultListing) GetContent() Content {
-	return &c.Content
-}
-
-func (c *VaultListing) SetContent(cc Content) {
-	c.Content = *cc.(*VaultListingContent)
-}
-
-func (c VaultListing) GetItemsKeyID() string {
-	return c.ItemsKeyID
-}
-
-func (c VaultListing) GetUUID() string {
-	return c.UUID
-}
-
-func (c VaultListing) GetDuplicateOf() string {
-	return c.DuplicateOf
-}
-
-func (c *VaultListing) SetUUID(u string) {
-	c.UUID = u
-}
-
-func (c VaultListing) GetContentType() string {
-	return c.ContentType
-}
-
-func (c VaultListing) GetCreatedAt() string {
-	return c.CreatedAt
-}
-
-func (c *VaultListing) SetCreatedAt(ca string) {
-	c.CreatedAt = ca
-}
-
-func (c VaultListing) GetUpdatedAt() string {
-	return c.UpdatedAt
-}
-
-func (c *VaultListing) SetUpdatedAt(ca string) {
-	c.UpdatedAt = ca
-}
-
-func (c VaultListing) GetCreatedAtTimestamp() int64 {
-	return c.CreatedAtTimestamp
-}
-
-func (c *VaultListing) SetCreatedAtTimestamp(ca int64) {
-	c.CreatedAtTimestamp = ca
-}
-
-func (c VaultListing) GetUpdatedAtTimestamp() int64 {
-	return c.UpdatedAtTimestamp
-}
-
-func (c *VaultListing) SetUpdatedAtTimestamp(ca int64) {
-	c.UpdatedAtTimestamp = ca
-}
-
-func (c *VaultListing) SetContentType(ct string) {
-	c.ContentType = ct
-}
-
-func (c VaultListing) GetContentSize() int {
-	return c.ContentSize
-}
+func (cc *VaultListingContent) MissingField() string {
+	switch {
+	case cc.Name == "":
+		return "name"
+	case cc.SystemIdentifier == "":
+		return "systemIdentifier"
+	}
 
-func (c *VaultListing) SetContentSize(s int) {
-	c.ContentSize = s
+	return ""
 }
 
 func (cc *VaultListingContent) GetUpdateTime() (time.Time, error) {
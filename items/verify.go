@@ -0,0 +1,64 @@
+package items
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/crypto"
+)
+
+// Verify checks the structure of an encrypted item and that both its content and
+// enc_item_key are bound to the item by their authenticated data, without decrypting either.
+// Errors returned match crypto.ErrInvalidPayload.
+func (ei EncryptedItem) Verify() error {
+	if ei.UUID == "" {
+		return fmt.Errorf("%w: item is missing uuid", crypto.ErrInvalidPayload)
+	}
+
+	// deleted items are returned by the server without content
+	if ei.Deleted {
+		return nil
+	}
+
+	contentAD, err := crypto.VerifyPayload(ei.Content, ei.UUID)
+	if err != nil {
+		return fmt.Errorf("item %s content | %w", ei.UUID, err)
+	}
+
+	keyAD, err := crypto.VerifyPayload(ei.EncItemKey, ei.UUID)
+	if err != nil {
+		return fmt.Errorf("item %s enc_item_key | %w", ei.UUID, err)
+	}
+
+	if (contentAD.KeyParams == nil) != (keyAD.KeyParams == nil) ||
+		contentAD.KeyParams != nil && *contentAD.KeyParams != *keyAD.KeyParams {
+		return fmt.Errorf("item %s | %w: content and enc_item_key authenticated data differ", ei.UUID, crypto.ErrInvalidPayload)
+	}
+
+	switch ei.ContentType {
+	case common.SNItemTypeItemsKey:
+		if contentAD.KeyParams == nil {
+			return fmt.Errorf("item %s | %w: items key authenticated data is missing key params", ei.UUID, crypto.ErrInvalidPayload)
+		}
+	default:
+		if ei.ItemsKeyID == "" && ei.KeySystemIdentifier == nil {
+			return fmt.Errorf("item %s | %w: item is missing items key id", ei.UUID, crypto.ErrInvalidPayload)
+		}
+	}
+
+	return nil
+}
+
+// Verify checks each encrypted item and returns the errors for any that fail verification.
+func (ei EncryptedItems) Verify() error {
+	var errs []error
+
+	for _, e := range ei {
+		if err := e.Verify(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
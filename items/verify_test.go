@@ -0,0 +1,94 @@
+package items
+
+import (
+	"testing"
+
+	"github.com/jonhadfield/gosn-v2/crypto"
+	"github.com/jonhadfield/gosn-v2/crypto/vectors"
+	"github.com/jonhadfield/gosn-v2/session"
+	"github.com/stretchr/testify/require"
+)
+
+// mustSecretKey decodes a hex encoded test key.
+func mustSecretKey(hexKey string) crypto.SecretKey {
+	k, err := crypto.NewSecretKey(hexKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return k
+}
+
+func vectorEncryptedItems() EncryptedItems {
+	var eis EncryptedItems
+
+	for _, v := range vectors.Items004 {
+		eis = append(eis, EncryptedItem{
+			UUID:        v.UUID,
+			ContentType: v.ContentType,
+			ItemsKeyID:  v.ItemsKeyID,
+			EncItemKey:  v.EncItemKey,
+			Content:     v.Content,
+		})
+	}
+
+	return eis
+}
+
+func TestVerifyEncryptedItems(t *testing.T) {
+	eis := vectorEncryptedItems()
+	require.NoError(t, eis.Verify())
+
+	deleted := EncryptedItem{UUID: "a", ContentType: "Note", Deleted: true}
+	require.NoError(t, deleted.Verify())
+}
+
+func TestVerifyEncryptedItemFailures(t *testing.T) {
+	eis := vectorEncryptedItems()
+	itemsKey, note := eis[0], eis[1]
+
+	swapped := note
+	swapped.Content = itemsKey.Content
+
+	mismatched := note
+	mismatched.UUID = itemsKey.UUID
+
+	missingKeyID := note
+	missingKeyID.ItemsKeyID = ""
+
+	mixedKey := itemsKey
+	mixedKey.EncItemKey = note.EncItemKey
+
+	for name, ei := range map[string]EncryptedItem{
+		"content from another item": swapped,
+		"uuid not bound":            mismatched,
+		"missing items key id":      missingKeyID,
+		"mixed authenticated data":  mixedKey,
+		"missing uuid":              {ContentType: "Note"},
+	} {
+		require.ErrorIs(t, ei.Verify(), crypto.ErrInvalidPayload, name)
+	}
+
+	require.ErrorIs(t, EncryptedItems{itemsKey, swapped}.Verify(), crypto.ErrInvalidPayload)
+}
+
+func TestDecryptVectorItems(t *testing.T) {
+	eis := vectorEncryptedItems()
+
+	for x, v := range vectors.Items004 {
+		if v.ItemsKeyID == "" {
+			continue
+		}
+
+		ik := session.SessionItemsKey{UUID: v.ItemsKeyID, ItemsKey: mustSecretKey(v.Key)}
+		s := &session.Session{ItemsKeys: []session.SessionItemsKey{ik}, DefaultItemsKey: ik}
+
+		di, err := DecryptItem(eis[x], s, s.ItemsKeys)
+		require.NoError(t, err, v.Description)
+		require.Equal(t, v.PlainText, di.Content, v.Description)
+
+		i, err := ParseItem(di)
+		require.NoError(t, err, v.Description)
+		require.Equal(t, v.UUID, i.GetUUID(), v.Description)
+	}
+}
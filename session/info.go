@@ -0,0 +1,184 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/jonhadfield/gosn-v2/common"
+	"github.com/jonhadfield/gosn-v2/log"
+	"github.com/zalando/go-keyring"
+)
+
+// AuthMode describes how a session's tokens are presented to the server.
+type AuthMode string
+
+const (
+	AuthModeToken  AuthMode = "token"  // tokens sent in the Authorization header only
+	AuthModeCookie AuthMode = "cookie" // tokens sent with their accompanying cookies
+)
+
+// ErrSessionRejected is returned by Validate when the server no longer accepts the session's tokens.
+var ErrSessionRejected = errors.New("session rejected by server")
+
+// SessionInfo is a machine-readable summary of a session's state.
+type SessionInfo struct {
+	Server              string    `json:"server"`
+	Identifier          string    `json:"identifier"`
+	ProtocolVersion     string    `json:"protocol_version"`
+	AccessExpiration    time.Time `json:"access_expiration"`
+	RefreshExpiration   time.Time `json:"refresh_expiration"`
+	EncryptedAtRest     bool      `json:"encrypted_at_rest"`
+	AuthMode            AuthMode  `json:"auth_mode"`
+	ItemsKeys           int       `json:"items_keys"`
+	DefaultItemsKeyUUID string    `json:"default_items_key_uuid,omitempty"`
+	ReadOnly            bool      `json:"read_only"`
+}
+
+// AccessExpired returns true if the access token has expired at the time specified.
+func (i SessionInfo) AccessExpired(now time.Time) bool {
+	return !now.Before(i.AccessExpiration)
+}
+
+// RefreshExpired returns true if the refresh token has expired at the time specified,
+// meaning the session can no longer be renewed and the user must sign in again.
+func (i SessionInfo) RefreshExpired(now time.Time) bool {
+	return !now.Before(i.RefreshExpiration)
+}
+
+// expirationTime converts a millisecond expiration timestamp returned by the server.
+func expirationTime(ms int64) time.Time {
+	return time.UnixMilli(ms).UTC()
+}
+
+// IsCookieBased returns true if the session's tokens are cookie based, which is the case for tokens
+// starting with "2:".
+func (sess *Session) IsCookieBased() bool {
+	parts := strings.Split(sess.AccessToken, ":")
+
+	return len(parts) >= 2 && parts[0] == "2"
+}
+
+// SetAuthHeaders sets the headers that authenticate a request with the session's access token. Cookie based
+// sessions also send the access token cookie, which is set manually as Go's cookie jar does not handle its
+// Partitioned attribute.
+func (sess *Session) SetAuthHeaders(h http.Header) {
+	h.Set("Authorization", "Bearer "+sess.AccessToken)
+
+	if sess.IsCookieBased() && sess.AccessTokenCookie != "" {
+		h.Set("Cookie", sess.AccessTokenCookie)
+	}
+}
+
+func (sess *Session) authMode() AuthMode {
+	if sess.IsCookieBased() {
+		return AuthModeCookie
+	}
+
+	return AuthModeToken
+}
+
+// Info returns a summary of the session's state.
+// EncryptedAtRest is only known for sessions loaded from the keyring, so is set by GetSessionInfo.
+func (sess *Session) Info() SessionInfo {
+	server := sess.Server
+	if server == "" {
+		server = common.APIServer
+	}
+
+	info := SessionInfo{
+		Server:            server,
+		Identifier:        sess.KeyParams.Identifier,
+		ProtocolVersion:   sess.KeyParams.Version,
+		AccessExpiration:  expirationTime(sess.AccessExpiration),
+		RefreshExpiration: expirationTime(sess.RefreshExpiration),
+		AuthMode:          sess.authMode(),
+		ItemsKeys:         len(sess.ItemsKeys),
+		ReadOnly:          sess.IsReadOnly(),
+	}
+
+	if sess.DefaultItemsKey.UUID != "" {
+		info.DefaultItemsKeyUUID = sess.DefaultItemsKey.UUID
+	} else {
+		for _, ik := range sess.ItemsKeys {
+			if ik.Default {
+				info.DefaultItemsKeyUUID = ik.UUID
+
+				break
+			}
+		}
+	}
+
+	return info
+}
+
+// GetSessionInfo returns a summary of the session stored in the keyring.
+// Items keys are only retrieved on sync, so ItemsKeys will be zero for a stored session.
+func GetSessionInfo(sKey string, k keyring.Keyring) (SessionInfo, error) {
+	session, encrypted, err := getStoredSessionContent(sKey, k)
+	if err != nil {
+		return SessionInfo{}, err
+	}
+
+	s, err := ParseSessionString(session)
+	if err != nil {
+		return SessionInfo{}, fmt.Errorf("failed to parse Session: %w", err)
+	}
+
+	info := s.Info()
+	info.EncryptedAtRest = encrypted
+
+	return info, nil
+}
+
+// Validate checks the session's tokens are accepted by the server without syncing any items.
+// ErrSessionRejected is returned if the server responds that the session is no longer valid.
+func (sess *Session) Validate() error {
+	if !sess.Valid() {
+		return errors.New("session is incomplete")
+	}
+
+	if sess.HTTPClient == nil {
+		sess.HTTPClient = common.NewHTTPClient()
+	}
+
+	server := sess.Server
+	if server == "" {
+		server = common.APIServer
+	}
+
+	req, err := retryablehttp.NewRequest(http.MethodGet, server+common.SessionsPath, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(common.HeaderContentType, common.SNAPIContentType)
+	sess.SetAuthHeaders(req.Header)
+
+	start := time.Now()
+	resp, err := sess.HTTPClient.Do(req)
+	log.DebugPrint(sess.Debug, fmt.Sprintf("validate session | request took: %+v", time.Since(start)), common.MaxDebugChars)
+
+	if err != nil {
+		return fmt.Errorf("Validate | %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: %s", ErrSessionRejected, resp.Status)
+	case resp.StatusCode >= http.StatusBadRequest:
+		return fmt.Errorf("Validate | unexpected response: %s", resp.Status)
+	}
+
+	return nil
+}
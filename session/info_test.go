@@ -0,0 +1,105 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSessionInfo(t *testing.T) {
+	info, err := GetSessionInfo("", MockKeyRingDefined{})
+	require.NoError(t, err)
+	require.Equal(t, "http://ramea:3000", info.Server)
+	require.Equal(t, "ramea@lessknown.co.uk", info.Identifier)
+	require.Equal(t, "004", info.ProtocolVersion)
+	require.Equal(t, time.UnixMilli(1698262966000).UTC(), info.AccessExpiration)
+	require.Equal(t, time.UnixMilli(1724635892000).UTC(), info.RefreshExpiration)
+	require.False(t, info.EncryptedAtRest)
+	require.Equal(t, AuthModeToken, info.AuthMode)
+	require.Zero(t, info.ItemsKeys)
+	require.False(t, info.ReadOnly)
+	require.True(t, info.AccessExpired(time.UnixMilli(1698262966000)))
+	require.False(t, info.RefreshExpired(time.UnixMilli(1698262966000)))
+}
+
+func TestGetSessionInfoInvalidSession(t *testing.T) {
+	_, err := GetSessionInfo("", MockKeyRingDodgy{})
+	require.Error(t, err)
+}
+
+func TestSessionInfo(t *testing.T) {
+	s := Session{
+		AccessToken:    "2:7d90df15-7d74-46e0-a7c6-4cfa1b70f42e:ODAyZWRlMTg2ZjM5",
+		ReadOnlyAccess: true,
+		ItemsKeys: []SessionItemsKey{
+			{UUID: "a"},
+			{UUID: "b", Default: true},
+		},
+	}
+
+	info := s.Info()
+	require.Equal(t, SNServerURL, info.Server)
+	require.Equal(t, AuthModeCookie, info.AuthMode)
+	require.Equal(t, 2, info.ItemsKeys)
+	require.Equal(t, "b", info.DefaultItemsKeyUUID)
+	require.True(t, info.ReadOnly)
+
+	s.DefaultItemsKey = SessionItemsKey{UUID: "c"}
+	require.Equal(t, "c", s.Info().DefaultItemsKeyUUID)
+}
+
+func validTestSession(server string) Session {
+	return Session{
+		Server:            server,
+		MasterKey:         mustSecretKey("03f7f410be71838897d35cacec799503355d486a0ef4a1e3e5f64abf262a640f"),
+		AccessToken:       "1:7d90df15-7d74-46e0-a7c6-4cfa1b70f42e:ODAyZWRlMTg2ZjM5",
+		RefreshToken:      "1:7d90df15-7d74-46e0-a7c6-4cfa1b70f42e:YTU4NWY3MmIxMmIz",
+		AccessExpiration:  1698262966000,
+		RefreshExpiration: 1724635892000,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	var authHeader string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+
+		if r.URL.Path != "/v1/sessions" || authHeader != "Bearer 1:7d90df15-7d74-46e0-a7c6-4cfa1b70f42e:ODAyZWRlMTg2ZjM5" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer ts.Close()
+
+	s := validTestSession(ts.URL)
+	require.NoError(t, s.Validate())
+	require.NotEmpty(t, authHeader)
+
+	s.AccessToken = "1:7d90df15-7d74-46e0-a7c6-4cfa1b70f42e:ZXhwaXJlZA"
+	require.ErrorIs(t, s.Validate(), ErrSessionRejected)
+}
+
+func TestValidateIncompleteSession(t *testing.T) {
+	s := Session{Server: "http://localhost:0"}
+	require.Error(t, s.Validate())
+}
+
+func TestSetAuthHeaders(t *testing.T) {
+	h := http.Header{}
+	(&Session{AccessToken: "1:token", AccessTokenCookie: "access_token=c"}).SetAuthHeaders(h)
+	require.Equal(t, "Bearer 1:token", h.Get("Authorization"))
+	require.Empty(t, h.Get("Cookie"))
+
+	// cookie based sessions also send the cookie
+	h = http.Header{}
+	(&Session{AccessToken: "2:token", AccessTokenCookie: "access_token=c"}).SetAuthHeaders(h)
+	require.Equal(t, "Bearer 2:token", h.Get("Authorization"))
+	require.Equal(t, "access_token=c", h.Get("Cookie"))
+}
@@ -0,0 +1,139 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jonhadfield/gosn-v2/crypto"
+)
+
+var (
+	// ErrSessionLocked is returned when keys are required from a session that has been locked.
+	ErrSessionLocked = errors.New("session is locked")
+	// ErrIncorrectPassword is returned when a session cannot be unlocked with the password provided.
+	ErrIncorrectPassword = errors.New("incorrect password")
+)
+
+// sealedKeys holds a locked session's items keys, encrypted with its master key.
+type sealedKeys struct {
+	nonce      string
+	cipherText string
+}
+
+type lockedKeys struct {
+	ItemsKeys       []SessionItemsKey `json:"itemsKeys"`
+	DefaultItemsKey SessionItemsKey   `json:"defaultItemsKey"`
+}
+
+func (sess *Session) lockAuthData() string {
+	return "gosn-v2 locked session:" + sess.KeyParams.Identifier
+}
+
+// IsLocked returns true if the session's keys have been removed by Lock.
+func (sess *Session) IsLocked() bool {
+	return sess != nil && sess.locked != nil
+}
+
+// Lock removes the master key and items keys from the session until Unlock is called with the account password.
+// The items keys are retained only in encrypted form and the buffers holding the keys are zeroed, so any copies
+// of the session's keys, such as items keys passed to other sessions, are wiped too.
+func (sess *Session) Lock() error {
+	if sess.IsLocked() {
+		return nil
+	}
+
+	if len(sess.MasterKey) == 0 {
+		return errors.New("session has no master key to lock")
+	}
+
+	plainText, err := json.Marshal(lockedKeys{
+		ItemsKeys:       sess.ItemsKeys,
+		DefaultItemsKey: sess.DefaultItemsKey,
+	})
+	if err != nil {
+		return fmt.Errorf("Lock | %w", err)
+	}
+
+	defer clear(plainText)
+
+	nonce, err := crypto.XChaCha20Provider{}.GenerateNonce()
+	if err != nil {
+		return fmt.Errorf("Lock | %w", err)
+	}
+
+	cipherText, err := crypto.EncryptWithKey(plainText, sess.MasterKey, nonce, sess.lockAuthData())
+	if err != nil {
+		return fmt.Errorf("Lock | %w", err)
+	}
+
+	sess.locked = &sealedKeys{nonce: nonce, cipherText: cipherText}
+
+	sess.MasterKey.Destroy()
+
+	for x := range sess.ItemsKeys {
+		sess.ItemsKeys[x].ItemsKey.Destroy()
+	}
+
+	sess.DefaultItemsKey.ItemsKey.Destroy()
+
+	sess.MasterKey = nil
+	sess.ItemsKeys = nil
+	sess.DefaultItemsKey = SessionItemsKey{}
+
+	return nil
+}
+
+// deriveMasterKey derives the master key from the account password using the session's key params.
+func (sess *Session) deriveMasterKey(password string) (crypto.SecretKey, error) {
+	nonce := sess.KeyParams.PwNonce
+	if nonce == "" {
+		nonce = sess.PasswordNonce
+	}
+
+	mk, sp, err := sess.CryptoProvider().DeriveKeys(crypto.GenerateEncryptedPasswordInput{
+		UserPassword:  password,
+		Identifier:    sess.KeyParams.Identifier,
+		PasswordNonce: nonce,
+		Debug:         sess.Debug,
+	})
+	sp.Destroy()
+
+	return mk, err
+}
+
+// Unlock restores the keys removed by Lock. ErrIncorrectPassword is returned if the
+// password does not derive the master key the session was locked with.
+func (sess *Session) Unlock(password string) error {
+	if !sess.IsLocked() {
+		return nil
+	}
+
+	mk, err := sess.deriveMasterKey(password)
+	if err != nil {
+		return fmt.Errorf("Unlock | %w", err)
+	}
+
+	plainText, err := crypto.DecryptWithKey(sess.locked.cipherText, mk, sess.locked.nonce, sess.lockAuthData())
+	if err != nil {
+		mk.Destroy()
+
+		return ErrIncorrectPassword
+	}
+
+	defer clear(plainText)
+
+	var keys lockedKeys
+	if err = json.Unmarshal(plainText, &keys); err != nil {
+		mk.Destroy()
+
+		return fmt.Errorf("Unlock | %w", err)
+	}
+
+	sess.MasterKey = mk
+	sess.ItemsKeys = keys.ItemsKeys
+	sess.DefaultItemsKey = keys.DefaultItemsKey
+	sess.locked = nil
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/jonhadfield/gosn-v2/auth"
+	"github.com/jonhadfield/gosn-v2/crypto"
+	"github.com/jonhadfield/gosn-v2/crypto/vectors"
+	"github.com/stretchr/testify/require"
+)
+
+func mustSecretKey(hexKey string) crypto.SecretKey {
+	k, err := crypto.NewSecretKey(hexKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return k
+}
+
+func lockTestSession() Session {
+	v := vectors.KeyDerivation004[0]
+	ik := SessionItemsKey{UUID: "a", ItemsKey: mustSecretKey(vectors.Items004[1].Key), Default: true}
+
+	return Session{
+		MasterKey: mustSecretKey(v.MasterKey),
+		KeyParams: auth.KeyParams{
+			Identifier: v.Identifier,
+			PwNonce:    v.PasswordNonce,
+			Version:    "004",
+		},
+		ItemsKeys:       []SessionItemsKey{ik},
+		DefaultItemsKey: ik,
+	}
+}
+
+func TestLockUnlock(t *testing.T) {
+	s := lockTestSession()
+	original := lockTestSession()
+	mk := s.MasterKey
+	ik := s.ItemsKeys[0].ItemsKey
+
+	require.False(t, s.IsLocked())
+	require.NoError(t, s.Lock())
+	require.True(t, s.IsLocked())
+	// the key material itself is wiped, not just removed from the session
+	require.True(t, mk.Destroyed())
+	require.True(t, ik.Destroyed())
+	require.Empty(t, s.MasterKey)
+	require.Empty(t, s.ItemsKeys)
+	require.Empty(t, s.DefaultItemsKey.ItemsKey)
+	require.False(t, s.Valid())
+
+	// locking twice is a no-op
+	require.NoError(t, s.Lock())
+
+	require.ErrorIs(t, s.Unlock("wrong password"), ErrIncorrectPassword)
+	require.True(t, s.IsLocked())
+
+	require.NoError(t, s.Unlock(vectors.KeyDerivation004[0].Password))
+	require.False(t, s.IsLocked())
+	require.Equal(t, original.MasterKey, s.MasterKey)
+	require.Equal(t, original.ItemsKeys, s.ItemsKeys)
+	require.Equal(t, original.DefaultItemsKey, s.DefaultItemsKey)
+
+	// unlocking an unlocked session is a no-op
+	require.NoError(t, s.Unlock("wrong password"))
+}
+
+func TestLockWithoutMasterKey(t *testing.T) {
+	s := Session{}
+	require.Error(t, s.Lock())
+	require.False(t, s.IsLocked())
+
+	var nilSession *Session
+	require.False(t, nilSession.IsLocked())
+}
@@ -12,7 +12,7 @@ func TestParseSessionStringValid(t *testing.T) {
 	sess, err := ParseSessionString(sessionString)
 	require.NoError(t, err)
 	require.Equal(t, "http://ramea:3000", sess.Server)
-	require.Equal(t, "5319f9c148ee3dbe78fc149e8643775242d7e83216060ee5e228ab2ec3d88a76", sess.MasterKey)
+	require.Equal(t, "5319f9c148ee3dbe78fc149e8643775242d7e83216060ee5e228ab2ec3d88a76", sess.MasterKey.Hex())
 	require.Equal(t, int64(1648647400000), sess.AccessExpiration)
 }
 
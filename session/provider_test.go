@@ -0,0 +1,45 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/jonhadfield/gosn-v2/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type testProvider struct {
+	crypto.XChaCha20Provider
+	version string
+}
+
+func (p testProvider) Version() string {
+	return p.version
+}
+
+func TestCryptoProvider(t *testing.T) {
+	var nilSession *Session
+	require.Equal(t, crypto.DefaultProvider, nilSession.CryptoProvider())
+
+	s := Session{}
+	require.Equal(t, crypto.DefaultProvider, s.CryptoProvider())
+
+	p := testProvider{version: "004"}
+	s.Crypto = p
+	require.Equal(t, p, s.CryptoProvider())
+}
+
+func TestCryptoProviderFor(t *testing.T) {
+	s := Session{Crypto: testProvider{version: "005"}}
+
+	p, err := s.CryptoProviderFor("005")
+	require.NoError(t, err)
+	require.Equal(t, s.Crypto, p)
+
+	// payloads of other versions are decrypted by the registered provider
+	p, err = s.CryptoProviderFor("004")
+	require.NoError(t, err)
+	require.Equal(t, crypto.DefaultProvider, p)
+
+	_, err = s.CryptoProviderFor("999")
+	require.Error(t, err)
+}
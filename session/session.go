@@ -35,16 +35,42 @@ const (
 	RefreshSessionThreshold  = 10 * time.Minute
 )
 
+// ErrReadOnlySession is matched by errors returned when an attempt is made to
+// write items using a session without write access.
+var ErrReadOnlySession = errors.New("session is read-only")
+
+// ReadOnlyError is returned when items cannot be written because the session is read-only,
+// either as reported by the server or because the caller opened the session in read-only mode.
+type ReadOnlyError struct {
+	// Requested is true if read-only mode was requested by the caller rather than imposed by the server
+	Requested bool
+	// Items is the number of items that were not written
+	Items int
+}
+
+func (e *ReadOnlyError) Error() string {
+	reason := "server granted read-only access"
+	if e.Requested {
+		reason = "read-only mode requested"
+	}
+
+	return fmt.Sprintf("%s: refusing to write %d items as %s", ErrReadOnlySession, e.Items, reason)
+}
+
+func (e *ReadOnlyError) Is(target error) bool {
+	return target == ErrReadOnlySession
+}
+
 type SessionItemsKey struct {
-	UUID               string `json:"uuid"`
-	ItemsKey           string `json:"itemsKey"`
-	Version            string `json:"version"`
-	Default            bool   `json:"isDefault"`
-	CreatedAt          string `json:"created_at"`
-	UpdatedAt          string `json:"updated_at"`
-	CreatedAtTimestamp int64  `json:"created_at_timestamp"`
-	UpdatedAtTimestamp int64  `json:"updated_at_timestamp"`
-	Deleted            bool   `json:"deleted"`
+	UUID               string           `json:"uuid"`
+	ItemsKey           crypto.SecretKey `json:"itemsKey"`
+	Version            string           `json:"version"`
+	Default            bool             `json:"isDefault"`
+	CreatedAt          string           `json:"created_at"`
+	UpdatedAt          string           `json:"updated_at"`
+	CreatedAtTimestamp int64            `json:"created_at_timestamp"`
+	UpdatedAtTimestamp int64            `json:"updated_at_timestamp"`
+	Deleted            bool             `json:"deleted"`
 	// Note: ItemReferences and AppData are typically empty for ItemsKeys
 	// but could be added if needed in the future
 }
@@ -61,9 +87,9 @@ type SessionItemsKey struct {
 //   - Connection pool state can be corrupted
 //
 // Safe concurrent usage patterns:
-//   1. Create separate Session instances for each goroutine
-//   2. Use mutex to serialize access to shared Session
-//   3. Never share HTTPClient with cookie jar across goroutines
+//  1. Create separate Session instances for each goroutine
+//  2. Use mutex to serialize access to shared Session
+//  3. Never share HTTPClient with cookie jar across goroutines
 //
 // See claudedocs/thread_safety.md for detailed guidance and examples.
 type Session struct {
@@ -73,7 +99,7 @@ type Session struct {
 	Server           string
 	FilesServerUrl   string `json:"filesServerUrl"`
 	Token            string
-	MasterKey        string
+	MasterKey        crypto.SecretKey
 	ItemsKeys        []SessionItemsKey
 	// ImporterItemsKeys is the key used to encrypt exported items and set during import only
 	// ImporterItemsKeys []SessionItemsKey
@@ -83,23 +109,30 @@ type Session struct {
 	RefreshToken      string         `json:"refresh_token"`
 	AccessExpiration  int64          `json:"access_expiration"`
 	RefreshExpiration int64          `json:"refresh_expiration"`
-	ReadOnlyAccess     bool           `json:"readonly_access"`
-	PasswordNonce      string
-	Schemas            map[string]*jsonschema.Schema
+	ReadOnlyAccess    bool           `json:"readonly_access"`
+	// ReadOnly is set by the caller to prevent any items being written, regardless of server access
+	ReadOnly      bool `json:"-"`
+	PasswordNonce string
+	Schemas       map[string]*jsonschema.Schema
 	// Cookie values extracted from Set-Cookie headers for manual cookie handling
 	AccessTokenCookie  string `json:"access_token_cookie,omitempty"`
 	RefreshTokenCookie string `json:"refresh_token_cookie,omitempty"`
+	// Crypto performs all encryption for this session, crypto.DefaultProvider is used if nil
+	Crypto crypto.Provider `json:"-"`
+	// locked holds the encrypted items keys while the session is locked
+	locked *sealedKeys
 }
 
 type MinimalSession struct {
 	Server             string
 	Token              string
-	MasterKey          string
+	MasterKey          crypto.SecretKey
 	KeyParams          auth.KeyParams `json:"keyParams"`
 	AccessToken        string         `json:"access_token"`
 	RefreshToken       string         `json:"refresh_token"`
 	AccessExpiration   int64          `json:"access_expiration"`
 	RefreshExpiration  int64          `json:"refresh_expiration"`
+	ReadOnlyAccess     bool           `json:"readonly_access,omitempty"`
 	SchemaValidation   bool
 	AccessTokenCookie  string `json:"access_token_cookie,omitempty"`
 	RefreshTokenCookie string `json:"refresh_token_cookie,omitempty"`
@@ -332,6 +365,7 @@ func makeMinimalSessionString(s Session) string {
 		RefreshToken:       s.RefreshToken,
 		AccessExpiration:   s.AccessExpiration,
 		RefreshExpiration:  s.RefreshExpiration,
+		ReadOnlyAccess:     s.ReadOnlyAccess,
 		SchemaValidation:   s.SchemaValidation,
 		AccessTokenCookie:  s.AccessTokenCookie,
 		RefreshTokenCookie: s.RefreshTokenCookie,
@@ -534,6 +568,10 @@ func GetSession(httpClient *retryablehttp.Client, loadSession bool, sessionKey,
 
 	session.Debug = debug
 
+	if slices.Contains([]string{"yes", "true", "1"}, os.Getenv(common.EnvReadOnly)) {
+		session.ReadOnly = true
+	}
+
 	if slices.Contains([]string{"yes", "true", "1"}, os.Getenv(common.EnvSchemaValidation)) {
 		session.SchemaValidation = true
 
@@ -559,6 +597,7 @@ func ParseSessionString(ss string) (Session, error) {
 		AccessExpiration:   ms.AccessExpiration,
 		RefreshToken:       ms.RefreshToken,
 		RefreshExpiration:  ms.RefreshExpiration,
+		ReadOnlyAccess:     ms.ReadOnlyAccess,
 		MasterKey:          ms.MasterKey,
 		KeyParams:          ms.KeyParams,
 		PasswordNonce:      ms.KeyParams.PwNonce,
@@ -605,7 +644,8 @@ func getSessionContent(key, rawSession string) (session string, err error) {
 	return session, err
 }
 
-func SessionStatus(sKey string, k keyring.Keyring) (msg string, err error) {
+// getStoredSessionContent returns the decrypted session stored in the keyring and whether it was encrypted at rest.
+func getStoredSessionContent(sKey string, k keyring.Keyring) (session string, encrypted bool, err error) {
 	var rawSession string
 
 	rawSession, err = GetSessionFromKeyring(k)
@@ -614,12 +654,13 @@ func SessionStatus(sKey string, k keyring.Keyring) (msg string, err error) {
 	}
 
 	if len(rawSession) == 0 {
-		return "", errors.New("keyring is empty")
+		return "", false, errors.New("keyring is empty")
 	}
+
+	encrypted = !isUnencryptedSession(rawSession)
+
 	// now decrypt if needed
-	var session string
 	session, err = getSessionContent(sKey, rawSession)
-
 	if err != nil {
 		if strings.Contains(err.Error(), "illegal base64") {
 			err = errors.New("stored Session is corrupt")
@@ -634,7 +675,14 @@ func SessionStatus(sKey string, k keyring.Keyring) (msg string, err error) {
 		} else {
 			err = fmt.Errorf("stored session is invalid and needs to be replaced, or is encrypted and requires a key to unlock")
 		}
+	}
 
+	return
+}
+
+func SessionStatus(sKey string, k keyring.Keyring) (msg string, err error) {
+	session, _, err := getStoredSessionContent(sKey, k)
+	if err != nil {
 		return
 	}
 
@@ -732,6 +780,47 @@ func createHTTPClient() *http.Client {
 	}
 }
 
+// IsReadOnly returns true if items must not be written using this session, either
+// because the server granted read-only access or the caller requested read-only mode.
+func (sess *Session) IsReadOnly() bool {
+	if sess == nil {
+		return false
+	}
+
+	return sess.ReadOnlyAccess || sess.ReadOnly
+}
+
+// CheckWritable returns a ReadOnlyError if the session cannot be used to write the specified number of items.
+func (sess *Session) CheckWritable(items int) error {
+	if items == 0 || !sess.IsReadOnly() {
+		return nil
+	}
+
+	return &ReadOnlyError{
+		Requested: sess.ReadOnly && !sess.ReadOnlyAccess,
+		Items:     items,
+	}
+}
+
+// CryptoProvider returns the Provider used to encrypt items with this session.
+func (sess *Session) CryptoProvider() crypto.Provider {
+	if sess == nil || sess.Crypto == nil {
+		return crypto.DefaultProvider
+	}
+
+	return sess.Crypto
+}
+
+// CryptoProviderFor returns the Provider used to decrypt payloads of the specified protocol version,
+// preferring the session's own Provider where it implements that version.
+func (sess *Session) CryptoProviderFor(version string) (crypto.Provider, error) {
+	if p := sess.CryptoProvider(); p.Version() == version {
+		return p, nil
+	}
+
+	return crypto.GetProvider(version)
+}
+
 func (sess *Session) Valid() bool {
 	if sess == nil {
 		fmt.Print("session is nil\n")
@@ -745,7 +834,7 @@ func (sess *Session) Valid() bool {
 	case sess.AccessToken == "":
 		log.DebugPrint(sess.Debug, "session is missing access token", common.MaxDebugChars)
 		return false
-	case sess.MasterKey == "":
+	case len(sess.MasterKey) == 0:
 		log.DebugPrint(sess.Debug, "session is missing master key", common.MaxDebugChars)
 		return false
 	case sess.AccessExpiration == 0:
@@ -197,3 +197,53 @@ func TestAddSessionWithoutExistingEnvVars(t *testing.T) {
 	_, err := AddSession(nil, serverURL, "", MockKeyRingUnDefined{}, true)
 	require.NoError(t, err)
 }
+
+func TestIsReadOnly(t *testing.T) {
+	var nilSession *Session
+	require.False(t, nilSession.IsReadOnly())
+
+	s := Session{}
+	require.False(t, s.IsReadOnly())
+	require.NoError(t, s.CheckWritable(1))
+
+	s.ReadOnly = true
+	require.True(t, s.IsReadOnly())
+
+	s = Session{ReadOnlyAccess: true}
+	require.True(t, s.IsReadOnly())
+}
+
+func TestCheckWritable(t *testing.T) {
+	s := Session{ReadOnly: true}
+
+	// nothing to write is always permitted
+	require.NoError(t, s.CheckWritable(0))
+
+	err := s.CheckWritable(3)
+	require.ErrorIs(t, err, ErrReadOnlySession)
+
+	var roErr *ReadOnlyError
+	require.ErrorAs(t, err, &roErr)
+	require.True(t, roErr.Requested)
+	require.Equal(t, 3, roErr.Items)
+	require.Contains(t, err.Error(), "read-only mode requested")
+
+	s = Session{ReadOnlyAccess: true, ReadOnly: true}
+	require.ErrorAs(t, s.CheckWritable(1), &roErr)
+	require.False(t, roErr.Requested)
+	require.Contains(t, roErr.Error(), "server granted read-only access")
+}
+
+func TestReadOnlyAccessSurvivesSessionString(t *testing.T) {
+	s := Session{
+		Server:         "http://ramea:3000",
+		ReadOnlyAccess: true,
+		ReadOnly:       true,
+	}
+
+	ps, err := ParseSessionString(makeMinimalSessionString(s))
+	require.NoError(t, err)
+	require.True(t, ps.ReadOnlyAccess)
+	// read-only mode is requested per use so is not persisted
+	require.False(t, ps.ReadOnly)
+}